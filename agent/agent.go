@@ -9,10 +9,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/PipeOpsHQ/agent-sdk-go/cost"
 	"github.com/PipeOpsHQ/agent-sdk-go/delivery"
 	"github.com/PipeOpsHQ/agent-sdk-go/llm"
 	"github.com/PipeOpsHQ/agent-sdk-go/observe"
 	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/tokenizer"
 	"github.com/PipeOpsHQ/agent-sdk-go/tools"
 	"github.com/PipeOpsHQ/agent-sdk-go/types"
 	"github.com/google/uuid"
@@ -36,6 +38,17 @@ type Agent struct {
 	conversationHistory []types.Message
 	contextManager      *ContextManager
 	responseSchema      map[string]any
+	generation          generationSettings
+	pricing             *cost.Table
+	budget              cost.Budget
+	ledger              cost.Ledger
+	sessionMemory       *SessionMemory
+	summarizer          Summarizer
+	tokenizer           tokenizer.Tokenizer
+	approval            *ApprovalPolicy
+	validateToolArgs    bool
+	runs                *RunRegistry
+	outputBudget        *ToolOutputBudget
 
 	mu        sync.RWMutex
 	tools     map[string]tools.Tool
@@ -113,6 +126,13 @@ func WithParallelToolCalls(enabled bool) Option {
 	return func(a *Agent) { a.parallelTools = enabled }
 }
 
+// WithToolArgumentValidation controls whether tool call arguments are
+// repaired and checked against the tool's JSON schema before execution.
+// It is enabled by default.
+func WithToolArgumentValidation(enabled bool) Option {
+	return func(a *Agent) { a.validateToolArgs = enabled }
+}
+
 func WithMaxParallelTools(max int) Option {
 	return func(a *Agent) {
 		if max > 0 {
@@ -198,15 +218,36 @@ func New(provider llm.Provider, opts ...Option) (*Agent, error) {
 		executionMode:    ExecutionModeLocal,
 		maxIterations:    6,
 		maxParallelTools: 10,
+		validateToolArgs: true,
+		runs:             DefaultRunRegistry,
 		maxInputTokens:   DefaultMaxInputTokens,
 		tools:            make(map[string]tools.Tool),
 		retryPolicy:      defaultRetryPolicy(),
 		contextManager:   NewContextManager(DefaultMaxInputTokens),
+		pricing:          cost.DefaultTable(),
+		ledger:           cost.NewMemoryLedger(),
 	}
 	for _, opt := range opts {
 		opt(a)
 	}
 	a.retryPolicy = normalizeRetryPolicy(a.retryPolicy)
+	if a.summarizer != nil {
+		a.contextManager.SetSummarizer(a.summarizer)
+	}
+	if a.tokenizer != nil {
+		a.contextManager.SetTokenizer(a.tokenizer)
+	}
+	if a.outputBudget != nil {
+		a.addArtifactReader()
+	}
+	if a.approval != nil {
+		if a.store == nil {
+			return nil, errors.New("tool approval requires a store")
+		}
+		if err := a.approval.compile(); err != nil {
+			return nil, err
+		}
+	}
 	return a, nil
 }
 
@@ -233,13 +274,14 @@ func (a *Agent) RunLiteDetailed(ctx context.Context, input string) (types.Respon
 	if input == "" {
 		return types.Response{}, errors.New("input is required")
 	}
-	messages := a.buildInitialMessages(input)
+	messages := a.buildInitialMessages(ctx, input)
 	req := types.Request{
 		SystemPrompt:    a.systemPrompt,
 		Messages:        messages,
 		MaxOutputTokens: a.maxOutputTokens,
 		ResponseSchema:  a.responseSchema,
 	}
+	a.generation.apply(&req)
 	resp, err := a.generateWithRetry(ctx, req)
 	if err != nil {
 		return types.Response{}, fmt.Errorf("generation failed: %w", err)
@@ -248,8 +290,12 @@ func (a *Agent) RunLiteDetailed(ctx context.Context, input string) (types.Respon
 	return resp, nil
 }
 
-// RunStream executes one generation turn and streams text chunks when the
-// provider supports it. When streaming is unavailable, it falls back to RunLite.
+// RunStream executes the full agent loop like RunDetailed while streaming
+// typed events through onChunk. Text, reasoning and tool-call deltas are
+// streamed natively when the provider implements llm.StreamProvider;
+// otherwise each generation is emitted as whole chunks. Tool calls are
+// reported when they start and finish, failures produce an error event, and
+// the final chunk carries Done and the completed RunResult.
 func (a *Agent) RunStream(ctx context.Context, input string, onChunk func(types.StreamChunk) error) (types.RunResult, error) {
 	if input == "" {
 		return types.RunResult{}, errors.New("input is required")
@@ -257,80 +303,48 @@ func (a *Agent) RunStream(ctx context.Context, input string, onChunk func(types.
 	if onChunk == nil {
 		return types.RunResult{}, errors.New("onChunk is required")
 	}
-
-	messages := a.buildInitialMessages(input)
-	runID := uuid.NewString()
-	sessionID := a.ensureSessionID()
-	start := time.Now().UTC()
-
-	sp, ok := a.provider.(llm.StreamProvider)
-	if !ok {
-		resp, err := a.RunLiteDetailed(ctx, input)
-		if err != nil {
-			return types.RunResult{}, err
-		}
-		if err := onChunk(types.StreamChunk{Text: resp.Message.Content, Done: true}); err != nil {
-			return types.RunResult{}, err
-		}
-		completed := time.Now().UTC()
-		resp.Message.Role = types.RoleAssistant
-		all := append(messages, resp.Message)
-		return types.RunResult{
-			Output:      resp.Message.Content,
-			Messages:    all,
-			Usage:       resp.Usage,
-			Iterations:  1,
-			Provider:    a.provider.Name(),
-			RunID:       runID,
-			SessionID:   sessionID,
-			StartedAt:   &start,
-			CompletedAt: &completed,
-		}, nil
-	}
-
-	toolDefs := a.listToolDefinitions()
-	trimmed := messages
-	if a.contextManager != nil {
-		trimmed = a.contextManager.TrimMessages(messages, a.systemPrompt, toolDefs, a.maxOutputTokens)
-	}
-	req := types.Request{
-		SystemPrompt:    a.systemPrompt,
-		Messages:        trimmed,
-		Tools:           toolDefs,
-		MaxOutputTokens: a.maxOutputTokens,
-		ResponseSchema:  a.responseSchema,
-	}
-	resp, err := sp.GenerateStream(ctx, req, onChunk)
+	stream := &streamEmitter{fn: onChunk}
+	result, err := a.run(ctx, input, stream)
 	if err != nil {
-		return types.RunResult{}, fmt.Errorf("generation failed: %w", err)
+		_ = stream.emit(types.StreamChunk{Type: types.StreamEventError, Error: err.Error()})
+		return types.RunResult{}, err
 	}
-	resp.Message.Role = types.RoleAssistant
-	all := append(messages, resp.Message)
-	completed := time.Now().UTC()
-	return types.RunResult{
-		Output:      resp.Message.Content,
-		Messages:    all,
-		Usage:       resp.Usage,
-		Iterations:  1,
-		Provider:    a.provider.Name(),
-		RunID:       runID,
-		SessionID:   sessionID,
-		StartedAt:   &start,
-		CompletedAt: &completed,
-	}, nil
+	return result, nil
 }
 
 func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult, error) {
 	if input == "" {
 		return types.RunResult{}, errors.New("input is required")
 	}
+	return a.run(ctx, input, nil)
+}
 
-	runID := uuid.NewString()
+func (a *Agent) run(ctx context.Context, input string, stream *streamEmitter) (types.RunResult, error) {
+	runID := runIDFromContext(ctx)
 	sessionID := a.ensureSessionID()
+
+	var history []types.Message
+	memory := &runMemory{}
+	if a.sessionMemoryEnabled() {
+		release, err := a.lockSession(ctx, sessionID)
+		if err != nil {
+			return types.RunResult{}, err
+		}
+		defer release()
+		if history, memory.summary, memory.historySummary, err = a.loadSessionHistory(ctx, sessionID); err != nil {
+			return types.RunResult{}, err
+		}
+		memory.historyMessages = len(history)
+	}
+	ctx = withRunMemory(ctx, memory)
+
 	startedAt := time.Now().UTC()
-	metadata := runMetadataFromContext(ctx)
+	metadata, err := a.runMetadata(ctx, runID)
+	if err != nil {
+		return types.RunResult{}, err
+	}
 
-	messages := a.buildInitialMessages(input)
+	messages := append(history, a.buildInitialMessages(ctx, input)...)
 	usage := &types.Usage{}
 	hasUsage := false
 	events := []types.Event{
@@ -363,17 +377,70 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 		return types.RunResult{}, fmt.Errorf("failed to persist run start: %w", err)
 	}
 
-	for i := 0; i < a.maxIterations; i++ {
+	return a.runLoop(ctx, &runState{
+		runID:     runID,
+		sessionID: sessionID,
+		input:     input,
+		startedAt: startedAt,
+		messages:  messages,
+		usage:     usage,
+		hasUsage:  hasUsage,
+		events:    events,
+		memory:    memory,
+	}, stream)
+}
+
+// runState is what the agent loop carries between iterations, so a run
+// suspended for approval can continue where it stopped.
+type runState struct {
+	runID     string
+	sessionID string
+	input     string
+	startedAt time.Time
+	messages  []types.Message
+	usage     *types.Usage
+	hasUsage  bool
+	events    []types.Event
+	memory    *runMemory
+	// iteration is the number of iterations already completed.
+	iteration int
+}
+
+func (a *Agent) runLoop(ctx context.Context, rs *runState, stream *streamEmitter) (types.RunResult, error) {
+	runID, sessionID, input, startedAt := rs.runID, rs.sessionID, rs.input, rs.startedAt
+	messages, usage, hasUsage, events, memory := rs.messages, rs.usage, rs.hasUsage, rs.events, rs.memory
+	output := outputSpecFromContext(ctx)
+	outputRetries := 0
+	ctx, handle, done := a.startRun(ctx, runID, sessionID, startedAt)
+	defer done()
+
+	for i := rs.iteration; i < a.maxIterations; i++ {
 		iteration := i + 1
 
-		// Apply context trimming to prevent exceeding token limits
+		rs.messages, rs.usage, rs.hasUsage, rs.events = messages, usage, hasUsage, events
+		if err := a.controlPoint(ctx, handle, rs, iteration); err != nil {
+			if persistErr := a.markFailed(ctx, runID, sessionID, startedAt, input, messages, usageOrNil(usage, hasUsage), err); persistErr != nil {
+				return types.RunResult{}, fmt.Errorf("%w (also failed to persist failure: %v)", err, persistErr)
+			}
+			return types.RunResult{}, err
+		}
+		messages, events = rs.messages, rs.events
+
+		// Apply context trimming to prevent exceeding token limits,
+		// summarizing older turns when a summarizer is configured.
 		toolDefs := a.listToolDefinitions()
-		trimmedMessages := a.contextManager.TrimMessages(
+		trimmedMessages, summary, err := a.contextManager.CompactMessages(
+			ctx,
 			messages,
 			a.systemPrompt,
 			toolDefs,
 			a.maxOutputTokens, // Reserve space for expected output
+			memory.summary,
 		)
+		if err != nil {
+			log.Printf("⚠️  %v; trimming history instead", err)
+		}
+		memory.summary = summary
 
 		req := types.Request{
 			SystemPrompt:    a.systemPrompt,
@@ -383,6 +450,33 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 			ResponseSchema:  a.responseSchema,
 		}
 
+		// Budgets are checked before each generation, so a final answer that
+		// crosses a limit is still returned while further rounds are refused.
+		if err := a.checkBudget(ctx, sessionID, usage); err != nil {
+			a.notifyError(ctx, &ErrorMiddlewareEvent{
+				RunID:     runID,
+				SessionID: sessionID,
+				Provider:  a.provider.Name(),
+				Iteration: iteration,
+				Stage:     "budget",
+				Err:       err,
+			})
+			if persistErr := a.markFailed(ctx, runID, sessionID, startedAt, input, messages, usageOrNil(usage, hasUsage), err); persistErr != nil {
+				return types.RunResult{}, fmt.Errorf("%w (also failed to persist failure: %v)", err, persistErr)
+			}
+			return types.RunResult{}, err
+		}
+
+		a.generation.apply(&req)
+		if output != nil {
+			output.apply(&req, a.provider.Capabilities().StructuredOutput)
+		}
+		// A forced tool choice only applies to the first turn; afterwards the
+		// model must be free to answer or the loop never terminates.
+		if i > 0 && req.ToolChoice != nil && req.ToolChoice.Mode != types.ToolChoiceNone {
+			req.ToolChoice = nil
+		}
+
 		genStarted := time.Now().UTC()
 		events = append(events, types.Event{
 			Type:      types.EventBeforeGenerate,
@@ -409,8 +503,11 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 			return types.RunResult{}, fmt.Errorf("middleware before-generate failed: %w", err)
 		}
 
-		resp, err := a.generateWithRetry(ctx, req)
+		resp, err := a.generate(ctx, req, stream)
 		if err != nil {
+			if cause := context.Cause(ctx); errors.Is(cause, ErrRunCanceled) {
+				err = cause
+			}
 			a.notifyError(ctx, &ErrorMiddlewareEvent{
 				RunID:     runID,
 				SessionID: sessionID,
@@ -434,23 +531,23 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 			}
 			return types.RunResult{}, fmt.Errorf("middleware after-generate failed: %w", err)
 		}
+		genUsage := a.accountUsage(ctx, sessionID, usage, resp)
+		hasUsage = hasUsage || genUsage != nil
+		if resp.Usage != nil && !resp.Cached {
+			a.contextManager.Calibrate(req, resp.Usage.InputTokens)
+		}
 		events = append(events, types.Event{
 			Type:      types.EventAfterGenerate,
 			Timestamp: genFinished,
 			RunID:     runID,
 			SessionID: sessionID,
-			Provider:  a.provider.Name(),
+			Provider:  a.servedBy(resp),
 			Iteration: iteration,
+			Model:     resp.Model,
+			Usage:     genUsage,
 		})
 		a.emitRuntimeEvent(ctx, events[len(events)-1])
 
-		if resp.Usage != nil {
-			usage.InputTokens += resp.Usage.InputTokens
-			usage.OutputTokens += resp.Usage.OutputTokens
-			usage.TotalTokens += resp.Usage.TotalTokens
-			hasUsage = true
-		}
-
 		modelMsg := resp.Message
 		modelMsg.Role = types.RoleAssistant
 		messages = append(messages, modelMsg)
@@ -466,16 +563,13 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 				// Remove the empty assistant message before retrying
 				messages = messages[:len(messages)-1]
 				time.Sleep(time.Duration(emptyRetry) * 500 * time.Millisecond)
-				retryResp, retryErr := a.generateWithRetry(ctx, req)
+				retryResp, retryErr := a.generate(ctx, req, stream)
 				if retryErr != nil {
 					continue
 				}
 				retryMsg := retryResp.Message
 				retryMsg.Role = types.RoleAssistant
-				if retryResp.Usage != nil {
-					usage.InputTokens += retryResp.Usage.InputTokens
-					usage.OutputTokens += retryResp.Usage.OutputTokens
-					usage.TotalTokens += retryResp.Usage.TotalTokens
+				if a.accountUsage(ctx, sessionID, usage, retryResp) != nil {
 					hasUsage = true
 				}
 				if retryMsg.Content != "" || len(retryMsg.ToolCalls) > 0 {
@@ -502,9 +596,39 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 			}
 		}
 
+		if _, native := a.provider.(llm.StreamProvider); stream != nil && !native {
+			// Providers without native streaming emit each turn as whole
+			// chunks, after output middleware has had a chance to rewrite it.
+			if err := llm.EmitResponse(types.Response{Message: modelMsg, Usage: resp.Usage}, stream.emit); err != nil {
+				return types.RunResult{}, err
+			}
+		}
+
 		if len(modelMsg.ToolCalls) == 0 {
-			// Validate response against schema if set
-			if len(a.responseSchema) > 0 && modelMsg.Content != "" {
+			if output != nil {
+				if violations := output.validate(modelMsg.Content); len(violations) > 0 {
+					if outputRetries >= output.retries {
+						outputErr := &OutputValidationError{Output: modelMsg.Content, Violations: violations}
+						a.notifyError(ctx, &ErrorMiddlewareEvent{
+							RunID:     runID,
+							SessionID: sessionID,
+							Provider:  a.provider.Name(),
+							Iteration: iteration,
+							Stage:     "validate_output",
+							Err:       outputErr,
+						})
+						if persistErr := a.markFailed(ctx, runID, sessionID, startedAt, input, messages, usageOrNil(usage, hasUsage), outputErr); persistErr != nil {
+							return types.RunResult{}, fmt.Errorf("%w (also failed to persist failure: %v)", outputErr, persistErr)
+						}
+						return types.RunResult{}, outputErr
+					}
+					outputRetries++
+					log.Printf("⚠️  Response does not match the output schema (retry %d/%d)", outputRetries, output.retries)
+					messages = append(messages, types.Message{Role: types.RoleUser, Content: outputRetryPrompt(violations)})
+					continue
+				}
+			} else if len(a.responseSchema) > 0 && modelMsg.Content != "" {
+				// Validate response against schema if set
 				if !json.Valid([]byte(modelMsg.Content)) {
 					log.Printf("⚠️  Response is not valid JSON, retrying with schema hint...")
 					messages = append(messages, types.Message{
@@ -515,12 +639,22 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 				}
 			}
 
+			// Steering queued during the final generation gets another
+			// turn instead of being dropped.
+			if !handle.finish() {
+				continue
+			}
+
 			var finalUsage *types.Usage
 			if hasUsage {
 				finalUsage = usage
 			}
 
 			completedAt := time.Now().UTC()
+			metadata, err := a.runMetadata(ctx, runID)
+			if err != nil {
+				return types.RunResult{}, fmt.Errorf("failed to persist run completion: %w", err)
+			}
 			if err := a.saveRun(ctx, state.RunRecord{
 				RunID:       runID,
 				SessionID:   sessionID,
@@ -547,10 +681,11 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 				Provider:  a.provider.Name(),
 				Iteration: iteration,
 				Message:   "run completed",
+				Usage:     copyUsage(finalUsage),
 			})
 			a.emitRuntimeEvent(ctx, events[len(events)-1])
 
-			return types.RunResult{
+			result := types.RunResult{
 				Output:      modelMsg.Content,
 				Messages:    append([]types.Message(nil), messages...),
 				Usage:       finalUsage,
@@ -561,10 +696,20 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 				StartedAt:   &startedAt,
 				CompletedAt: &completedAt,
 				Events:      append([]types.Event(nil), events...),
-			}, nil
+			}
+			if err := stream.emit(types.StreamChunk{Type: types.StreamEventDone, Done: true, Result: &result}); err != nil {
+				return types.RunResult{}, err
+			}
+			return result, nil
 		}
 
-		toolMessages, toolEvents, err := a.executeToolCalls(ctx, runID, sessionID, iteration, modelMsg.ToolCalls)
+		if pending := a.pendingApprovals(iteration, &modelMsg); len(pending) > 0 {
+			messages[len(messages)-1] = modelMsg
+			rs.messages, rs.usage, rs.hasUsage = messages, usage, hasUsage
+			return types.RunResult{}, a.suspendForApproval(ctx, rs, iteration, pending)
+		}
+
+		toolMessages, toolEvents, err := a.executeToolCalls(ctx, runID, sessionID, iteration, modelMsg.ToolCalls, nil, stream)
 		if err != nil {
 			if persistErr := a.markFailed(ctx, runID, sessionID, startedAt, input, messages, usageOrNil(usage, hasUsage), err); persistErr != nil {
 				return types.RunResult{}, fmt.Errorf("tool execution failed: %w (also failed to persist failure: %v)", err, persistErr)
@@ -573,6 +718,9 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 		}
 		events = append(events, toolEvents...)
 		a.emitRuntimeEvents(ctx, toolEvents)
+		if addSubRunUsage(usage, toolEvents) {
+			hasUsage = true
+		}
 		messages = append(messages, toolMessages...)
 		if err := a.saveProgress(ctx, runID, sessionID, startedAt, input, messages, usageOrNil(usage, hasUsage)); err != nil {
 			return types.RunResult{}, fmt.Errorf("failed to persist tool progress: %w", err)
@@ -594,17 +742,62 @@ func (a *Agent) RunDetailed(ctx context.Context, input string) (types.RunResult,
 	return types.RunResult{}, iterationErr
 }
 
+// generate performs one provider generation. When stream is set and the
+// provider supports streaming, incremental chunks are forwarded to it as they
+// arrive.
+func (a *Agent) generate(ctx context.Context, req types.Request, stream *streamEmitter) (types.Response, error) {
+	sp, ok := a.provider.(llm.StreamProvider)
+	if stream == nil || !ok {
+		return a.generateWithRetry(ctx, req)
+	}
+	return a.retryGenerate(ctx, func(ctx context.Context) (types.Response, error) {
+		emitted := false
+		resp, err := sp.GenerateStream(ctx, req, func(chunk types.StreamChunk) error {
+			// The agent emits its own terminal chunk once the whole run is done.
+			if chunk.Kind() == types.StreamEventDone {
+				return nil
+			}
+			chunk.Type = chunk.Kind()
+			chunk.Done = false
+			emitted = true
+			if err := stream.emit(chunk); err != nil {
+				return &permanentError{err: err}
+			}
+			return nil
+		})
+		if err != nil && emitted {
+			// Partial output has already reached the caller; retrying would
+			// duplicate it.
+			var perm *permanentError
+			if !errors.As(err, &perm) {
+				err = &permanentError{err: err}
+			}
+		}
+		return resp, err
+	})
+}
+
 func (a *Agent) generateWithRetry(ctx context.Context, req types.Request) (types.Response, error) {
+	return a.retryGenerate(ctx, func(ctx context.Context) (types.Response, error) {
+		return a.provider.Generate(ctx, req)
+	})
+}
+
+func (a *Agent) retryGenerate(ctx context.Context, call func(context.Context) (types.Response, error)) (types.Response, error) {
 	policy := normalizeRetryPolicy(a.retryPolicy)
 
 	var lastErr error
 	rateLimitAttempts := 0
 
 	for attempt := 1; attempt <= policy.MaxAttempts; attempt++ {
-		resp, err := a.provider.Generate(ctx, req)
+		resp, err := call(ctx)
 		if err == nil {
 			return resp, nil
 		}
+		var perm *permanentError
+		if errors.As(err, &perm) {
+			return types.Response{}, perm.err
+		}
 		lastErr = err
 
 		// Check if this is a rate limit error
@@ -658,6 +851,8 @@ func (a *Agent) executeToolCalls(
 	sessionID string,
 	iteration int,
 	calls []types.ToolCall,
+	decisions map[string]ApprovalDecision,
+	stream *streamEmitter,
 ) ([]types.Message, []types.Event, error) {
 	toolset := a.snapshotTools()
 	results := make([]types.Message, len(calls))
@@ -684,7 +879,7 @@ func (a *Agent) executeToolCalls(
 			go func() {
 				defer wg.Done()
 				defer func() { <-sem }() // release
-				msg, evs, err := a.executeOneToolCall(ctx, runID, sessionID, iteration, toolset, call)
+				msg, evs, err := a.executeOneToolCall(ctx, runID, sessionID, iteration, i, toolset, call, decisions[call.ID], stream)
 				if err != nil {
 					errMu.Lock()
 					if firstErr == nil {
@@ -703,7 +898,7 @@ func (a *Agent) executeToolCalls(
 		}
 	} else {
 		for i, call := range calls {
-			msg, evs, err := a.executeOneToolCall(ctx, runID, sessionID, iteration, toolset, call)
+			msg, evs, err := a.executeOneToolCall(ctx, runID, sessionID, iteration, i, toolset, call, decisions[call.ID], stream)
 			if err != nil {
 				return nil, nil, err
 			}
@@ -735,8 +930,11 @@ func (a *Agent) executeOneToolCall(
 	runID string,
 	sessionID string,
 	iteration int,
+	index int,
 	toolset map[string]tools.Tool,
 	call types.ToolCall,
+	decision ApprovalDecision,
+	stream *streamEmitter,
 ) (types.Message, []types.Event, error) {
 	toolCall := call
 	startedAt := time.Now().UTC()
@@ -765,25 +963,49 @@ func (a *Agent) executeOneToolCall(
 	if err := a.runBeforeTool(ctx, toolEvent); err != nil {
 		return types.Message{}, nil, err
 	}
+	if err := stream.emit(types.StreamChunk{Type: types.StreamEventToolCall, ToolCall: &toolCall}); err != nil {
+		return types.Message{}, nil, err
+	}
 
 	tool, ok := toolset[toolCall.Name]
 	var (
 		payload any
 		toolErr error
+		scope   *toolCallScope
 	)
-	if !ok {
+	if decision.Action == ApprovalReject {
+		toolErr = rejectedToolError(decision)
+		payload = map[string]any{"error": toolErr.Error()}
+	} else if !ok {
 		toolErr = fmt.Errorf("tool %q not found", toolCall.Name)
 		payload = map[string]any{"error": toolErr.Error()}
+	} else if args, violations := a.toolArguments(tool, toolCall.Arguments); len(violations) > 0 {
+		toolErr = &ToolArgumentsError{Tool: toolCall.Name, Violations: violations}
+		payload = map[string]any{
+			"error":      toolErr.Error(),
+			"violations": violations,
+			"schema":     tool.Definition().JSONSchema,
+		}
+		events = append(events, types.Event{
+			Type:       types.EventToolValidationFailed,
+			Timestamp:  time.Now().UTC(),
+			RunID:      runID,
+			SessionID:  sessionID,
+			Provider:   a.provider.Name(),
+			Iteration:  iteration,
+			ToolName:   toolCall.Name,
+			ToolCallID: toolCall.ID,
+			Error:      toolErr.Error(),
+		})
 	} else {
-		args := toolCall.Arguments
-		if len(args) == 0 {
-			args = json.RawMessage(`{}`)
+		scope = &toolCallScope{runID: runID, iteration: iteration, callID: toolCall.ID}
+		toolCtx := withToolCallScope(ctx, scope)
+		if a.outputBudget != nil {
+			toolCtx = tools.WithArtifactDir(toolCtx, toolOutputDir(runID))
 		}
-
-		toolCtx := ctx
 		cancel := func() {}
 		if a.toolTimeout > 0 {
-			toolCtx, cancel = context.WithTimeout(ctx, a.toolTimeout)
+			toolCtx, cancel = context.WithTimeout(toolCtx, a.toolTimeout)
 		}
 		out, err := tool.Execute(toolCtx, args)
 		cancel()
@@ -799,6 +1021,7 @@ func (a *Agent) executeOneToolCall(
 	if err != nil {
 		encoded = []byte(fmt.Sprintf(`{"error":"failed to encode tool output","detail":%q}`, err.Error()))
 	}
+	encoded = a.spoolToolOutput(ctx, runID, iteration, index, toolCall, payload, encoded)
 	result := types.Message{
 		Role:       types.RoleTool,
 		Name:       toolCall.Name,
@@ -816,6 +1039,9 @@ func (a *Agent) executeOneToolCall(
 	if toolEvent.Result != nil {
 		result = *toolEvent.Result
 	}
+	if err := stream.emit(types.StreamChunk{Type: types.StreamEventToolResult, ToolResult: &result}); err != nil {
+		return types.Message{}, nil, err
+	}
 
 	afterEvent := types.Event{
 		Type:       types.EventAfterTool,
@@ -830,6 +1056,10 @@ func (a *Agent) executeOneToolCall(
 	if toolErr != nil {
 		afterEvent.Error = toolErr.Error()
 	}
+	if scope != nil {
+		// Usage of sub-runs the tool started, rolled up into this run's.
+		afterEvent.Usage = scope.subRunUsage()
+	}
 	events = append(events, afterEvent)
 
 	return result, events, nil
@@ -932,14 +1162,32 @@ func (a *Agent) saveProgress(
 	input string,
 	messages []types.Message,
 	usage *types.Usage,
+) error {
+	return a.saveRunWithStatus(ctx, runID, sessionID, createdAt, input, messages, usage, "running")
+}
+
+// saveRunWithStatus saves an unfinished run with status, such as running or
+// paused.
+func (a *Agent) saveRunWithStatus(
+	ctx context.Context,
+	runID string,
+	sessionID string,
+	createdAt time.Time,
+	input string,
+	messages []types.Message,
+	usage *types.Usage,
+	status string,
 ) error {
 	now := time.Now().UTC()
-	metadata := runMetadataFromContext(ctx)
+	metadata, err := a.runMetadata(ctx, runID)
+	if err != nil {
+		return err
+	}
 	return a.saveRun(ctx, state.RunRecord{
 		RunID:     runID,
 		SessionID: sessionID,
 		Provider:  a.provider.Name(),
-		Status:    "running",
+		Status:    status,
 		Input:     input,
 		Output:    "",
 		Messages:  append([]types.Message(nil), messages...),
@@ -962,16 +1210,25 @@ func (a *Agent) markFailed(
 	runErr error,
 ) error {
 	now := time.Now().UTC()
-	metadata := runMetadataFromContext(ctx)
 	errText := ""
 	if runErr != nil {
 		errText = runErr.Error()
 	}
-	err := a.saveRun(ctx, state.RunRecord{
+	status, eventType, message := "failed", types.EventRunFailed, "run failed"
+	if errors.Is(context.Cause(ctx), ErrRunCanceled) {
+		status, eventType, message = RunStatusCanceled, types.EventRunCanceled, "run canceled"
+	}
+	// The run's own context may be canceled; the failure is still recorded.
+	ctx = context.WithoutCancel(ctx)
+	metadata, err := a.runMetadata(ctx, runID)
+	if err != nil {
+		return err
+	}
+	err = a.saveRun(ctx, state.RunRecord{
 		RunID:       runID,
 		SessionID:   sessionID,
 		Provider:    a.provider.Name(),
-		Status:      "failed",
+		Status:      status,
 		Input:       input,
 		Output:      "",
 		Messages:    append([]types.Message(nil), messages...),
@@ -986,17 +1243,54 @@ func (a *Agent) markFailed(
 		return err
 	}
 	a.emitRuntimeEvent(ctx, types.Event{
-		Type:      types.EventRunFailed,
+		Type:      eventType,
 		Timestamp: now,
 		RunID:     runID,
 		SessionID: sessionID,
 		Provider:  a.provider.Name(),
 		Error:     errText,
-		Message:   "run failed",
+		Message:   message,
 	})
 	return nil
 }
 
+// agentMetadataKeys are the run metadata keys the agent owns. Every save
+// replaces or removes them; other keys of the stored run, such as the tools
+// and workflow a caller recorded for resuming it, are kept.
+var agentMetadataKeys = []string{
+	pendingApprovalsKey,
+	approvalIterationKey,
+	approvalDecisionsKey,
+	historyMessagesKey,
+	contextSummaryKey,
+	historySummaryKey,
+	approvalHistoryKey,
+}
+
+// runMetadata returns the metadata to save runID with: the stored run's,
+// with the values from ctx laid over it. A resumed run has none of its
+// original context values, so the reply target and parent run of the
+// stored run survive it.
+func (a *Agent) runMetadata(ctx context.Context, runID string) (map[string]any, error) {
+	md := map[string]any{}
+	if a.store != nil {
+		run, err := a.store.LoadRun(ctx, runID)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return nil, fmt.Errorf("failed to load run metadata: %w", err)
+		}
+		for key, value := range run.Metadata {
+			md[key] = value
+		}
+		for _, key := range agentMetadataKeys {
+			delete(md, key)
+		}
+	}
+	for key, value := range runMetadataFromContext(ctx) {
+		md[key] = value
+	}
+	return md, nil
+}
+
 func runMetadataFromContext(ctx context.Context) map[string]any {
 	md := map[string]any{}
 	if target := delivery.FromContext(ctx); target != nil {
@@ -1014,6 +1308,20 @@ func runMetadataFromContext(ctx context.Context) map[string]any {
 	if parentRunID := delivery.ParentRunIDFromContext(ctx); parentRunID != "" {
 		md["parent_run_id"] = parentRunID
 	}
+	if memory, ok := ctx.Value(runMemoryContextKey{}).(*runMemory); ok {
+		if memory.historyMessages > 0 {
+			md[historyMessagesKey] = memory.historyMessages
+		}
+		if memory.summary != nil {
+			md[contextSummaryKey] = memory.summary
+		}
+		if memory.historySummary != nil {
+			md[historySummaryKey] = memory.historySummary
+		}
+		if len(memory.approvals) > 0 {
+			md[approvalHistoryKey] = memory.approvals
+		}
+	}
 	return md
 }
 
@@ -1039,6 +1347,50 @@ func copyUsage(in *types.Usage) *types.Usage {
 	return &out
 }
 
+// streamEmitter serializes stream callbacks so parallel tool execution can
+// report progress safely. A nil emitter discards all chunks, and once the
+// callback fails no further chunks are delivered.
+type streamEmitter struct {
+	mu     sync.Mutex
+	fn     func(types.StreamChunk) error
+	failed bool
+}
+
+func (e *streamEmitter) emit(chunk types.StreamChunk) error {
+	if e == nil || e.fn == nil {
+		return nil
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.failed {
+		return nil
+	}
+	chunk.Type = chunk.Kind()
+	if err := e.fn(chunk); err != nil {
+		e.failed = true
+		return err
+	}
+	return nil
+}
+
+// permanentError marks a generation failure that must not be retried.
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// servedBy reports the backend that produced resp, falling back to the
+// configured provider's name.
+func (a *Agent) servedBy(resp types.Response) string {
+	if resp.Provider != "" {
+		return resp.Provider
+	}
+	return a.provider.Name()
+}
+
 func (a *Agent) emitRuntimeEvents(ctx context.Context, events []types.Event) {
 	for _, event := range events {
 		a.emitRuntimeEvent(ctx, event)
@@ -1049,20 +1401,20 @@ func (a *Agent) emitRuntimeEvent(ctx context.Context, event types.Event) {
 	if a == nil || a.observer == nil {
 		return
 	}
-	_ = a.observer.Emit(ctx, observe.FromRuntimeEvent(event))
+	_ = a.observer.Emit(ctx, observe.FromRuntimeEventContext(ctx, event))
 }
 
-func (a *Agent) buildInitialMessages(input string) []types.Message {
+func (a *Agent) buildInitialMessages(ctx context.Context, input string) []types.Message {
 	var messages []types.Message
 	if len(a.conversationHistory) > 0 {
 		messages = make([]types.Message, 0, len(a.conversationHistory)+1)
 		for _, m := range a.conversationHistory {
 			if m.Role == types.RoleUser || (m.Role == types.RoleAssistant && m.Content != "" && len(m.ToolCalls) == 0) {
-				messages = append(messages, types.Message{Role: m.Role, Content: m.Content})
+				messages = append(messages, types.Message{Role: m.Role, Content: m.Content, Parts: m.Parts})
 			}
 		}
 	}
-	messages = append(messages, types.Message{Role: types.RoleUser, Content: input})
+	messages = append(messages, types.Message{Role: types.RoleUser, Content: input, Parts: AttachmentsFromContext(ctx)})
 	return messages
 }
 
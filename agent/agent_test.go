@@ -863,3 +863,275 @@ func TestAgent_RunStream_EmitsChunks(t *testing.T) {
 		t.Fatalf("unexpected chunks: %#v", chunks)
 	}
 }
+
+type streamToolProvider struct {
+	calls int
+}
+
+func (p *streamToolProvider) Name() string { return "stream-tool-provider" }
+
+func (p *streamToolProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true, Streaming: true}
+}
+
+func (p *streamToolProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	return types.Response{}, errors.New("Generate should not be called when streaming")
+}
+
+func (p *streamToolProvider) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	_ = ctx
+	p.calls++
+	if p.calls == 1 {
+		if err := onChunk(types.StreamChunk{Done: true}); err != nil {
+			return types.Response{}, err
+		}
+		return types.Response{Message: types.Message{
+			Role: types.RoleAssistant,
+			ToolCalls: []types.ToolCall{
+				{ID: "call-1", Name: "test_tool", Arguments: json.RawMessage(`{"value":"hi"}`)},
+			},
+		}}, nil
+	}
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role != types.RoleTool {
+		return types.Response{}, errors.New("expected tool result before second generation")
+	}
+	if err := onChunk(types.StreamChunk{Text: "done"}); err != nil {
+		return types.Response{}, err
+	}
+	if err := onChunk(types.StreamChunk{Done: true}); err != nil {
+		return types.Response{}, err
+	}
+	return types.Response{
+		Message: types.Message{Role: types.RoleAssistant, Content: "done"},
+		Usage:   &types.Usage{InputTokens: 1, OutputTokens: 1, TotalTokens: 2},
+	}, nil
+}
+
+func echoTool() tools.Tool {
+	return tools.NewFuncTool(
+		"test_tool",
+		"test tool",
+		map[string]any{"type": "object"},
+		func(ctx context.Context, args json.RawMessage) (any, error) {
+			_ = ctx
+			var in struct {
+				Value string `json:"value"`
+			}
+			_ = json.Unmarshal(args, &in)
+			return map[string]any{"echo": in.Value}, nil
+		},
+	)
+}
+
+func TestAgent_RunStream_ExecutesToolsAndPersists(t *testing.T) {
+	store := newMemoryStateStore()
+	p := &streamToolProvider{}
+	a, err := New(p, WithTool(echoTool()), WithStore(store), WithMaxIterations(3))
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	var chunks []types.StreamChunk
+	result, err := a.RunStream(context.Background(), "run", func(chunk types.StreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunStream failed: %v", err)
+	}
+	if result.Output != "done" || result.Iterations != 2 || p.calls != 2 {
+		t.Fatalf("unexpected result: output=%q iterations=%d calls=%d", result.Output, result.Iterations, p.calls)
+	}
+	if len(chunks) != 4 {
+		t.Fatalf("expected 4 chunks (tool start, tool result, text, done), got %d: %#v", len(chunks), chunks)
+	}
+	if chunks[0].ToolCall == nil || chunks[0].ToolCall.Name != "test_tool" {
+		t.Fatalf("expected tool start chunk, got %#v", chunks[0])
+	}
+	if chunks[1].ToolResult == nil || !strings.Contains(chunks[1].ToolResult.Content, "hi") {
+		t.Fatalf("expected tool result chunk, got %#v", chunks[1])
+	}
+	if chunks[2].Text != "done" || chunks[2].Done {
+		t.Fatalf("expected text chunk, got %#v", chunks[2])
+	}
+	if !chunks[3].Done || chunks[3].Result == nil || chunks[3].Result.RunID != result.RunID {
+		t.Fatalf("expected terminal chunk with result, got %#v", chunks[3])
+	}
+
+	run, err := store.LoadRun(context.Background(), result.RunID)
+	if err != nil {
+		t.Fatalf("load run failed: %v", err)
+	}
+	if run.Status != "completed" || len(run.Messages) != 4 {
+		t.Fatalf("unexpected persisted run: status=%q messages=%d", run.Status, len(run.Messages))
+	}
+}
+
+func TestAgent_RunStream_FallsBackToGenerateWithTools(t *testing.T) {
+	mock := &mockProvider{}
+	a, err := New(mock, WithTool(echoTool()), WithMaxIterations(3))
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	var text string
+	var toolStarts int
+	result, err := a.RunStream(context.Background(), "run", func(chunk types.StreamChunk) error {
+		text += chunk.Text
+		if chunk.ToolCall != nil {
+			toolStarts++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunStream failed: %v", err)
+	}
+	if result.Output != "done" || text != "done" {
+		t.Fatalf("unexpected output: result=%q streamed=%q", result.Output, text)
+	}
+	if toolStarts != 1 || mock.calls != 2 {
+		t.Fatalf("expected one tool call over two generations, got tools=%d calls=%d", toolStarts, mock.calls)
+	}
+}
+
+func TestAgent_RunStream_MaxIterations(t *testing.T) {
+	store := newMemoryStateStore()
+	p := &streamToolProvider{}
+	a, err := New(p, WithTool(echoTool()), WithStore(store), WithMaxIterations(1))
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	_, err = a.RunStream(context.Background(), "run", func(types.StreamChunk) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "max iterations") {
+		t.Fatalf("expected max iterations error, got %v", err)
+	}
+	runs, _ := store.ListRuns(context.Background(), state.ListRunsQuery{})
+	if len(runs) != 1 || runs[0].Status != "failed" {
+		t.Fatalf("expected one failed run, got %#v", runs)
+	}
+}
+
+func TestAgent_RunStream_EmitsTypedEvents(t *testing.T) {
+	a, err := New(&usageProvider{})
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	var kinds []types.StreamEventType
+	_, err = a.RunStream(context.Background(), "hello", func(chunk types.StreamChunk) error {
+		kinds = append(kinds, chunk.Type)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("RunStream failed: %v", err)
+	}
+	want := []types.StreamEventType{types.StreamEventText, types.StreamEventUsage, types.StreamEventDone}
+	if fmt.Sprint(kinds) != fmt.Sprint(want) {
+		t.Fatalf("unexpected event types: got %v want %v", kinds, want)
+	}
+
+	failing, err := New(&flakyProvider{}, WithProviderRetries(0))
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	var last types.StreamChunk
+	_, err = failing.RunStream(context.Background(), "hello", func(chunk types.StreamChunk) error {
+		last = chunk
+		return nil
+	})
+	if err == nil {
+		t.Fatalf("expected stream run to fail")
+	}
+	if last.Type != types.StreamEventError || last.Error == "" {
+		t.Fatalf("expected trailing error event, got %#v", last)
+	}
+}
+
+type captureProvider struct {
+	last types.Request
+}
+
+func (p *captureProvider) Name() string { return "capture" }
+
+func (p *captureProvider) Capabilities() llm.Capabilities { return llm.Capabilities{} }
+
+func (p *captureProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.last = req
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "seen"}}, nil
+}
+
+func TestAgent_Run_PassesAttachmentsFromContext(t *testing.T) {
+	p := &captureProvider{}
+	a, err := New(p)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	ctx := ContextWithAttachments(context.Background(), types.ImagePart([]byte{0x89, 'P', 'N', 'G'}, "image/png"))
+	if _, err := a.Run(ctx, "what is in this screenshot?"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	last := p.last.Messages[len(p.last.Messages)-1]
+	if last.Content != "what is in this screenshot?" {
+		t.Fatalf("unexpected content: %q", last.Content)
+	}
+	if len(last.Parts) != 1 || last.Parts[0].Type != types.ContentPartImage || last.Parts[0].MIMEType != "image/png" {
+		t.Fatalf("expected image attachment on user message, got %#v", last.Parts)
+	}
+
+	if _, err := a.Run(context.Background(), "plain"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if parts := p.last.Messages[len(p.last.Messages)-1].Parts; parts != nil {
+		t.Fatalf("expected no parts without attachments, got %#v", parts)
+	}
+}
+
+func TestAgent_Run_AppliesGenerationControls(t *testing.T) {
+	p := &captureProvider{}
+	a, err := New(p,
+		WithTemperature(0.2),
+		WithTopK(40),
+		WithStopSequences("END"),
+		WithSeed(7),
+		WithToolChoice(types.ToolChoice{Mode: types.ToolChoiceRequired}),
+		WithParallelToolCallsHint(false),
+	)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	if _, err := a.Run(context.Background(), "hi"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	req := p.last
+	if req.Temperature == nil || *req.Temperature != 0.2 {
+		t.Fatalf("expected temperature 0.2, got %v", req.Temperature)
+	}
+	if req.TopK == nil || *req.TopK != 40 {
+		t.Fatalf("expected topK 40, got %v", req.TopK)
+	}
+	if len(req.StopSequences) != 1 || req.StopSequences[0] != "END" {
+		t.Fatalf("unexpected stop sequences: %v", req.StopSequences)
+	}
+	if req.Seed == nil || *req.Seed != 7 {
+		t.Fatalf("expected seed 7, got %v", req.Seed)
+	}
+	if req.ToolChoice == nil || req.ToolChoice.Mode != types.ToolChoiceRequired {
+		t.Fatalf("expected required tool choice, got %+v", req.ToolChoice)
+	}
+	if req.ParallelToolCalls == nil || *req.ParallelToolCalls {
+		t.Fatalf("expected parallel hint false, got %v", req.ParallelToolCalls)
+	}
+	if req.TopP != nil {
+		t.Fatalf("expected unset topP to stay nil")
+	}
+
+	unsupported := a.UnsupportedGenerationControls()
+	if len(unsupported) != 6 {
+		t.Fatalf("expected all configured controls unsupported by capture provider, got %v", unsupported)
+	}
+}
@@ -0,0 +1,615 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+	"github.com/google/uuid"
+)
+
+const (
+	// RunStatusAwaitingApproval is the status of a run suspended until its
+	// pending tool calls are approved, rejected or edited.
+	RunStatusAwaitingApproval = "awaiting_approval"
+
+	pendingApprovalsKey  = "pending_approvals"
+	approvalDecisionsKey = "approval_decisions"
+	approvalIterationKey = "approval_iteration"
+	approvalHistoryKey   = "approvals"
+
+	// approvalLockTTL bounds how long a run's decisions stay locked on
+	// stores that implement state.RunLocker.
+	approvalLockTTL = time.Minute
+)
+
+// RiskLevel ranks how much damage a tool can do when misused.
+type RiskLevel string
+
+const (
+	RiskLow    RiskLevel = "low"
+	RiskMedium RiskLevel = "medium"
+	RiskHigh   RiskLevel = "high"
+)
+
+func (r RiskLevel) rank() int {
+	switch r {
+	case RiskLow:
+		return 1
+	case RiskMedium:
+		return 2
+	case RiskHigh:
+		return 3
+	default:
+		return 0
+	}
+}
+
+// DefaultToolRisk is the risk of the built-in tools that change
+// infrastructure or run arbitrary commands.
+var DefaultToolRisk = map[string]RiskLevel{
+	"shell_command": RiskHigh,
+	"kubectl":       RiskHigh,
+	"docker":        RiskHigh,
+	"git_repo":      RiskMedium,
+}
+
+// ApprovalRule requires approval for calls of Tool ("*" for any tool) whose
+// raw JSON arguments match Arguments. An empty Arguments matches every call.
+type ApprovalRule struct {
+	Tool      string
+	Arguments string
+	Reason    string
+
+	args *regexp.Regexp
+}
+
+// ApprovalPolicy decides which tool calls suspend the run for a human
+// decision. A call needs approval when it matches one of Rules, or when
+// MinRisk is set and the tool's risk, looked up in Risk and then in
+// DefaultToolRisk, is at least MinRisk.
+type ApprovalPolicy struct {
+	Rules   []ApprovalRule
+	MinRisk RiskLevel
+	Risk    map[string]RiskLevel
+}
+
+// WithToolApproval suspends runs before executing tool calls that the
+// policy matches. The run is saved with status RunStatusAwaitingApproval and
+// the pending calls, RunDetailed returns an *ApprovalRequiredError, and
+// ResumeRun continues it once every call has a decision. Requires a store.
+func WithToolApproval(policy ApprovalPolicy) Option {
+	return func(a *Agent) { a.approval = &policy }
+}
+
+// ApprovalPolicyFromEnv reads AGENT_APPROVAL_TOOLS (comma separated tool
+// names), AGENT_APPROVAL_RULES (semicolon separated tool=pattern pairs) and
+// AGENT_APPROVAL_MIN_RISK (low, medium or high). It returns nil when none
+// is set.
+func ApprovalPolicyFromEnv() (*ApprovalPolicy, error) {
+	var policy ApprovalPolicy
+	for _, name := range strings.Split(os.Getenv("AGENT_APPROVAL_TOOLS"), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			policy.Rules = append(policy.Rules, ApprovalRule{Tool: name})
+		}
+	}
+	for _, raw := range strings.Split(os.Getenv("AGENT_APPROVAL_RULES"), ";") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		tool, pattern, ok := strings.Cut(raw, "=")
+		if !ok || strings.TrimSpace(tool) == "" {
+			return nil, fmt.Errorf("invalid AGENT_APPROVAL_RULES entry %q", raw)
+		}
+		policy.Rules = append(policy.Rules, ApprovalRule{Tool: strings.TrimSpace(tool), Arguments: pattern})
+	}
+	if raw := strings.ToLower(strings.TrimSpace(os.Getenv("AGENT_APPROVAL_MIN_RISK"))); raw != "" {
+		policy.MinRisk = RiskLevel(raw)
+	}
+	if len(policy.Rules) == 0 && policy.MinRisk == "" {
+		return nil, nil
+	}
+	if err := policy.compile(); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+func (p *ApprovalPolicy) compile() error {
+	if p.MinRisk != "" && p.MinRisk.rank() == 0 {
+		return fmt.Errorf("invalid approval risk level %q", p.MinRisk)
+	}
+	for i := range p.Rules {
+		rule := &p.Rules[i]
+		if strings.TrimSpace(rule.Tool) == "" {
+			rule.Tool = "*"
+		}
+		if rule.Arguments == "" {
+			continue
+		}
+		re, err := regexp.Compile(rule.Arguments)
+		if err != nil {
+			return fmt.Errorf("invalid approval pattern for %s: %w", rule.Tool, err)
+		}
+		rule.args = re
+	}
+	return nil
+}
+
+func (p *ApprovalPolicy) riskOf(tool string) RiskLevel {
+	if risk, ok := p.Risk[tool]; ok {
+		return risk
+	}
+	return DefaultToolRisk[tool]
+}
+
+// match reports whether call needs approval and why.
+func (p *ApprovalPolicy) match(call types.ToolCall) (string, bool) {
+	for _, rule := range p.Rules {
+		if rule.Tool != "*" && rule.Tool != call.Name {
+			continue
+		}
+		if rule.args != nil && !rule.args.Match(call.Arguments) {
+			continue
+		}
+		if rule.Reason != "" {
+			return rule.Reason, true
+		}
+		return fmt.Sprintf("%s requires approval", call.Name), true
+	}
+	if p.MinRisk != "" {
+		if risk := p.riskOf(call.Name); risk.rank() >= p.MinRisk.rank() {
+			return fmt.Sprintf("%s is %s risk", call.Name, risk), true
+		}
+	}
+	return "", false
+}
+
+// PendingApproval is a tool call waiting for a decision.
+type PendingApproval struct {
+	ToolCallID  string          `json:"toolCallId"`
+	ToolName    string          `json:"toolName"`
+	Arguments   json.RawMessage `json:"arguments,omitempty"`
+	Reason      string          `json:"reason,omitempty"`
+	RequestedAt time.Time       `json:"requestedAt"`
+}
+
+type ApprovalAction string
+
+const (
+	ApprovalApprove ApprovalAction = "approve"
+	ApprovalReject  ApprovalAction = "reject"
+	// ApprovalEdit approves the call with Arguments replacing the model's.
+	ApprovalEdit ApprovalAction = "edit"
+)
+
+// ApprovalDecision resolves one pending tool call.
+type ApprovalDecision struct {
+	ToolCallID string          `json:"toolCallId"`
+	Action     ApprovalAction  `json:"action"`
+	Arguments  json.RawMessage `json:"arguments,omitempty"`
+	Reason     string          `json:"reason,omitempty"`
+	DecidedBy  string          `json:"decidedBy,omitempty"`
+	DecidedAt  time.Time       `json:"decidedAt"`
+}
+
+func (d *ApprovalDecision) validate() error {
+	switch d.Action {
+	case ApprovalApprove, ApprovalReject:
+	case ApprovalEdit:
+		var args map[string]any
+		if err := json.Unmarshal(d.Arguments, &args); err != nil {
+			return fmt.Errorf("edited arguments for %s must be a JSON object: %w", d.ToolCallID, err)
+		}
+	default:
+		return fmt.Errorf("invalid approval action %q", d.Action)
+	}
+	if d.DecidedAt.IsZero() {
+		d.DecidedAt = time.Now().UTC()
+	}
+	return nil
+}
+
+// ErrApprovalRequired is matched by errors.Is for runs suspended for
+// approval.
+var ErrApprovalRequired = errors.New("tool approval required")
+
+// ApprovalRequiredError is returned when a run is suspended, or is resumed
+// before every pending call has a decision.
+type ApprovalRequiredError struct {
+	RunID     string
+	SessionID string
+	Pending   []PendingApproval
+}
+
+func (e *ApprovalRequiredError) Error() string {
+	names := make([]string, 0, len(e.Pending))
+	for _, p := range e.Pending {
+		names = append(names, p.ToolName)
+	}
+	return fmt.Sprintf("run %s is awaiting approval for %s", e.RunID, strings.Join(names, ", "))
+}
+
+func (e *ApprovalRequiredError) Is(target error) bool { return target == ErrApprovalRequired }
+
+// PendingApprovals returns the tool calls of a suspended run that have no
+// decision yet.
+func PendingApprovals(run state.RunRecord) []PendingApproval {
+	if run.Status != RunStatusAwaitingApproval {
+		return nil
+	}
+	decided := map[string]bool{}
+	for _, d := range ApprovalDecisions(run) {
+		decided[d.ToolCallID] = true
+	}
+	var out []PendingApproval
+	for _, p := range decodeMetadata[PendingApproval](run.Metadata, pendingApprovalsKey) {
+		if !decided[p.ToolCallID] {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// ApprovalDecisions returns the decisions recorded so far for a suspended
+// run's pending calls.
+func ApprovalDecisions(run state.RunRecord) []ApprovalDecision {
+	return decodeMetadata[ApprovalDecision](run.Metadata, approvalDecisionsKey)
+}
+
+// RecordApprovalDecisions stores decisions for a suspended run without
+// resuming it, so they can be collected from several reviewers or
+// processes. It returns the calls still waiting for a decision; once none
+// remain the run can be resumed with ResumeRun.
+func RecordApprovalDecisions(ctx context.Context, store state.Store, runID string, decisions ...ApprovalDecision) ([]PendingApproval, error) {
+	if store == nil {
+		return nil, errors.New("store is required")
+	}
+	unlock, err := lockApprovals(ctx, store, runID)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	run, err := store.LoadRun(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordDecisions(&run, decisions); err != nil {
+		return nil, err
+	}
+	if len(decisions) > 0 {
+		now := time.Now().UTC()
+		run.UpdatedAt = &now
+		if err := store.SaveRun(ctx, run); err != nil {
+			return nil, fmt.Errorf("failed to save approval decisions: %w", err)
+		}
+	}
+	return PendingApprovals(run), nil
+}
+
+// lockApprovals serializes recording decisions for runID and claiming it
+// to resume, so concurrent reviewers do not overwrite each other's
+// decisions and a run's approved calls run once.
+func lockApprovals(ctx context.Context, store state.Store, runID string) (func(), error) {
+	return state.LockRun(ctx, store, "approval:"+runID, approvalLockTTL)
+}
+
+// recordDecisions validates decisions against the run's pending calls and
+// adds them to its metadata. A later decision for the same call replaces
+// the earlier one.
+func recordDecisions(run *state.RunRecord, decisions []ApprovalDecision) error {
+	if run.Status != RunStatusAwaitingApproval {
+		return fmt.Errorf("run %s is %s, not awaiting approval", run.RunID, run.Status)
+	}
+	pending := map[string]bool{}
+	for _, p := range decodeMetadata[PendingApproval](run.Metadata, pendingApprovalsKey) {
+		pending[p.ToolCallID] = true
+	}
+	recorded := ApprovalDecisions(*run)
+	for _, d := range decisions {
+		if !pending[d.ToolCallID] {
+			return fmt.Errorf("run %s has no pending tool call %q", run.RunID, d.ToolCallID)
+		}
+		if err := d.validate(); err != nil {
+			return err
+		}
+		replaced := false
+		for i := range recorded {
+			if recorded[i].ToolCallID == d.ToolCallID {
+				recorded[i], replaced = d, true
+			}
+		}
+		if !replaced {
+			recorded = append(recorded, d)
+		}
+	}
+	if run.Metadata == nil {
+		run.Metadata = map[string]any{}
+	}
+	run.Metadata[approvalDecisionsKey] = recorded
+	return nil
+}
+
+// decodeMetadata reads a list from run metadata, which holds typed values
+// in memory and decoded JSON once stored.
+func decodeMetadata[T any](metadata map[string]any, key string) []T {
+	switch v := metadata[key].(type) {
+	case nil:
+		return nil
+	case []T:
+		return append([]T(nil), v...)
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var out []T
+		if err := json.Unmarshal(raw, &out); err != nil {
+			return nil
+		}
+		return out
+	}
+}
+
+func metadataInt(metadata map[string]any, key string) int {
+	switch v := metadata[key].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+type runIDContextKey struct{}
+
+// ContextWithRunID makes the next run started with ctx use runID instead of
+// a generated one, for callers that create the run record up front, such as
+// distributed workers.
+func ContextWithRunID(ctx context.Context, runID string) context.Context {
+	return context.WithValue(ctx, runIDContextKey{}, strings.TrimSpace(runID))
+}
+
+func runIDFromContext(ctx context.Context) string {
+	if runID, ok := ctx.Value(runIDContextKey{}).(string); ok && runID != "" {
+		return runID
+	}
+	return uuid.NewString()
+}
+
+// pendingApprovals returns the calls of msg the policy holds back. Calls
+// the provider left without an ID, as Ollama and some Gemini models do, are
+// given call_<iteration>_<index> first, so decisions and results refer to
+// one call each; msg gets a copy of its calls with the IDs filled in.
+func (a *Agent) pendingApprovals(iteration int, msg *types.Message) []PendingApproval {
+	if a.approval == nil {
+		return nil
+	}
+	now := time.Now().UTC()
+	var (
+		pending []PendingApproval
+		calls   = msg.ToolCalls
+	)
+	for i := range calls {
+		reason, ok := a.approval.match(calls[i])
+		if !ok {
+			continue
+		}
+		if pending == nil {
+			calls = withToolCallIDs(calls, iteration)
+		}
+		pending = append(pending, PendingApproval{
+			ToolCallID:  calls[i].ID,
+			ToolName:    calls[i].Name,
+			Arguments:   calls[i].Arguments,
+			Reason:      reason,
+			RequestedAt: now,
+		})
+	}
+	msg.ToolCalls = calls
+	return pending
+}
+
+// withToolCallIDs returns a copy of calls in which calls without an ID are
+// named after the iteration and their position.
+func withToolCallIDs(calls []types.ToolCall, iteration int) []types.ToolCall {
+	out := append([]types.ToolCall(nil), calls...)
+	for i := range out {
+		if out[i].ID == "" {
+			out[i].ID = fmt.Sprintf("call_%d_%d", iteration, i)
+		}
+	}
+	return out
+}
+
+// suspendForApproval saves the run as awaiting approval after iteration,
+// whose assistant message with the tool calls is the last of messages.
+func (a *Agent) suspendForApproval(ctx context.Context, rs *runState, iteration int, pending []PendingApproval) error {
+	now := time.Now().UTC()
+	metadata, err := a.runMetadata(ctx, rs.runID)
+	if err != nil {
+		return fmt.Errorf("failed to persist approval request: %w", err)
+	}
+	metadata[pendingApprovalsKey] = pending
+	metadata[approvalIterationKey] = iteration
+	if err := a.saveRun(ctx, state.RunRecord{
+		RunID:     rs.runID,
+		SessionID: rs.sessionID,
+		Provider:  a.provider.Name(),
+		Status:    RunStatusAwaitingApproval,
+		Input:     rs.input,
+		Messages:  append([]types.Message(nil), rs.messages...),
+		Usage:     usageOrNil(rs.usage, rs.hasUsage),
+		Metadata:  metadata,
+		CreatedAt: &rs.startedAt,
+		UpdatedAt: &now,
+	}); err != nil {
+		return fmt.Errorf("failed to persist approval request: %w", err)
+	}
+	for _, p := range pending {
+		a.emitRuntimeEvent(ctx, types.Event{
+			Type:       types.EventApprovalRequested,
+			Timestamp:  now,
+			RunID:      rs.runID,
+			SessionID:  rs.sessionID,
+			Provider:   a.provider.Name(),
+			Iteration:  iteration,
+			ToolName:   p.ToolName,
+			ToolCallID: p.ToolCallID,
+			Message:    p.Reason,
+		})
+	}
+	return &ApprovalRequiredError{RunID: rs.runID, SessionID: rs.sessionID, Pending: pending}
+}
+
+// ResumeRun continues a run suspended for tool approval. decisions are
+// recorded first; while calls are still undecided an *ApprovalRequiredError
+// listing them is returned. Once all are decided, approved calls run (with
+// the edited arguments, for edits), rejected calls are answered with the
+// rejection so the model can adapt, and the loop carries on where it
+// stopped.
+func (a *Agent) ResumeRun(ctx context.Context, runID string, decisions ...ApprovalDecision) (types.RunResult, error) {
+	if a.store == nil {
+		return types.RunResult{}, errors.New("resuming a run requires a store")
+	}
+	run, err := a.store.LoadRun(ctx, runID)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	if a.sessionMemoryEnabled() {
+		release, err := a.lockSession(ctx, run.SessionID)
+		if err != nil {
+			return types.RunResult{}, err
+		}
+		defer release()
+	}
+	unlock, err := lockApprovals(ctx, a.store, runID)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	defer func() { unlock() }()
+	// Another caller may have decided or resumed the run while we waited.
+	if run, err = a.store.LoadRun(ctx, runID); err != nil {
+		return types.RunResult{}, err
+	}
+	if err := recordDecisions(&run, decisions); err != nil {
+		return types.RunResult{}, err
+	}
+	if remaining := PendingApprovals(run); len(remaining) > 0 {
+		if len(decisions) > 0 {
+			now := time.Now().UTC()
+			run.UpdatedAt = &now
+			if err := a.store.SaveRun(ctx, run); err != nil {
+				return types.RunResult{}, fmt.Errorf("failed to save approval decisions: %w", err)
+			}
+		}
+		return types.RunResult{}, &ApprovalRequiredError{RunID: run.RunID, SessionID: run.SessionID, Pending: remaining}
+	}
+
+	messages := append([]types.Message(nil), run.Messages...)
+	if len(messages) == 0 || messages[len(messages)-1].Role != types.RoleAssistant {
+		return types.RunResult{}, fmt.Errorf("run %s has no tool calls to resume", run.RunID)
+	}
+	last := messages[len(messages)-1]
+	calls := append([]types.ToolCall(nil), last.ToolCalls...)
+	resolved := ApprovalDecisions(run)
+	byCall := make(map[string]ApprovalDecision, len(resolved))
+	for _, d := range resolved {
+		byCall[d.ToolCallID] = d
+	}
+	for i := range calls {
+		if d, ok := byCall[calls[i].ID]; ok && d.Action == ApprovalEdit {
+			calls[i].Arguments = d.Arguments
+		}
+	}
+	last.ToolCalls = calls
+	messages[len(messages)-1] = last
+
+	memory := &runMemory{
+		historyMessages: historyMessages(run.Metadata),
+		summary:         contextSummary(run.Metadata),
+		historySummary:  metadataSummary(run.Metadata, historySummaryKey),
+		approvals:       append(decodeMetadata[ApprovalDecision](run.Metadata, approvalHistoryKey), resolved...),
+	}
+	ctx = withRunMemory(ctx, memory)
+
+	startedAt := runCreatedAt(run)
+	if startedAt.IsZero() {
+		startedAt = time.Now().UTC()
+	}
+	rs := &runState{
+		runID:     run.RunID,
+		sessionID: run.SessionID,
+		input:     run.Input,
+		startedAt: startedAt,
+		messages:  messages,
+		usage:     &types.Usage{},
+		memory:    memory,
+		iteration: metadataInt(run.Metadata, approvalIterationKey),
+	}
+	if run.Usage != nil {
+		rs.usage, rs.hasUsage = copyUsage(run.Usage), true
+	}
+	// Claim the run before executing anything, so a second resume sees it
+	// is no longer awaiting approval once it gets the lock.
+	if err := a.saveProgress(ctx, rs.runID, rs.sessionID, startedAt, rs.input, messages, usageOrNil(rs.usage, rs.hasUsage)); err != nil {
+		return types.RunResult{}, fmt.Errorf("failed to persist run resume: %w", err)
+	}
+	unlock()
+	unlock = func() {}
+
+	now := time.Now().UTC()
+	for _, d := range resolved {
+		event := types.Event{
+			Type:       types.EventApprovalResolved,
+			Timestamp:  now,
+			RunID:      rs.runID,
+			SessionID:  rs.sessionID,
+			Provider:   a.provider.Name(),
+			Iteration:  rs.iteration,
+			ToolCallID: d.ToolCallID,
+			Message:    string(d.Action),
+		}
+		for _, call := range calls {
+			if call.ID == d.ToolCallID {
+				event.ToolName = call.Name
+			}
+		}
+		rs.events = append(rs.events, event)
+		a.emitRuntimeEvent(ctx, event)
+	}
+
+	toolMessages, toolEvents, err := a.executeToolCalls(ctx, rs.runID, rs.sessionID, rs.iteration, calls, byCall, nil)
+	if err != nil {
+		if persistErr := a.markFailed(ctx, rs.runID, rs.sessionID, startedAt, rs.input, messages, usageOrNil(rs.usage, rs.hasUsage), err); persistErr != nil {
+			return types.RunResult{}, fmt.Errorf("tool execution failed: %w (also failed to persist failure: %v)", err, persistErr)
+		}
+		return types.RunResult{}, fmt.Errorf("tool execution failed: %w", err)
+	}
+	rs.events = append(rs.events, toolEvents...)
+	a.emitRuntimeEvents(ctx, toolEvents)
+	if addSubRunUsage(rs.usage, toolEvents) {
+		rs.hasUsage = true
+	}
+	rs.messages = append(rs.messages, toolMessages...)
+	if err := a.saveProgress(ctx, rs.runID, rs.sessionID, startedAt, rs.input, rs.messages, usageOrNil(rs.usage, rs.hasUsage)); err != nil {
+		return types.RunResult{}, fmt.Errorf("failed to persist tool progress: %w", err)
+	}
+	return a.runLoop(ctx, rs, nil)
+}
+
+// rejectedToolError is the tool result the model sees for a rejected call.
+func rejectedToolError(d ApprovalDecision) error {
+	if d.Reason != "" {
+		return fmt.Errorf("tool call rejected by reviewer: %s", d.Reason)
+	}
+	return errors.New("tool call rejected by reviewer")
+}
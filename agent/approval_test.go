@@ -0,0 +1,350 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/delivery"
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func newApprovalAgent(t *testing.T, store state.Store, executed *[]string) *Agent {
+	t.Helper()
+	tool := tools.NewFuncTool("test_tool", "test tool", map[string]any{"type": "object"},
+		func(ctx context.Context, args json.RawMessage) (any, error) {
+			_ = ctx
+			*executed = append(*executed, string(args))
+			return map[string]any{"ok": true}, nil
+		})
+	a, err := New(&mockProvider{},
+		WithTool(tool),
+		WithStore(store),
+		WithMaxIterations(3),
+		WithToolApproval(ApprovalPolicy{Rules: []ApprovalRule{{Tool: "test_tool", Arguments: `"hello"`}}}),
+	)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	return a
+}
+
+func suspendedRun(t *testing.T, a *Agent) string {
+	t.Helper()
+	_, err := a.RunDetailed(context.Background(), "run")
+	var approvalErr *ApprovalRequiredError
+	if !errors.As(err, &approvalErr) || !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("expected approval required, got %v", err)
+	}
+	if len(approvalErr.Pending) != 1 || approvalErr.Pending[0].ToolCallID != "call-1" {
+		t.Fatalf("unexpected pending approvals %+v", approvalErr.Pending)
+	}
+	return approvalErr.RunID
+}
+
+func TestAgent_ToolApproval_EditAndResume(t *testing.T) {
+	store := newMemoryStateStore()
+	var executed []string
+	a := newApprovalAgent(t, store, &executed)
+
+	runID := suspendedRun(t, a)
+	if len(executed) != 0 {
+		t.Fatalf("tool ran before approval: %v", executed)
+	}
+	run, _ := store.LoadRun(context.Background(), runID)
+	if run.Status != RunStatusAwaitingApproval || len(PendingApprovals(run)) != 1 {
+		t.Fatalf("expected a suspended run, got %s with %+v", run.Status, PendingApprovals(run))
+	}
+
+	result, err := a.ResumeRun(context.Background(), runID, ApprovalDecision{
+		ToolCallID: "call-1",
+		Action:     ApprovalEdit,
+		Arguments:  json.RawMessage(`{"value":"bye"}`),
+		DecidedBy:  "alice",
+	})
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Output != "done" || result.RunID != runID {
+		t.Fatalf("unexpected result %+v", result)
+	}
+	if len(executed) != 1 || executed[0] != `{"value":"bye"}` {
+		t.Fatalf("expected the edited arguments to run, got %v", executed)
+	}
+	run, _ = store.LoadRun(context.Background(), runID)
+	if run.Status != "completed" {
+		t.Fatalf("expected completed run, got %s", run.Status)
+	}
+	history := decodeMetadata[ApprovalDecision](run.Metadata, approvalHistoryKey)
+	if len(history) != 1 || history[0].DecidedBy != "alice" {
+		t.Fatalf("expected the decision in the run's approval history, got %+v", history)
+	}
+	if _, err := a.ResumeRun(context.Background(), runID); err == nil {
+		t.Fatalf("expected resuming a completed run to fail")
+	}
+}
+
+func TestAgent_ToolApproval_ResumeKeepsRunMetadata(t *testing.T) {
+	store := newMemoryStateStore()
+	var executed []string
+	a := newApprovalAgent(t, store, &executed)
+
+	ctx := delivery.WithTarget(context.Background(), &delivery.Target{Channel: "slack", Destination: "C123"})
+	ctx = delivery.WithParentRunID(ctx, "parent-run")
+	_, err := a.RunDetailed(ctx, "run")
+	var approvalErr *ApprovalRequiredError
+	if !errors.As(err, &approvalErr) {
+		t.Fatalf("expected approval required, got %v", err)
+	}
+	// A caller records what it needs to resume the run.
+	run, _ := store.LoadRun(context.Background(), approvalErr.RunID)
+	run.Metadata["tools"] = []string{"test_tool"}
+	if err := store.SaveRun(context.Background(), run); err != nil {
+		t.Fatalf("save run: %v", err)
+	}
+
+	// The resume runs without the original context, as from a worker.
+	if _, err := a.ResumeRun(context.Background(), approvalErr.RunID, ApprovalDecision{ToolCallID: "call-1", Action: ApprovalApprove}); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	run, _ = store.LoadRun(context.Background(), approvalErr.RunID)
+	if run.Status != "completed" {
+		t.Fatalf("expected completed run, got %s", run.Status)
+	}
+	replyTo, _ := run.Metadata["replyTo"].(map[string]any)
+	if replyTo["channel"] != "slack" || replyTo["destination"] != "C123" {
+		t.Fatalf("expected the reply target to survive the resume, got %v", run.Metadata)
+	}
+	if run.Metadata["parent_run_id"] != "parent-run" || run.Metadata["tools"] == nil {
+		t.Fatalf("expected the parent run and tools to survive the resume, got %v", run.Metadata)
+	}
+	if _, ok := run.Metadata[pendingApprovalsKey]; ok {
+		t.Fatalf("expected the pending approvals to be cleared, got %v", run.Metadata)
+	}
+}
+
+// idlessProvider asks for two gated calls without IDs, then answers.
+type idlessProvider struct{ calls int }
+
+func (p *idlessProvider) Name() string { return "idless" }
+
+func (p *idlessProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *idlessProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.calls++
+	if p.calls == 1 {
+		return types.Response{Message: types.Message{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{Name: "test_tool", Arguments: json.RawMessage(`{"value":"hello"}`)},
+			{Name: "test_tool", Arguments: json.RawMessage(`{"value":"hello again"}`)},
+		}}}, nil
+	}
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "done"}}, nil
+}
+
+func TestAgent_ToolApproval_CallsWithoutIDs(t *testing.T) {
+	store := newMemoryStateStore()
+	var executed []string
+	tool := tools.NewFuncTool("test_tool", "test tool", map[string]any{"type": "object"},
+		func(ctx context.Context, args json.RawMessage) (any, error) {
+			_ = ctx
+			executed = append(executed, string(args))
+			return map[string]any{"ok": true}, nil
+		})
+	a, err := New(&idlessProvider{},
+		WithTool(tool),
+		WithStore(store),
+		WithToolApproval(ApprovalPolicy{Rules: []ApprovalRule{{Tool: "test_tool", Arguments: `"hello`}}}),
+	)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+
+	_, err = a.RunDetailed(context.Background(), "run")
+	var approvalErr *ApprovalRequiredError
+	if !errors.As(err, &approvalErr) {
+		t.Fatalf("expected approval required, got %v", err)
+	}
+	if len(approvalErr.Pending) != 2 || approvalErr.Pending[0].ToolCallID != "call_1_0" || approvalErr.Pending[1].ToolCallID != "call_1_1" {
+		t.Fatalf("expected distinct IDs for the calls, got %+v", approvalErr.Pending)
+	}
+	run, _ := store.LoadRun(context.Background(), approvalErr.RunID)
+	last := run.Messages[len(run.Messages)-1]
+	if last.ToolCalls[0].ID != "call_1_0" || last.ToolCalls[1].ID != "call_1_1" {
+		t.Fatalf("expected the IDs in the stored assistant message, got %+v", last.ToolCalls)
+	}
+
+	result, err := a.ResumeRun(context.Background(), approvalErr.RunID,
+		ApprovalDecision{ToolCallID: "call_1_0", Action: ApprovalEdit, Arguments: json.RawMessage(`{"value":"edited"}`)},
+		ApprovalDecision{ToolCallID: "call_1_1", Action: ApprovalReject},
+	)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if len(executed) != 1 || executed[0] != `{"value":"edited"}` {
+		t.Fatalf("expected only the edited call to run, got %v", executed)
+	}
+	var results []string
+	for _, m := range result.Messages {
+		if m.Role == types.RoleTool {
+			results = append(results, m.ToolCallID)
+		}
+	}
+	if len(results) != 2 || results[0] != "call_1_0" || results[1] != "call_1_1" {
+		t.Fatalf("expected a result per call, got %v", results)
+	}
+}
+
+func TestAgent_ToolApproval_Reject(t *testing.T) {
+	store := newMemoryStateStore()
+	var executed []string
+	a := newApprovalAgent(t, store, &executed)
+	runID := suspendedRun(t, a)
+
+	remaining, err := RecordApprovalDecisions(context.Background(), store, runID,
+		ApprovalDecision{ToolCallID: "call-1", Action: ApprovalReject, Reason: "not in prod"})
+	if err != nil || len(remaining) != 0 {
+		t.Fatalf("expected all calls decided, got %+v, %v", remaining, err)
+	}
+	result, err := a.ResumeRun(context.Background(), runID)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if len(executed) != 0 {
+		t.Fatalf("rejected tool ran: %v", executed)
+	}
+	var toolMsg types.Message
+	for _, m := range result.Messages {
+		if m.Role == types.RoleTool {
+			toolMsg = m
+		}
+	}
+	if !strings.Contains(toolMsg.Content, "not in prod") {
+		t.Fatalf("expected the rejection to reach the model, got %q", toolMsg.Content)
+	}
+}
+
+func TestAgent_ToolApproval_ResumeNeedsEveryDecision(t *testing.T) {
+	store := newMemoryStateStore()
+	var executed []string
+	a := newApprovalAgent(t, store, &executed)
+	runID := suspendedRun(t, a)
+
+	if _, err := a.ResumeRun(context.Background(), runID); !errors.Is(err, ErrApprovalRequired) {
+		t.Fatalf("expected approval required, got %v", err)
+	}
+	if _, err := a.ResumeRun(context.Background(), runID, ApprovalDecision{ToolCallID: "other", Action: ApprovalApprove}); err == nil {
+		t.Fatalf("expected unknown tool call error")
+	}
+	if _, err := a.ResumeRun(context.Background(), runID, ApprovalDecision{ToolCallID: "call-1", Action: ApprovalEdit, Arguments: json.RawMessage(`[]`)}); err == nil {
+		t.Fatalf("expected invalid edit error")
+	}
+}
+
+// slowSaveStore widens the window between loading a run and saving it.
+type slowSaveStore struct {
+	*memoryStateStore
+}
+
+func (s slowSaveStore) SaveRun(ctx context.Context, run state.RunRecord) error {
+	time.Sleep(10 * time.Millisecond)
+	return s.memoryStateStore.SaveRun(ctx, run)
+}
+
+func TestAgent_ToolApproval_ConcurrentResume(t *testing.T) {
+	store := slowSaveStore{newMemoryStateStore()}
+	var executed []string
+	a := newApprovalAgent(t, store, &executed)
+	runID := suspendedRun(t, a)
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		resumed int
+	)
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := a.ResumeRun(context.Background(), runID, ApprovalDecision{ToolCallID: "call-1", Action: ApprovalApprove})
+			if err == nil {
+				mu.Lock()
+				resumed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if resumed != 1 || len(executed) != 1 {
+		t.Fatalf("expected one resume to run the approved call once, got %d resumes and calls %v", resumed, executed)
+	}
+}
+
+func TestApprovalPolicy_Match(t *testing.T) {
+	policy := ApprovalPolicy{
+		Rules:   []ApprovalRule{{Tool: "git_repo", Arguments: `"action"\s*:\s*"push"`, Reason: "pushes need review"}},
+		MinRisk: RiskHigh,
+		Risk:    map[string]RiskLevel{"docker": RiskLow},
+	}
+	if err := policy.compile(); err != nil {
+		t.Fatalf("compile: %v", err)
+	}
+	cases := []struct {
+		call types.ToolCall
+		want bool
+	}{
+		{types.ToolCall{Name: "git_repo", Arguments: json.RawMessage(`{"action": "push"}`)}, true},
+		{types.ToolCall{Name: "git_repo", Arguments: json.RawMessage(`{"action":"status"}`)}, false},
+		{types.ToolCall{Name: "kubectl", Arguments: json.RawMessage(`{}`)}, true},
+		{types.ToolCall{Name: "docker", Arguments: json.RawMessage(`{}`)}, false},
+		{types.ToolCall{Name: "calculator", Arguments: json.RawMessage(`{}`)}, false},
+	}
+	for _, tc := range cases {
+		if _, got := policy.match(tc.call); got != tc.want {
+			t.Errorf("match(%s %s) = %v, want %v", tc.call.Name, tc.call.Arguments, got, tc.want)
+		}
+	}
+
+	if err := (&ApprovalPolicy{Rules: []ApprovalRule{{Arguments: "("}}}).compile(); err == nil {
+		t.Fatalf("expected invalid pattern error")
+	}
+	if _, err := New(&mockProvider{}, WithToolApproval(ApprovalPolicy{MinRisk: RiskHigh})); err == nil {
+		t.Fatalf("expected approval without a store to fail")
+	}
+}
+
+func TestApprovalPolicyFromEnv(t *testing.T) {
+	t.Setenv("AGENT_APPROVAL_TOOLS", "kubectl, docker")
+	t.Setenv("AGENT_APPROVAL_RULES", `git_repo="action":"push"`)
+	t.Setenv("AGENT_APPROVAL_MIN_RISK", "HIGH")
+	policy, err := ApprovalPolicyFromEnv()
+	if err != nil {
+		t.Fatalf("policy from env: %v", err)
+	}
+	if len(policy.Rules) != 3 || policy.MinRisk != RiskHigh {
+		t.Fatalf("unexpected policy %+v", policy)
+	}
+	if _, ok := policy.match(types.ToolCall{Name: "git_repo", Arguments: json.RawMessage(`{"action":"push"}`)}); !ok {
+		t.Fatalf("expected the push rule to match")
+	}
+
+	t.Setenv("AGENT_APPROVAL_MIN_RISK", "extreme")
+	if _, err := ApprovalPolicyFromEnv(); err == nil {
+		t.Fatalf("expected invalid risk error")
+	}
+	t.Setenv("AGENT_APPROVAL_TOOLS", "")
+	t.Setenv("AGENT_APPROVAL_RULES", "")
+	t.Setenv("AGENT_APPROVAL_MIN_RISK", "")
+	if policy, err := ApprovalPolicyFromEnv(); err != nil || policy != nil {
+		t.Fatalf("expected no policy, got %+v, %v", policy, err)
+	}
+}
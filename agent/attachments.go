@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"context"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+type attachmentsContextKey struct{}
+
+// ContextWithAttachments attaches multimodal content parts (images, files,
+// audio) to the user input of any run started with the returned context.
+// This works for Run, RunDetailed, RunStream and RunLite, and for graph or
+// workflow executors that invoke the agent with the same context.
+func ContextWithAttachments(ctx context.Context, parts ...types.ContentPart) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(parts) == 0 {
+		return ctx
+	}
+	merged := append(AttachmentsFromContext(ctx), parts...)
+	return context.WithValue(ctx, attachmentsContextKey{}, merged)
+}
+
+// AttachmentsFromContext returns the content parts attached to ctx.
+func AttachmentsFromContext(ctx context.Context) []types.ContentPart {
+	if ctx == nil {
+		return nil
+	}
+	parts, _ := ctx.Value(attachmentsContextKey{}).([]types.ContentPart)
+	return append([]types.ContentPart(nil), parts...)
+}
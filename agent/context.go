@@ -1,8 +1,11 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
 	"strings"
 
+	"github.com/PipeOpsHQ/agent-sdk-go/tokenizer"
 	"github.com/PipeOpsHQ/agent-sdk-go/types"
 )
 
@@ -21,6 +24,8 @@ const (
 // exceeding provider rate limits.
 type ContextManager struct {
 	maxInputTokens int
+	summarizer     Summarizer
+	tokenizer      tokenizer.Tokenizer
 }
 
 // NewContextManager creates a ContextManager with the specified token limit.
@@ -32,6 +37,20 @@ func NewContextManager(maxTokens int) *ContextManager {
 	return &ContextManager{maxInputTokens: maxTokens}
 }
 
+// WithTokenizer makes the agent count context tokens with t, for example
+// tokenizer.For(provider, model), instead of the ~4 characters per token
+// heuristic. Tokenizers that implement tokenizer.Calibrator are corrected
+// from the input tokens providers report after each generation.
+func WithTokenizer(t tokenizer.Tokenizer) Option {
+	return func(a *Agent) { a.tokenizer = t }
+}
+
+// SetTokenizer makes the context manager count tokens with t instead of the
+// character heuristic. A nil tokenizer restores the heuristic.
+func (cm *ContextManager) SetTokenizer(t tokenizer.Tokenizer) {
+	cm.tokenizer = t
+}
+
 // EstimateTokens provides a rough token count for a string.
 // This uses a simple character-based heuristic (~4 chars per token).
 func EstimateTokens(text string) int {
@@ -50,6 +69,11 @@ func EstimateMessageTokens(msg types.Message) int {
 	// Content tokens
 	tokens += EstimateTokens(msg.Content)
 
+	// Multimodal parts
+	for _, part := range msg.Parts {
+		tokens += EstimateContentPartTokens(part)
+	}
+
 	// Tool call overhead
 	for _, tc := range msg.ToolCalls {
 		tokens += 10 // ID, name overhead
@@ -64,6 +88,25 @@ func EstimateMessageTokens(msg types.Message) int {
 	return tokens
 }
 
+// attachmentTokens is a flat estimate for a binary attachment. Providers
+// bill images, documents and audio very differently, so this errs on the
+// side of a typical high-detail image.
+const attachmentTokens = 1000
+
+// EstimateContentPartTokens estimates tokens for a multimodal content part.
+// Text and textual files are counted by length; other attachments use a
+// flat per-attachment estimate.
+func EstimateContentPartTokens(part types.ContentPart) int {
+	switch {
+	case part.Type == types.ContentPartText:
+		return EstimateTokens(part.Text)
+	case part.Type == types.ContentPartFile && part.IsTextual():
+		return EstimateTokens(string(part.Data))
+	default:
+		return attachmentTokens
+	}
+}
+
 // EstimateMessagesTokens estimates total tokens for a slice of messages.
 func EstimateMessagesTokens(messages []types.Message) int {
 	total := 0
@@ -85,6 +128,94 @@ func EstimateToolDefinitionsTokens(tools []types.ToolDefinition) int {
 	return total
 }
 
+// CountRequestTokens returns the input tokens req is expected to use, with
+// the configured tokenizer when there is one.
+func (cm *ContextManager) CountRequestTokens(req types.Request) int {
+	return cm.countText(req.SystemPrompt) + cm.countTools(req.Tools) + cm.countMessages(req.Messages)
+}
+
+// Calibrate reports the input tokens a provider billed for req, so a
+// calibrating tokenizer can correct its estimates.
+func (cm *ContextManager) Calibrate(req types.Request, actual int) {
+	c, ok := cm.tokenizer.(tokenizer.Calibrator)
+	if !ok || actual <= 0 {
+		return
+	}
+	c.Calibrate(cm.CountRequestTokens(req), actual)
+}
+
+// countRequest counts req with a tokenizer that counts whole requests,
+// calibrating the tokenizer's own counts from the result.
+func (cm *ContextManager) countRequest(ctx context.Context, req types.Request) (int, bool) {
+	rt, ok := cm.tokenizer.(tokenizer.RequestTokenizer)
+	if !ok {
+		return 0, false
+	}
+	total, err := rt.CountRequest(ctx, req)
+	if err != nil {
+		return 0, false
+	}
+	cm.Calibrate(req, total)
+	return total, true
+}
+
+func (cm *ContextManager) countText(text string) int {
+	if cm.tokenizer == nil {
+		return EstimateTokens(text)
+	}
+	return cm.tokenizer.Count(text)
+}
+
+// countMessage mirrors EstimateMessageTokens with the configured tokenizer.
+func (cm *ContextManager) countMessage(msg types.Message) int {
+	if cm.tokenizer == nil {
+		return EstimateMessageTokens(msg)
+	}
+	tokens := 4 + cm.tokenizer.Count(msg.Content)
+	for _, part := range msg.Parts {
+		switch {
+		case part.Type == types.ContentPartText:
+			tokens += cm.tokenizer.Count(part.Text)
+		case part.Type == types.ContentPartFile && part.IsTextual():
+			tokens += cm.tokenizer.Count(string(part.Data))
+		default:
+			tokens += attachmentTokens
+		}
+	}
+	for _, tc := range msg.ToolCalls {
+		tokens += 10 + cm.tokenizer.Count(tc.Name) + cm.tokenizer.Count(string(tc.Arguments))
+	}
+	if msg.ToolCallID != "" {
+		tokens += 5
+	}
+	return tokens
+}
+
+func (cm *ContextManager) countMessages(messages []types.Message) int {
+	total := 0
+	for _, msg := range messages {
+		total += cm.countMessage(msg)
+	}
+	return total
+}
+
+// countTools counts the schema of each tool instead of the flat estimate
+// EstimateToolDefinitionsTokens uses.
+func (cm *ContextManager) countTools(tools []types.ToolDefinition) int {
+	if cm.tokenizer == nil {
+		return EstimateToolDefinitionsTokens(tools)
+	}
+	total := 0
+	for _, tool := range tools {
+		total += 10 + cm.tokenizer.Count(tool.Name) + cm.tokenizer.Count(tool.Description)
+		if len(tool.JSONSchema) > 0 {
+			raw, _ := json.Marshal(tool.JSONSchema)
+			total += cm.tokenizer.Count(string(raw))
+		}
+	}
+	return total
+}
+
 // TrimMessages trims conversation history to fit within the token budget.
 // It preserves:
 // 1. The system prompt (counted separately)
@@ -107,7 +238,7 @@ func (cm *ContextManager) TrimMessages(
 	}
 
 	// Calculate fixed overhead
-	fixedTokens := EstimateTokens(systemPrompt) + EstimateToolDefinitionsTokens(tools) + reserveTokens
+	fixedTokens := cm.countText(systemPrompt) + cm.countTools(tools) + reserveTokens
 	availableTokens := cm.maxInputTokens - fixedTokens
 
 	if availableTokens <= 0 {
@@ -119,7 +250,7 @@ func (cm *ContextManager) TrimMessages(
 	}
 
 	// Calculate total tokens needed
-	totalTokens := EstimateMessagesTokens(messages)
+	totalTokens := cm.countMessages(messages)
 
 	// If we're under budget, return all messages
 	if totalTokens <= availableTokens {
@@ -132,13 +263,13 @@ func (cm *ContextManager) TrimMessages(
 
 	// Always include the last message (current user input)
 	lastMsg := messages[len(messages)-1]
-	lastMsgTokens := EstimateMessageTokens(lastMsg)
+	lastMsgTokens := cm.countMessage(lastMsg)
 	usedTokens += lastMsgTokens
 
 	// Work backwards from second-to-last message
 	for i := len(messages) - 2; i >= 0; i-- {
 		msg := messages[i]
-		msgTokens := EstimateMessageTokens(msg)
+		msgTokens := cm.countMessage(msg)
 
 		if usedTokens+msgTokens > availableTokens {
 			break
@@ -291,9 +422,9 @@ func (cm *ContextManager) ShouldTrim(
 	systemPrompt string,
 	tools []types.ToolDefinition,
 ) bool {
-	fixedTokens := EstimateTokens(systemPrompt) + EstimateToolDefinitionsTokens(tools)
+	fixedTokens := cm.countText(systemPrompt) + cm.countTools(tools)
 	availableTokens := cm.maxInputTokens - fixedTokens
-	totalTokens := EstimateMessagesTokens(messages)
+	totalTokens := cm.countMessages(messages)
 	return totalTokens > availableTokens
 }
 
@@ -305,7 +436,7 @@ func (cm *ContextManager) SummarizeMessages(messages []types.Message) types.Mess
 	}
 
 	var summaryParts []string
-	summaryParts = append(summaryParts, "[Previous conversation summary]")
+	summaryParts = append(summaryParts, summaryHeader)
 
 	for _, msg := range messages {
 		switch msg.Role {
@@ -332,7 +463,7 @@ func (cm *ContextManager) SummarizeMessages(messages []types.Message) types.Mess
 		}
 	}
 
-	summaryParts = append(summaryParts, "[End of summary]")
+	summaryParts = append(summaryParts, summaryFooter)
 
 	return types.Message{
 		Role:    types.RoleUser,
@@ -1,6 +1,8 @@
 package agent
 
 import (
+	"context"
+	"strings"
 	"testing"
 
 	"github.com/PipeOpsHQ/agent-sdk-go/types"
@@ -155,3 +157,93 @@ func TestNewContextManager(t *testing.T) {
 		}
 	})
 }
+
+// wordTokenizer counts one token per word and records calibrations.
+type wordTokenizer struct {
+	calibrations [][2]int
+}
+
+func (w *wordTokenizer) Name() string          { return "words" }
+func (w *wordTokenizer) Count(text string) int { return len(strings.Fields(text)) }
+func (w *wordTokenizer) Calibrate(estimated, actual int) {
+	w.calibrations = append(w.calibrations, [2]int{estimated, actual})
+}
+
+func TestContextManager_Tokenizer(t *testing.T) {
+	// 30 words of 20 characters: ~150 heuristic tokens, but 30 real ones.
+	content := strings.TrimSpace(strings.Repeat("abcdefghijklmnopqrs ", 30))
+	messages := []types.Message{{Role: types.RoleUser, Content: content}}
+
+	cm := NewContextManager(50)
+	if !cm.ShouldTrim(messages, "", nil) {
+		t.Fatalf("expected the heuristic to exceed the budget")
+	}
+	cm.SetTokenizer(&wordTokenizer{})
+	if cm.ShouldTrim(messages, "", nil) {
+		t.Fatalf("expected the tokenizer count to fit the budget")
+	}
+	req := types.Request{SystemPrompt: "be brief", Messages: messages}
+	if got := cm.CountRequestTokens(req); got != 2+4+30 {
+		t.Fatalf("expected 36 request tokens, got %d", got)
+	}
+}
+
+func TestAgent_CalibratesTokenizer(t *testing.T) {
+	tok := &wordTokenizer{}
+	a, err := New(&usageProvider{}, WithSystemPrompt("be brief"), WithMaxInputTokens(1000), WithTokenizer(tok))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	if _, err := a.Run(context.Background(), "how are you"); err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if len(tok.calibrations) != 1 || tok.calibrations[0] != [2]int{2 + 4 + 3, 10} {
+		t.Fatalf("unexpected calibrations %v", tok.calibrations)
+	}
+}
+
+type ctxKey struct{}
+
+// requestTokenizer counts requests as a whole like tokenizer.Remote.
+type requestTokenizer struct {
+	wordTokenizer
+	total int
+	calls int
+	ctx   context.Context
+}
+
+func (r *requestTokenizer) CountRequest(ctx context.Context, req types.Request) (int, error) {
+	r.calls++
+	r.ctx = ctx
+	return r.total, nil
+}
+
+func TestContextManager_CountsRequestOnce(t *testing.T) {
+	content := strings.TrimSpace(strings.Repeat("word ", 40))
+	messages := []types.Message{
+		{Role: types.RoleUser, Content: content},
+		{Role: types.RoleAssistant, Content: content},
+		{Role: types.RoleUser, Content: "and now?"},
+	}
+	tok := &requestTokenizer{total: 60}
+	cm := NewContextManager(100)
+	cm.SetTokenizer(tok)
+
+	ctx := context.WithValue(context.Background(), ctxKey{}, "run")
+	kept, _, err := cm.CompactMessages(ctx, messages, "", nil, 10, nil)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if len(kept) != 3 || tok.calls != 1 || tok.ctx.Value(ctxKey{}) != "run" {
+		t.Fatalf("expected one count of the request within the run to keep every message, got %d messages after %d calls", len(kept), tok.calls)
+	}
+	if len(tok.calibrations) != 1 || tok.calibrations[0] != [2]int{4 + 40 + 4 + 40 + 4 + 2, 60} {
+		t.Fatalf("expected the word counts to be calibrated from the request count, got %v", tok.calibrations)
+	}
+
+	tok.total = 150
+	kept, _, _ = cm.CompactMessages(ctx, messages, "", nil, 10, nil)
+	if len(kept) != 2 || tok.calls != 2 {
+		t.Fatalf("expected an oversized request to be trimmed, got %d messages after %d calls", len(kept), tok.calls)
+	}
+}
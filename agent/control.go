@@ -0,0 +1,282 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const (
+	// RunStatusPaused is the status of a local run held between iterations
+	// by RunHandle.Pause.
+	RunStatusPaused = "paused"
+	// RunStatusCanceled is the status of a run stopped by RunHandle.Cancel.
+	RunStatusCanceled = "canceled"
+)
+
+var (
+	// ErrRunCanceled is the cause of a run's context once it is canceled
+	// through its handle.
+	ErrRunCanceled = errors.New("run canceled")
+	// ErrRunNotActive is returned for run IDs with no in-flight local run.
+	ErrRunNotActive = errors.New("run is not active in this process")
+	// ErrRunFinishing is returned by RunHandle.Steer once the run has given
+	// its final answer and can no longer take steering.
+	ErrRunFinishing = errors.New("run is finishing")
+)
+
+// RunRegistry tracks the runs in flight in this process so they can be
+// cancelled, steered and paused by run ID, for example from DevUI. Agents
+// register with DefaultRunRegistry unless WithRunRegistry is used.
+type RunRegistry struct {
+	mu   sync.RWMutex
+	runs map[string]*RunHandle
+}
+
+func NewRunRegistry() *RunRegistry {
+	return &RunRegistry{runs: map[string]*RunHandle{}}
+}
+
+// DefaultRunRegistry holds the in-flight runs of agents that were not given
+// a registry of their own.
+var DefaultRunRegistry = NewRunRegistry()
+
+// WithRunRegistry registers the agent's runs with registry instead of
+// DefaultRunRegistry.
+func WithRunRegistry(registry *RunRegistry) Option {
+	return func(a *Agent) {
+		if registry != nil {
+			a.runs = registry
+		}
+	}
+}
+
+// Handle returns the in-flight run with runID, or ErrRunNotActive.
+func (r *RunRegistry) Handle(runID string) (*RunHandle, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	h, ok := r.runs[strings.TrimSpace(runID)]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrRunNotActive, runID)
+	}
+	return h, nil
+}
+
+// List returns the in-flight runs, oldest first.
+func (r *RunRegistry) List() []RunInfo {
+	r.mu.RLock()
+	out := make([]RunInfo, 0, len(r.runs))
+	for _, h := range r.runs {
+		out = append(out, h.Info())
+	}
+	r.mu.RUnlock()
+	sort.Slice(out, func(i, j int) bool { return out[i].StartedAt.Before(out[j].StartedAt) })
+	return out
+}
+
+func (r *RunRegistry) register(h *RunHandle) {
+	r.mu.Lock()
+	r.runs[h.runID] = h
+	r.mu.Unlock()
+}
+
+func (r *RunRegistry) unregister(h *RunHandle) {
+	r.mu.Lock()
+	if r.runs[h.runID] == h {
+		delete(r.runs, h.runID)
+	}
+	r.mu.Unlock()
+}
+
+// RunInfo describes an in-flight run.
+type RunInfo struct {
+	RunID     string    `json:"runId"`
+	SessionID string    `json:"sessionId,omitempty"`
+	Status    string    `json:"status"`
+	StartedAt time.Time `json:"startedAt"`
+	// PendingSteering counts steering messages not yet sent to the model.
+	PendingSteering int `json:"pendingSteering,omitempty"`
+}
+
+// RunHandle controls one in-flight run. Steering messages, pauses and
+// cancellation take effect between iterations, before the next generation;
+// cancellation also interrupts a generation or tool call in progress.
+type RunHandle struct {
+	runID     string
+	sessionID string
+	startedAt time.Time
+	cancel    context.CancelCauseFunc
+
+	mu       sync.Mutex
+	steering []string
+	// finishing is set once the run takes no more steering.
+	finishing bool
+	paused    bool
+	// resumed is closed when a paused run is resumed.
+	resumed chan struct{}
+}
+
+func (h *RunHandle) RunID() string { return h.runID }
+
+func (h *RunHandle) Info() RunInfo {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	status := "running"
+	if h.paused {
+		status = RunStatusPaused
+	}
+	return RunInfo{
+		RunID:           h.runID,
+		SessionID:       h.sessionID,
+		Status:          status,
+		StartedAt:       h.startedAt,
+		PendingSteering: len(h.steering),
+	}
+}
+
+// Cancel stops the run. It fails with an error wrapping ErrRunCanceled and
+// is persisted with RunStatusCanceled.
+func (h *RunHandle) Cancel(reason string) {
+	cause := ErrRunCanceled
+	if reason = strings.TrimSpace(reason); reason != "" {
+		cause = fmt.Errorf("%w: %s", ErrRunCanceled, reason)
+	}
+	h.cancel(cause)
+}
+
+// Steer queues a user message that is appended to the conversation before
+// the run's next generation. Steering that arrives during what would be the
+// final generation gets the run another one; once the final answer is
+// being saved, Steer fails with ErrRunFinishing.
+func (h *RunHandle) Steer(message string) error {
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return errors.New("steering message is required")
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.finishing {
+		return fmt.Errorf("%w: %s", ErrRunFinishing, h.runID)
+	}
+	h.steering = append(h.steering, message)
+	return nil
+}
+
+// Pause holds the run before its next generation until Resume or Cancel.
+func (h *RunHandle) Pause() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.paused {
+		h.paused = true
+		h.resumed = make(chan struct{})
+	}
+}
+
+// Resume lets a paused run continue.
+func (h *RunHandle) Resume() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.paused {
+		h.paused = false
+		close(h.resumed)
+	}
+}
+
+func (h *RunHandle) takeSteering() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	out := h.steering
+	h.steering = nil
+	return out
+}
+
+// finish stops the handle taking steering and reports true, unless steering
+// is queued, which the run must send to the model first.
+func (h *RunHandle) finish() bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if len(h.steering) > 0 {
+		return false
+	}
+	h.finishing = true
+	return true
+}
+
+func (h *RunHandle) pauseState() (bool, chan struct{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.paused, h.resumed
+}
+
+// startRun registers a handle for the run and returns the context the run
+// must use, which its handle can cancel, and a function that unregisters
+// it.
+func (a *Agent) startRun(ctx context.Context, runID, sessionID string, startedAt time.Time) (context.Context, *RunHandle, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	h := &RunHandle{runID: runID, sessionID: sessionID, startedAt: startedAt, cancel: cancel}
+	a.runs.register(h)
+	return ctx, h, func() {
+		a.runs.unregister(h)
+		cancel(nil)
+	}
+}
+
+// controlPoint runs between iterations: it waits while the run is paused,
+// appends queued steering messages and stops a canceled run.
+func (a *Agent) controlPoint(ctx context.Context, h *RunHandle, rs *runState, iteration int) error {
+	if paused, resumed := h.pauseState(); paused {
+		if err := a.saveRunStatus(ctx, rs, RunStatusPaused); err != nil {
+			return fmt.Errorf("failed to persist run pause: %w", err)
+		}
+		a.emitRuntimeEvent(ctx, a.controlEvent(types.EventRunPaused, rs, iteration, "run paused"))
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return context.Cause(ctx)
+		}
+		if err := a.saveRunStatus(ctx, rs, "running"); err != nil {
+			return fmt.Errorf("failed to persist run resume: %w", err)
+		}
+		a.emitRuntimeEvent(ctx, a.controlEvent(types.EventRunResumed, rs, iteration, "run resumed"))
+	}
+	if err := ctx.Err(); err != nil {
+		return context.Cause(ctx)
+	}
+
+	steering := h.takeSteering()
+	if len(steering) == 0 {
+		return nil
+	}
+	for _, msg := range steering {
+		rs.messages = append(rs.messages, types.Message{Role: types.RoleUser, Content: msg})
+		event := a.controlEvent(types.EventRunSteered, rs, iteration, msg)
+		rs.events = append(rs.events, event)
+		a.emitRuntimeEvent(ctx, event)
+	}
+	if err := a.saveRunStatus(ctx, rs, "running"); err != nil {
+		return fmt.Errorf("failed to persist steering: %w", err)
+	}
+	return nil
+}
+
+func (a *Agent) controlEvent(eventType types.EventType, rs *runState, iteration int, message string) types.Event {
+	return types.Event{
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		RunID:     rs.runID,
+		SessionID: rs.sessionID,
+		Provider:  a.provider.Name(),
+		Iteration: iteration,
+		Message:   message,
+	}
+}
+
+func (a *Agent) saveRunStatus(ctx context.Context, rs *runState, status string) error {
+	return a.saveRunWithStatus(ctx, rs.runID, rs.sessionID, rs.startedAt, rs.input, rs.messages, usageOrNil(rs.usage, rs.hasUsage), status)
+}
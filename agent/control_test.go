@@ -0,0 +1,170 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/observe"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// newControlledAgent builds an agent whose test_tool runs control against
+// the run's own handle, as an operator would from another goroutine.
+func newControlledAgent(t *testing.T, store *memoryStateStore, provider *mockProvider, sink observe.Sink, control func(h *RunHandle)) *Agent {
+	t.Helper()
+	registry := NewRunRegistry()
+	tool := tools.NewFuncTool("test_tool", "test tool", map[string]any{"type": "object"},
+		func(ctx context.Context, args json.RawMessage) (any, error) {
+			_, _ = ctx, args
+			h, err := registry.Handle("run-1")
+			if err != nil {
+				return nil, err
+			}
+			control(h)
+			return map[string]any{"ok": true}, nil
+		})
+	a, err := New(provider, WithTool(tool), WithStore(store), WithObserver(sink), WithRunRegistry(registry))
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	return a
+}
+
+func TestAgent_RunControl_SteerPauseResume(t *testing.T) {
+	store := newMemoryStateStore()
+	var (
+		mu     sync.Mutex
+		events []string
+	)
+	sink := observe.SinkFunc(func(ctx context.Context, event observe.Event) error {
+		_ = ctx
+		mu.Lock()
+		events = append(events, event.Attributes["eventType"].(string))
+		mu.Unlock()
+		return nil
+	})
+	provider := &mockProvider{}
+	a := newControlledAgent(t, store, provider, sink, func(h *RunHandle) {
+		if err := h.Steer("also check staging"); err != nil {
+			t.Errorf("steer: %v", err)
+		}
+		h.Pause()
+		go func() {
+			// Resume once the pause has been persisted.
+			for {
+				if run, err := store.LoadRun(context.Background(), "run-1"); err == nil && run.Status == RunStatusPaused {
+					h.Resume()
+					return
+				}
+				time.Sleep(time.Millisecond)
+			}
+		}()
+	})
+
+	result, err := a.RunDetailed(ContextWithRunID(context.Background(), "run-1"), "run")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	steeredAt, toolAt := -1, -1
+	for i, m := range result.Messages {
+		switch {
+		case m.Role == types.RoleTool:
+			toolAt = i
+		case m.Role == types.RoleUser && m.Content == "also check staging":
+			steeredAt = i
+		}
+	}
+	if steeredAt < 0 || steeredAt < toolAt {
+		t.Fatalf("expected the steering message after the tool result, got %+v", result.Messages)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	want := map[string]bool{string(types.EventRunPaused): false, string(types.EventRunResumed): false, string(types.EventRunSteered): false}
+	for _, e := range events {
+		if _, ok := want[e]; ok {
+			want[e] = true
+		}
+	}
+	for e, seen := range want {
+		if !seen {
+			t.Errorf("expected a %s event, got %v", e, events)
+		}
+	}
+	if _, err := a.runs.Handle("run-1"); !errors.Is(err, ErrRunNotActive) {
+		t.Fatalf("expected the finished run to be unregistered, got %v", err)
+	}
+}
+
+// steerDuringAnswerProvider answers without tools, steering its own run
+// while the first answer is being generated. It records the last message
+// of each request.
+type steerDuringAnswerProvider struct {
+	registry *RunRegistry
+	handle   *RunHandle
+	last     []types.Message
+}
+
+func (p *steerDuringAnswerProvider) Name() string { return "steer-provider" }
+
+func (p *steerDuringAnswerProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *steerDuringAnswerProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.last = append(p.last, req.Messages[len(req.Messages)-1])
+	if len(p.last) == 1 {
+		h, err := p.registry.Handle("run-1")
+		if err != nil {
+			return types.Response{}, err
+		}
+		p.handle = h
+		if err := h.Steer("answer for staging too"); err != nil {
+			return types.Response{}, err
+		}
+		return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "production is fine"}}, nil
+	}
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "staging is fine too"}}, nil
+}
+
+func TestAgent_RunControl_SteerDuringFinalAnswer(t *testing.T) {
+	registry := NewRunRegistry()
+	provider := &steerDuringAnswerProvider{registry: registry}
+	a, err := New(provider, WithStore(newMemoryStateStore()), WithRunRegistry(registry))
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+
+	result, err := a.RunDetailed(ContextWithRunID(context.Background(), "run-1"), "check production")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Output != "staging is fine too" || len(provider.last) != 2 || provider.last[1].Content != "answer for staging too" {
+		t.Fatalf("expected the steering to get another generation, got %q after %+v", result.Output, provider.last)
+	}
+	if err := provider.handle.Steer("too late"); !errors.Is(err, ErrRunFinishing) {
+		t.Fatalf("expected steering a finished run to fail, got %v", err)
+	}
+}
+
+func TestAgent_RunControl_Cancel(t *testing.T) {
+	store := newMemoryStateStore()
+	a := newControlledAgent(t, store, &mockProvider{}, observe.NoopSink{}, func(h *RunHandle) {
+		h.Cancel("operator stop")
+	})
+
+	_, err := a.RunDetailed(ContextWithRunID(context.Background(), "run-1"), "run")
+	if !errors.Is(err, ErrRunCanceled) {
+		t.Fatalf("expected a canceled run, got %v", err)
+	}
+	run, _ := store.LoadRun(context.Background(), "run-1")
+	if run.Status != RunStatusCanceled || run.Error != "run canceled: operator stop" {
+		t.Fatalf("expected the cancellation to be persisted, got %s %q", run.Status, run.Error)
+	}
+}
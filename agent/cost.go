@@ -0,0 +1,66 @@
+package agent
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/cost"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// WithPricing replaces the pricing table used to cost provider usage. The
+// default is cost.DefaultTable.
+func WithPricing(table *cost.Table) Option {
+	return func(a *Agent) {
+		if table != nil {
+			a.pricing = table
+		}
+	}
+}
+
+// WithBudget sets spending limits. A run fails with an error matching
+// cost.ErrBudgetExceeded once its spend, its session's spend or the day's
+// spend passes the corresponding limit.
+func WithBudget(budget cost.Budget) Option {
+	return func(a *Agent) { a.budget = budget }
+}
+
+// WithSpendLedger sets where spend is accumulated for session and daily
+// budgets. Share one ledger between agents to enforce a common limit; the
+// default is an in-memory ledger private to the agent.
+func WithSpendLedger(ledger cost.Ledger) Option {
+	return func(a *Agent) {
+		if ledger != nil {
+			a.ledger = ledger
+		}
+	}
+}
+
+// accountUsage adds the tokens and cost of resp to the run total and records
+// the spend in the budget ledger. It returns the generation's usage with its
+// cost filled in, or nil when the provider reported none. Cached responses
+// spent nothing and are not counted.
+func (a *Agent) accountUsage(ctx context.Context, sessionID string, total *types.Usage, resp types.Response) *types.Usage {
+	if resp.Usage == nil || resp.Cached {
+		return nil
+	}
+	gen := *resp.Usage
+	if gen.CostUSD == 0 {
+		gen.CostUSD, _ = a.pricing.Compute(a.servedBy(resp), resp.Model, gen)
+	}
+	addUsage(total, &gen)
+	if a.ledger != nil && gen.CostUSD > 0 {
+		if err := a.ledger.Record(ctx, sessionID, time.Now().UTC(), gen.CostUSD); err != nil {
+			log.Printf("⚠️  Failed to record spend: %v", err)
+		}
+	}
+	return &gen
+}
+
+func (a *Agent) checkBudget(ctx context.Context, sessionID string, usage *types.Usage) error {
+	if !a.budget.Enabled() {
+		return nil
+	}
+	return a.budget.Check(ctx, a.ledger, sessionID, time.Now().UTC(), usage.CostUSD)
+}
@@ -0,0 +1,143 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/cost"
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// spendingProvider keeps calling a tool and reports one million input tokens
+// per generation.
+type spendingProvider struct {
+	calls int
+}
+
+func (p *spendingProvider) Name() string { return "spender" }
+
+func (p *spendingProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *spendingProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	_ = req
+	p.calls++
+	return types.Response{
+		Message: types.Message{
+			Role:      types.RoleAssistant,
+			ToolCalls: []types.ToolCall{{ID: "call", Name: "noop", Arguments: json.RawMessage(`{}`)}},
+		},
+		Usage: &types.Usage{InputTokens: 1_000_000, TotalTokens: 1_000_000},
+		Model: "metered-model",
+	}, nil
+}
+
+func noopTool() tools.Tool {
+	return tools.NewFuncTool("noop", "does nothing", map[string]any{"type": "object"}, func(ctx context.Context, args json.RawMessage) (any, error) {
+		return "ok", nil
+	})
+}
+
+func TestAgent_Run_StopsAtRunBudget(t *testing.T) {
+	store := newMemoryStateStore()
+	p := &spendingProvider{}
+	a, err := New(p,
+		WithTool(noopTool()),
+		WithMaxIterations(5),
+		WithStore(store),
+		WithSessionID("budget-session"),
+		WithPricing(cost.NewTable(map[string]cost.Price{"metered-model": {InputPerMillion: 1}})),
+		WithBudget(cost.Budget{PerRunUSD: 1.5}),
+	)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	_, err = a.RunDetailed(context.Background(), "spend")
+	if !errors.Is(err, cost.ErrBudgetExceeded) {
+		t.Fatalf("expected budget error, got %v", err)
+	}
+	if p.calls != 2 {
+		t.Fatalf("expected 2 provider calls before the budget stopped the run, got %d", p.calls)
+	}
+
+	runs, err := store.ListRuns(context.Background(), state.ListRunsQuery{SessionID: "budget-session"})
+	if err != nil || len(runs) != 1 {
+		t.Fatalf("expected one persisted run, got %d (%v)", len(runs), err)
+	}
+	if runs[0].Status != "failed" {
+		t.Fatalf("expected failed status, got %q", runs[0].Status)
+	}
+	if runs[0].Usage == nil || runs[0].Usage.CostUSD != 2 || runs[0].Usage.InputTokens != 2_000_000 {
+		t.Fatalf("expected persisted usage with cost, got %+v", runs[0].Usage)
+	}
+}
+
+func TestAgent_Run_SessionBudgetSpansRuns(t *testing.T) {
+	ledger := cost.NewMemoryLedger()
+	a, err := New(&usageProvider{},
+		WithSessionID("shared"),
+		WithPricing(cost.NewTable(map[string]cost.Price{"usage-provider/*": {InputPerMillion: 100_000}})),
+		WithBudget(cost.Budget{PerSessionUSD: 1.5}),
+		WithSpendLedger(ledger),
+	)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := a.Run(context.Background(), "hello"); err != nil {
+			t.Fatalf("run %d failed: %v", i+1, err)
+		}
+	}
+	if spent, _ := ledger.SessionSpend(context.Background(), "shared"); spent != 2 {
+		t.Fatalf("expected $2 recorded for the session, got %v", spent)
+	}
+	if _, err := a.Run(context.Background(), "hello"); !errors.Is(err, cost.ErrBudgetExceeded) {
+		t.Fatalf("expected session budget error, got %v", err)
+	}
+}
+
+type cachedProvider struct{}
+
+func (cachedProvider) Name() string { return "cached" }
+
+func (cachedProvider) Capabilities() llm.Capabilities { return llm.Capabilities{} }
+
+func (cachedProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	return types.Response{
+		Message: types.Message{Role: types.RoleAssistant, Content: "from cache"},
+		Usage:   &types.Usage{InputTokens: 1_000_000, TotalTokens: 1_000_000},
+		Model:   "metered-model",
+		Cached:  true,
+	}, nil
+}
+
+func TestAgent_Run_DoesNotChargeCachedResponses(t *testing.T) {
+	ledger := cost.NewMemoryLedger()
+	a, err := New(cachedProvider{},
+		WithSessionID("cached-session"),
+		WithPricing(cost.NewTable(map[string]cost.Price{"metered-model": {InputPerMillion: 1}})),
+		WithSpendLedger(ledger),
+	)
+	if err != nil {
+		t.Fatalf("failed to create agent: %v", err)
+	}
+	result, err := a.RunDetailed(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("RunDetailed failed: %v", err)
+	}
+	if result.Usage != nil && result.Usage.CostUSD != 0 {
+		t.Fatalf("expected no cost for cached response, got %+v", result.Usage)
+	}
+	if spent, _ := ledger.SessionSpend(context.Background(), "cached-session"); spent != 0 {
+		t.Fatalf("expected nothing recorded, got %v", spent)
+	}
+}
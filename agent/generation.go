@@ -0,0 +1,96 @@
+package agent
+
+import "github.com/PipeOpsHQ/agent-sdk-go/types"
+
+// generationSettings holds sampling controls copied onto every request the
+// agent sends. Unset fields leave the provider default in place.
+type generationSettings struct {
+	temperature       *float64
+	topP              *float64
+	topK              *int
+	stopSequences     []string
+	seed              *int64
+	presencePenalty   *float64
+	frequencyPenalty  *float64
+	toolChoice        *types.ToolChoice
+	parallelToolCalls *bool
+}
+
+func (g generationSettings) apply(req *types.Request) {
+	req.Temperature = g.temperature
+	req.TopP = g.topP
+	req.TopK = g.topK
+	req.StopSequences = g.stopSequences
+	req.Seed = g.seed
+	req.PresencePenalty = g.presencePenalty
+	req.FrequencyPenalty = g.frequencyPenalty
+	req.ToolChoice = g.toolChoice
+	req.ParallelToolCalls = g.parallelToolCalls
+}
+
+// WithTemperature sets the sampling temperature.
+func WithTemperature(temperature float64) Option {
+	return func(a *Agent) { a.generation.temperature = &temperature }
+}
+
+// WithTopP sets nucleus sampling probability mass.
+func WithTopP(topP float64) Option {
+	return func(a *Agent) { a.generation.topP = &topP }
+}
+
+// WithTopK limits sampling to the k most likely tokens.
+func WithTopK(topK int) Option {
+	return func(a *Agent) {
+		if topK > 0 {
+			a.generation.topK = &topK
+		}
+	}
+}
+
+// WithStopSequences sets sequences that end generation when produced.
+func WithStopSequences(stop ...string) Option {
+	return func(a *Agent) { a.generation.stopSequences = append([]string(nil), stop...) }
+}
+
+// WithSeed requests deterministic sampling where the provider supports it.
+func WithSeed(seed int64) Option {
+	return func(a *Agent) { a.generation.seed = &seed }
+}
+
+// WithPresencePenalty penalizes tokens that have already appeared at all.
+func WithPresencePenalty(penalty float64) Option {
+	return func(a *Agent) { a.generation.presencePenalty = &penalty }
+}
+
+// WithFrequencyPenalty penalizes tokens by how often they have appeared.
+func WithFrequencyPenalty(penalty float64) Option {
+	return func(a *Agent) { a.generation.frequencyPenalty = &penalty }
+}
+
+// WithToolChoice controls whether the model may, must or must not call tools.
+// Use types.ToolChoiceTool with a name to force a specific tool. Forcing modes
+// apply to the first model turn only so the run can still finish.
+func WithToolChoice(choice types.ToolChoice) Option {
+	return func(a *Agent) {
+		if choice.Mode == "" {
+			a.generation.toolChoice = nil
+			return
+		}
+		a.generation.toolChoice = &choice
+	}
+}
+
+// WithParallelToolCallsHint tells the provider whether the model may return
+// several tool calls in one turn. It is independent of WithParallelToolCalls,
+// which controls how the agent executes the calls it receives.
+func WithParallelToolCallsHint(enabled bool) Option {
+	return func(a *Agent) { a.generation.parallelToolCalls = &enabled }
+}
+
+// UnsupportedGenerationControls lists configured sampling controls that the
+// agent's provider does not honour.
+func (a *Agent) UnsupportedGenerationControls() []string {
+	var req types.Request
+	a.generation.apply(&req)
+	return a.provider.Capabilities().Unsupported(req)
+}
@@ -0,0 +1,223 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const (
+	// DefaultSessionMemoryRuns is how many prior runs are loaded when
+	// SessionMemory.MaxRuns is not set.
+	DefaultSessionMemoryRuns = 20
+
+	defaultSessionLockTTL = 5 * time.Minute
+	historyMessagesKey    = "history_messages"
+	// historySummaryKey is the run metadata key the summary of the session
+	// history beyond MaxMessages is persisted under.
+	historySummaryKey = "history_summary"
+)
+
+// SessionMemory configures how an agent with a store and a session ID
+// continues the session's conversation. Before each run the messages of the
+// session's completed runs, including tool calls and tool results, are
+// loaded from the store and placed before the new input.
+type SessionMemory struct {
+	// MaxRuns is how many of the most recent completed runs are loaded.
+	// Defaults to DefaultSessionMemoryRuns.
+	MaxRuns int
+	// MaxMessages keeps only the most recent loaded messages. Zero keeps all
+	// of them and leaves trimming to the context manager.
+	MaxMessages int
+	// Summarize replaces the messages beyond MaxMessages with a single
+	// summary message instead of dropping them. The summary is written by
+	// the agent's summarizer (see WithSummarizer) and extended with only
+	// the messages it does not cover yet on later runs; without a
+	// summarizer, or when it fails, the messages are abbreviated instead.
+	Summarize bool
+	// LockTTL bounds how long the session lock outlives a run whose process
+	// dies, on stores that implement state.RunLocker; the lock is renewed
+	// while the run lasts. Defaults to five minutes.
+	LockTTL time.Duration
+}
+
+// WithSessionMemory loads prior turns of the session from the store before
+// each run. Runs of one session are serialized: within the process, and
+// across processes through the store's run lock when it implements
+// state.RunLocker (for example the redis store). Runs of other sessions are
+// not held up.
+func WithSessionMemory(memory SessionMemory) Option {
+	return func(a *Agent) {
+		if memory.MaxRuns <= 0 {
+			memory.MaxRuns = DefaultSessionMemoryRuns
+		}
+		if memory.MaxMessages < 0 {
+			memory.MaxMessages = 0
+		}
+		if memory.LockTTL <= 0 {
+			memory.LockTTL = defaultSessionLockTTL
+		}
+		a.sessionMemory = &memory
+	}
+}
+
+func (a *Agent) sessionMemoryEnabled() bool {
+	return a.sessionMemory != nil && a.store != nil
+}
+
+// lockSession serializes runs of sessionID until the returned release
+// function is called.
+func (a *Agent) lockSession(ctx context.Context, sessionID string) (func(), error) {
+	release, err := state.LockRun(ctx, a.store, "session:"+sessionID, a.sessionMemory.LockTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock session: %w", err)
+	}
+	return release, nil
+}
+
+// loadSessionHistory returns the messages of the session's completed runs,
+// oldest first, with the window or summary policy applied, and the running
+// context summary of the latest run. Each run record also holds the history
+// it was given, so only the messages the run added itself are taken from it.
+func (a *Agent) loadSessionHistory(ctx context.Context, sessionID string) ([]types.Message, *Summary, *Summary, error) {
+	runs, err := a.store.ListRuns(ctx, state.ListRunsQuery{
+		SessionID: sessionID,
+		Status:    "completed",
+		Limit:     a.sessionMemory.MaxRuns,
+	})
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to load session history: %w", err)
+	}
+	sort.SliceStable(runs, func(i, j int) bool {
+		return runCreatedAt(runs[i]).Before(runCreatedAt(runs[j]))
+	})
+
+	var (
+		history        []types.Message
+		summary        *Summary
+		historySummary *Summary
+	)
+	for _, run := range runs {
+		if run.Status != "completed" {
+			continue
+		}
+		own := run.Messages
+		if n := historyMessages(run.Metadata); n > 0 && n <= len(own) {
+			own = own[n:]
+		}
+		history = append(history, own...)
+		summary = contextSummary(run.Metadata)
+		historySummary = metadataSummary(run.Metadata, historySummaryKey)
+	}
+
+	if limit := a.sessionMemory.MaxMessages; limit > 0 && len(history) > limit {
+		dropped := history[:len(history)-limit]
+		history = append([]types.Message(nil), history[len(history)-limit:]...)
+		// A window that starts inside a tool exchange would leave orphaned
+		// tool results behind.
+		history = a.contextManager.ensureValidStructure(history)
+		if a.sessionMemory.Summarize {
+			var msg types.Message
+			msg, historySummary = a.summarizeHistory(ctx, dropped, historySummary)
+			history = append([]types.Message{msg}, history...)
+		}
+	}
+	return history, summary, historySummary, nil
+}
+
+// summarizeHistory summarizes the session messages that fell out of the
+// window with the agent's summarizer, extending prev when it covers their
+// beginning. Without a summarizer, or when it fails, the messages are
+// abbreviated instead.
+func (a *Agent) summarizeHistory(ctx context.Context, dropped []types.Message, prev *Summary) (types.Message, *Summary) {
+	if a.summarizer == nil {
+		return a.contextManager.SummarizeMessages(dropped), nil
+	}
+	previous, start := "", 0
+	if prev != nil && prev.Messages > 0 && prev.Messages <= len(dropped) && prev.Digest == digestMessages(dropped[:prev.Messages]) {
+		previous, start = prev.Text, prev.Messages
+	}
+	summary := prev
+	if start < len(dropped) {
+		text, err := a.summarizer.Summarize(ctx, previous, dropped[start:])
+		if err != nil {
+			log.Printf("⚠️  session history summarization failed: %v; abbreviating history instead", err)
+			return a.contextManager.SummarizeMessages(dropped), nil
+		}
+		summary = &Summary{Text: strings.TrimSpace(text), Messages: len(dropped), Digest: digestMessages(dropped)}
+	}
+	return summaryMessage(summary.Text), summary
+}
+
+func runCreatedAt(run state.RunRecord) time.Time {
+	if run.CreatedAt != nil {
+		return *run.CreatedAt
+	}
+	if run.UpdatedAt != nil {
+		return *run.UpdatedAt
+	}
+	return time.Time{}
+}
+
+// historyMessages reads the number of loaded history messages from run
+// metadata. Stores that round-trip metadata through JSON return a float64.
+func historyMessages(metadata map[string]any) int {
+	switch v := metadata[historyMessagesKey].(type) {
+	case int:
+		return v
+	case float64:
+		return int(v)
+	default:
+		return 0
+	}
+}
+
+// contextSummary reads the running context summary from run metadata.
+func contextSummary(metadata map[string]any) *Summary {
+	return metadataSummary(metadata, contextSummaryKey)
+}
+
+// metadataSummary reads a summary from run metadata, which holds a *Summary
+// in memory and a decoded JSON object once stored.
+func metadataSummary(metadata map[string]any, key string) *Summary {
+	switch v := metadata[key].(type) {
+	case nil:
+		return nil
+	case *Summary:
+		return v
+	default:
+		raw, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		var summary Summary
+		if err := json.Unmarshal(raw, &summary); err != nil || summary.Text == "" {
+			return nil
+		}
+		return &summary
+	}
+}
+
+// runMemory holds the per-run values that are persisted in the run's
+// metadata so later runs of the session can build on them.
+type runMemory struct {
+	historyMessages int
+	summary         *Summary
+	// historySummary summarizes the session history beyond MaxMessages.
+	historySummary *Summary
+	// approvals are the decisions of the run's resolved approval requests.
+	approvals []ApprovalDecision
+}
+
+type runMemoryContextKey struct{}
+
+func withRunMemory(ctx context.Context, memory *runMemory) context.Context {
+	return context.WithValue(ctx, runMemoryContextKey{}, memory)
+}
@@ -0,0 +1,262 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// sessionProvider calls test_tool once per run and then answers, recording
+// the messages of every request.
+type sessionProvider struct {
+	mu       sync.Mutex
+	requests [][]types.Message
+}
+
+func (p *sessionProvider) Name() string { return "session" }
+
+func (p *sessionProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *sessionProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.mu.Lock()
+	p.requests = append(p.requests, append([]types.Message(nil), req.Messages...))
+	p.mu.Unlock()
+
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role == types.RoleUser {
+		return types.Response{Message: types.Message{
+			Role:      types.RoleAssistant,
+			ToolCalls: []types.ToolCall{{ID: "call-" + last.Content, Name: "test_tool", Arguments: json.RawMessage(`{}`)}},
+		}}, nil
+	}
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "done"}}, nil
+}
+
+// firstRequests returns the messages of each run's first request.
+func (p *sessionProvider) firstRequests() [][]types.Message {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var out [][]types.Message
+	for _, msgs := range p.requests {
+		if msgs[len(msgs)-1].Role == types.RoleUser {
+			out = append(out, msgs)
+		}
+	}
+	return out
+}
+
+func newSessionAgent(t *testing.T, provider llm.Provider, store *memoryStateStore, memory SessionMemory) *Agent {
+	t.Helper()
+	tool := tools.NewFuncTool("test_tool", "test", map[string]any{"type": "object"}, func(ctx context.Context, args json.RawMessage) (any, error) {
+		return "tool output", nil
+	})
+	a, err := New(provider, WithStore(store), WithSessionID("s1"), WithSessionMemory(memory), WithTool(tool))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	return a
+}
+
+func TestAgent_SessionMemory_LoadsPriorTurnsWithTools(t *testing.T) {
+	provider := &sessionProvider{}
+	store := newMemoryStateStore()
+	for _, input := range []string{"first", "second", "third"} {
+		// A fresh agent per run, as a server handling one request at a time would.
+		a := newSessionAgent(t, provider, store, SessionMemory{})
+		if _, err := a.Run(context.Background(), input); err != nil {
+			t.Fatalf("run %q: %v", input, err)
+		}
+	}
+
+	first := provider.firstRequests()
+	if len(first) != 3 {
+		t.Fatalf("expected 3 runs, got %d", len(first))
+	}
+	for i, want := range []int{1, 5, 9} {
+		if len(first[i]) != want {
+			t.Fatalf("run %d: expected %d messages, got %d: %+v", i+1, want, len(first[i]), first[i])
+		}
+	}
+	third := first[2]
+	if third[0].Content != "first" || third[4].Content != "second" || third[8].Content != "third" {
+		t.Fatalf("expected turns in order, got %+v", third)
+	}
+	if len(third[1].ToolCalls) != 1 || third[2].Role != types.RoleTool || third[2].ToolCallID != "call-first" {
+		t.Fatalf("expected prior tool exchange to be loaded, got %+v", third[1:3])
+	}
+}
+
+func TestAgent_SessionMemory_WindowAndSummary(t *testing.T) {
+	provider := &sessionProvider{}
+	store := newMemoryStateStore()
+	for _, input := range []string{"first", "second"} {
+		a := newSessionAgent(t, provider, store, SessionMemory{})
+		if _, err := a.Run(context.Background(), input); err != nil {
+			t.Fatalf("run %q: %v", input, err)
+		}
+	}
+
+	windowed := newSessionAgent(t, provider, store, SessionMemory{MaxMessages: 2})
+	if _, err := windowed.Run(context.Background(), "third"); err != nil {
+		t.Fatalf("windowed run: %v", err)
+	}
+	msgs := provider.firstRequests()[2]
+	// The last two loaded messages are a tool result and the final answer;
+	// the orphaned result is dropped with its call.
+	if len(msgs) != 2 || msgs[0].Content != "done" || msgs[1].Content != "third" {
+		t.Fatalf("expected windowed history, got %+v", msgs)
+	}
+
+	summarized := newSessionAgent(t, provider, store, SessionMemory{MaxMessages: 3, Summarize: true})
+	if _, err := summarized.Run(context.Background(), "fourth"); err != nil {
+		t.Fatalf("summarized run: %v", err)
+	}
+	msgs = provider.firstRequests()[3]
+	if len(msgs) != 5 || !strings.Contains(msgs[0].Content, "[Previous conversation summary]") {
+		t.Fatalf("expected summary followed by the window, got %+v", msgs)
+	}
+	if !strings.Contains(msgs[0].Content, "User: first") {
+		t.Fatalf("expected summary of dropped turns, got %q", msgs[0].Content)
+	}
+}
+
+func TestAgent_SessionMemory_SerializesConcurrentRuns(t *testing.T) {
+	provider := &sessionProvider{}
+	store := newMemoryStateStore()
+	a := newSessionAgent(t, provider, store, SessionMemory{})
+
+	var wg sync.WaitGroup
+	for _, input := range []string{"a", "b"} {
+		wg.Add(1)
+		go func(input string) {
+			defer wg.Done()
+			if _, err := a.Run(context.Background(), input); err != nil {
+				t.Errorf("run %q: %v", input, err)
+			}
+		}(input)
+	}
+	wg.Wait()
+
+	first := provider.firstRequests()
+	if len(first) != 2 || len(first[0]) != 1 || len(first[1]) != 5 {
+		t.Fatalf("expected the second run to see the first one's turns, got %+v", first)
+	}
+}
+
+func TestAgent_SessionMemory_SummarizesWithSummarizer(t *testing.T) {
+	provider := &sessionProvider{}
+	store := newMemoryStateStore()
+	for _, input := range []string{"first", "second"} {
+		a := newSessionAgent(t, provider, store, SessionMemory{})
+		if _, err := a.Run(context.Background(), input); err != nil {
+			t.Fatalf("run %q: %v", input, err)
+		}
+	}
+
+	summarizer := &fakeSummarizer{}
+	for _, input := range []string{"third", "fourth"} {
+		a := newSessionAgent(t, provider, store, SessionMemory{MaxMessages: 3, Summarize: true})
+		WithSummarizer(summarizer)(a)
+		if _, err := a.Run(context.Background(), input); err != nil {
+			t.Fatalf("run %q: %v", input, err)
+		}
+	}
+
+	if len(summarizer.calls) != 2 {
+		t.Fatalf("expected 2 summarizer calls, got %d", len(summarizer.calls))
+	}
+	if first := summarizer.calls[0]; first.previous != "" || len(first.messages) != 5 || first.messages[0].Content != "first" {
+		t.Fatalf("expected the dropped turns to be summarized, got %+v", first)
+	}
+	// The next run extends the persisted summary with only the newly
+	// dropped messages.
+	if second := summarizer.calls[1]; second.previous != "summary-1" || len(second.messages) != 4 {
+		t.Fatalf("expected an incremental summary, got %+v", second)
+	}
+	msgs := provider.firstRequests()[3]
+	if !strings.Contains(msgs[0].Content, "summary-1 summary-2") {
+		t.Fatalf("expected the summarizer's summary, got %q", msgs[0].Content)
+	}
+}
+
+func TestAgent_SessionMemory_SerializesRunsAcrossAgents(t *testing.T) {
+	provider := &sessionProvider{}
+	store := newMemoryStateStore()
+
+	var wg sync.WaitGroup
+	for _, input := range []string{"a", "b"} {
+		a := newSessionAgent(t, provider, store, SessionMemory{})
+		wg.Add(1)
+		go func(input string) {
+			defer wg.Done()
+			if _, err := a.Run(context.Background(), input); err != nil {
+				t.Errorf("run %q: %v", input, err)
+			}
+		}(input)
+	}
+	wg.Wait()
+
+	first := provider.firstRequests()
+	if len(first) != 2 || len(first[0]) != 1 || len(first[1]) != 5 {
+		t.Fatalf("expected the second run to see the first one's turns, got %+v", first)
+	}
+}
+
+func TestAgent_SessionMemory_LocksPerSession(t *testing.T) {
+	provider := &sessionProvider{}
+	store := newMemoryStateStore()
+	release, err := state.LockRun(context.Background(), store, "session:s1", time.Minute)
+	if err != nil {
+		t.Fatalf("lock session: %v", err)
+	}
+	defer release()
+
+	other, err := New(provider, WithStore(store), WithSessionID("s2"), WithSessionMemory(SessionMemory{}), WithTool(tools.NewFuncTool("test_tool", "test", map[string]any{"type": "object"}, func(ctx context.Context, args json.RawMessage) (any, error) {
+		return "ok", nil
+	})))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+	if _, err := other.Run(ctx, "hello"); err != nil {
+		t.Fatalf("expected another session to run while s1 is locked: %v", err)
+	}
+
+	locked := newSessionAgent(t, provider, store, SessionMemory{})
+	ctx, cancel = context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := locked.Run(ctx, "hello"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the locked session to wait, got %v", err)
+	}
+}
+
+func TestAgent_SessionMemory_RequiresStore(t *testing.T) {
+	provider := &sessionProvider{}
+	a, err := New(provider, WithSessionID("s1"), WithSessionMemory(SessionMemory{}), WithTool(tools.NewFuncTool("test_tool", "test", map[string]any{"type": "object"}, func(ctx context.Context, args json.RawMessage) (any, error) {
+		return "ok", nil
+	})))
+	if err != nil {
+		t.Fatalf("new agent: %v", err)
+	}
+	for _, input := range []string{"first", "second"} {
+		if _, err := a.Run(context.Background(), input); err != nil {
+			t.Fatalf("run %q: %v", input, err)
+		}
+	}
+	if first := provider.firstRequests(); len(first[1]) != 1 {
+		t.Fatalf("expected no history without a store, got %+v", first[1])
+	}
+}
@@ -0,0 +1,228 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/storage"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// DefaultToolOutputPreviewBytes is how much of a spooled tool result the
+// model sees when ToolOutputBudget.PreviewBytes is not set.
+const DefaultToolOutputPreviewBytes = 2000
+
+// ToolOutputBudget caps how much tool output enters the conversation. A
+// result whose JSON encoding is larger than its tool's limit is saved as an
+// artifact, and the model receives a preview and the artifact path, which
+// it can page or grep through with the artifact_reader tool.
+type ToolOutputBudget struct {
+	// MaxBytes is the limit for tools without an entry in PerTool. Zero
+	// leaves them unlimited.
+	MaxBytes int
+	// PerTool overrides MaxBytes by tool name; zero exempts the tool.
+	PerTool map[string]int
+	// PreviewBytes is the size of the preview; it defaults to
+	// DefaultToolOutputPreviewBytes and never exceeds the tool's limit.
+	PreviewBytes int
+	// Storage saves the artifacts, and uploads them to S3 when it is
+	// configured to. It defaults to storage.Default().
+	Storage *storage.Manager
+}
+
+// WithToolOutputBudget spools tool results larger than the budget to
+// artifacts. The artifact_reader tool is added unless the agent already has
+// a tool with that name.
+func WithToolOutputBudget(budget ToolOutputBudget) Option {
+	return func(a *Agent) { a.outputBudget = &budget }
+}
+
+// ToolOutputBudgetFromEnv builds a budget from AGENT_TOOL_OUTPUT_MAX_BYTES,
+// AGENT_TOOL_OUTPUT_LIMITS (tool=bytes pairs separated by commas) and
+// AGENT_TOOL_OUTPUT_PREVIEW_BYTES. It returns nil when no limit is set.
+func ToolOutputBudgetFromEnv() (*ToolOutputBudget, error) {
+	var budget ToolOutputBudget
+	var err error
+	if budget.MaxBytes, err = envBytes("AGENT_TOOL_OUTPUT_MAX_BYTES"); err != nil {
+		return nil, err
+	}
+	if budget.PreviewBytes, err = envBytes("AGENT_TOOL_OUTPUT_PREVIEW_BYTES"); err != nil {
+		return nil, err
+	}
+	for _, raw := range strings.Split(os.Getenv("AGENT_TOOL_OUTPUT_LIMITS"), ",") {
+		if raw = strings.TrimSpace(raw); raw == "" {
+			continue
+		}
+		tool, limit, ok := strings.Cut(raw, "=")
+		n, err := strconv.Atoi(strings.TrimSpace(limit))
+		if !ok || strings.TrimSpace(tool) == "" || err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid AGENT_TOOL_OUTPUT_LIMITS entry %q", raw)
+		}
+		if budget.PerTool == nil {
+			budget.PerTool = map[string]int{}
+		}
+		budget.PerTool[strings.TrimSpace(tool)] = n
+	}
+	if budget.MaxBytes == 0 && len(budget.PerTool) == 0 {
+		return nil, nil
+	}
+	return &budget, nil
+}
+
+func envBytes(key string) (int, error) {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid %s %q", key, raw)
+	}
+	return n, nil
+}
+
+func (b *ToolOutputBudget) limit(tool string) int {
+	if n, ok := b.PerTool[tool]; ok {
+		return n
+	}
+	return b.MaxBytes
+}
+
+func (b *ToolOutputBudget) storage() *storage.Manager {
+	if b.Storage != nil {
+		return b.Storage
+	}
+	return storage.Default()
+}
+
+// addArtifactReader gives an agent with an output budget the tool to read
+// spooled results.
+func (a *Agent) addArtifactReader() {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if _, exists := a.tools[tools.ArtifactReaderName]; !exists {
+		a.tools[tools.ArtifactReaderName] = tools.NewArtifactReader(a.outputBudget.storage())
+	}
+}
+
+var artifactNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9._-]+`)
+
+// toolOutputDir is the directory a run's tool output is spooled to, and
+// the only one its artifact_reader calls may read.
+func toolOutputDir(runID string) string {
+	return "tool-outputs/" + artifactNameInvalid.ReplaceAllString(runID, "-")
+}
+
+// spoolToolOutput returns encoded unchanged when it fits the tool's budget.
+// Otherwise it saves the output under tool-outputs/<run>/<call>.txt and
+// returns the encoded preview that replaces it in the conversation. Calls
+// without an ID are named by their tool, iteration and index in the model's
+// message instead.
+func (a *Agent) spoolToolOutput(ctx context.Context, runID string, iteration, index int, call types.ToolCall, payload any, encoded []byte) []byte {
+	if a.outputBudget == nil || call.Name == tools.ArtifactReaderName {
+		return encoded
+	}
+	limit := a.outputBudget.limit(call.Name)
+	if limit <= 0 || len(encoded) <= limit {
+		return encoded
+	}
+	previewBytes := a.outputBudget.PreviewBytes
+	if previewBytes <= 0 {
+		previewBytes = DefaultToolOutputPreviewBytes
+	}
+	// The preview must not carry more than the budget allows.
+	previewBytes = min(previewBytes, limit)
+
+	text := artifactText(payload, encoded)
+	callID := call.ID
+	if callID == "" {
+		callID = fmt.Sprintf("%s-%d-%d", call.Name, iteration, index)
+	}
+	path := toolOutputDir(runID) + "/" + artifactNameInvalid.ReplaceAllString(callID, "-") + ".txt"
+	spooled := map[string]any{
+		"truncated": true,
+		"bytes":     len(text),
+		"lines":     strings.Count(strings.TrimSuffix(text, "\n"), "\n") + 1,
+		"preview":   truncateUTF8(text, previewBytes),
+	}
+	saved, err := a.outputBudget.storage().SaveBytes(ctx, path, "", []byte(text))
+	if err != nil {
+		spooled["artifactError"] = err.Error()
+		spooled["hint"] = fmt.Sprintf("Output exceeded %d bytes and could not be stored; only the preview is available.", limit)
+	} else {
+		spooled["artifact"] = path
+		if saved.Backup != nil {
+			spooled["backup"] = saved.Backup
+		}
+		spooled["hint"] = fmt.Sprintf("Output exceeded %d bytes and was stored. Call %s with this artifact to read lines by offset/limit or grep for a pattern.", limit, tools.ArtifactReaderName)
+	}
+	out, err := json.Marshal(spooled)
+	if err != nil {
+		return encoded
+	}
+	return out
+}
+
+// artifactText renders tool output for line-oriented reading. Strings are
+// stored as is. For objects, multi-line string fields such as stdout or
+// logs are written out as plain text sections after the JSON of the other
+// fields, so paging and grep see real lines instead of escaped ones.
+func artifactText(payload any, encoded []byte) string {
+	if s, ok := payload.(string); ok {
+		return s
+	}
+	var value any
+	if err := json.Unmarshal(encoded, &value); err != nil {
+		return string(encoded)
+	}
+	obj, ok := value.(map[string]any)
+	if !ok {
+		indented, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return string(encoded)
+		}
+		return string(indented)
+	}
+
+	sections := map[string]string{}
+	var keys []string
+	for key, v := range obj {
+		if s, ok := v.(string); ok && strings.Contains(s, "\n") {
+			sections[key] = s
+			keys = append(keys, key)
+			delete(obj, key)
+		}
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	if len(obj) > 0 || len(keys) == 0 {
+		indented, _ := json.MarshalIndent(obj, "", "  ")
+		b.Write(indented)
+		b.WriteString("\n")
+	}
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n===== %s =====\n%s", key, sections[key])
+		if !strings.HasSuffix(sections[key], "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+func truncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,291 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/storage"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// spoolProvider calls the logs tool, then greps the artifact it is handed
+// back with artifact_reader, or the artifact it is told to, then finishes.
+// It records the tool results.
+type spoolProvider struct {
+	artifact string
+	calls    int
+	results  []string
+}
+
+func (p *spoolProvider) Name() string { return "spool-provider" }
+
+func (p *spoolProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *spoolProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role == types.RoleTool {
+		p.results = append(p.results, last.Content)
+	}
+	p.calls++
+	switch p.calls {
+	case 1:
+		return types.Response{Message: types.Message{
+			Role:      types.RoleAssistant,
+			ToolCalls: []types.ToolCall{{ID: "call-1", Name: "logs", Arguments: json.RawMessage(`{}`)}},
+		}}, nil
+	case 2:
+		var spooled struct {
+			Artifact string `json:"artifact"`
+		}
+		if err := json.Unmarshal([]byte(last.Content), &spooled); err != nil || spooled.Artifact == "" {
+			return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "no artifact"}}, nil
+		}
+		if p.artifact != "" {
+			spooled.Artifact = p.artifact
+		}
+		args, _ := json.Marshal(map[string]any{"artifact": spooled.Artifact, "operation": "grep", "pattern": "ERROR"})
+		return types.Response{Message: types.Message{
+			Role:      types.RoleAssistant,
+			ToolCalls: []types.ToolCall{{ID: "call-2", Name: tools.ArtifactReaderName, Arguments: args}},
+		}}, nil
+	default:
+		return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "done"}}, nil
+	}
+}
+
+func newLogsTool(lines int) tools.Tool {
+	return tools.NewFuncTool("logs", "read logs", map[string]any{"type": "object"}, func(ctx context.Context, args json.RawMessage) (any, error) {
+		_ = ctx
+		var b strings.Builder
+		for i := 1; i <= lines; i++ {
+			level := "INFO"
+			if i == lines/2 {
+				level = "ERROR"
+			}
+			fmt.Fprintf(&b, "%s line %d\n", level, i)
+		}
+		return map[string]any{"exitCode": 0, "stdout": b.String()}, nil
+	})
+}
+
+func newTestStorage(t *testing.T) *storage.Manager {
+	t.Helper()
+	t.Setenv("AGENT_STORAGE_DIR", t.TempDir())
+	return storage.NewFromEnv()
+}
+
+func TestAgent_ToolOutputBudget_SpoolsOversizeOutput(t *testing.T) {
+	mgr := newTestStorage(t)
+	provider := &spoolProvider{}
+	a, err := New(provider,
+		WithTool(newLogsTool(1000)),
+		WithToolOutputBudget(ToolOutputBudget{MaxBytes: 4096, PreviewBytes: 100, Storage: mgr}),
+	)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	result, err := a.RunDetailed(context.Background(), "check the logs")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(provider.results) != 2 {
+		t.Fatalf("expected 2 tool results, got %d: %v", len(provider.results), provider.results)
+	}
+
+	var spooled map[string]any
+	if err := json.Unmarshal([]byte(provider.results[0]), &spooled); err != nil {
+		t.Fatalf("spooled result is not JSON: %v", err)
+	}
+	if len(provider.results[0]) > 1024 {
+		t.Fatalf("expected the spooled result to be small, got %d bytes", len(provider.results[0]))
+	}
+	if spooled["truncated"] != true || len(spooled["preview"].(string)) > 100 {
+		t.Fatalf("unexpected spooled result: %v", spooled)
+	}
+	artifact, _ := spooled["artifact"].(string)
+	if artifact != fmt.Sprintf("tool-outputs/%s/call-1.txt", result.RunID) {
+		t.Fatalf("unexpected artifact path %q", artifact)
+	}
+	stored, err := os.ReadFile(filepath.Join(mgr.BaseDir(), artifact))
+	if err != nil {
+		t.Fatalf("artifact was not stored: %v", err)
+	}
+	// Multi-line fields are stored as real lines rather than escaped JSON.
+	if !strings.Contains(string(stored), "===== stdout =====\nINFO line 1\n") {
+		t.Fatalf("unexpected artifact content: %.200s", stored)
+	}
+
+	if !strings.Contains(provider.results[1], `"line":505`) || !strings.Contains(provider.results[1], "ERROR line 500") {
+		t.Fatalf("expected the grep to find the error line, got %s", provider.results[1])
+	}
+}
+
+// idlessLogsProvider calls the logs tool twice in one message, without call
+// IDs, then finishes. It records the tool results.
+type idlessLogsProvider struct {
+	calls   int
+	results []string
+}
+
+func (p *idlessLogsProvider) Name() string { return "idless-logs-provider" }
+
+func (p *idlessLogsProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *idlessLogsProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.calls++
+	if p.calls == 1 {
+		return types.Response{Message: types.Message{
+			Role: types.RoleAssistant,
+			ToolCalls: []types.ToolCall{
+				{Name: "logs", Arguments: json.RawMessage(`{}`)},
+				{Name: "logs", Arguments: json.RawMessage(`{}`)},
+			},
+		}}, nil
+	}
+	for _, msg := range req.Messages {
+		if msg.Role == types.RoleTool {
+			p.results = append(p.results, msg.Content)
+		}
+	}
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "done"}}, nil
+}
+
+func TestAgent_ToolOutputBudget_CallsWithoutIDs(t *testing.T) {
+	mgr := newTestStorage(t)
+	provider := &idlessLogsProvider{}
+	// The default preview is larger than the limit, so the limit caps it.
+	a, err := New(provider,
+		WithTool(newLogsTool(1000)),
+		WithToolOutputBudget(ToolOutputBudget{MaxBytes: 500, Storage: mgr}),
+	)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	result, err := a.RunDetailed(context.Background(), "check the logs twice")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(provider.results) != 2 {
+		t.Fatalf("expected 2 tool results, got %d", len(provider.results))
+	}
+	var artifacts []string
+	for _, raw := range provider.results {
+		var spooled map[string]any
+		if err := json.Unmarshal([]byte(raw), &spooled); err != nil {
+			t.Fatalf("spooled result is not JSON: %v", err)
+		}
+		if preview, _ := spooled["preview"].(string); len(preview) > 500 {
+			t.Fatalf("expected the preview to fit the limit, got %d bytes", len(preview))
+		}
+		artifact, _ := spooled["artifact"].(string)
+		artifacts = append(artifacts, artifact)
+	}
+	want := []string{
+		fmt.Sprintf("tool-outputs/%s/logs-1-0.txt", result.RunID),
+		fmt.Sprintf("tool-outputs/%s/logs-1-1.txt", result.RunID),
+	}
+	if artifacts[0] != want[0] || artifacts[1] != want[1] {
+		t.Fatalf("expected an artifact per call, got %v", artifacts)
+	}
+}
+
+func TestAgent_ToolOutputBudget_ReadsOnlyTheRunsArtifacts(t *testing.T) {
+	mgr := newTestStorage(t)
+	if _, err := mgr.SaveBytes(context.Background(), "tool-outputs/other-run/call-1.txt", "", []byte("ERROR secret\n")); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	provider := &spoolProvider{artifact: "tool-outputs/other-run/call-1.txt"}
+	a, err := New(provider,
+		WithTool(newLogsTool(1000)),
+		WithToolOutputBudget(ToolOutputBudget{MaxBytes: 4096, Storage: mgr}),
+	)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	if _, err := a.Run(context.Background(), "check the logs"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(provider.results) != 2 || strings.Contains(provider.results[1], "secret") || !strings.Contains(provider.results[1], "is not under tool-outputs/") {
+		t.Fatalf("expected another run's artifact to be refused, got %v", provider.results)
+	}
+}
+
+func TestAgent_ToolOutputBudget_PerToolLimit(t *testing.T) {
+	mgr := newTestStorage(t)
+	provider := &spoolProvider{}
+	a, err := New(provider,
+		WithTool(newLogsTool(1000)),
+		WithToolOutputBudget(ToolOutputBudget{MaxBytes: 4096, PerTool: map[string]int{"logs": 0}, Storage: mgr}),
+	)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	if _, err := a.Run(context.Background(), "check the logs"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(provider.results) == 0 || !strings.Contains(provider.results[0], `INFO line 1000`) {
+		t.Fatalf("expected the exempt tool's full output")
+	}
+	if _, err := os.Stat(filepath.Join(mgr.BaseDir(), "tool-outputs")); !os.IsNotExist(err) {
+		t.Fatalf("expected nothing to be spooled, stat err: %v", err)
+	}
+}
+
+func TestAgent_ToolOutputBudget_AddsArtifactReader(t *testing.T) {
+	a, err := New(&spoolProvider{}, WithToolOutputBudget(ToolOutputBudget{MaxBytes: 1024}))
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	if _, ok := a.snapshotTools()[tools.ArtifactReaderName]; !ok {
+		t.Fatalf("expected %s to be added", tools.ArtifactReaderName)
+	}
+
+	b, err := New(&spoolProvider{})
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	if _, ok := b.snapshotTools()[tools.ArtifactReaderName]; ok {
+		t.Fatalf("did not expect %s without a budget", tools.ArtifactReaderName)
+	}
+}
+
+func TestToolOutputBudgetFromEnv(t *testing.T) {
+	t.Setenv("AGENT_TOOL_OUTPUT_MAX_BYTES", "")
+	t.Setenv("AGENT_TOOL_OUTPUT_LIMITS", "")
+	t.Setenv("AGENT_TOOL_OUTPUT_PREVIEW_BYTES", "")
+	if budget, err := ToolOutputBudgetFromEnv(); err != nil || budget != nil {
+		t.Fatalf("expected no budget, got %+v, %v", budget, err)
+	}
+
+	t.Setenv("AGENT_TOOL_OUTPUT_MAX_BYTES", "65536")
+	t.Setenv("AGENT_TOOL_OUTPUT_LIMITS", "kubectl=20000, calculator=0")
+	t.Setenv("AGENT_TOOL_OUTPUT_PREVIEW_BYTES", "500")
+	budget, err := ToolOutputBudgetFromEnv()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if budget.MaxBytes != 65536 || budget.PreviewBytes != 500 {
+		t.Fatalf("unexpected budget: %+v", budget)
+	}
+	if budget.limit("kubectl") != 20000 || budget.limit("calculator") != 0 || budget.limit("curl") != 65536 {
+		t.Fatalf("unexpected per-tool limits: %v", budget.PerTool)
+	}
+
+	t.Setenv("AGENT_TOOL_OUTPUT_LIMITS", "kubectl")
+	if _, err := ToolOutputBudgetFromEnv(); err == nil {
+		t.Fatalf("expected an error for an entry without a limit")
+	}
+}
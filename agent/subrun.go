@@ -0,0 +1,220 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/delivery"
+	"github.com/PipeOpsHQ/agent-sdk-go/observe"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// DefaultMaxSubRunDepth is how deeply agents exposed as tools may call one
+// another before further calls are refused.
+const DefaultMaxSubRunDepth = 3
+
+// ErrMaxSubRunDepth is returned by an agent tool called beyond its depth
+// limit.
+var ErrMaxSubRunDepth = errors.New("sub-run depth limit reached")
+
+// RunFunc starts a run. Agent.RunDetailed and graph.Executor.Run match it.
+type RunFunc func(ctx context.Context, input string) (types.RunResult, error)
+
+type agentToolConfig struct {
+	schema   map[string]any
+	input    func(args json.RawMessage) (string, error)
+	maxDepth int
+}
+
+// AgentToolOption configures NewAgentTool.
+type AgentToolOption func(*agentToolConfig)
+
+// WithAgentToolSchema replaces the default {"task": string} arguments.
+// input builds the sub-run's input from the call arguments; when it is nil
+// the arguments JSON is passed as the input.
+func WithAgentToolSchema(schema map[string]any, input func(args json.RawMessage) (string, error)) AgentToolOption {
+	return func(c *agentToolConfig) {
+		if schema != nil {
+			c.schema = schema
+			c.input = input
+		}
+	}
+}
+
+// WithMaxSubRunDepth sets how many agent tool calls may be nested, counting
+// the call being made. It defaults to DefaultMaxSubRunDepth.
+func WithMaxSubRunDepth(depth int) AgentToolOption {
+	return func(c *agentToolConfig) {
+		if depth > 0 {
+			c.maxDepth = depth
+		}
+	}
+}
+
+// NewAgentTool exposes an agent or graph as a tool, e.g.
+//
+//	planner, _ := agent.New(provider, agent.WithTool(
+//		agent.NewAgentTool("researcher", "Research a topic and report findings.", researcher.RunDetailed),
+//	))
+//
+// Each call is an isolated sub-run with its own run ID, recorded with the
+// calling run as its parent_run_id. Its trace is nested under the tool
+// call's span and its token usage is added to the calling run's usage.
+// Agents with session memory run one at a time, so such an agent must not
+// be reachable from its own runs.
+func NewAgentTool(name, description string, run RunFunc, opts ...AgentToolOption) tools.Tool {
+	cfg := agentToolConfig{
+		schema: map[string]any{
+			"type": "object",
+			"properties": map[string]any{
+				"task": map[string]any{
+					"type":        "string",
+					"description": "The task to hand off, with any context needed to complete it.",
+				},
+			},
+			"required": []string{"task"},
+		},
+		input:    taskInput,
+		maxDepth: DefaultMaxSubRunDepth,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return tools.NewFuncTool(name, description, cfg.schema, func(ctx context.Context, args json.RawMessage) (any, error) {
+		if run == nil {
+			return nil, fmt.Errorf("tool %q has no run function", name)
+		}
+		depth := subRunDepth(ctx) + 1
+		if depth > cfg.maxDepth {
+			return nil, fmt.Errorf("%w: %q would be nested %d deep (max %d)", ErrMaxSubRunDepth, name, depth, cfg.maxDepth)
+		}
+		input := string(args)
+		if cfg.input != nil {
+			var err error
+			if input, err = cfg.input(args); err != nil {
+				return nil, fmt.Errorf("invalid %s args: %w", name, err)
+			}
+		}
+
+		result, err := run(subRunContext(ctx, depth), input)
+		if err != nil {
+			return nil, fmt.Errorf("%s failed: %w", name, err)
+		}
+		if scope := toolCallScopeFromContext(ctx); scope != nil {
+			scope.addUsage(result.Usage)
+		}
+		return map[string]any{
+			"output":     result.Output,
+			"runId":      result.RunID,
+			"iterations": result.Iterations,
+		}, nil
+	})
+}
+
+// AsTool exposes the agent as a tool; see NewAgentTool.
+func (a *Agent) AsTool(name, description string, opts ...AgentToolOption) tools.Tool {
+	return NewAgentTool(name, description, a.RunDetailed, opts...)
+}
+
+func taskInput(args json.RawMessage) (string, error) {
+	var in struct {
+		Task string `json:"task"`
+	}
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(in.Task) == "" {
+		return "", errors.New("task is required")
+	}
+	return in.Task, nil
+}
+
+type subRunDepthContextKey struct{}
+
+func subRunDepth(ctx context.Context) int {
+	depth, _ := ctx.Value(subRunDepthContextKey{}).(int)
+	return depth
+}
+
+// subRunContext isolates a sub-run from the run that called it: it gets a
+// run ID of its own rather than one assigned to the caller, and neither the
+// caller's output schema nor its attachments. It keeps the caller's
+// cancellation and deadline.
+func subRunContext(ctx context.Context, depth int) context.Context {
+	ctx = context.WithValue(ctx, subRunDepthContextKey{}, depth)
+	ctx = context.WithValue(ctx, runIDContextKey{}, "")
+	ctx = context.WithValue(ctx, outputSpecContextKey{}, (*outputSpec)(nil))
+	ctx = context.WithValue(ctx, attachmentsContextKey{}, []types.ContentPart(nil))
+	ctx = delivery.WithTurnType(ctx, "tool")
+	if scope := toolCallScopeFromContext(ctx); scope != nil {
+		ctx = delivery.WithParentRunID(ctx, scope.runID)
+		ctx = observe.WithParentSpanID(ctx, observe.ToolSpanID(scope.runID, scope.iteration, scope.callID))
+		ctx = context.WithValue(ctx, toolCallScopeContextKey{}, (*toolCallScope)(nil))
+	}
+	return ctx
+}
+
+// toolCallScope identifies the tool call a tool is executing for and
+// collects the usage of sub-runs it starts.
+type toolCallScope struct {
+	runID     string
+	iteration int
+	callID    string
+
+	mu    sync.Mutex
+	usage *types.Usage
+}
+
+type toolCallScopeContextKey struct{}
+
+func withToolCallScope(ctx context.Context, scope *toolCallScope) context.Context {
+	return context.WithValue(ctx, toolCallScopeContextKey{}, scope)
+}
+
+func toolCallScopeFromContext(ctx context.Context) *toolCallScope {
+	scope, _ := ctx.Value(toolCallScopeContextKey{}).(*toolCallScope)
+	return scope
+}
+
+func (s *toolCallScope) addUsage(usage *types.Usage) {
+	if usage == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.usage == nil {
+		s.usage = &types.Usage{}
+	}
+	addUsage(s.usage, usage)
+}
+
+func (s *toolCallScope) subRunUsage() *types.Usage {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return copyUsage(s.usage)
+}
+
+// addSubRunUsage adds the usage of sub-runs reported on after-tool events
+// to total, reporting whether there was any.
+func addSubRunUsage(total *types.Usage, events []types.Event) bool {
+	added := false
+	for _, event := range events {
+		if event.Type == types.EventAfterTool && event.Usage != nil {
+			addUsage(total, event.Usage)
+			added = true
+		}
+	}
+	return added
+}
+
+func addUsage(total, usage *types.Usage) {
+	total.InputTokens += usage.InputTokens
+	total.OutputTokens += usage.OutputTokens
+	total.TotalTokens += usage.TotalTokens
+	total.CostUSD += usage.CostUSD
+}
@@ -0,0 +1,205 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/graph"
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/observe"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// delegatingProvider hands the input to a tool once and answers with the
+// tool's result. Every generation reports usage.
+type delegatingProvider struct {
+	tool  string
+	usage types.Usage
+}
+
+func (p *delegatingProvider) Name() string { return "delegating-provider" }
+
+func (p *delegatingProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *delegatingProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	usage := p.usage
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role == types.RoleTool || p.tool == "" {
+		return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "answer: " + last.Content}, Usage: &usage}, nil
+	}
+	args, _ := json.Marshal(map[string]string{"task": last.Content})
+	return types.Response{
+		Message: types.Message{
+			Role:      types.RoleAssistant,
+			ToolCalls: []types.ToolCall{{ID: "call-1", Name: p.tool, Arguments: args}},
+		},
+		Usage: &usage,
+	}, nil
+}
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []observe.Event
+}
+
+func (r *eventRecorder) Emit(ctx context.Context, event observe.Event) error {
+	_ = ctx
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+	return nil
+}
+
+func TestAgentTool_SubRun(t *testing.T) {
+	store := newMemoryStateStore()
+	sink := &eventRecorder{}
+	researcher, err := New(&delegatingProvider{usage: types.Usage{InputTokens: 100, OutputTokens: 50, TotalTokens: 150}},
+		WithStore(store), WithObserver(sink))
+	if err != nil {
+		t.Fatalf("failed to build researcher: %v", err)
+	}
+	planner, err := New(&delegatingProvider{tool: "researcher", usage: types.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}},
+		WithStore(store), WithObserver(sink),
+		WithTool(researcher.AsTool("researcher", "Research a topic.")))
+	if err != nil {
+		t.Fatalf("failed to build planner: %v", err)
+	}
+
+	ctx := ContextWithRunID(context.Background(), "parent-run")
+	result, err := planner.RunDetailed(ctx, "find the latest release")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if !strings.Contains(result.Output, "answer: find the latest release") {
+		t.Fatalf("expected the researcher's answer to reach the planner, got %q", result.Output)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 2*15+150 {
+		t.Fatalf("expected sub-run usage rolled up into the parent, got %+v", result.Usage)
+	}
+
+	var sub []string
+	for id, run := range store.runs {
+		if id == "parent-run" {
+			continue
+		}
+		sub = append(sub, id)
+		if run.Metadata["parent_run_id"] != "parent-run" || run.Metadata["turn_type"] != "tool" {
+			t.Fatalf("unexpected sub-run metadata: %v", run.Metadata)
+		}
+		if run.Input != "find the latest release" || run.Status != "completed" {
+			t.Fatalf("unexpected sub-run record: %+v", run)
+		}
+	}
+	if len(sub) != 1 {
+		t.Fatalf("expected one sub-run with its own run ID, got %v", sub)
+	}
+
+	toolSpan := observe.ToolSpanID("parent-run", 1, "call-1")
+	nested := false
+	for _, event := range sink.events {
+		if event.RunID == sub[0] && event.Kind == observe.KindRun && event.Status == observe.StatusStarted {
+			nested = event.ParentSpanID == toolSpan
+		}
+		if event.RunID == "parent-run" && event.Kind == observe.KindRun && event.Status == observe.StatusStarted && event.ParentSpanID != "" {
+			t.Fatalf("expected the parent run to be a root span, got parent %q", event.ParentSpanID)
+		}
+	}
+	if !nested {
+		t.Fatalf("expected the sub-run to be nested under %q", toolSpan)
+	}
+}
+
+func TestAgentTool_SubRunWithToolTimeout(t *testing.T) {
+	store := newMemoryStateStore()
+	researcher, err := New(&delegatingProvider{usage: types.Usage{TotalTokens: 150}}, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build researcher: %v", err)
+	}
+	planner, err := New(&delegatingProvider{tool: "researcher", usage: types.Usage{TotalTokens: 15}},
+		WithStore(store), WithToolTimeout(time.Minute),
+		WithTool(researcher.AsTool("researcher", "Research a topic.")))
+	if err != nil {
+		t.Fatalf("failed to build planner: %v", err)
+	}
+
+	ctx := ContextWithRunID(context.Background(), "parent-run")
+	result, err := planner.RunDetailed(ctx, "find the latest release")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Usage == nil || result.Usage.TotalTokens != 2*15+150 {
+		t.Fatalf("expected sub-run usage rolled up under a tool timeout, got %+v", result.Usage)
+	}
+	for id, run := range store.runs {
+		if id != "parent-run" && run.Metadata["parent_run_id"] != "parent-run" {
+			t.Fatalf("expected the sub-run to be linked under a tool timeout, got %v", run.Metadata)
+		}
+	}
+}
+
+func TestAgentTool_DepthLimit(t *testing.T) {
+	store := newMemoryStateStore()
+	a, err := New(&delegatingProvider{tool: "self"}, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	a.RegisterTool(a.AsTool("self", "Ask yourself.", WithMaxSubRunDepth(2)))
+
+	result, err := a.RunDetailed(context.Background(), "loop")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(store.runs) != 3 {
+		t.Fatalf("expected the top-level run and two nested sub-runs, got %d", len(store.runs))
+	}
+	if !strings.Contains(result.Output, ErrMaxSubRunDepth.Error()) {
+		t.Fatalf("expected the depth error to surface to the model, got %q", result.Output)
+	}
+}
+
+func TestAgentTool_Graph(t *testing.T) {
+	g := graph.New("triage").
+		AddNode("classify", graph.NewToolNode(func(ctx context.Context, s *graph.State) error {
+			_ = ctx
+			s.Output = "severity: high for " + s.Input
+			return nil
+		})).
+		SetStart("classify")
+	executor, err := graph.NewExecutor(g)
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	tool := NewAgentTool("triage", "Triage an incident.", executor.Run,
+		WithAgentToolSchema(map[string]any{
+			"type":       "object",
+			"properties": map[string]any{"incident": map[string]any{"type": "string"}},
+			"required":   []string{"incident"},
+		}, nil))
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"incident":"db down"}`))
+	if err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	result := out.(map[string]any)
+	if result["output"] != `severity: high for {"incident":"db down"}` || result["runId"] == "" {
+		t.Fatalf("unexpected result: %v", result)
+	}
+
+	failing := NewAgentTool("broken", "", func(ctx context.Context, input string) (types.RunResult, error) {
+		return types.RunResult{}, errors.New("boom")
+	})
+	if _, err := failing.Execute(context.Background(), json.RawMessage(`{"task":"x"}`)); err == nil || !strings.Contains(err.Error(), "boom") {
+		t.Fatalf("expected the sub-run error, got %v", err)
+	}
+	if _, err := failing.Execute(context.Background(), json.RawMessage(`{}`)); err == nil || !strings.Contains(err.Error(), "task is required") {
+		t.Fatalf("expected a missing task error, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+package agent
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const (
+	summaryHeader = "[Previous conversation summary]"
+	summaryFooter = "[End of summary]"
+	// contextSummaryKey is the run metadata key the running summary is
+	// persisted under, so later runs of the session can extend it.
+	contextSummaryKey = "context_summary"
+)
+
+// Summarizer condenses conversation turns into a running summary. previous
+// is the summary of the turns before messages and is empty on the first
+// call; the returned summary replaces it.
+type Summarizer interface {
+	Summarize(ctx context.Context, previous string, messages []types.Message) (string, error)
+}
+
+// Summary is the running summary of a conversation's oldest messages.
+// Digest identifies the summarized messages so the summary is only reused
+// for a conversation with the same beginning.
+type Summary struct {
+	Text     string `json:"text"`
+	Messages int    `json:"messages"`
+	Digest   string `json:"digest"`
+}
+
+// WithSummarizer makes the agent summarize older turns instead of dropping
+// them once the conversation exceeds the input token budget. The summary is
+// extended incrementally as the conversation grows and persisted with the
+// run, so later runs of a session with session memory continue from it.
+func WithSummarizer(summarizer Summarizer) Option {
+	return func(a *Agent) { a.summarizer = summarizer }
+}
+
+// SetSummarizer enables CompactMessages to summarize older turns. A nil
+// summarizer restores plain trimming.
+func (cm *ContextManager) SetSummarizer(summarizer Summarizer) {
+	cm.summarizer = summarizer
+}
+
+// CompactMessages fits messages into the token budget like TrimMessages, but
+// when a summarizer is set the oldest messages are folded into a running
+// summary instead of being dropped. prev is the summary returned by an
+// earlier call for the same conversation, or nil; only messages it does not
+// cover yet are sent to the summarizer. The returned summary should be
+// passed to the next call.
+//
+// With a tokenizer.RequestTokenizer, the request is counted as a whole
+// first, and the per-message counts used to trim are calibrated from it.
+//
+// Messages are split at a turn boundary, so a tool call and its results
+// are always summarized or kept together. If the summarizer fails the
+// messages are trimmed and the error is returned alongside them.
+func (cm *ContextManager) CompactMessages(
+	ctx context.Context,
+	messages []types.Message,
+	systemPrompt string,
+	tools []types.ToolDefinition,
+	reserveTokens int,
+	prev *Summary,
+) ([]types.Message, *Summary, error) {
+	if total, ok := cm.countRequest(ctx, types.Request{SystemPrompt: systemPrompt, Messages: messages, Tools: tools}); ok && total+reserveTokens <= cm.maxInputTokens {
+		return cm.ensureValidStructure(messages), prev, nil
+	}
+	if cm.summarizer == nil || len(messages) < 2 || !cm.exceedsBudget(messages, systemPrompt, tools, reserveTokens) {
+		return cm.TrimMessages(messages, systemPrompt, tools, reserveTokens), prev, nil
+	}
+
+	split := cm.summarySplit(messages, systemPrompt, tools, reserveTokens)
+	if split == 0 {
+		return cm.TrimMessages(messages, systemPrompt, tools, reserveTokens), prev, nil
+	}
+
+	previous, start := "", 0
+	if prev != nil && prev.Messages > 0 && prev.Messages <= split && prev.Digest == digestMessages(messages[:prev.Messages]) {
+		previous, start = prev.Text, prev.Messages
+	}
+	summary := prev
+	if start < split {
+		text, err := cm.summarizer.Summarize(ctx, previous, messages[start:split])
+		if err != nil {
+			return cm.TrimMessages(messages, systemPrompt, tools, reserveTokens), prev, fmt.Errorf("context summarization failed: %w", err)
+		}
+		summary = &Summary{Text: strings.TrimSpace(text), Messages: split, Digest: digestMessages(messages[:split])}
+	}
+
+	compacted := append([]types.Message{summaryMessage(summary.Text)}, messages[split:]...)
+	return cm.TrimMessages(compacted, systemPrompt, tools, reserveTokens), summary, nil
+}
+
+func (cm *ContextManager) exceedsBudget(messages []types.Message, systemPrompt string, tools []types.ToolDefinition, reserveTokens int) bool {
+	available := cm.maxInputTokens - cm.countText(systemPrompt) - cm.countTools(tools) - reserveTokens
+	return cm.countMessages(messages) > available
+}
+
+// summarySplit returns how many leading messages to summarize: everything
+// except the most recent turns that fit in half of the available budget,
+// leaving the other half for the summary and the turns still to come.
+func (cm *ContextManager) summarySplit(messages []types.Message, systemPrompt string, tools []types.ToolDefinition, reserveTokens int) int {
+	available := cm.maxInputTokens - cm.countText(systemPrompt) - cm.countTools(tools) - reserveTokens
+	keep := available / 2
+
+	split := len(messages) - 1
+	used := cm.countMessage(messages[split])
+	for split > 0 {
+		next := cm.countMessage(messages[split-1])
+		if used+next > keep {
+			break
+		}
+		used += next
+		split--
+	}
+	// Never start the kept turns with tool results; move their call into
+	// the kept turns too.
+	for split > 0 && messages[split].Role == types.RoleTool {
+		split--
+	}
+	return split
+}
+
+func summaryMessage(text string) types.Message {
+	return types.Message{
+		Role:    types.RoleUser,
+		Content: summaryHeader + "\n" + text + "\n" + summaryFooter,
+	}
+}
+
+func digestMessages(messages []types.Message) string {
+	raw, _ := json.Marshal(messages)
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// LLMSummarizer asks a model to maintain the running summary. Summary calls
+// are separate from the agent's run and are not charged to its budget.
+type LLMSummarizer struct {
+	provider        llm.Provider
+	model           string
+	maxOutputTokens int
+}
+
+func NewLLMSummarizer(provider llm.Provider, opts ...func(*LLMSummarizer)) (*LLMSummarizer, error) {
+	if provider == nil {
+		return nil, fmt.Errorf("summarizer provider is required")
+	}
+	s := &LLMSummarizer{provider: provider, maxOutputTokens: 1024}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s, nil
+}
+
+func WithSummaryModel(model string) func(*LLMSummarizer) {
+	return func(s *LLMSummarizer) {
+		if s != nil {
+			s.model = strings.TrimSpace(model)
+		}
+	}
+}
+
+func WithSummaryMaxTokens(max int) func(*LLMSummarizer) {
+	return func(s *LLMSummarizer) {
+		if s != nil && max > 0 {
+			s.maxOutputTokens = max
+		}
+	}
+}
+
+func (s *LLMSummarizer) Summarize(ctx context.Context, previous string, messages []types.Message) (string, error) {
+	if s == nil || s.provider == nil {
+		return "", fmt.Errorf("summarizer provider is required")
+	}
+	var b strings.Builder
+	if previous != "" {
+		b.WriteString("Summary so far:\n")
+		b.WriteString(previous)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("New conversation turns:\n")
+	for _, msg := range messages {
+		b.WriteString(transcriptLine(msg))
+		b.WriteString("\n")
+	}
+
+	resp, err := s.provider.Generate(ctx, types.Request{
+		Model: s.model,
+		SystemPrompt: "You maintain the running summary of a conversation between a user and an assistant that uses tools. " +
+			"Update the summary with the new turns. Keep every durable fact, finding, decision, open question and tool result " +
+			"the assistant may need later, including identifiers, numbers and error messages. Drop chit-chat. " +
+			"Return only the updated summary.",
+		Messages:        []types.Message{{Role: types.RoleUser, Content: b.String()}},
+		MaxOutputTokens: s.maxOutputTokens,
+	})
+	if err != nil {
+		return "", fmt.Errorf("summary generate failed: %w", err)
+	}
+	text := strings.TrimSpace(resp.Message.Content)
+	if text == "" {
+		return "", fmt.Errorf("summary generate returned no content")
+	}
+	return text, nil
+}
+
+func transcriptLine(msg types.Message) string {
+	switch {
+	case msg.Role == types.RoleTool:
+		name := msg.Name
+		if name == "" {
+			name = msg.ToolCallID
+		}
+		return fmt.Sprintf("Tool result (%s): %s", name, msg.Content)
+	case len(msg.ToolCalls) > 0:
+		calls := make([]string, 0, len(msg.ToolCalls))
+		for _, call := range msg.ToolCalls {
+			calls = append(calls, fmt.Sprintf("%s(%s)", call.Name, string(call.Arguments)))
+		}
+		line := "Assistant called: " + strings.Join(calls, ", ")
+		if msg.Content != "" {
+			line = "Assistant: " + msg.Content + "\n" + line
+		}
+		return line
+	case msg.Role == types.RoleAssistant:
+		return "Assistant: " + msg.Content
+	default:
+		return "User: " + msg.Content
+	}
+}
@@ -0,0 +1,257 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+type summaryCall struct {
+	previous string
+	messages []types.Message
+}
+
+type fakeSummarizer struct {
+	mu    sync.Mutex
+	calls []summaryCall
+	err   error
+}
+
+func (s *fakeSummarizer) Summarize(ctx context.Context, previous string, messages []types.Message) (string, error) {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls = append(s.calls, summaryCall{previous: previous, messages: append([]types.Message(nil), messages...)})
+	if s.err != nil {
+		return "", s.err
+	}
+	return strings.TrimSpace(previous + fmt.Sprintf(" summary-%d", len(s.calls))), nil
+}
+
+func long(prefix string) string {
+	return prefix + " " + strings.Repeat("x", 200)
+}
+
+func TestContextManager_CompactMessages_SummarizesIncrementally(t *testing.T) {
+	cm := NewContextManager(200)
+	summarizer := &fakeSummarizer{}
+	cm.SetSummarizer(summarizer)
+	ctx := context.Background()
+
+	messages := []types.Message{
+		{Role: types.RoleUser, Content: long("u1")},
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "c1", Name: "lookup", Arguments: json.RawMessage(`{}`)}}},
+		{Role: types.RoleTool, ToolCallID: "c1", Name: "lookup", Content: long("finding")},
+		{Role: types.RoleAssistant, Content: long("a1")},
+		{Role: types.RoleUser, Content: long("u2")},
+	}
+	compacted, summary, err := cm.CompactMessages(ctx, messages, "", nil, 0, nil)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if len(summarizer.calls) != 1 || len(summarizer.calls[0].messages) != 4 || summarizer.calls[0].previous != "" {
+		t.Fatalf("expected the four oldest messages to be summarized, got %+v", summarizer.calls)
+	}
+	if len(compacted) != 2 || !strings.Contains(compacted[0].Content, "summary-1") || compacted[1].Content != messages[4].Content {
+		t.Fatalf("expected summary followed by the latest turn, got %+v", compacted)
+	}
+	if summary == nil || summary.Messages != 4 {
+		t.Fatalf("expected summary covering 4 messages, got %+v", summary)
+	}
+
+	// Reusing the summary for the same prefix does not call the summarizer.
+	if _, again, _ := cm.CompactMessages(ctx, messages, "", nil, 0, summary); again != summary || len(summarizer.calls) != 1 {
+		t.Fatalf("expected the summary to be reused, got %+v after %d calls", again, len(summarizer.calls))
+	}
+
+	messages = append(messages,
+		types.Message{Role: types.RoleAssistant, Content: long("a2")},
+		types.Message{Role: types.RoleUser, Content: long("u3")},
+	)
+	_, summary, err = cm.CompactMessages(ctx, messages, "", nil, 0, summary)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if len(summarizer.calls) != 2 {
+		t.Fatalf("expected a second summarizer call, got %d", len(summarizer.calls))
+	}
+	second := summarizer.calls[1]
+	if second.previous != "summary-1" || len(second.messages) != 2 || second.messages[0].Content != long("u2") {
+		t.Fatalf("expected only new turns on top of the previous summary, got %+v", second)
+	}
+	if summary.Messages != 6 || summary.Text != "summary-1 summary-2" {
+		t.Fatalf("unexpected running summary: %+v", summary)
+	}
+}
+
+func TestContextManager_CompactMessages_KeepsToolResultsWithCalls(t *testing.T) {
+	cm := NewContextManager(200)
+	cm.SetSummarizer(&fakeSummarizer{})
+	messages := []types.Message{
+		{Role: types.RoleUser, Content: long("u1")},
+		{Role: types.RoleAssistant, Content: long("a1")},
+		{Role: types.RoleUser, Content: "check both"},
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{
+			{ID: "c1", Name: "lookup", Arguments: json.RawMessage(`{}`)},
+			{ID: "c2", Name: "lookup", Arguments: json.RawMessage(`{}`)},
+		}},
+		{Role: types.RoleTool, ToolCallID: "c1", Name: "lookup", Content: long("r1")},
+		{Role: types.RoleTool, ToolCallID: "c2", Name: "lookup", Content: long("r2")},
+	}
+	compacted, summary, err := cm.CompactMessages(context.Background(), messages, "", nil, 0, nil)
+	if err != nil {
+		t.Fatalf("compact: %v", err)
+	}
+	if summary == nil || summary.Messages != 3 {
+		t.Fatalf("expected the split to move before the tool call, got %+v", summary)
+	}
+	if len(compacted) != 4 || len(compacted[1].ToolCalls) != 2 || compacted[3].ToolCallID != "c2" {
+		t.Fatalf("expected the tool call to stay with its results, got %+v", compacted)
+	}
+}
+
+func TestContextManager_CompactMessages_FallsBackToTrimming(t *testing.T) {
+	cm := NewContextManager(200)
+	cm.SetSummarizer(&fakeSummarizer{err: errors.New("model down")})
+	messages := []types.Message{
+		{Role: types.RoleUser, Content: long("u1")},
+		{Role: types.RoleAssistant, Content: long("a1")},
+		{Role: types.RoleUser, Content: long("u2")},
+		{Role: types.RoleAssistant, Content: long("a2")},
+		{Role: types.RoleUser, Content: long("u3")},
+	}
+	compacted, summary, err := cm.CompactMessages(context.Background(), messages, "", nil, 0, nil)
+	if err == nil || summary != nil {
+		t.Fatalf("expected summarizer error without a summary, got %v, %+v", err, summary)
+	}
+	if len(compacted) == 0 || len(compacted) >= len(messages) || compacted[len(compacted)-1].Content != long("u3") {
+		t.Fatalf("expected trimmed messages, got %d", len(compacted))
+	}
+}
+
+// longProvider answers every request at length and records the requests.
+type longProvider struct {
+	mu       sync.Mutex
+	requests [][]types.Message
+}
+
+func (p *longProvider) Name() string                   { return "long" }
+func (p *longProvider) Capabilities() llm.Capabilities { return llm.Capabilities{} }
+
+func (p *longProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.mu.Lock()
+	p.requests = append(p.requests, append([]types.Message(nil), req.Messages...))
+	p.mu.Unlock()
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: long("answer")}}, nil
+}
+
+func TestAgent_SummarizerPersistsRunningSummaryInSession(t *testing.T) {
+	provider := &longProvider{}
+	store := newMemoryStateStore()
+	summarizer := &fakeSummarizer{}
+	newAgent := func() *Agent {
+		a, err := New(provider,
+			WithStore(store),
+			WithSessionID("s1"),
+			WithSessionMemory(SessionMemory{}),
+			WithMaxInputTokens(250),
+			WithSummarizer(summarizer),
+		)
+		if err != nil {
+			t.Fatalf("new agent: %v", err)
+		}
+		return a
+	}
+
+	var runIDs []string
+	for _, input := range []string{long("q1"), long("q2"), long("q3"), long("q4")} {
+		result, err := newAgent().RunDetailed(context.Background(), input)
+		if err != nil {
+			t.Fatalf("run: %v", err)
+		}
+		runIDs = append(runIDs, result.RunID)
+	}
+
+	if len(summarizer.calls) < 2 {
+		t.Fatalf("expected the summary to be extended across runs, got %d calls", len(summarizer.calls))
+	}
+	last := summarizer.calls[len(summarizer.calls)-1]
+	if last.previous == "" {
+		t.Fatalf("expected later runs to extend the persisted summary, got %+v", summarizer.calls)
+	}
+	run, err := store.LoadRun(context.Background(), runIDs[len(runIDs)-1])
+	if err != nil {
+		t.Fatalf("load run: %v", err)
+	}
+	if summary := contextSummary(run.Metadata); summary == nil || summary.Messages == 0 {
+		t.Fatalf("expected the running summary in run metadata, got %+v", run.Metadata)
+	}
+	final := provider.requests[len(provider.requests)-1]
+	if !strings.HasPrefix(final[0].Content, summaryHeader) {
+		t.Fatalf("expected the request to start with the summary, got %q", final[0].Content)
+	}
+}
+
+type capturingProvider struct {
+	req types.Request
+}
+
+func (p *capturingProvider) Name() string                   { return "capture" }
+func (p *capturingProvider) Capabilities() llm.Capabilities { return llm.Capabilities{} }
+
+func (p *capturingProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.req = req
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: " pod crashlooping on OOM "}}, nil
+}
+
+func TestLLMSummarizer_Summarize(t *testing.T) {
+	provider := &capturingProvider{}
+	s, err := NewLLMSummarizer(provider, WithSummaryModel("small"))
+	if err != nil {
+		t.Fatalf("new summarizer: %v", err)
+	}
+	text, err := s.Summarize(context.Background(), "investigating api outage", []types.Message{
+		{Role: types.RoleUser, Content: "why is api down?"},
+		{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "c1", Name: "kubectl", Arguments: json.RawMessage(`{"cmd":"get pods"}`)}}},
+		{Role: types.RoleTool, ToolCallID: "c1", Name: "kubectl", Content: "api-0 OOMKilled"},
+	})
+	if err != nil {
+		t.Fatalf("summarize: %v", err)
+	}
+	if text != "pod crashlooping on OOM" {
+		t.Fatalf("unexpected summary %q", text)
+	}
+	prompt := provider.req.Messages[0].Content
+	for _, want := range []string{"investigating api outage", `kubectl({"cmd":"get pods"})`, "Tool result (kubectl): api-0 OOMKilled"} {
+		if !strings.Contains(prompt, want) {
+			t.Fatalf("expected prompt to contain %q, got %q", want, prompt)
+		}
+	}
+	if provider.req.Model != "small" {
+		t.Fatalf("expected summary model, got %q", provider.req.Model)
+	}
+
+	if _, err := NewLLMSummarizer(nil); err == nil {
+		t.Fatalf("expected provider to be required")
+	}
+}
+
+func TestContextSummary_DecodesStoredMetadata(t *testing.T) {
+	raw, _ := json.Marshal(map[string]any{contextSummaryKey: &Summary{Text: "t", Messages: 3, Digest: "d"}})
+	var metadata map[string]any
+	if err := json.Unmarshal(raw, &metadata); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if got := contextSummary(metadata); got == nil || *got != (Summary{Text: "t", Messages: 3, Digest: "d"}) {
+		t.Fatalf("unexpected summary %+v", got)
+	}
+}
@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+)
+
+// ToolArgumentsError reports tool call arguments that are not valid JSON or
+// do not match the tool's schema. The tool is not executed; the violations
+// are returned to the model so it can correct the call.
+type ToolArgumentsError struct {
+	Tool       string
+	Violations []string
+}
+
+func (e *ToolArgumentsError) Error() string {
+	return fmt.Sprintf("invalid arguments for tool %q: %s", e.Tool, strings.Join(e.Violations, "; "))
+}
+
+// toolArguments prepares a call's arguments for tool, repairing malformed
+// JSON where possible, and returns the schema violations that remain.
+func (a *Agent) toolArguments(tool tools.Tool, raw json.RawMessage) (json.RawMessage, []string) {
+	if len(raw) == 0 {
+		raw = json.RawMessage(`{}`)
+	}
+	if !a.validateToolArgs {
+		return raw, nil
+	}
+	if fixed, ok := tools.RepairJSON(raw); ok {
+		raw = fixed
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return raw, []string{fmt.Sprintf("$: arguments are not valid JSON: %v", err)}
+	}
+	return raw, tools.ValidateSchema(value, tool.Definition().JSONSchema)
+}
@@ -0,0 +1,118 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/observe"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// argsProvider calls the tool once per scripted argument payload, then
+// finishes. It records the tool results it was sent.
+type argsProvider struct {
+	args    []string
+	calls   int
+	results []string
+}
+
+func (p *argsProvider) Name() string { return "args-provider" }
+
+func (p *argsProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *argsProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	if last := req.Messages[len(req.Messages)-1]; last.Role == types.RoleTool {
+		p.results = append(p.results, last.Content)
+	}
+	if p.calls >= len(p.args) {
+		return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "done"}}, nil
+	}
+	p.calls++
+	return types.Response{Message: types.Message{
+		Role: types.RoleAssistant,
+		ToolCalls: []types.ToolCall{{
+			ID:        fmt.Sprintf("call-%d", p.calls),
+			Name:      "lookup",
+			Arguments: json.RawMessage(p.args[p.calls-1]),
+		}},
+	}}, nil
+}
+
+func newLookupTool(executed *[]string) tools.Tool {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"id": map[string]any{"type": "integer"},
+		},
+		"required": []string{"id"},
+	}
+	return tools.NewFuncTool("lookup", "look up a record", schema, func(ctx context.Context, args json.RawMessage) (any, error) {
+		_ = ctx
+		*executed = append(*executed, string(args))
+		return map[string]any{"ok": true}, nil
+	})
+}
+
+func TestAgent_ToolArguments_ViolationGoesBackToModel(t *testing.T) {
+	var executed []string
+	var validationEvents []observe.Event
+	sink := observe.SinkFunc(func(ctx context.Context, event observe.Event) error {
+		_ = ctx
+		if event.Attributes["eventType"] == string(types.EventToolValidationFailed) {
+			validationEvents = append(validationEvents, event)
+		}
+		return nil
+	})
+	provider := &argsProvider{args: []string{`{"id":"seven"}`, "```json\n{\"id\": 7,}\n```"}}
+	a, err := New(provider, WithTool(newLookupTool(&executed)), WithObserver(sink))
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	if _, err := a.Run(context.Background(), "find record seven"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	if len(executed) != 1 || executed[0] != `{"id": 7}` {
+		t.Fatalf("expected only the repaired call to run, got %v", executed)
+	}
+	if len(provider.results) != 2 {
+		t.Fatalf("expected two tool results, got %v", provider.results)
+	}
+	var feedback struct {
+		Error      string         `json:"error"`
+		Violations []string       `json:"violations"`
+		Schema     map[string]any `json:"schema"`
+	}
+	if err := json.Unmarshal([]byte(provider.results[0]), &feedback); err != nil {
+		t.Fatalf("expected a structured tool error, got %q", provider.results[0])
+	}
+	if len(feedback.Violations) != 1 || !strings.Contains(feedback.Violations[0], "$.id: expected integer") || feedback.Schema == nil {
+		t.Fatalf("unexpected validation feedback %+v", feedback)
+	}
+	if len(validationEvents) != 1 || validationEvents[0].Kind != observe.KindTool || validationEvents[0].Status != observe.StatusFailed {
+		t.Fatalf("expected one failed tool validation event, got %+v", validationEvents)
+	}
+}
+
+func TestAgent_ToolArguments_ValidationDisabled(t *testing.T) {
+	var executed []string
+	provider := &argsProvider{args: []string{`{"id":"seven"}`}}
+	a, err := New(provider, WithTool(newLookupTool(&executed)), WithToolArgumentValidation(false))
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	if _, err := a.Run(context.Background(), "find record seven"); err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if len(executed) != 1 || executed[0] != `{"id":"seven"}` {
+		t.Fatalf("expected the unvalidated call to run, got %v", executed)
+	}
+}
@@ -0,0 +1,159 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// DefaultOutputRetries is how many times RunTyped re-prompts the model with
+// validation errors before giving up.
+const DefaultOutputRetries = 2
+
+// ErrInvalidOutput is matched by OutputValidationError.
+var ErrInvalidOutput = errors.New("output does not match the schema")
+
+// OutputValidationError reports a final answer that still did not match the
+// requested schema after every re-prompt.
+type OutputValidationError struct {
+	Output     string
+	Violations []string
+}
+
+func (e *OutputValidationError) Error() string {
+	return fmt.Sprintf("%v: %s", ErrInvalidOutput, strings.Join(e.Violations, "; "))
+}
+
+func (e *OutputValidationError) Is(target error) bool { return target == ErrInvalidOutput }
+
+type typedConfig struct {
+	retries int
+	name    string
+}
+
+// TypedOption configures RunTyped.
+type TypedOption func(*typedConfig)
+
+// WithOutputRetries sets how many times the model is re-prompted with the
+// validation errors of an answer that does not match the schema.
+func WithOutputRetries(retries int) TypedOption {
+	return func(c *typedConfig) {
+		if retries >= 0 {
+			c.retries = retries
+		}
+	}
+}
+
+// WithOutputName names the schema sent to providers, which some use as the
+// response format name. It defaults to the Go type name.
+func WithOutputName(name string) TypedOption {
+	return func(c *typedConfig) { c.name = name }
+}
+
+// RunTyped runs the agent and decodes its final answer into T. The JSON
+// schema derived from T with SchemaFor is sent to providers that support
+// structured output and described in the system prompt for those that do
+// not. Answers that fail validation are sent back to the model with the
+// violations, up to the configured number of retries.
+func RunTyped[T any](ctx context.Context, a *Agent, input string, opts ...TypedOption) (T, types.RunResult, error) {
+	var zero T
+	cfg := typedConfig{retries: DefaultOutputRetries}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	schema, err := tools.SchemaOf(t)
+	if err != nil {
+		return zero, types.RunResult{}, err
+	}
+	if cfg.name == "" {
+		cfg.name = t.Name()
+	}
+	spec := &outputSpec{name: schemaName(cfg.name), schema: schema, retries: cfg.retries}
+
+	result, err := a.RunDetailed(context.WithValue(ctx, outputSpecContextKey{}, spec), input)
+	if err != nil {
+		return zero, result, err
+	}
+	var out T
+	if err := json.Unmarshal([]byte(normalizeOutput(result.Output)), &out); err != nil {
+		return zero, result, fmt.Errorf("failed to decode %s output: %w", t, err)
+	}
+	return out, result, nil
+}
+
+// outputSpec is the schema a typed run's final answer must match.
+type outputSpec struct {
+	name    string
+	schema  map[string]any
+	retries int
+}
+
+type outputSpecContextKey struct{}
+
+func outputSpecFromContext(ctx context.Context) *outputSpec {
+	spec, _ := ctx.Value(outputSpecContextKey{}).(*outputSpec)
+	return spec
+}
+
+// apply asks for the schema natively when the provider supports structured
+// output, and through the system prompt otherwise.
+func (s *outputSpec) apply(req *types.Request, native bool) {
+	if native {
+		req.ResponseSchema = map[string]any{"name": s.name, "schema": s.schema}
+		return
+	}
+	req.ResponseSchema = nil
+	schemaJSON, _ := json.Marshal(s.schema)
+	instruction := "Respond ONLY with a JSON value matching this JSON schema, with no other text:\n" + string(schemaJSON)
+	if req.SystemPrompt == "" {
+		req.SystemPrompt = instruction
+		return
+	}
+	req.SystemPrompt += "\n\n" + instruction
+}
+
+func (s *outputSpec) validate(output string) []string {
+	var value any
+	if err := json.Unmarshal([]byte(normalizeOutput(output)), &value); err != nil {
+		return []string{fmt.Sprintf("$: not valid JSON: %v", err)}
+	}
+	return tools.ValidateSchema(value, s.schema)
+}
+
+func outputRetryPrompt(violations []string) string {
+	return "Your response did not match the requested JSON schema:\n- " + strings.Join(violations, "\n- ") +
+		"\nRespond again with ONLY a JSON value that fixes these problems."
+}
+
+// normalizeOutput strips the code fences and trailing commas models add to
+// JSON answers, particularly without native structured output.
+func normalizeOutput(output string) string {
+	if fixed, ok := tools.RepairJSON([]byte(output)); ok {
+		return string(fixed)
+	}
+	return strings.TrimSpace(output)
+}
+
+var schemaNameInvalid = regexp.MustCompile(`[^a-zA-Z0-9_-]+`)
+
+func schemaName(name string) string {
+	name = schemaNameInvalid.ReplaceAllString(name, "_")
+	if name == "" {
+		return "output"
+	}
+	return name
+}
+
+// SchemaFor derives a JSON schema from T; see tools.SchemaFor for the
+// struct tags it understands.
+func SchemaFor[T any]() (map[string]any, error) {
+	return tools.SchemaFor[T]()
+}
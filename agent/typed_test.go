@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+type review struct {
+	Sentiment string   `json:"sentiment" enum:"positive,negative,neutral" description:"overall tone"`
+	Score     float64  `json:"score"`
+	Tags      []string `json:"tags,omitempty"`
+	Internal  string   `json:"-"`
+}
+
+// outputProvider answers with each scripted output in turn and records the
+// requests it received.
+type outputProvider struct {
+	native   bool
+	outputs  []string
+	requests []types.Request
+}
+
+func (p *outputProvider) Name() string { return "output-provider" }
+
+func (p *outputProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{StructuredOutput: p.native}
+}
+
+func (p *outputProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.requests = append(p.requests, req)
+	out := p.outputs[len(p.requests)-1]
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: out}}, nil
+}
+
+func TestRunTyped_NativeRetriesWithViolations(t *testing.T) {
+	p := &outputProvider{native: true, outputs: []string{
+		`{"sentiment":"great","score":0.9}`,
+		`{"sentiment":"positive","score":0.9,"tags":["fast"]}`,
+	}}
+	a, err := New(p)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	out, result, err := RunTyped[review](context.Background(), a, "review this")
+	if err != nil {
+		t.Fatalf("run typed: %v", err)
+	}
+	if out.Sentiment != "positive" || out.Score != 0.9 || len(out.Tags) != 1 || result.RunID == "" {
+		t.Fatalf("unexpected output %+v", out)
+	}
+	if len(p.requests) != 2 {
+		t.Fatalf("expected one re-prompt, got %d requests", len(p.requests))
+	}
+	if p.requests[0].ResponseSchema["name"] != "review" || p.requests[0].ResponseSchema["schema"] == nil {
+		t.Fatalf("expected the schema to be sent natively, got %v", p.requests[0].ResponseSchema)
+	}
+	retry := p.requests[1].Messages[len(p.requests[1].Messages)-1]
+	if retry.Role != types.RoleUser || !strings.Contains(retry.Content, "$.sentiment: value not in enum") {
+		t.Fatalf("expected the violation in the re-prompt, got %+v", retry)
+	}
+}
+
+func TestRunTyped_PromptFallback(t *testing.T) {
+	p := &outputProvider{outputs: []string{"```json\n{\"sentiment\":\"neutral\",\"score\":0.5}\n```"}}
+	a, err := New(p, WithSystemPrompt("You review products."))
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	out, _, err := RunTyped[review](context.Background(), a, "review this")
+	if err != nil {
+		t.Fatalf("run typed: %v", err)
+	}
+	if out.Sentiment != "neutral" {
+		t.Fatalf("unexpected output %+v", out)
+	}
+	req := p.requests[0]
+	if req.ResponseSchema != nil || !strings.HasPrefix(req.SystemPrompt, "You review products.") || !strings.Contains(req.SystemPrompt, `"sentiment"`) {
+		t.Fatalf("expected the schema in the system prompt, got %q", req.SystemPrompt)
+	}
+}
+
+func TestRunTyped_GivesUpAfterRetries(t *testing.T) {
+	p := &outputProvider{native: true, outputs: []string{"not json", `{"score":1}`}}
+	a, err := New(p)
+	if err != nil {
+		t.Fatalf("failed to build agent: %v", err)
+	}
+	_, _, err = RunTyped[review](context.Background(), a, "review this", WithOutputRetries(1))
+	var outputErr *OutputValidationError
+	if !errors.Is(err, ErrInvalidOutput) || !errors.As(err, &outputErr) {
+		t.Fatalf("expected invalid output error, got %v", err)
+	}
+	if len(outputErr.Violations) != 1 || !strings.Contains(outputErr.Violations[0], "sentiment") {
+		t.Fatalf("unexpected violations %v", outputErr.Violations)
+	}
+}
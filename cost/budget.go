@@ -0,0 +1,160 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Scope identifies which budget limit was hit.
+type Scope string
+
+const (
+	ScopeRun     Scope = "run"
+	ScopeSession Scope = "session"
+	ScopeDay     Scope = "day"
+)
+
+// ErrBudgetExceeded matches every *BudgetExceededError with errors.Is.
+var ErrBudgetExceeded = errors.New("budget exceeded")
+
+// BudgetExceededError reports a spending limit that was passed.
+type BudgetExceededError struct {
+	Scope    Scope
+	LimitUSD float64
+	SpentUSD float64
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("%s budget exceeded: spent $%.4f of $%.4f", e.Scope, e.SpentUSD, e.LimitUSD)
+}
+
+func (e *BudgetExceededError) Is(target error) bool { return target == ErrBudgetExceeded }
+
+// Budget holds spending limits in USD. Zero disables a limit. Days are
+// calendar days in UTC.
+type Budget struct {
+	PerRunUSD     float64
+	PerSessionUSD float64
+	PerDayUSD     float64
+}
+
+// BudgetFromEnv reads AGENT_BUDGET_RUN_USD, AGENT_BUDGET_SESSION_USD and
+// AGENT_BUDGET_DAY_USD. Unset variables leave the limit disabled.
+func BudgetFromEnv() (Budget, error) {
+	var b Budget
+	for _, item := range []struct {
+		key   string
+		limit *float64
+	}{
+		{"AGENT_BUDGET_RUN_USD", &b.PerRunUSD},
+		{"AGENT_BUDGET_SESSION_USD", &b.PerSessionUSD},
+		{"AGENT_BUDGET_DAY_USD", &b.PerDayUSD},
+	} {
+		raw := strings.TrimSpace(os.Getenv(item.key))
+		if raw == "" {
+			continue
+		}
+		v, err := strconv.ParseFloat(raw, 64)
+		if err != nil || v < 0 {
+			return Budget{}, fmt.Errorf("invalid %s %q", item.key, raw)
+		}
+		*item.limit = v
+	}
+	return b, nil
+}
+
+// Enabled reports whether any limit is set.
+func (b Budget) Enabled() bool {
+	return b.PerRunUSD > 0 || b.PerSessionUSD > 0 || b.PerDayUSD > 0
+}
+
+// Check returns a *BudgetExceededError for the first limit that current
+// spend has passed. runSpend is the spend of the current run; session and day
+// totals come from ledger.
+func (b Budget) Check(ctx context.Context, ledger Ledger, sessionID string, now time.Time, runSpend float64) error {
+	if b.PerRunUSD > 0 && runSpend > b.PerRunUSD {
+		return &BudgetExceededError{Scope: ScopeRun, LimitUSD: b.PerRunUSD, SpentUSD: runSpend}
+	}
+	if ledger == nil {
+		return nil
+	}
+	if b.PerSessionUSD > 0 && sessionID != "" {
+		spent, err := ledger.SessionSpend(ctx, sessionID)
+		if err != nil {
+			return fmt.Errorf("failed to read session spend: %w", err)
+		}
+		if spent > b.PerSessionUSD {
+			return &BudgetExceededError{Scope: ScopeSession, LimitUSD: b.PerSessionUSD, SpentUSD: spent}
+		}
+	}
+	if b.PerDayUSD > 0 {
+		spent, err := ledger.DaySpend(ctx, now)
+		if err != nil {
+			return fmt.Errorf("failed to read daily spend: %w", err)
+		}
+		if spent > b.PerDayUSD {
+			return &BudgetExceededError{Scope: ScopeDay, LimitUSD: b.PerDayUSD, SpentUSD: spent}
+		}
+	}
+	return nil
+}
+
+// Ledger accumulates spend across runs so session and daily budgets can be
+// enforced. Share one ledger between agents to enforce a common budget.
+type Ledger interface {
+	Record(ctx context.Context, sessionID string, at time.Time, usd float64) error
+	SessionSpend(ctx context.Context, sessionID string) (float64, error)
+	DaySpend(ctx context.Context, day time.Time) (float64, error)
+}
+
+// MemoryLedger is a process-local Ledger.
+type MemoryLedger struct {
+	mu       sync.Mutex
+	sessions map[string]float64
+	days     map[string]float64
+}
+
+func NewMemoryLedger() *MemoryLedger {
+	return &MemoryLedger{
+		sessions: map[string]float64{},
+		days:     map[string]float64{},
+	}
+}
+
+func (l *MemoryLedger) Record(ctx context.Context, sessionID string, at time.Time, usd float64) error {
+	_ = ctx
+	if usd <= 0 {
+		return nil
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if sessionID != "" {
+		l.sessions[sessionID] += usd
+	}
+	l.days[dayKey(at)] += usd
+	return nil
+}
+
+func (l *MemoryLedger) SessionSpend(ctx context.Context, sessionID string) (float64, error) {
+	_ = ctx
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.sessions[sessionID], nil
+}
+
+func (l *MemoryLedger) DaySpend(ctx context.Context, day time.Time) (float64, error) {
+	_ = ctx
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.days[dayKey(day)], nil
+}
+
+func dayKey(t time.Time) string {
+	return t.UTC().Format("2006-01-02")
+}
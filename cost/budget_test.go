@@ -0,0 +1,49 @@
+package cost
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBudget_Check(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	ledger := NewMemoryLedger()
+	_ = ledger.Record(ctx, "s1", now, 0.6)
+	_ = ledger.Record(ctx, "s2", now, 0.6)
+	_ = ledger.Record(ctx, "s1", now.AddDate(0, 0, -1), 5)
+
+	cases := []struct {
+		name     string
+		budget   Budget
+		session  string
+		runSpend float64
+		scope    Scope
+	}{
+		{name: "within limits", budget: Budget{PerRunUSD: 1, PerSessionUSD: 10, PerDayUSD: 2}, session: "s2"},
+		{name: "run", budget: Budget{PerRunUSD: 0.5}, runSpend: 0.51, scope: ScopeRun},
+		{name: "run at limit", budget: Budget{PerRunUSD: 0.5}, runSpend: 0.5},
+		{name: "session", budget: Budget{PerSessionUSD: 5}, session: "s1", scope: ScopeSession},
+		{name: "day", budget: Budget{PerDayUSD: 1}, session: "s2", scope: ScopeDay},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.budget.Check(ctx, ledger, tc.session, now, tc.runSpend)
+			if tc.scope == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, ErrBudgetExceeded) {
+				t.Fatalf("expected ErrBudgetExceeded, got %v", err)
+			}
+			var budgetErr *BudgetExceededError
+			if !errors.As(err, &budgetErr) || budgetErr.Scope != tc.scope {
+				t.Fatalf("expected %s scope, got %v", tc.scope, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,161 @@
+// Package cost turns token usage into spend and enforces spending budgets.
+package cost
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// Price is the list price of a model in USD per million tokens.
+type Price struct {
+	InputPerMillion  float64 `json:"inputPerMillion"`
+	OutputPerMillion float64 `json:"outputPerMillion"`
+}
+
+// Compute returns the USD cost of usage at this price.
+func (p Price) Compute(usage types.Usage) float64 {
+	return (float64(usage.InputTokens)*p.InputPerMillion + float64(usage.OutputTokens)*p.OutputPerMillion) / 1_000_000
+}
+
+// defaultPrices are published list prices at the time of writing. Providers
+// change them regularly, so deployments that bill on them should override
+// the table.
+var defaultPrices = map[string]Price{
+	"gpt-4o":            {InputPerMillion: 2.50, OutputPerMillion: 10.00},
+	"gpt-4o-mini":       {InputPerMillion: 0.15, OutputPerMillion: 0.60},
+	"gpt-4.1":           {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+	"gpt-4.1-mini":      {InputPerMillion: 0.40, OutputPerMillion: 1.60},
+	"gpt-4.1-nano":      {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"o3":                {InputPerMillion: 2.00, OutputPerMillion: 8.00},
+	"o3-mini":           {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+	"o4-mini":           {InputPerMillion: 1.10, OutputPerMillion: 4.40},
+	"claude-3-5-haiku":  {InputPerMillion: 0.80, OutputPerMillion: 4.00},
+	"claude-3-5-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-3-7-sonnet": {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-sonnet-4":   {InputPerMillion: 3.00, OutputPerMillion: 15.00},
+	"claude-opus-4":     {InputPerMillion: 15.00, OutputPerMillion: 75.00},
+	"gemini-2.0-flash":  {InputPerMillion: 0.10, OutputPerMillion: 0.40},
+	"gemini-2.5-flash":  {InputPerMillion: 0.30, OutputPerMillion: 2.50},
+	"gemini-2.5-pro":    {InputPerMillion: 1.25, OutputPerMillion: 10.00},
+	"ollama/*":          {},
+}
+
+// Table maps models to prices. Keys are a model name ("gpt-4o"), a
+// provider-qualified model ("azureopenai/gpt-4o") or a provider wildcard
+// ("ollama/*"). Model names also match versioned variants, so "gpt-4o-mini"
+// prices "gpt-4o-mini-2024-07-18".
+type Table struct {
+	mu     sync.RWMutex
+	prices map[string]Price
+}
+
+// NewTable returns a table containing only prices.
+func NewTable(prices map[string]Price) *Table {
+	t := &Table{prices: make(map[string]Price, len(prices))}
+	t.Merge(prices)
+	return t
+}
+
+// DefaultTable returns a table seeded with built-in list prices.
+func DefaultTable() *Table {
+	return NewTable(defaultPrices)
+}
+
+// LoadTable returns the default table overridden by the JSON object in path,
+// which maps table keys to prices.
+func LoadTable(path string) (*Table, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read pricing file: %w", err)
+	}
+	var prices map[string]Price
+	if err := json.Unmarshal(raw, &prices); err != nil {
+		return nil, fmt.Errorf("failed to decode pricing file: %w", err)
+	}
+	t := DefaultTable()
+	t.Merge(prices)
+	return t, nil
+}
+
+// TableFromEnv loads AGENT_PRICING_FILE when set and otherwise returns the
+// default table.
+func TableFromEnv() (*Table, error) {
+	if path := strings.TrimSpace(os.Getenv("AGENT_PRICING_FILE")); path != "" {
+		return LoadTable(path)
+	}
+	return DefaultTable(), nil
+}
+
+// Set adds or replaces the price for key.
+func (t *Table) Set(key string, price Price) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.prices[normalizeKey(key)] = price
+}
+
+// Merge adds or replaces every price in prices.
+func (t *Table) Merge(prices map[string]Price) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for k, v := range prices {
+		t.prices[normalizeKey(k)] = v
+	}
+}
+
+// Lookup finds the price for a model served by provider. The most specific
+// key wins: provider/model, model, the longest matching model prefix, then
+// the provider wildcard.
+func (t *Table) Lookup(provider, model string) (Price, bool) {
+	if t == nil {
+		return Price{}, false
+	}
+	provider, model = normalizeKey(provider), normalizeKey(model)
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if model != "" {
+		if p, ok := t.prices[provider+"/"+model]; ok {
+			return p, true
+		}
+		if p, ok := t.prices[model]; ok {
+			return p, true
+		}
+		best, bestLen := Price{}, 0
+		for key, p := range t.prices {
+			name := key
+			if i := strings.Index(key, "/"); i >= 0 {
+				if key[:i] != provider {
+					continue
+				}
+				name = key[i+1:]
+			}
+			if name != "*" && strings.HasPrefix(model, name) && len(name) > bestLen {
+				best, bestLen = p, len(name)
+			}
+		}
+		if bestLen > 0 {
+			return best, true
+		}
+	}
+	p, ok := t.prices[provider+"/*"]
+	return p, ok
+}
+
+// Compute returns the USD cost of usage, and false when the model has no
+// price.
+func (t *Table) Compute(provider, model string, usage types.Usage) (float64, bool) {
+	price, ok := t.Lookup(provider, model)
+	if !ok {
+		return 0, false
+	}
+	return price.Compute(usage), true
+}
+
+func normalizeKey(key string) string {
+	return strings.ToLower(strings.TrimSpace(key))
+}
@@ -0,0 +1,74 @@
+package cost
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestTable_Lookup(t *testing.T) {
+	table := NewTable(map[string]Price{
+		"gpt-4o":             {InputPerMillion: 2.5, OutputPerMillion: 10},
+		"gpt-4o-mini":        {InputPerMillion: 0.15, OutputPerMillion: 0.6},
+		"azureopenai/gpt-4o": {InputPerMillion: 3, OutputPerMillion: 12},
+		"ollama/*":           {},
+	})
+
+	cases := []struct {
+		provider, model string
+		want            float64
+		found           bool
+	}{
+		{"openai", "gpt-4o", 2.5, true},
+		{"openai", "GPT-4o", 2.5, true},
+		{"azureopenai", "gpt-4o", 3, true},
+		{"openai", "gpt-4o-mini-2024-07-18", 0.15, true},
+		{"openai", "gpt-4o-2024-08-06", 2.5, true},
+		{"ollama", "llama3.1:8b", 0, true},
+		{"openai", "unknown-model", 0, false},
+	}
+	for _, tc := range cases {
+		price, ok := table.Lookup(tc.provider, tc.model)
+		if ok != tc.found || price.InputPerMillion != tc.want {
+			t.Errorf("Lookup(%q, %q) = %+v, %v; want input %v, %v", tc.provider, tc.model, price, ok, tc.want, tc.found)
+		}
+	}
+}
+
+func TestTable_Compute(t *testing.T) {
+	table := NewTable(map[string]Price{"m": {InputPerMillion: 2, OutputPerMillion: 8}})
+	usd, ok := table.Compute("p", "m", types.Usage{InputTokens: 1_000, OutputTokens: 500})
+	if !ok {
+		t.Fatal("expected model to be priced")
+	}
+	if math.Abs(usd-0.006) > 1e-12 {
+		t.Fatalf("expected 0.006, got %v", usd)
+	}
+	if _, ok := table.Compute("p", "other", types.Usage{InputTokens: 1}); ok {
+		t.Fatal("expected unknown model to be unpriced")
+	}
+}
+
+func TestLoadTable_OverridesDefaults(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "prices.json")
+	raw := `{"gpt-4o":{"inputPerMillion":1,"outputPerMillion":2},"custom/model":{"inputPerMillion":5}}`
+	if err := os.WriteFile(path, []byte(raw), 0o600); err != nil {
+		t.Fatalf("write pricing file: %v", err)
+	}
+	table, err := LoadTable(path)
+	if err != nil {
+		t.Fatalf("load table: %v", err)
+	}
+	if p, _ := table.Lookup("openai", "gpt-4o"); p.InputPerMillion != 1 {
+		t.Fatalf("expected override, got %+v", p)
+	}
+	if p, ok := table.Lookup("custom", "model"); !ok || p.InputPerMillion != 5 {
+		t.Fatalf("expected custom price, got %+v %v", p, ok)
+	}
+	if _, ok := table.Lookup("gemini", "gemini-2.5-pro"); !ok {
+		t.Fatal("expected defaults to be kept")
+	}
+}
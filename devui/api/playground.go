@@ -8,6 +8,8 @@ import (
 )
 
 type PlaygroundRequest struct {
+	// RunID, when set, is used for the run instead of a generated ID.
+	RunID        string           `json:"runId,omitempty"`
 	Input        string           `json:"input"`
 	SessionID    string           `json:"sessionId,omitempty"`
 	History      []types.Message  `json:"history,omitempty"`
@@ -41,3 +43,33 @@ type PlaygroundRunner interface {
 type PlaygroundStreamRunner interface {
 	RunStream(ctx context.Context, req PlaygroundRequest, onChunk func(types.StreamChunk) error) (PlaygroundResponse, error)
 }
+
+// PlaygroundResumer is implemented by playground runners that can continue
+// runs suspended for tool approval.
+type PlaygroundResumer interface {
+	Resume(ctx context.Context, runID string) (PlaygroundResponse, error)
+}
+
+// PlaygroundInputResumer is implemented by playground runners that can
+// continue graph runs interrupted for human input.
+type PlaygroundInputResumer interface {
+	ResumeWithInput(ctx context.Context, runID string, input map[string]any) (PlaygroundResponse, error)
+}
+
+// PlaygroundForkRequest starts a graph run from a checkpoint of another run.
+// Workflow, Tools and SystemPrompt default to those recorded on the run.
+type PlaygroundForkRequest struct {
+	RunID        string         `json:"runId"`
+	Seq          int            `json:"seq"`
+	Data         map[string]any `json:"data,omitempty"`
+	NextNode     string         `json:"nextNode,omitempty"`
+	Workflow     string         `json:"workflow,omitempty"`
+	Tools        []string       `json:"tools,omitempty"`
+	SystemPrompt string         `json:"systemPrompt,omitempty"`
+}
+
+// PlaygroundForker is implemented by playground runners that can fork
+// graph runs from a checkpoint.
+type PlaygroundForker interface {
+	Fork(ctx context.Context, req PlaygroundForkRequest) (PlaygroundResponse, error)
+}
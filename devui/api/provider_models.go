@@ -10,6 +10,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/providers/openaicompat"
 )
 
 type providerModelsResponse struct {
@@ -31,6 +33,8 @@ func providerModelEnvKey(provider string) string {
 		return "AZURE_OPENAI_MODEL"
 	case "ollama":
 		return "OLLAMA_MODEL"
+	case "openaicompat":
+		return "OPENAI_COMPAT_MODEL"
 	default:
 		return "GEMINI_MODEL"
 	}
@@ -81,6 +85,8 @@ func fetchProviderModels(provider string) ([]string, string, error) {
 		return nil, "fallback", fmt.Errorf("anthropic model listing API not configured")
 	case "azureopenai":
 		return nil, "fallback", fmt.Errorf("azure openai model listing API not configured")
+	case "openaicompat":
+		return fetchOpenAICompatModels()
 	default:
 		return nil, "fallback", fmt.Errorf("unsupported provider %q", provider)
 	}
@@ -126,6 +132,47 @@ func fetchOpenAIModels() ([]string, string, error) {
 	return uniqueSorted(out), "api", nil
 }
 
+func fetchOpenAICompatModels() ([]string, string, error) {
+	base := strings.TrimSpace(os.Getenv("OPENAI_COMPAT_BASE_URL"))
+	if base == "" {
+		profile, ok := openaicompat.LookupProfile(os.Getenv("OPENAI_COMPAT_PROFILE"))
+		if !ok || profile.BaseURL == "" {
+			return nil, "fallback", fmt.Errorf("OPENAI_COMPAT_BASE_URL not configured")
+		}
+		base = profile.BaseURL
+	}
+	endpoint := strings.TrimRight(base, "/") + "/v1/models"
+	req, _ := http.NewRequest(http.MethodGet, endpoint, nil)
+	if apiKey := strings.TrimSpace(os.Getenv("OPENAI_COMPAT_API_KEY")); apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+	client := &http.Client{Timeout: 20 * time.Second}
+	res, err := client.Do(req)
+	if err != nil {
+		return nil, "fallback", err
+	}
+	defer res.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(res.Body, 2*1024*1024))
+	if res.StatusCode >= 300 {
+		return nil, "fallback", fmt.Errorf("openai-compatible HTTP %d", res.StatusCode)
+	}
+	var parsed struct {
+		Data []struct {
+			ID string `json:"id"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return nil, "fallback", err
+	}
+	out := make([]string, 0, len(parsed.Data))
+	for _, d := range parsed.Data {
+		if strings.TrimSpace(d.ID) != "" {
+			out = append(out, d.ID)
+		}
+	}
+	return uniqueSorted(out), "api", nil
+}
+
 func fetchGeminiModels() ([]string, string, error) {
 	apiKey := strings.TrimSpace(os.Getenv("GEMINI_API_KEY"))
 	if apiKey == "" {
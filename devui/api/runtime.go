@@ -16,3 +16,9 @@ type RuntimeService interface {
 	RequeueRun(ctx context.Context, runID string) error
 	ListDLQ(ctx context.Context, limit int) ([]queue.Delivery, error)
 }
+
+// RuntimeResumer is implemented by runtimes that can continue runs
+// suspended for tool approval on a worker.
+type RuntimeResumer interface {
+	ResumeRun(ctx context.Context, runID string) error
+}
@@ -15,6 +15,7 @@ import (
 	"sync"
 	"time"
 
+	agentfw "github.com/PipeOpsHQ/agent-sdk-go/agent"
 	"github.com/PipeOpsHQ/agent-sdk-go/delivery"
 	"github.com/PipeOpsHQ/agent-sdk-go/devui/auth"
 	"github.com/PipeOpsHQ/agent-sdk-go/devui/catalog"
@@ -48,6 +49,9 @@ type Config struct {
 	PromptSpecDir    string
 	FlowSpecDir      string
 	ToolSpecDir      string
+	// Runs tracks the agent runs in flight in this process. Defaults to
+	// agent.DefaultRunRegistry.
+	Runs *agentfw.RunRegistry
 }
 
 type Server struct {
@@ -84,6 +88,9 @@ func NewServer(cfg Config) *Server {
 	if strings.TrimSpace(cfg.ToolSpecDir) == "" {
 		cfg.ToolSpecDir = "./.ai-agent/tools"
 	}
+	if cfg.Runs == nil {
+		cfg.Runs = agentfw.DefaultRunRegistry
+	}
 	s := &Server{
 		cfg:             cfg,
 		stream:          newEventStream(),
@@ -153,6 +160,9 @@ func (s *Server) Close() error {
 func (s *Server) registerRoutes() {
 	s.mux.HandleFunc("/api/v1/runs", s.require(auth.RoleViewer, s.handleRuns))
 	s.mux.HandleFunc("/api/v1/runs/", s.require(auth.RoleViewer, s.handleRunSubresources))
+	s.mux.HandleFunc("/api/v1/approvals", s.require(auth.RoleViewer, s.handleApprovals))
+	s.mux.HandleFunc("/api/v1/interrupts", s.require(auth.RoleViewer, s.handleInterrupts))
+	s.mux.HandleFunc("/api/v1/active-runs", s.require(auth.RoleViewer, s.handleActiveRuns))
 	s.mux.HandleFunc("/api/v1/sessions/", s.require(auth.RoleViewer, s.handleSessionRuns))
 	s.mux.HandleFunc("/api/v1/metrics/summary", s.require(auth.RoleViewer, s.handleMetrics))
 	s.mux.HandleFunc("/api/v1/stream/events", s.require(auth.RoleViewer, s.handleSSE))
@@ -360,6 +370,10 @@ func (s *Server) handleRunSubresources(w http.ResponseWriter, r *http.Request, p
 		}
 		writeJSON(w, http.StatusOK, events)
 	case "checkpoints":
+		if len(parts) > 2 {
+			s.handleRunCheckpoint(w, r, runID, parts[2])
+			return
+		}
 		if r.Method != http.MethodGet {
 			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
 			return
@@ -413,6 +427,14 @@ func (s *Server) handleRunSubresources(w http.ResponseWriter, r *http.Request, p
 		default:
 			writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
 		}
+	case "approvals":
+		s.handleRunApprovals(w, r, p, runID)
+	case "input":
+		s.handleRunInput(w, r, p, runID)
+	case "fork":
+		s.handleRunFork(w, r, p, runID)
+	case "control":
+		s.handleRunControl(w, r, p, runID)
 	default:
 		writeError(w, http.StatusNotFound, fmt.Errorf("unsupported run endpoint"))
 	}
@@ -818,8 +840,8 @@ func (s *Server) handlePlaygroundStream(w http.ResponseWriter, r *http.Request,
 	for {
 		select {
 		case chunk := <-chunkCh:
-			if strings.TrimSpace(chunk.Text) != "" {
-				sendSSE("delta", map[string]any{"text": chunk.Text})
+			if name, ok := streamChunkSSEEvent(chunk); ok {
+				sendSSE(name, chunk)
 			}
 		case event := <-eventCh:
 			sendSSE("progress", map[string]any{
@@ -835,8 +857,8 @@ func (s *Server) handlePlaygroundStream(w http.ResponseWriter, r *http.Request,
 				for {
 					select {
 					case chunk := <-chunkCh:
-						if strings.TrimSpace(chunk.Text) != "" {
-							sendSSE("delta", map[string]any{"text": chunk.Text})
+						if name, ok := streamChunkSSEEvent(chunk); ok {
+							sendSSE(name, chunk)
 						}
 					default:
 						goto chunksDrained
@@ -887,6 +909,24 @@ func (s *Server) handlePlaygroundStream(w http.ResponseWriter, r *http.Request,
 	}
 }
 
+// streamChunkSSEEvent maps a typed stream chunk to its SSE event name. Text
+// keeps the historical "delta" name; every other event type is forwarded
+// under its own name with the full chunk as payload. Terminal chunks are
+// dropped because the handler sends its own "complete" event.
+func streamChunkSSEEvent(chunk fwtypes.StreamChunk) (string, bool) {
+	switch kind := chunk.Kind(); kind {
+	case "", fwtypes.StreamEventDone:
+		return "", false
+	case fwtypes.StreamEventText:
+		if chunk.Text == "" {
+			return "", false
+		}
+		return "delta", true
+	default:
+		return string(kind), true
+	}
+}
+
 func splitOutputChunks(s string, n int) []string {
 	if n <= 0 {
 		n = 180
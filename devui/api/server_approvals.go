@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+
+	agentfw "github.com/PipeOpsHQ/agent-sdk-go/agent"
+	"github.com/PipeOpsHQ/agent-sdk-go/devui/auth"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+type approvalSummary struct {
+	RunID     string                     `json:"runId"`
+	SessionID string                     `json:"sessionId,omitempty"`
+	Status    string                     `json:"status"`
+	Input     string                     `json:"input,omitempty"`
+	UpdatedAt *time.Time                 `json:"updatedAt,omitempty"`
+	Pending   []agentfw.PendingApproval  `json:"pending"`
+	Decisions []agentfw.ApprovalDecision `json:"decisions,omitempty"`
+}
+
+type approvalRequest struct {
+	Decisions []agentfw.ApprovalDecision `json:"decisions"`
+	// Resume continues the run once every call is decided. Defaults to true.
+	Resume *bool `json:"resume,omitempty"`
+}
+
+func newApprovalSummary(run state.RunRecord) approvalSummary {
+	pending := agentfw.PendingApprovals(run)
+	if pending == nil {
+		pending = []agentfw.PendingApproval{}
+	}
+	return approvalSummary{
+		RunID:     run.RunID,
+		SessionID: run.SessionID,
+		Status:    run.Status,
+		Input:     run.Input,
+		UpdatedAt: run.UpdatedAt,
+		Pending:   pending,
+		Decisions: agentfw.ApprovalDecisions(run),
+	}
+}
+
+// handleApprovals lists the runs waiting for tool approval.
+func (s *Server) handleApprovals(w http.ResponseWriter, r *http.Request, _ principal) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	if s.cfg.StateStore == nil {
+		writeJSON(w, http.StatusOK, []approvalSummary{})
+		return
+	}
+	runs, err := s.cfg.StateStore.ListRuns(r.Context(), state.ListRunsQuery{
+		Status: agentfw.RunStatusAwaitingApproval,
+		Limit:  parseInt(r.URL.Query().Get("limit"), 100),
+		Offset: parseInt(r.URL.Query().Get("offset"), 0),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]approvalSummary, 0, len(runs))
+	for _, run := range runs {
+		out = append(out, newApprovalSummary(run))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleRunApprovals shows a run's pending tool calls and records decisions
+// for them. Once every call is decided the run is resumed, on a worker when
+// the runtime supports it and otherwise through the playground runner.
+func (s *Server) handleRunApprovals(w http.ResponseWriter, r *http.Request, p principal, runID string) {
+	if s.cfg.StateStore == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("state store not configured"))
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		run, err := s.cfg.StateStore.LoadRun(r.Context(), runID)
+		if err != nil {
+			status := http.StatusInternalServerError
+			if errors.Is(err, state.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, newApprovalSummary(run))
+	case http.MethodPost:
+		if p.Role.Rank() < auth.RoleOperator.Rank() {
+			writeError(w, http.StatusForbidden, fmt.Errorf("insufficient role: requires %s", auth.RoleOperator))
+			return
+		}
+		var req approvalRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if len(req.Decisions) == 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("decisions are required"))
+			return
+		}
+		for i := range req.Decisions {
+			req.Decisions[i].Action = agentfw.ApprovalAction(strings.ToLower(strings.TrimSpace(string(req.Decisions[i].Action))))
+			if strings.TrimSpace(req.Decisions[i].DecidedBy) == "" {
+				req.Decisions[i].DecidedBy = p.KeyID
+			}
+		}
+		remaining, err := agentfw.RecordApprovalDecisions(r.Context(), s.cfg.StateStore, runID, req.Decisions...)
+		if err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, state.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			writeError(w, status, err)
+			return
+		}
+		s.audit(r.Context(), p, "run.approval", "runs/"+runID, req.Decisions)
+
+		resp := map[string]any{"ok": true, "runId": runID, "pending": remaining, "resumed": false}
+		if len(remaining) > 0 || (req.Resume != nil && !*req.Resume) {
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		if resumer, ok := s.cfg.Runtime.(RuntimeResumer); ok {
+			if err := resumer.ResumeRun(r.Context(), runID); err != nil {
+				writeError(w, http.StatusInternalServerError, err)
+				return
+			}
+			resp["resumed"] = true
+			resp["status"] = "queued"
+			writeJSON(w, http.StatusOK, resp)
+			return
+		}
+		resumer, ok := s.cfg.Playground.(PlaygroundResumer)
+		if !ok {
+			writeError(w, http.StatusNotImplemented, fmt.Errorf("no runner can resume runs"))
+			return
+		}
+		resumeInBackground(r.Context(), runID, func(ctx context.Context) error {
+			_, err := resumer.Resume(ctx, runID)
+			return err
+		})
+		resp["resumed"] = true
+		resp["status"] = "running"
+		writeJSON(w, http.StatusAccepted, resp)
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+	}
+}
+
+// resumeInBackground resumes a run apart from the request that asked for
+// it, so a client that disconnects does not cancel the run halfway through
+// its tool calls. The run records its own outcome.
+func resumeInBackground(ctx context.Context, runID string, resume func(context.Context) error) {
+	ctx = context.WithoutCancel(ctx)
+	go func() {
+		if err := resume(ctx); err != nil {
+			log.Printf("resume of run %s failed: %v", runID, err)
+		}
+	}()
+}
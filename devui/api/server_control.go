@@ -0,0 +1,92 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	agentfw "github.com/PipeOpsHQ/agent-sdk-go/agent"
+	"github.com/PipeOpsHQ/agent-sdk-go/devui/auth"
+)
+
+type runControlRequest struct {
+	// Action is one of cancel, steer, pause or resume.
+	Action  string `json:"action"`
+	Message string `json:"message,omitempty"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+// handleActiveRuns lists the agent runs in flight in this process.
+func (s *Server) handleActiveRuns(w http.ResponseWriter, r *http.Request, _ principal) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	writeJSON(w, http.StatusOK, s.cfg.Runs.List())
+}
+
+// handleRunControl cancels, steers, pauses or resumes an in-flight local
+// run. Cancelling a run that is not in flight here falls back to the
+// distributed runtime when one is configured.
+func (s *Server) handleRunControl(w http.ResponseWriter, r *http.Request, p principal, runID string) {
+	switch r.Method {
+	case http.MethodGet:
+		handle, err := s.cfg.Runs.Handle(runID)
+		if err != nil {
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, handle.Info())
+	case http.MethodPost:
+		if p.Role.Rank() < auth.RoleOperator.Rank() {
+			writeError(w, http.StatusForbidden, fmt.Errorf("insufficient role: requires %s", auth.RoleOperator))
+			return
+		}
+		var req runControlRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		action := strings.ToLower(strings.TrimSpace(req.Action))
+		handle, err := s.cfg.Runs.Handle(runID)
+		if err != nil {
+			if action == "cancel" && errors.Is(err, agentfw.ErrRunNotActive) && s.cfg.Runtime != nil {
+				if err := s.cfg.Runtime.CancelRun(r.Context(), runID); err != nil {
+					writeError(w, http.StatusBadRequest, err)
+					return
+				}
+				s.audit(r.Context(), p, "run.control.cancel", "runs/"+runID, req)
+				writeJSON(w, http.StatusOK, map[string]any{"ok": true, "runId": runID, "runtime": true})
+				return
+			}
+			writeError(w, http.StatusNotFound, err)
+			return
+		}
+		switch action {
+		case "cancel":
+			handle.Cancel(req.Reason)
+		case "steer":
+			if err := handle.Steer(req.Message); err != nil {
+				status := http.StatusBadRequest
+				if errors.Is(err, agentfw.ErrRunFinishing) {
+					status = http.StatusConflict
+				}
+				writeError(w, status, err)
+				return
+			}
+		case "pause":
+			handle.Pause()
+		case "resume":
+			handle.Resume()
+		default:
+			writeError(w, http.StatusBadRequest, fmt.Errorf("unsupported run control action %q", req.Action))
+			return
+		}
+		s.audit(r.Context(), p, "run.control."+action, "runs/"+runID, req)
+		writeJSON(w, http.StatusOK, map[string]any{"ok": true, "runId": runID, "run": handle.Info()})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+	}
+}
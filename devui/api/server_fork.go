@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/devui/auth"
+	"github.com/PipeOpsHQ/agent-sdk-go/graph"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+// handleRunCheckpoint serves one checkpoint of a run by sequence number, or
+// with "diff" the changes between two checkpoints: from and to are
+// sequence numbers of this run, unless toRun names another run, such as a
+// fork, that to belongs to.
+func (s *Server) handleRunCheckpoint(w http.ResponseWriter, r *http.Request, runID, name string) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	if s.cfg.StateStore == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("state store not configured"))
+		return
+	}
+	if name != "diff" {
+		seq, err := strconv.Atoi(name)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid checkpoint seq %q", name))
+			return
+		}
+		checkpoint, err := state.LoadCheckpoint(r.Context(), s.cfg.StateStore, runID, seq)
+		if err != nil {
+			writeCheckpointError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, checkpoint)
+		return
+	}
+
+	query := r.URL.Query()
+	fromSeq, err := strconv.Atoi(query.Get("from"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("from must be a checkpoint seq"))
+		return
+	}
+	toSeq, err := strconv.Atoi(query.Get("to"))
+	if err != nil {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("to must be a checkpoint seq"))
+		return
+	}
+	toRunID := strings.TrimSpace(query.Get("toRun"))
+	if toRunID == "" {
+		toRunID = runID
+	}
+	from, err := state.LoadCheckpoint(r.Context(), s.cfg.StateStore, runID, fromSeq)
+	if err != nil {
+		writeCheckpointError(w, err)
+		return
+	}
+	to, err := state.LoadCheckpoint(r.Context(), s.cfg.StateStore, toRunID, toSeq)
+	if err != nil {
+		writeCheckpointError(w, err)
+		return
+	}
+	diff, err := graph.DiffCheckpoints(from, to)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, diff)
+}
+
+func writeCheckpointError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, state.ErrNotFound) {
+		status = http.StatusNotFound
+	}
+	writeError(w, status, err)
+}
+
+// handleRunFork starts a new run from one of the run's checkpoints through
+// the playground runner.
+func (s *Server) handleRunFork(w http.ResponseWriter, r *http.Request, p principal, runID string) {
+	if r.Method != http.MethodPost {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	if p.Role.Rank() < auth.RoleOperator.Rank() {
+		writeError(w, http.StatusForbidden, fmt.Errorf("insufficient role: requires %s", auth.RoleOperator))
+		return
+	}
+	var req PlaygroundForkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	req.RunID = runID
+	if req.Seq < 1 {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("seq is required"))
+		return
+	}
+	forker, ok := s.cfg.Playground.(PlaygroundForker)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("no runner can fork runs"))
+		return
+	}
+	s.audit(r.Context(), p, "run.fork", "runs/"+runID, req)
+	result, err := forker.Fork(r.Context(), req)
+	if err != nil {
+		writeCheckpointError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{
+		"ok":       true,
+		"parentId": runID,
+		"runId":    result.RunID,
+		"status":   result.Status,
+		"result":   result,
+	})
+}
@@ -0,0 +1,128 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/devui/auth"
+	"github.com/PipeOpsHQ/agent-sdk-go/graph"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+type interruptSummary struct {
+	RunID     string               `json:"runId"`
+	SessionID string               `json:"sessionId,omitempty"`
+	Status    string               `json:"status"`
+	Input     string               `json:"input,omitempty"`
+	Graph     string               `json:"graph,omitempty"`
+	UpdatedAt *time.Time           `json:"updatedAt,omitempty"`
+	Interrupt *graph.NodeInterrupt `json:"interrupt,omitempty"`
+}
+
+type runInputRequest struct {
+	Input map[string]any `json:"input"`
+}
+
+func newInterruptSummary(run state.RunRecord) interruptSummary {
+	summary := interruptSummary{
+		RunID:     run.RunID,
+		SessionID: run.SessionID,
+		Status:    run.Status,
+		Input:     run.Input,
+		UpdatedAt: run.UpdatedAt,
+	}
+	if name, ok := run.Metadata["graph"].(string); ok {
+		summary.Graph = name
+	}
+	if interrupt, ok := graph.PendingInterrupt(run); ok {
+		summary.Interrupt = &interrupt
+	}
+	return summary
+}
+
+// handleInterrupts lists the graph runs waiting for human input.
+func (s *Server) handleInterrupts(w http.ResponseWriter, r *http.Request, _ principal) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+		return
+	}
+	if s.cfg.StateStore == nil {
+		writeJSON(w, http.StatusOK, []interruptSummary{})
+		return
+	}
+	runs, err := s.cfg.StateStore.ListRuns(r.Context(), state.ListRunsQuery{
+		Status: graph.RunStatusInterrupted,
+		Limit:  parseInt(r.URL.Query().Get("limit"), 100),
+		Offset: parseInt(r.URL.Query().Get("offset"), 0),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	out := make([]interruptSummary, 0, len(runs))
+	for _, run := range runs {
+		out = append(out, newInterruptSummary(run))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// handleRunInput shows what an interrupted run is waiting for and resumes
+// it with the submitted input through the playground runner, apart from
+// the request.
+func (s *Server) handleRunInput(w http.ResponseWriter, r *http.Request, p principal, runID string) {
+	if s.cfg.StateStore == nil {
+		writeError(w, http.StatusNotImplemented, fmt.Errorf("state store not configured"))
+		return
+	}
+	run, err := s.cfg.StateStore.LoadRun(r.Context(), runID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, state.ErrNotFound) {
+			status = http.StatusNotFound
+		}
+		writeError(w, status, err)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, newInterruptSummary(run))
+	case http.MethodPost:
+		if p.Role.Rank() < auth.RoleOperator.Rank() {
+			writeError(w, http.StatusForbidden, fmt.Errorf("insufficient role: requires %s", auth.RoleOperator))
+			return
+		}
+		if _, ok := graph.PendingInterrupt(run); !ok {
+			writeError(w, http.StatusConflict, fmt.Errorf("run %s is not waiting for input", runID))
+			return
+		}
+		var req runInputRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		if req.Input == nil {
+			req.Input = map[string]any{}
+		}
+		resumer, ok := s.cfg.Playground.(PlaygroundInputResumer)
+		if !ok {
+			writeError(w, http.StatusNotImplemented, fmt.Errorf("no runner can resume interrupted runs"))
+			return
+		}
+		s.audit(r.Context(), p, "run.input", "runs/"+runID, req.Input)
+		resumeInBackground(r.Context(), runID, func(ctx context.Context) error {
+			_, err := resumer.ResumeWithInput(ctx, runID, req.Input)
+			return err
+		})
+		writeJSON(w, http.StatusAccepted, map[string]any{
+			"ok":     true,
+			"runId":  runID,
+			"status": "running",
+		})
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed"))
+	}
+}
@@ -481,6 +481,10 @@ func (s *Server) applyIntervention(ctx context.Context, p principal, runID strin
 	}
 	switch action {
 	case "cancel":
+		if handle, err := s.cfg.Runs.Handle(runID); err == nil {
+			handle.Cancel(req.Reason)
+			break
+		}
 		if s.cfg.Runtime == nil {
 			return nil, fmt.Errorf("runtime service not configured")
 		}
@@ -529,6 +533,7 @@ func (s *Server) workflowTopology(ctx context.Context, workflowName string) map[
 		ID           string  `json:"id"`
 		Label        string  `json:"label"`
 		Kind         string  `json:"kind"`
+		Graph        string  `json:"graph,omitempty"`
 		X            int     `json:"x"`
 		Y            int     `json:"y"`
 		Executions   int     `json:"executions"`
@@ -539,6 +544,8 @@ func (s *Server) workflowTopology(ctx context.Context, workflowName string) map[
 		From        string `json:"from"`
 		To          string `json:"to"`
 		Conditional bool   `json:"conditional,omitempty"`
+		Parallel    bool   `json:"parallel,omitempty"`
+		OnError     bool   `json:"onError,omitempty"`
 	}
 	nodes := []node{}
 	edges := []edge{}
@@ -561,12 +568,13 @@ func (s *Server) workflowTopology(ctx context.Context, workflowName string) map[
 						ID:    ni.ID,
 						Label: label,
 						Kind:  ni.Kind,
+						Graph: ni.Graph,
 						X:     80 + i*xStep,
 						Y:     120,
 					})
 				}
 				for _, ei := range edgeInfos {
-					edges = append(edges, edge{From: ei.From, To: ei.To, Conditional: ei.Conditional})
+					edges = append(edges, edge{From: ei.From, To: ei.To, Conditional: ei.Conditional, Parallel: ei.Parallel, OnError: ei.OnError})
 				}
 			}
 		}
@@ -589,6 +589,8 @@ async function loadDashboard() {
       document.getElementById('status-workers').textContent = 'None';
     }
 
+    renderSpend(metrics);
+
     setLiveSummaryReactive({
       completed,
       running,
@@ -606,6 +608,34 @@ async function loadDashboard() {
   }
 }
 
+function formatUSD(value) {
+  const amount = Number(value) || 0;
+  return `$${amount < 1 && amount > 0 ? amount.toFixed(4) : amount.toFixed(2)}`;
+}
+
+function renderSpend(metrics) {
+  const list = document.getElementById('spendList');
+  const total = document.getElementById('spendTotal');
+  if (total) {
+    total.textContent = formatUSD(metrics.totalCostUsd);
+  }
+  if (!list) return;
+  const rows = Array.isArray(metrics.spend) ? metrics.spend : [];
+  if (rows.length === 0) {
+    list.innerHTML = '<div class="empty-state"><p>No priced provider calls yet</p></div>';
+    return;
+  }
+  list.innerHTML = rows.map(row => {
+    const label = row.model ? `${row.provider || 'unknown'} / ${row.model}` : (row.provider || 'unknown');
+    const tokens = `${row.inputTokens || 0} in · ${row.outputTokens || 0} out · ${row.calls || 0} calls`;
+    return `
+      <div class="status-item" title="${escapeHtml(tokens)}">
+        <span class="status-name">${escapeHtml(label)}</span>
+        <span class="status-value">${escapeHtml(formatUSD(row.costUsd))}</span>
+      </div>`;
+  }).join('');
+}
+
 async function loadRecentActivity() {
   const container = document.getElementById('activityList');
   const liveStrip = document.getElementById('liveRunsStrip');
@@ -2029,6 +2059,7 @@ function initSettings() {
     anthropic: 'ANTHROPIC_API_KEY',
     azureopenai: 'AZURE_OPENAI_API_KEY',
     ollama: 'OLLAMA_API_KEY',
+    openaicompat: 'OPENAI_COMPAT_API_KEY',
   };
   const providerModelMap = {
     gemini: 'GEMINI_MODEL',
@@ -2036,6 +2067,7 @@ function initSettings() {
     anthropic: 'ANTHROPIC_MODEL',
     azureopenai: 'AZURE_OPENAI_MODEL',
     ollama: 'OLLAMA_MODEL',
+    openaicompat: 'OPENAI_COMPAT_MODEL',
   };
 
   const loadProviderModels = async (provider, currentModel = '') => {
@@ -2649,6 +2681,17 @@ function appendStreamingDelta(el, text) {
   out.textContent = (out.textContent || '') + String(text || '');
 }
 
+function appendStreamingReasoning(el, text) {
+  if (!el || !text) return;
+  let out = el.querySelector('.streaming-reasoning');
+  if (!out) {
+    out = document.createElement('div');
+    out.className = 'streaming-reasoning';
+    el.insertBefore(out, el.querySelector('.streaming-output'));
+  }
+  out.textContent = (out.textContent || '') + String(text);
+}
+
 function removeStreamingProgress(el) {
   if (el) el.remove();
 }
@@ -2759,6 +2802,14 @@ async function streamPlaygroundRun(payload, progressEl) {
             updateStreamingProgress(progressEl, data);
           } else if (eventType === 'delta') {
             appendStreamingDelta(progressEl, data?.text || '');
+          } else if (eventType === 'reasoning') {
+            appendStreamingReasoning(progressEl, data?.reasoning || '');
+          } else if (eventType === 'tool_call') {
+            updateStreamingProgress(progressEl, { kind: 'tool', status: 'started', name: data?.toolCall?.name });
+          } else if (eventType === 'tool_result') {
+            updateStreamingProgress(progressEl, { kind: 'tool', status: 'completed', name: data?.toolResult?.name });
+          } else if (eventType === 'error') {
+            updateStreamingProgress(progressEl, { message: `❌ ${data?.error || 'stream error'}` });
           } else if (eventType === 'complete') {
             finalResponse = data;
           }
@@ -61,6 +61,7 @@ import (
 	_ "github.com/PipeOpsHQ/agent-sdk-go/graphs/mapreduce" // registers "map-reduce" workflow
 	_ "github.com/PipeOpsHQ/agent-sdk-go/graphs/router"    // registers "router" workflow
 	"github.com/PipeOpsHQ/agent-sdk-go/guardrail"
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
 	"github.com/PipeOpsHQ/agent-sdk-go/observe"
 	observesqlite "github.com/PipeOpsHQ/agent-sdk-go/observe/store/sqlite"
 	providerfactory "github.com/PipeOpsHQ/agent-sdk-go/providers/factory"
@@ -225,16 +226,29 @@ func Start(ctx context.Context, opts ...Options) error {
 
 		// Inline worker
 		processor := func(ctx context.Context, task queue.Task) (distributed.ProcessResult, error) {
-			resp, err := playground.Run(ctx, devuiapi.PlaygroundRequest{
-				Input:        task.Input,
-				Workflow:     task.Workflow,
-				Tools:        task.Tools,
-				SystemPrompt: task.SystemPrompt,
-			})
+			var (
+				resp devuiapi.PlaygroundResponse
+				err  error
+			)
+			if distributed.IsResume(task) {
+				resp, err = playground.Resume(ctx, task.RunID)
+			} else {
+				resp, err = playground.Run(ctx, devuiapi.PlaygroundRequest{
+					RunID:        task.RunID,
+					Input:        task.Input,
+					Workflow:     task.Workflow,
+					Tools:        task.Tools,
+					SystemPrompt: task.SystemPrompt,
+				})
+			}
 			if err != nil {
 				return distributed.ProcessResult{}, err
 			}
-			return distributed.ProcessResult{Output: resp.Output, Provider: resp.Provider}, nil
+			result := distributed.ProcessResult{Output: resp.Output, Provider: resp.Provider}
+			if resp.Status == agentfw.RunStatusAwaitingApproval {
+				result.Status = resp.Status
+			}
+			return result, nil
 		}
 
 		inlineWorker, wErr := distributed.NewWorker(
@@ -559,17 +573,9 @@ func (r *playgroundRunner) Run(ctx context.Context, req devuiapi.PlaygroundReque
 	sessionID := strings.TrimSpace(req.SessionID)
 	if sessionID != "" {
 		agentOpts = append(agentOpts, agentfw.WithSessionID(sessionID))
-		// Load previous messages from the most recent completed run in this session.
-		if r.store != nil {
-			prevRuns, _ := r.store.ListRuns(ctx, state.ListRunsQuery{
-				SessionID: sessionID,
-				Status:    "completed",
-				Limit:     1,
-			})
-			if len(prevRuns) > 0 && len(prevRuns[0].Messages) > 0 {
-				agentOpts = append(agentOpts, agentfw.WithConversationHistory(prevRuns[0].Messages))
-			}
-		}
+		// Continue the session's conversation, including tool exchanges,
+		// from the runs persisted in the store.
+		agentOpts = append(agentOpts, agentfw.WithSessionMemory(agentfw.SessionMemory{}))
 	}
 
 	if r.store != nil {
@@ -590,6 +596,13 @@ func (r *playgroundRunner) Run(ctx context.Context, req devuiapi.PlaygroundReque
 		}
 	}
 
+	// Hold tool calls for approval when a policy is configured.
+	envOpts, err := r.envOptions()
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	agentOpts = append(agentOpts, envOpts...)
+
 	agent, err := agentfw.New(provider, agentOpts...)
 	if err != nil {
 		return devuiapi.PlaygroundResponse{}, fmt.Errorf("agent create failed: %w", err)
@@ -597,7 +610,15 @@ func (r *playgroundRunner) Run(ctx context.Context, req devuiapi.PlaygroundReque
 
 	// Direct run (no workflow graph)
 	if wfName == "" {
+		if runID := strings.TrimSpace(req.RunID); runID != "" {
+			runCtx = agentfw.ContextWithRunID(runCtx, runID)
+		}
 		result, runErr := agent.RunDetailed(runCtx, req.Input)
+		if resp, ok := r.awaitingApproval(ctx, runErr, req.Tools, systemPrompt, provider.Name()); ok {
+			resp.AppliedSkills = appliedSkills
+			resp.ReplyTo = req.ReplyTo
+			return resp, nil
+		}
 		if runErr != nil {
 			return devuiapi.PlaygroundResponse{}, runErr
 		}
@@ -613,11 +634,16 @@ func (r *playgroundRunner) Run(ctx context.Context, req devuiapi.PlaygroundReque
 	}
 
 	// Workflow graph run
-	exec, err := buildExecutor(agent, r.store, r.observer, wfName)
+	exec, err := buildExecutor(agent, r.store, r.observer, wfName, req.Tools, systemPrompt)
 	if err != nil {
 		return devuiapi.PlaygroundResponse{}, fmt.Errorf("executor create failed: %w", err)
 	}
 	result, runErr := exec.Run(runCtx, req.Input)
+	if resp, ok := r.interrupted(runErr, provider.Name()); ok {
+		resp.AppliedSkills = appliedSkills
+		resp.ReplyTo = req.ReplyTo
+		return resp, nil
+	}
 	if runErr != nil {
 		return devuiapi.PlaygroundResponse{}, runErr
 	}
@@ -632,6 +658,263 @@ func (r *playgroundRunner) Run(ctx context.Context, req devuiapi.PlaygroundReque
 	}, nil
 }
 
+// envOptions returns the tool output budget and tool approval options set
+// by AGENT_TOOL_OUTPUT_* and AGENT_APPROVAL_* environment variables.
+func (r *playgroundRunner) envOptions() ([]agentfw.Option, error) {
+	var opts []agentfw.Option
+	budget, err := agentfw.ToolOutputBudgetFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if budget != nil {
+		opts = append(opts, agentfw.WithToolOutputBudget(*budget))
+	}
+	policy, err := agentfw.ApprovalPolicyFromEnv()
+	if err != nil || policy == nil {
+		return opts, err
+	}
+	if r.store == nil {
+		return nil, fmt.Errorf("tool approval is configured but no state store is available")
+	}
+	return append(opts, agentfw.WithToolApproval(*policy)), nil
+}
+
+// awaitingApproval turns an approval error into a response and records the
+// run's tools and system prompt so Resume can rebuild its agent.
+func (r *playgroundRunner) awaitingApproval(ctx context.Context, err error, toolNames []string, systemPrompt, providerName string) (devuiapi.PlaygroundResponse, bool) {
+	var approvalErr *agentfw.ApprovalRequiredError
+	if !errors.As(err, &approvalErr) {
+		return devuiapi.PlaygroundResponse{}, false
+	}
+	r.recordResumeOptions(ctx, approvalErr.RunID, map[string]any{
+		"tools":         toolNames,
+		"system_prompt": systemPrompt,
+	})
+	return devuiapi.PlaygroundResponse{
+		Status:    agentfw.RunStatusAwaitingApproval,
+		Output:    approvalErr.Error(),
+		RunID:     approvalErr.RunID,
+		SessionID: approvalErr.SessionID,
+		Provider:  providerName,
+	}, true
+}
+
+// Resume continues a run suspended for tool approval with the tools and
+// system prompt recorded when it was suspended.
+func (r *playgroundRunner) Resume(ctx context.Context, runID string) (devuiapi.PlaygroundResponse, error) {
+	if r.store == nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("state store is required to resume runs")
+	}
+	run, err := r.store.LoadRun(ctx, runID)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	provider, err := providerfactory.FromEnv(ctx)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("provider setup failed: %w", err)
+	}
+	agent, toolNames, systemPrompt, err := r.resumeAgent(provider, run)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	result, err := agent.ResumeRun(ctx, runID)
+	if resp, ok := r.awaitingApproval(ctx, err, toolNames, systemPrompt, provider.Name()); ok {
+		return resp, nil
+	}
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	return devuiapi.PlaygroundResponse{
+		Status:    "completed",
+		Output:    result.Output,
+		RunID:     result.RunID,
+		SessionID: result.SessionID,
+		Provider:  provider.Name(),
+	}, nil
+}
+
+// ResumeWithInput continues a workflow run interrupted for human input,
+// rebuilding its agent and workflow from what was recorded when it started.
+func (r *playgroundRunner) ResumeWithInput(ctx context.Context, runID string, input map[string]any) (devuiapi.PlaygroundResponse, error) {
+	if r.store == nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("state store is required to resume runs")
+	}
+	run, err := r.store.LoadRun(ctx, runID)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	wfName, _ := run.Metadata["workflow"].(string)
+	if strings.TrimSpace(wfName) == "" {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("run %s has no recorded workflow", runID)
+	}
+	provider, err := providerfactory.FromEnv(ctx)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("provider setup failed: %w", err)
+	}
+	agent, toolNames, systemPrompt, err := r.resumeAgent(provider, run)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	exec, err := buildExecutor(agent, r.store, r.observer, wfName, toolNames, systemPrompt)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("executor create failed: %w", err)
+	}
+	result, err := exec.ResumeWithInput(ctx, runID, input)
+	if resp, ok := r.interrupted(err, provider.Name()); ok {
+		return resp, nil
+	}
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	return devuiapi.PlaygroundResponse{
+		Status:    "completed",
+		Output:    result.Output,
+		RunID:     result.RunID,
+		SessionID: result.SessionID,
+		Provider:  provider.Name(),
+	}, nil
+}
+
+// Fork starts a workflow run from a checkpoint of another run. The
+// workflow, tools and system prompt default to those recorded on the run.
+func (r *playgroundRunner) Fork(ctx context.Context, req devuiapi.PlaygroundForkRequest) (devuiapi.PlaygroundResponse, error) {
+	if r.store == nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("state store is required to fork runs")
+	}
+	run, err := r.store.LoadRun(ctx, req.RunID)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	metadata := map[string]any{}
+	for k, v := range run.Metadata {
+		metadata[k] = v
+	}
+	if len(req.Tools) > 0 {
+		metadata["tools"] = req.Tools
+	}
+	if systemPrompt := strings.TrimSpace(req.SystemPrompt); systemPrompt != "" {
+		metadata["system_prompt"] = systemPrompt
+	}
+	run.Metadata = metadata
+	wfName := strings.TrimSpace(req.Workflow)
+	if wfName == "" {
+		wfName, _ = metadata["workflow"].(string)
+	}
+	if wfName == "" {
+		wfName, _ = metadata["graph"].(string)
+	}
+	if strings.TrimSpace(wfName) == "" {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("run %s has no recorded workflow", req.RunID)
+	}
+
+	provider, err := providerfactory.FromEnv(ctx)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("provider setup failed: %w", err)
+	}
+	agent, toolNames, systemPrompt, err := r.resumeAgent(provider, run)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	exec, err := buildExecutor(agent, r.store, r.observer, wfName, toolNames, systemPrompt)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("executor create failed: %w", err)
+	}
+	result, err := exec.Fork(ctx, req.RunID, req.Seq, graph.WithForkData(req.Data), graph.WithForkNextNode(req.NextNode))
+	if resp, ok := r.interrupted(err, provider.Name()); ok {
+		return resp, nil
+	}
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	return devuiapi.PlaygroundResponse{
+		Status:    "completed",
+		Output:    result.Output,
+		RunID:     result.RunID,
+		SessionID: result.SessionID,
+		Provider:  provider.Name(),
+	}, nil
+}
+
+// interrupted turns a graph interrupt into a response.
+func (r *playgroundRunner) interrupted(err error, providerName string) (devuiapi.PlaygroundResponse, bool) {
+	var interruptErr *graph.InterruptedError
+	if !errors.As(err, &interruptErr) {
+		return devuiapi.PlaygroundResponse{}, false
+	}
+	return devuiapi.PlaygroundResponse{
+		Status:    graph.RunStatusInterrupted,
+		Output:    interruptErr.Interrupt.Prompt,
+		RunID:     interruptErr.RunID,
+		SessionID: interruptErr.SessionID,
+		Provider:  providerName,
+	}, true
+}
+
+// recordResumeOptions adds what is needed to rebuild a suspended run's
+// agent to its metadata.
+func (r *playgroundRunner) recordResumeOptions(ctx context.Context, runID string, options map[string]any) {
+	if r.store == nil {
+		return
+	}
+	run, err := r.store.LoadRun(ctx, runID)
+	if err != nil {
+		return
+	}
+	if run.Metadata == nil {
+		run.Metadata = map[string]any{}
+	}
+	for k, v := range options {
+		run.Metadata[k] = v
+	}
+	if err := r.store.SaveRun(ctx, run); err != nil {
+		log.Printf("failed to record resume options for %s: %v", runID, err)
+	}
+}
+
+// resumeAgent rebuilds the agent of a suspended run with the tools and
+// system prompt recorded when it was suspended.
+func (r *playgroundRunner) resumeAgent(provider llm.Provider, run state.RunRecord) (*agentfw.Agent, []string, string, error) {
+	systemPrompt, _ := run.Metadata["system_prompt"].(string)
+	var toolNames []string
+	switch v := run.Metadata["tools"].(type) {
+	case []string:
+		toolNames = v
+	case []any:
+		for _, t := range v {
+			if name, ok := t.(string); ok {
+				toolNames = append(toolNames, name)
+			}
+		}
+	}
+
+	agentOpts := []agentfw.Option{
+		agentfw.WithSystemPrompt(systemPrompt),
+		agentfw.WithMaxIterations(25),
+		agentfw.WithStore(r.store),
+	}
+	if r.observer != nil {
+		agentOpts = append(agentOpts, agentfw.WithObserver(r.observer))
+	}
+	if len(toolNames) > 0 {
+		selected, err := tools.BuildSelection(toolNames)
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("tool selection failed: %w", err)
+		}
+		for _, t := range selected {
+			agentOpts = append(agentOpts, agentfw.WithTool(t))
+		}
+	}
+	envOpts, err := r.envOptions()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	agent, err := agentfw.New(provider, append(agentOpts, envOpts...)...)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("agent create failed: %w", err)
+	}
+	return agent, toolNames, systemPrompt, nil
+}
+
 func (r *playgroundRunner) RunStream(ctx context.Context, req devuiapi.PlaygroundRequest, onChunk func(fwtypes.StreamChunk) error) (devuiapi.PlaygroundResponse, error) {
 	if onChunk == nil {
 		return devuiapi.PlaygroundResponse{}, fmt.Errorf("stream callback is required")
@@ -723,16 +1006,7 @@ func (r *playgroundRunner) RunStream(ctx context.Context, req devuiapi.Playgroun
 	sessionID := strings.TrimSpace(req.SessionID)
 	if sessionID != "" {
 		agentOpts = append(agentOpts, agentfw.WithSessionID(sessionID))
-		if r.store != nil {
-			prevRuns, _ := r.store.ListRuns(ctx, state.ListRunsQuery{
-				SessionID: sessionID,
-				Status:    "completed",
-				Limit:     1,
-			})
-			if len(prevRuns) > 0 && len(prevRuns[0].Messages) > 0 {
-				agentOpts = append(agentOpts, agentfw.WithConversationHistory(prevRuns[0].Messages))
-			}
-		}
+		agentOpts = append(agentOpts, agentfw.WithSessionMemory(agentfw.SessionMemory{}))
 	}
 
 	if r.store != nil {
@@ -773,11 +1047,19 @@ func (r *playgroundRunner) RunStream(ctx context.Context, req devuiapi.Playgroun
 		}, nil
 	}
 
-	exec, err := buildExecutor(agent, r.store, r.observer, wfName)
+	exec, err := buildExecutor(agent, r.store, r.observer, wfName, req.Tools, systemPrompt)
 	if err != nil {
 		return devuiapi.PlaygroundResponse{}, fmt.Errorf("executor create failed: %w", err)
 	}
 	result, runErr := exec.Run(runCtx, req.Input)
+	if resp, ok := r.interrupted(runErr, provider.Name()); ok {
+		if err := onChunk(fwtypes.StreamChunk{Text: resp.Output, Done: true}); err != nil {
+			return devuiapi.PlaygroundResponse{}, err
+		}
+		resp.AppliedSkills = appliedSkills
+		resp.ReplyTo = req.ReplyTo
+		return resp, nil
+	}
 	if runErr != nil {
 		return devuiapi.PlaygroundResponse{}, runErr
 	}
@@ -840,7 +1122,15 @@ func sortedSkillNames(skills map[string]bool) []string {
 	return out
 }
 
-func buildExecutor(agent *agentfw.Agent, store state.Store, observer observe.Sink, wfName string) (*graph.Executor, error) {
+// buildExecutor builds the executor of a workflow run. The run records its
+// tools, system prompt and workflow from the start so it can be resumed or
+// forked with the same agent.
+func buildExecutor(agent *agentfw.Agent, store state.Store, observer observe.Sink, wfName string, toolNames []string, systemPrompt string) (*graph.Executor, error) {
+	runOptions := map[string]any{
+		"tools":         toolNames,
+		"system_prompt": systemPrompt,
+		"workflow":      wfName,
+	}
 	if alias, ok := workflowAlias(wfName); ok {
 		wfName = alias
 	}
@@ -853,6 +1143,7 @@ func buildExecutor(agent *agentfw.Agent, store state.Store, observer observe.Sin
 		return nil, err
 	}
 	exec.SetObserver(observer)
+	exec.SetRunMetadata(runOptions)
 	return exec, nil
 }
 
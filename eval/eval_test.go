@@ -5,10 +5,12 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	"github.com/PipeOpsHQ/agent-sdk-go/tokenizer"
 	"github.com/PipeOpsHQ/agent-sdk-go/types"
 )
 
@@ -107,6 +109,43 @@ func TestRunnerRun(t *testing.T) {
 	}
 }
 
+func TestRunnerEstimatesMissingUsage(t *testing.T) {
+	t.Parallel()
+
+	start := time.Now().UTC()
+	end := start.Add(2 * time.Second)
+	agent := &fakeAgent{responses: map[string]fakeResult{
+		"aaaaaaaa": {result: types.RunResult{
+			Output: "bbbbbbbbbbbb",
+			Messages: []types.Message{
+				{Role: types.RoleUser, Content: "aaaaaaaa"},
+				{Role: types.RoleAssistant, Content: "bbbbbbbbbbbb"},
+			},
+			StartedAt:   &start,
+			CompletedAt: &end,
+		}},
+	}}
+
+	runner, err := NewRunner(RunnerConfig{Agent: agent, Tokenizer: tokenizer.NewEstimator(4)})
+	if err != nil {
+		t.Fatalf("NewRunner failed: %v", err)
+	}
+	report, err := runner.Run(context.Background(), []Case{{ID: "a", Input: "aaaaaaaa"}}, RunOptions{})
+	if err != nil {
+		t.Fatalf("Run returned error: %v", err)
+	}
+	res := report.Results[0]
+	if !res.UsageEstimated || res.Usage == nil || res.Usage.InputTokens != 2 || res.Usage.OutputTokens != 3 {
+		t.Fatalf("unexpected estimated usage %+v", res.Usage)
+	}
+	if report.EstimatedUsageCases != 1 || report.OutputTokensPerSecond != 1.5 {
+		t.Fatalf("unexpected report usage: %+v", report)
+	}
+	if md := FormatMarkdown(report); !strings.Contains(md, "estimated for 1 cases") || !strings.Contains(md, "1.5` output tokens/s") {
+		t.Fatalf("unexpected markdown:\n%s", md)
+	}
+}
+
 func TestRunnerJudgeCheck(t *testing.T) {
 	t.Parallel()
 
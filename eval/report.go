@@ -13,7 +13,14 @@ func FormatMarkdown(report Report) string {
 	b.WriteString(fmt.Sprintf("- provider: `%s`\n", report.Provider))
 	b.WriteString(fmt.Sprintf("- pass rate: `%.2f%%` (%d/%d)\n", report.PassRate, report.Passed, report.Total))
 	b.WriteString(fmt.Sprintf("- latency: avg `%.2fms`, p50 `%dms`, p95 `%dms`\n", report.AvgLatencyMs, report.LatencyP50Ms, report.LatencyP95Ms))
-	b.WriteString(fmt.Sprintf("- tokens: in `%d`, out `%d`, total `%d`\n", report.TotalInputTokens, report.TotalOutputTokens, report.TotalTokens))
+	b.WriteString(fmt.Sprintf("- tokens: in `%d`, out `%d`, total `%d`", report.TotalInputTokens, report.TotalOutputTokens, report.TotalTokens))
+	if report.EstimatedUsageCases > 0 {
+		b.WriteString(fmt.Sprintf(" (estimated for %d cases)", report.EstimatedUsageCases))
+	}
+	b.WriteString("\n")
+	if report.OutputTokensPerSecond > 0 {
+		b.WriteString(fmt.Sprintf("- throughput: `%.1f` output tokens/s\n", report.OutputTokensPerSecond))
+	}
 	b.WriteString(fmt.Sprintf("- tool constraint accuracy: `%.2f%%` (%d/%d)\n", report.ToolConstraintAccuracy, report.ToolConstraintPassed, report.ToolConstraintCases))
 
 	if len(report.PerTag) > 0 {
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/PipeOpsHQ/agent-sdk-go/tokenizer"
 	"github.com/PipeOpsHQ/agent-sdk-go/types"
 )
 
@@ -18,13 +19,17 @@ type Agent interface {
 }
 
 type Runner struct {
-	agent Agent
-	judge Judge
+	agent     Agent
+	judge     Judge
+	tokenizer tokenizer.Tokenizer
 }
 
 type RunnerConfig struct {
 	Agent Agent
 	Judge Judge
+	// Tokenizer estimates usage for runs whose provider reports none, so
+	// token totals and throughput still cover every case.
+	Tokenizer tokenizer.Tokenizer
 }
 
 type RunOptions struct {
@@ -55,6 +60,8 @@ type Report struct {
 	TotalInputTokens       int                   `json:"totalInputTokens"`
 	TotalOutputTokens      int                   `json:"totalOutputTokens"`
 	TotalTokens            int                   `json:"totalTokens"`
+	EstimatedUsageCases    int                   `json:"estimatedUsageCases,omitempty"`
+	OutputTokensPerSecond  float64               `json:"outputTokensPerSecond,omitempty"`
 	ToolConstraintCases    int                   `json:"toolConstraintCases"`
 	ToolConstraintPassed   int                   `json:"toolConstraintPassed"`
 	ToolConstraintAccuracy float64               `json:"toolConstraintAccuracy"`
@@ -70,26 +77,29 @@ type TagMetrics struct {
 }
 
 type CaseResult struct {
-	CaseID    string         `json:"caseId"`
-	Input     string         `json:"input,omitempty"`
-	Output    string         `json:"output,omitempty"`
-	Tags      []string       `json:"tags,omitempty"`
-	Pass      bool           `json:"pass"`
-	Error     string         `json:"error,omitempty"`
-	LatencyMs int64          `json:"latencyMs"`
-	Usage     *types.Usage   `json:"usage,omitempty"`
-	UsedTools []string       `json:"usedTools,omitempty"`
-	Checks    []CheckResult  `json:"checks"`
-	Metadata  map[string]any `json:"metadata,omitempty"`
-	Attempts  int            `json:"attempts,omitempty"`
-	Judge     *JudgeResult   `json:"judge,omitempty"`
+	CaseID    string       `json:"caseId"`
+	Input     string       `json:"input,omitempty"`
+	Output    string       `json:"output,omitempty"`
+	Tags      []string     `json:"tags,omitempty"`
+	Pass      bool         `json:"pass"`
+	Error     string       `json:"error,omitempty"`
+	LatencyMs int64        `json:"latencyMs"`
+	Usage     *types.Usage `json:"usage,omitempty"`
+	// UsageEstimated is set when Usage was counted by the runner's
+	// tokenizer because the provider reported none.
+	UsageEstimated bool           `json:"usageEstimated,omitempty"`
+	UsedTools      []string       `json:"usedTools,omitempty"`
+	Checks         []CheckResult  `json:"checks"`
+	Metadata       map[string]any `json:"metadata,omitempty"`
+	Attempts       int            `json:"attempts,omitempty"`
+	Judge          *JudgeResult   `json:"judge,omitempty"`
 }
 
 func NewRunner(cfg RunnerConfig) (*Runner, error) {
 	if cfg.Agent == nil {
 		return nil, errors.New("runner agent is required")
 	}
-	return &Runner{agent: cfg.Agent, judge: cfg.Judge}, nil
+	return &Runner{agent: cfg.Agent, judge: cfg.Judge, tokenizer: cfg.Tokenizer}, nil
 }
 
 func (r *Runner) Run(ctx context.Context, cases []Case, opts RunOptions) (Report, error) {
@@ -173,6 +183,7 @@ dispatchLoop:
 	}
 
 	latencies := make([]int64, 0, len(cases))
+	var usageLatencyMs int64
 	for _, res := range results {
 		report.Results = append(report.Results, res)
 		report.Total++
@@ -186,6 +197,10 @@ dispatchLoop:
 			report.TotalInputTokens += res.Usage.InputTokens
 			report.TotalOutputTokens += res.Usage.OutputTokens
 			report.TotalTokens += res.Usage.TotalTokens
+			usageLatencyMs += res.LatencyMs
+			if res.UsageEstimated {
+				report.EstimatedUsageCases++
+			}
 		}
 
 		for _, tag := range res.Tags {
@@ -219,6 +234,9 @@ dispatchLoop:
 	report.AvgLatencyMs = averageInt64(latencies)
 	report.LatencyP50Ms = percentile(latencies, 50)
 	report.LatencyP95Ms = percentile(latencies, 95)
+	if usageLatencyMs > 0 {
+		report.OutputTokensPerSecond = float64(report.TotalOutputTokens) / (float64(usageLatencyMs) / 1000)
+	}
 	report.ToolConstraintAccuracy = ratio(report.ToolConstraintPassed, report.ToolConstraintCases)
 
 	for tag, m := range report.PerTag {
@@ -303,6 +321,10 @@ func (r *Runner) runCaseWithOptions(ctx context.Context, c Case, runOpts RunOpti
 
 	result.Output = runResult.Output
 	result.Usage = runResult.Usage
+	if result.Usage == nil && r.tokenizer != nil {
+		result.Usage = estimateUsage(r.tokenizer, runResult.Messages)
+		result.UsageEstimated = result.Usage != nil
+	}
 	result.UsedTools = extractUsedTools(runResult)
 	result.LatencyMs = latencyFromRun(runResult, caseStarted)
 
@@ -458,6 +480,31 @@ func extractUsedTools(result types.RunResult) []string {
 	return used
 }
 
+// estimateUsage counts the tokens of a run from its transcript. Every model
+// turn is billed for the conversation before it as input and for its own
+// content and tool calls as output. The system prompt and tool definitions
+// are not part of the transcript, so input is a lower bound.
+func estimateUsage(t tokenizer.Tokenizer, messages []types.Message) *types.Usage {
+	usage := types.Usage{}
+	history := 0
+	for _, msg := range messages {
+		tokens := t.Count(msg.Content)
+		for _, tc := range msg.ToolCalls {
+			tokens += t.Count(tc.Name) + t.Count(string(tc.Arguments))
+		}
+		if msg.Role == types.RoleAssistant {
+			usage.InputTokens += history
+			usage.OutputTokens += tokens
+		}
+		history += tokens
+	}
+	if usage.InputTokens == 0 && usage.OutputTokens == 0 {
+		return nil
+	}
+	usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+	return &usage
+}
+
 func latencyFromRun(result types.RunResult, started time.Time) int64 {
 	if result.StartedAt != nil && result.CompletedAt != nil {
 		d := result.CompletedAt.Sub(*result.StartedAt)
@@ -4,20 +4,34 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
+	"github.com/PipeOpsHQ/agent-sdk-go/delivery"
 	"github.com/PipeOpsHQ/agent-sdk-go/observe"
 	"github.com/PipeOpsHQ/agent-sdk-go/state"
 	"github.com/PipeOpsHQ/agent-sdk-go/types"
 	"github.com/google/uuid"
 )
 
+// resumeLockTTL bounds how long a resumed run's lock outlives its process on
+// stores that implement state.RunLocker; the lock is renewed while the run
+// lasts.
+const resumeLockTTL = 30 * time.Minute
+
 type Executor struct {
 	graph     *Graph
 	store     state.Store
 	sessionID string
 	observer  observe.Sink
 	mode      ExecutionMode
+
+	maxConcurrency int
+	// runMetadata is recorded on every run the executor saves.
+	runMetadata map[string]any
+	// onComplete, when set, is called with the final state of a completed
+	// run; subgraph nodes use it to read their child run's data.
+	onComplete func(State)
 }
 
 // Graph returns the underlying graph for introspection.
@@ -63,6 +77,23 @@ func WithExecutionMode(mode ExecutionMode) ExecutorOption {
 	}
 }
 
+// WithMaxConcurrency limits how many fan-out branches or map items run at
+// once. It defaults to DefaultMaxConcurrency.
+func WithMaxConcurrency(n int) ExecutorOption {
+	return func(e *Executor) {
+		if n > 0 {
+			e.maxConcurrency = n
+		}
+	}
+}
+
+// WithRunMetadata records metadata on every run the executor saves, from
+// the start of the run, such as what a caller needs to rebuild the run's
+// agent to resume or fork it.
+func WithRunMetadata(metadata map[string]any) ExecutorOption {
+	return func(e *Executor) { e.runMetadata = metadata }
+}
+
 func NewExecutor(graph *Graph, opts ...ExecutorOption) (*Executor, error) {
 	if graph == nil {
 		return nil, fmt.Errorf("graph is required")
@@ -70,7 +101,7 @@ func NewExecutor(graph *Graph, opts ...ExecutorOption) (*Executor, error) {
 	if err := graph.Compile(); err != nil {
 		return nil, err
 	}
-	executor := &Executor{graph: graph, mode: ExecutionModeLocal}
+	executor := &Executor{graph: graph, mode: ExecutionModeLocal, maxConcurrency: DefaultMaxConcurrency}
 	for _, opt := range opts {
 		opt(executor)
 	}
@@ -84,6 +115,14 @@ func (e *Executor) SetObserver(observer observe.Sink) {
 	e.observer = observer
 }
 
+// SetRunMetadata is WithRunMetadata for an executor that was already built.
+func (e *Executor) SetRunMetadata(metadata map[string]any) {
+	if e == nil {
+		return
+	}
+	e.runMetadata = metadata
+}
+
 func (e *Executor) SetExecutionMode(mode ExecutionMode) {
 	if e == nil || mode == "" {
 		return
@@ -95,18 +134,39 @@ func (e *Executor) Run(ctx context.Context, input string) (types.RunResult, erro
 	if e == nil || e.graph == nil {
 		return types.RunResult{}, fmt.Errorf("executor is not initialized")
 	}
+	return e.start(ctx, "", input, nil)
+}
+
+// start runs the graph from its start node as runID, or a new run ID when
+// empty, with data as the initial Data.
+func (e *Executor) start(ctx context.Context, runID, input string, data map[string]any) (types.RunResult, error) {
 	now := time.Now().UTC()
-	runID := uuid.NewString()
+	if runID == "" {
+		runID = uuid.NewString()
+	}
 	sessionID := e.sessionID
 	if sessionID == "" {
 		sessionID = uuid.NewString()
 	}
 
 	runtimeState := newState(runID, sessionID, input, now)
-	return e.execute(ctx, runtimeState, e.graph.startNodeID, 1)
+	for k, v := range data {
+		runtimeState.Data[k] = v
+	}
+	return e.execute(ctx, runtimeState, e.graph.startNodeID, 1, nil)
 }
 
+// Resume continues a run from its latest checkpoint.
 func (e *Executor) Resume(ctx context.Context, runID string) (types.RunResult, error) {
+	return e.ResumeWithInput(ctx, runID, nil)
+}
+
+// ResumeWithInput continues a run from its latest checkpoint with input
+// merged into State.Data. A run waiting on an interrupt runs the node that
+// interrupted it again, which gets the input from ResumeInput; without
+// input the node interrupts again. A run is resumed by one caller at a
+// time; while it is, ResumeWithInput fails with state.ErrConflict.
+func (e *Executor) ResumeWithInput(ctx context.Context, runID string, input map[string]any) (types.RunResult, error) {
 	if e == nil || e.graph == nil {
 		return types.RunResult{}, fmt.Errorf("executor is not initialized")
 	}
@@ -116,6 +176,14 @@ func (e *Executor) Resume(ctx context.Context, runID string) (types.RunResult, e
 	if e.store == nil {
 		return types.RunResult{}, fmt.Errorf("state store is required for resume")
 	}
+	unlock, ok, err := state.TryLockRun(ctx, e.store, "graph:"+runID, resumeLockTTL)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	if !ok {
+		return types.RunResult{}, fmt.Errorf("run %q is already being resumed: %w", runID, state.ErrConflict)
+	}
+	defer unlock()
 
 	run, err := e.store.LoadRun(ctx, runID)
 	if err != nil {
@@ -140,10 +208,17 @@ func (e *Executor) Resume(ctx context.Context, runID string) (types.RunResult, e
 		return types.RunResult{}, err
 	}
 
-	runtimeState, nextNodeID, err := restoreStateFromCheckpoint(checkpoint.State)
+	snapshot, err := restoreCheckpoint(checkpoint.State)
 	if err != nil {
 		return types.RunResult{}, err
 	}
+	runtimeState, nextNodeID, progress := snapshot.State, snapshot.NextNodeID, snapshot.Branches
+	for k, v := range input {
+		runtimeState.Data[k] = v
+	}
+	if snapshot.Interrupt != nil && input != nil {
+		ctx = withPendingResume(ctx, snapshot.Interrupt.NodeID, input)
+	}
 	if runtimeState.RunID == "" {
 		runtimeState.RunID = run.RunID
 	}
@@ -175,6 +250,9 @@ func (e *Executor) Resume(ctx context.Context, runID string) (types.RunResult, e
 		if err := e.persistRun(ctx, runtimeState, "completed", run.Output, nil, &completedAt); err != nil {
 			return types.RunResult{}, err
 		}
+		if e.onComplete != nil {
+			e.onComplete(runtimeState)
+		}
 		return types.RunResult{
 			Output:      run.Output,
 			Provider:    e.graphProviderName(),
@@ -185,10 +263,71 @@ func (e *Executor) Resume(ctx context.Context, runID string) (types.RunResult, e
 		}, nil
 	}
 
-	return e.execute(ctx, runtimeState, nextNodeID, checkpoint.Seq+1)
+	return e.execute(ctx, runtimeState, nextNodeID, checkpoint.Seq+1, progress)
 }
 
-func (e *Executor) execute(ctx context.Context, runtimeState State, startNodeID string, seq int) (types.RunResult, error) {
+// graphRun holds what an execution collects, shared with the branches of
+// fan-outs and map nodes running concurrently.
+type graphRun struct {
+	mu        sync.Mutex
+	seq       int
+	events    []types.Event
+	nodeTrace []string
+}
+
+func (r *graphRun) nextSeq() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	seq := r.seq
+	r.seq++
+	return seq
+}
+
+func (r *graphRun) traceNode(nodeID string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.nodeTrace = append(r.nodeTrace, nodeID)
+}
+
+func (e *Executor) emit(ctx context.Context, run *graphRun, event types.Event) {
+	run.mu.Lock()
+	run.events = append(run.events, event)
+	run.mu.Unlock()
+	e.emitRuntimeEvent(ctx, event)
+}
+
+func (e *Executor) emitNode(ctx context.Context, run *graphRun, runtimeState *State, eventType types.EventType, nodeID string) {
+	e.emit(ctx, run, types.Event{
+		Type:      eventType,
+		Timestamp: time.Now().UTC(),
+		RunID:     runtimeState.RunID,
+		SessionID: runtimeState.SessionID,
+		Provider:  e.graphProviderName(),
+		ToolName:  nodeID,
+	})
+}
+
+// emitNodeResult reports a node as completed, or as failed with an error
+// its policy handled.
+func (e *Executor) emitNodeResult(ctx context.Context, run *graphRun, runtimeState *State, nodeID string, nodeErr error) {
+	if nodeErr == nil {
+		e.emitNode(ctx, run, runtimeState, types.EventGraphNodeCompleted, nodeID)
+		return
+	}
+	e.emit(ctx, run, types.Event{
+		Type:      types.EventGraphNodeFailed,
+		Timestamp: time.Now().UTC(),
+		RunID:     runtimeState.RunID,
+		SessionID: runtimeState.SessionID,
+		Provider:  e.graphProviderName(),
+		ToolName:  nodeID,
+		Error:     nodeErr.Error(),
+	})
+}
+
+// execute runs the graph from startNodeID. progress, restored from a
+// checkpoint, is a fan-out or map node to finish before continuing.
+func (e *Executor) execute(ctx context.Context, runtimeState State, startNodeID string, seq int, progress *branchProgress) (types.RunResult, error) {
 	if startNodeID == "" {
 		return types.RunResult{}, fmt.Errorf("start node is empty")
 	}
@@ -196,18 +335,15 @@ func (e *Executor) execute(ctx context.Context, runtimeState State, startNodeID
 		return types.RunResult{}, err
 	}
 
-	nodeTrace := []string{}
-	events := []types.Event{
-		{
-			Type:      types.EventRunStarted,
-			Timestamp: time.Now().UTC(),
-			RunID:     runtimeState.RunID,
-			SessionID: runtimeState.SessionID,
-			Provider:  e.graphProviderName(),
-			Message:   "graph run started",
-		},
-	}
-	e.emitRuntimeEvent(ctx, events[0])
+	run := &graphRun{seq: seq, nodeTrace: []string{}}
+	e.emit(ctx, run, types.Event{
+		Type:      types.EventRunStarted,
+		Timestamp: time.Now().UTC(),
+		RunID:     runtimeState.RunID,
+		SessionID: runtimeState.SessionID,
+		Provider:  e.graphProviderName(),
+		Message:   "graph run started",
+	})
 
 	currentNodeID := startNodeID
 	for currentNodeID != "" {
@@ -218,45 +354,84 @@ func (e *Executor) execute(ctx context.Context, runtimeState State, startNodeID
 			return types.RunResult{}, err
 		}
 
-		events = append(events, types.Event{
-			Type:      types.EventGraphNodeStarted,
-			Timestamp: time.Now().UTC(),
-			RunID:     runtimeState.RunID,
-			SessionID: runtimeState.SessionID,
-			Provider:  e.graphProviderName(),
-			ToolName:  currentNodeID,
-		})
-		e.emitRuntimeEvent(ctx, events[len(events)-1])
+		if progress != nil && progress.Node != currentNodeID {
+			fo, ok := e.graph.fanOuts[progress.Node]
+			if !ok || fo.join != currentNodeID {
+				err := fmt.Errorf("checkpoint has branches of unknown fan-out %q", progress.Node)
+				_ = e.persistFailure(ctx, runtimeState, err)
+				return types.RunResult{}, err
+			}
+			if err := e.runFanOut(ctx, run, &runtimeState, fo, progress); err != nil {
+				if interrupt, ok := asInterrupt(err, ""); ok {
+					return e.interrupt(ctx, run, runtimeState, currentNodeID, progress, interrupt)
+				}
+				_ = e.persistFailure(ctx, runtimeState, err)
+				return types.RunResult{}, err
+			}
+			progress = nil
+		}
 
-		if err := node.Execute(ctx, &runtimeState); err != nil {
+		e.emitNode(ctx, run, &runtimeState, types.EventGraphNodeStarted, currentNodeID)
+
+		nodeID := currentNodeID
+		exec := node.Execute
+		if sg, ok := node.(*SubgraphNode); ok {
+			// The child's run ID is checkpointed before it starts, so a
+			// resumed run continues the same child.
+			if progress == nil {
+				progress = &branchProgress{Node: nodeID, RunID: uuid.NewString()}
+				if err := e.persistCheckpoint(ctx, run.nextSeq(), nodeID, checkpointSnapshot{State: runtimeState, NextNodeID: nodeID, Branches: progress}); err != nil {
+					_ = e.persistFailure(ctx, runtimeState, err)
+					return types.RunResult{}, err
+				}
+			}
+			childRunID := progress.RunID
+			exec = func(ctx context.Context, s *State) error {
+				return sg.run(ctx, s, e, childRunID)
+			}
+		}
+		if m, ok := node.(*MapNode); ok {
+			if progress == nil {
+				progress = &branchProgress{Node: nodeID}
+			}
+			exec = func(ctx context.Context, s *State) error {
+				return m.run(ctx, s, e.maxConcurrency, progress, func() error {
+					return e.persistCheckpoint(ctx, run.nextSeq(), nodeID, checkpointSnapshot{State: runtimeState, NextNodeID: nodeID, Branches: progress})
+				})
+			}
+		}
+		nodeErr, err := e.runNode(ctx, run, &runtimeState, nodeID, exec, func(attempt int) error {
+			return e.persistCheckpoint(ctx, run.nextSeq(), nodeID, checkpointSnapshot{State: runtimeState, NextNodeID: nodeID, Branches: progress, Attempt: attempt})
+		})
+		if interrupt, ok := asInterrupt(err, nodeID); ok {
+			return e.interrupt(ctx, run, runtimeState, currentNodeID, progress, interrupt)
+		}
+		progress = nil
+		if err != nil {
 			_ = e.persistFailure(ctx, runtimeState, err)
 			return types.RunResult{}, fmt.Errorf("node %q failed: %w", currentNodeID, err)
 		}
 
 		runtimeState.LastNodeID = currentNodeID
 		runtimeState.UpdatedAt = time.Now().UTC()
-		nodeTrace = append(nodeTrace, currentNodeID)
-
-		nextNodeID, err := e.selectNextNode(ctx, currentNodeID, &runtimeState)
-		if err != nil {
+		run.traceNode(currentNodeID)
+
+		var nextNodeID string
+		if errorEdge := e.graph.policies[currentNodeID].ErrorEdge; nodeErr != nil && errorEdge != "" {
+			nextNodeID = errorEdge
+		} else if fo, ok := e.graph.fanOuts[currentNodeID]; ok {
+			nextNodeID = fo.join
+			progress = &branchProgress{Node: currentNodeID}
+		} else if nextNodeID, err = e.selectNextNode(ctx, currentNodeID, &runtimeState); err != nil {
 			_ = e.persistFailure(ctx, runtimeState, err)
 			return types.RunResult{}, err
 		}
-		if err := e.persistCheckpoint(ctx, runtimeState, seq, currentNodeID, nextNodeID); err != nil {
+		if err := e.persistCheckpoint(ctx, run.nextSeq(), currentNodeID, checkpointSnapshot{State: runtimeState, NextNodeID: nextNodeID, Branches: progress}); err != nil {
 			_ = e.persistFailure(ctx, runtimeState, err)
 			return types.RunResult{}, err
 		}
-		seq++
-
-		events = append(events, types.Event{
-			Type:      types.EventGraphNodeCompleted,
-			Timestamp: time.Now().UTC(),
-			RunID:     runtimeState.RunID,
-			SessionID: runtimeState.SessionID,
-			Provider:  e.graphProviderName(),
-			ToolName:  currentNodeID,
-		})
-		e.emitRuntimeEvent(ctx, events[len(events)-1])
+
+		e.emitNodeResult(ctx, run, &runtimeState, currentNodeID, nodeErr)
 
 		if err := e.persistRun(ctx, runtimeState, "running", "", nil, nil); err != nil {
 			return types.RunResult{}, err
@@ -276,7 +451,10 @@ func (e *Executor) execute(ctx context.Context, runtimeState State, startNodeID
 	if err := e.persistRun(ctx, runtimeState, "completed", output, nil, &completedAt); err != nil {
 		return types.RunResult{}, err
 	}
-	events = append(events, types.Event{
+	if e.onComplete != nil {
+		e.onComplete(runtimeState)
+	}
+	e.emit(ctx, run, types.Event{
 		Type:      types.EventRunCompleted,
 		Timestamp: completedAt,
 		RunID:     runtimeState.RunID,
@@ -284,22 +462,84 @@ func (e *Executor) execute(ctx context.Context, runtimeState State, startNodeID
 		Provider:  e.graphProviderName(),
 		Message:   "graph run completed",
 	})
-	e.emitRuntimeEvent(ctx, events[len(events)-1])
 
 	startedAt := runtimeState.StartedAt
 	return types.RunResult{
 		Output:      output,
-		Iterations:  len(nodeTrace),
+		Iterations:  len(run.nodeTrace),
 		Provider:    e.graphProviderName(),
 		RunID:       runtimeState.RunID,
 		SessionID:   runtimeState.SessionID,
 		StartedAt:   &startedAt,
 		CompletedAt: &completedAt,
-		Events:      events,
-		NodeTrace:   nodeTrace,
+		Events:      run.events,
+		NodeTrace:   run.nodeTrace,
 	}, nil
 }
 
+// runFanOut runs the branches of fo not yet in progress on copies of the
+// state, checkpointing each one as it finishes, then merges them into the
+// state for the join.
+func (e *Executor) runFanOut(ctx context.Context, run *graphRun, runtimeState *State, fo fanOut, progress *branchProgress) error {
+	if progress.States == nil {
+		progress.States = map[string]State{}
+	}
+	branches := make([]State, len(fo.branches))
+	finished := make([]bool, len(fo.branches))
+	for i, id := range fo.branches {
+		branches[i], finished[i] = progress.States[id]
+	}
+	err := runBranches(ctx, len(fo.branches), e.maxConcurrency,
+		func(i int) bool { return finished[i] },
+		func(ctx context.Context, i int) error {
+			branches[i] = runtimeState.branch()
+			return e.runBranch(ctx, run, &branches[i], fo.branches[i], fo.join)
+		},
+		func(i int) error {
+			progress.States[fo.branches[i]] = branches[i]
+			return e.persistCheckpoint(ctx, run.nextSeq(), fo.branches[i], checkpointSnapshot{State: *runtimeState, NextNodeID: fo.join, Branches: progress})
+		})
+	if err != nil {
+		return err
+	}
+	var reducers map[string]Reducer
+	if join, ok := e.graph.nodes[fo.join].(*JoinNode); ok {
+		reducers = join.Reducers
+	}
+	if err := mergeBranches(runtimeState, branches, reducers); err != nil {
+		return fmt.Errorf("join %q: %w", fo.join, err)
+	}
+	return nil
+}
+
+// runBranch follows edges from start until join or the end of the branch.
+func (e *Executor) runBranch(ctx context.Context, run *graphRun, branch *State, start, join string) error {
+	for nodeID := start; nodeID != "" && nodeID != join; {
+		e.emitNode(ctx, run, branch, types.EventGraphNodeStarted, nodeID)
+		exec := e.graph.nodes[nodeID].Execute
+		if sg, ok := e.graph.nodes[nodeID].(*SubgraphNode); ok {
+			exec = func(ctx context.Context, s *State) error { return sg.run(ctx, s, e, "") }
+		}
+		nodeErr, err := e.runNode(ctx, run, branch, nodeID, exec, nil)
+		if err != nil {
+			return fmt.Errorf("node %q failed: %w", nodeID, err)
+		}
+		branch.LastNodeID = nodeID
+		branch.UpdatedAt = time.Now().UTC()
+		run.traceNode(nodeID)
+
+		next := e.graph.policies[nodeID].ErrorEdge
+		if nodeErr == nil || next == "" {
+			if next, err = e.selectNextNode(ctx, nodeID, branch); err != nil {
+				return err
+			}
+		}
+		e.emitNodeResult(ctx, run, branch, nodeID, nodeErr)
+		nodeID = next
+	}
+	return nil
+}
+
 func (e *Executor) selectNextNode(ctx context.Context, from string, runtimeState *State) (string, error) {
 	edges := e.graph.edges[from]
 	for _, edge := range edges {
@@ -317,11 +557,12 @@ func (e *Executor) selectNextNode(ctx context.Context, from string, runtimeState
 	return "", nil
 }
 
-func (e *Executor) persistCheckpoint(ctx context.Context, runtimeState State, seq int, nodeID string, nextNodeID string) error {
+func (e *Executor) persistCheckpoint(ctx context.Context, seq int, nodeID string, checkpoint checkpointSnapshot) error {
 	if e.store == nil {
 		return nil
 	}
-	snapshot, err := runtimeState.snapshot(nextNodeID)
+	runtimeState, nextNodeID := checkpoint.State, checkpoint.NextNodeID
+	snapshot, err := checkpoint.toMap()
 	if err != nil {
 		return err
 	}
@@ -336,21 +577,56 @@ func (e *Executor) persistCheckpoint(ctx context.Context, runtimeState State, se
 		return err
 	}
 	if err == nil {
+		attributes := map[string]any{
+			"seq":        seq,
+			"nextNodeId": nextNodeID,
+		}
+		if checkpoint.Attempt > 0 {
+			attributes["attempt"] = checkpoint.Attempt
+		}
 		_ = e.emitObserverEvent(ctx, observe.Event{
-			RunID:     runtimeState.RunID,
-			SessionID: runtimeState.SessionID,
-			Kind:      observe.KindCheckpoint,
-			Status:    observe.StatusCompleted,
-			Name:      nodeID,
-			Attributes: map[string]any{
-				"seq":        seq,
-				"nextNodeId": nextNodeID,
-			},
+			RunID:      runtimeState.RunID,
+			SessionID:  runtimeState.SessionID,
+			Kind:       observe.KindCheckpoint,
+			Status:     observe.StatusCompleted,
+			Name:       nodeID,
+			Attributes: attributes,
 		})
 	}
 	return nil
 }
 
+// interrupt pauses the run at nodeID for the input interrupt asks for. The
+// checkpoint taken resumes at nodeID, with any branches already finished.
+func (e *Executor) interrupt(ctx context.Context, run *graphRun, runtimeState State, nodeID string, progress *branchProgress, interrupt *NodeInterrupt) (types.RunResult, error) {
+	if err := e.persistCheckpoint(ctx, run.nextSeq(), interrupt.NodeID, checkpointSnapshot{
+		State:      runtimeState,
+		NextNodeID: nodeID,
+		Branches:   progress,
+		Interrupt:  interrupt,
+	}); err != nil {
+		_ = e.persistFailure(ctx, runtimeState, err)
+		return types.RunResult{}, err
+	}
+	e.emit(ctx, run, types.Event{
+		Type:      types.EventRunInterrupted,
+		Timestamp: time.Now().UTC(),
+		RunID:     runtimeState.RunID,
+		SessionID: runtimeState.SessionID,
+		Provider:  e.graphProviderName(),
+		ToolName:  interrupt.NodeID,
+		Message:   interrupt.Prompt,
+	})
+	if err := e.saveRun(ctx, runtimeState, RunStatusInterrupted, "", nil, nil, map[string]any{"interrupt": *interrupt}); err != nil {
+		return types.RunResult{}, err
+	}
+	return types.RunResult{}, &InterruptedError{
+		RunID:     runtimeState.RunID,
+		SessionID: runtimeState.SessionID,
+		Interrupt: *interrupt,
+	}
+}
+
 func (e *Executor) persistFailure(ctx context.Context, runtimeState State, runErr error) error {
 	completedAt := time.Now().UTC()
 	errText := ""
@@ -376,15 +652,48 @@ func (e *Executor) persistRun(
 	output string,
 	errText *string,
 	completedAt *time.Time,
+) error {
+	return e.saveRun(ctx, runtimeState, status, output, errText, completedAt, nil)
+}
+
+func (e *Executor) saveRun(
+	ctx context.Context,
+	runtimeState State,
+	status string,
+	output string,
+	errText *string,
+	completedAt *time.Time,
+	extra map[string]any,
 ) error {
 	if e.store == nil {
 		return nil
 	}
 
 	now := time.Now().UTC()
-	metadata := map[string]any{
-		"graph":      e.graph.Name(),
-		"lastNodeId": runtimeState.LastNodeID,
+	// Keep what others stored on the run, such as a caller's resume
+	// options or the parent run of a run resumed without its context.
+	metadata := map[string]any{}
+	stored, err := e.store.LoadRun(ctx, runtimeState.RunID)
+	if err != nil && !errors.Is(err, state.ErrNotFound) {
+		return fmt.Errorf("failed to load run metadata: %w", err)
+	}
+	for k, v := range stored.Metadata {
+		metadata[k] = v
+	}
+	delete(metadata, "interrupt")
+	for k, v := range e.runMetadata {
+		metadata[k] = v
+	}
+	metadata["graph"] = e.graph.Name()
+	metadata["lastNodeId"] = runtimeState.LastNodeID
+	if runtimeState.ForkedFrom != nil {
+		metadata["forkedFrom"] = *runtimeState.ForkedFrom
+	}
+	for k, v := range extra {
+		metadata[k] = v
+	}
+	if parentRunID := delivery.ParentRunIDFromContext(ctx); parentRunID != "" {
+		metadata["parent_run_id"] = parentRunID
 	}
 	errValue := ""
 	if errText != nil {
@@ -428,7 +737,7 @@ func (e *Executor) emitRuntimeEvent(ctx context.Context, event types.Event) {
 	if e == nil || e.observer == nil {
 		return
 	}
-	_ = e.observer.Emit(ctx, observe.FromRuntimeEvent(event))
+	_ = e.observer.Emit(ctx, observe.FromRuntimeEventContext(ctx, event))
 }
 
 func (e *Executor) emitObserverEvent(ctx context.Context, event observe.Event) error {
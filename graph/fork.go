@@ -0,0 +1,294 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+	"github.com/google/uuid"
+)
+
+// ForkOrigin is the checkpoint a forked run started from. It is recorded
+// in the forked run's metadata under "forkedFrom".
+type ForkOrigin struct {
+	RunID string `json:"runId"`
+	Seq   int    `json:"seq"`
+}
+
+type forkConfig struct {
+	data     map[string]any
+	nextNode string
+}
+
+// ForkOption configures a run started with Fork.
+type ForkOption func(*forkConfig)
+
+// WithForkData merges data into the forked run's State.Data. A nil value
+// removes the key.
+func WithForkData(data map[string]any) ForkOption {
+	return func(c *forkConfig) {
+		if c.data == nil {
+			c.data = map[string]any{}
+		}
+		for k, v := range data {
+			c.data[k] = v
+		}
+	}
+}
+
+// WithForkNextNode continues the forked run at nodeID instead of the node
+// the checkpoint was taken before, such as the branch a router did not
+// take.
+func WithForkNextNode(nodeID string) ForkOption {
+	return func(c *forkConfig) { c.nextNode = strings.TrimSpace(nodeID) }
+}
+
+// Fork starts a new run from checkpoint seq of runID, leaving the original
+// run untouched. The new run continues where the checkpoint would have
+// resumed, after applying any options, and records its origin in its
+// metadata. Its first checkpoint, seq 1, holds the forked state.
+func (e *Executor) Fork(ctx context.Context, runID string, seq int, opts ...ForkOption) (types.RunResult, error) {
+	if e == nil || e.graph == nil {
+		return types.RunResult{}, fmt.Errorf("executor is not initialized")
+	}
+	if runID == "" {
+		return types.RunResult{}, fmt.Errorf("runID is required")
+	}
+	if e.store == nil {
+		return types.RunResult{}, fmt.Errorf("state store is required for fork")
+	}
+	cfg := forkConfig{}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&cfg)
+		}
+	}
+
+	parent, err := e.store.LoadRun(ctx, runID)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+	if name, ok := parent.Metadata["graph"].(string); ok && name != "" && name != e.graph.Name() {
+		return types.RunResult{}, fmt.Errorf("run %q belongs to graph %q, not %q", runID, name, e.graph.Name())
+	}
+	checkpoint, err := state.LoadCheckpoint(ctx, e.store, runID, seq)
+	if err != nil {
+		return types.RunResult{}, fmt.Errorf("failed to load checkpoint %d of run %q: %w", seq, runID, err)
+	}
+	snapshot, err := restoreCheckpoint(checkpoint.State)
+	if err != nil {
+		return types.RunResult{}, err
+	}
+
+	now := time.Now().UTC()
+	runtimeState := snapshot.State
+	runtimeState.RunID = uuid.NewString()
+	if e.sessionID != "" {
+		runtimeState.SessionID = e.sessionID
+	}
+	if runtimeState.SessionID == "" {
+		runtimeState.SessionID = parent.SessionID
+	}
+	if runtimeState.Input == "" {
+		runtimeState.Input = parent.Input
+	}
+	runtimeState.StartedAt = now
+	runtimeState.UpdatedAt = now
+	runtimeState.ForkedFrom = &ForkOrigin{RunID: runID, Seq: seq}
+	for k, v := range cfg.data {
+		if v == nil {
+			delete(runtimeState.Data, k)
+			continue
+		}
+		runtimeState.Data[k] = v
+	}
+
+	nextNodeID, progress := snapshot.NextNodeID, snapshot.Branches
+	if cfg.nextNode != "" && cfg.nextNode != nextNodeID {
+		if _, ok := e.graph.nodes[cfg.nextNode]; !ok {
+			return types.RunResult{}, fmt.Errorf("node %q does not exist", cfg.nextNode)
+		}
+		nextNodeID, progress = cfg.nextNode, nil
+	}
+	if progress != nil && progress.RunID != "" {
+		// The child run belongs to the original run; the fork starts the
+		// subgraph node over with a child of its own.
+		progress = nil
+	}
+	if nextNodeID == "" {
+		nextNodeID, err = e.selectNextNode(ctx, runtimeState.LastNodeID, &runtimeState)
+		if err != nil {
+			return types.RunResult{}, err
+		}
+	}
+
+	if err := e.persistRun(ctx, runtimeState, "running", "", nil, nil); err != nil {
+		return types.RunResult{}, err
+	}
+	if err := e.persistCheckpoint(ctx, 1, checkpoint.NodeID, checkpointSnapshot{State: runtimeState, NextNodeID: nextNodeID, Branches: progress}); err != nil {
+		_ = e.persistFailure(ctx, runtimeState, err)
+		return types.RunResult{}, err
+	}
+	if nextNodeID == "" {
+		completedAt := time.Now().UTC()
+		if err := e.persistRun(ctx, runtimeState, "completed", runtimeState.Output, nil, &completedAt); err != nil {
+			return types.RunResult{}, err
+		}
+		return types.RunResult{
+			Output:      runtimeState.Output,
+			Provider:    e.graphProviderName(),
+			RunID:       runtimeState.RunID,
+			SessionID:   runtimeState.SessionID,
+			StartedAt:   &runtimeState.StartedAt,
+			CompletedAt: &completedAt,
+		}, nil
+	}
+	return e.execute(ctx, runtimeState, nextNodeID, 2, progress)
+}
+
+// ForkedFrom returns the checkpoint a forked run started from.
+func ForkedFrom(run state.RunRecord) (ForkOrigin, bool) {
+	if run.Metadata == nil {
+		return ForkOrigin{}, false
+	}
+	raw, err := json.Marshal(run.Metadata["forkedFrom"])
+	if err != nil {
+		return ForkOrigin{}, false
+	}
+	var origin ForkOrigin
+	if err := json.Unmarshal(raw, &origin); err != nil || origin.RunID == "" {
+		return ForkOrigin{}, false
+	}
+	return origin, true
+}
+
+// CheckpointRef identifies one side of a CheckpointDiff.
+type CheckpointRef struct {
+	RunID      string `json:"runId"`
+	Seq        int    `json:"seq"`
+	NodeID     string `json:"nodeId"`
+	NextNodeID string `json:"nextNodeId,omitempty"`
+}
+
+// StateChange is a value that differs between two checkpoints. Path is the
+// dotted path of the value in the checkpoint, such as "state.data.route".
+type StateChange struct {
+	Path   string `json:"path"`
+	Op     string `json:"op"`
+	Before any    `json:"before,omitempty"`
+	After  any    `json:"after,omitempty"`
+}
+
+// Change operations reported in StateChange.Op.
+const (
+	ChangeAdded   = "added"
+	ChangeRemoved = "removed"
+	ChangeChanged = "changed"
+)
+
+// CheckpointDiff lists what changed from one checkpoint to another, which
+// may belong to different runs, such as a run and its fork.
+type CheckpointDiff struct {
+	From    CheckpointRef `json:"from"`
+	To      CheckpointRef `json:"to"`
+	Changes []StateChange `json:"changes"`
+}
+
+// diffIgnoredPaths differ between any two checkpoints and say nothing about
+// how the run went.
+var diffIgnoredPaths = map[string]bool{
+	"state.runId":      true,
+	"state.startedAt":  true,
+	"state.updatedAt":  true,
+	"state.forkedFrom": true,
+}
+
+// DiffCheckpoints compares the state recorded in two checkpoints. Maps are
+// compared key by key; any other values, including lists, are compared as
+// a whole.
+func DiffCheckpoints(from, to state.CheckpointRecord) (CheckpointDiff, error) {
+	before, err := normalizeCheckpoint(from.State)
+	if err != nil {
+		return CheckpointDiff{}, err
+	}
+	after, err := normalizeCheckpoint(to.State)
+	if err != nil {
+		return CheckpointDiff{}, err
+	}
+	diff := CheckpointDiff{
+		From:    checkpointRef(from, before),
+		To:      checkpointRef(to, after),
+		Changes: []StateChange{},
+	}
+	diffValues(&diff.Changes, "", before, after)
+	return diff, nil
+}
+
+func checkpointRef(record state.CheckpointRecord, snapshot map[string]any) CheckpointRef {
+	next, _ := snapshot["nextNodeId"].(string)
+	return CheckpointRef{RunID: record.RunID, Seq: record.Seq, NodeID: record.NodeID, NextNodeID: next}
+}
+
+// normalizeCheckpoint round-trips a checkpoint through JSON so both sides
+// of a diff hold the same types.
+func normalizeCheckpoint(raw map[string]any) (map[string]any, error) {
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal checkpoint state: %w", err)
+	}
+	out := map[string]any{}
+	if err := json.Unmarshal(encoded, &out); err != nil {
+		return nil, fmt.Errorf("failed to decode checkpoint state: %w", err)
+	}
+	return out, nil
+}
+
+func diffValues(changes *[]StateChange, path string, before, after any) {
+	if diffIgnoredPaths[path] {
+		return
+	}
+	beforeMap, beforeIsMap := before.(map[string]any)
+	afterMap, afterIsMap := after.(map[string]any)
+	if !beforeIsMap || !afterIsMap {
+		if !reflect.DeepEqual(before, after) {
+			*changes = append(*changes, StateChange{Path: path, Op: ChangeChanged, Before: before, After: after})
+		}
+		return
+	}
+
+	keys := make([]string, 0, len(beforeMap)+len(afterMap))
+	for k := range beforeMap {
+		keys = append(keys, k)
+	}
+	for k := range afterMap {
+		if _, ok := beforeMap[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		childPath := k
+		if path != "" {
+			childPath = path + "." + k
+		}
+		if diffIgnoredPaths[childPath] {
+			continue
+		}
+		beforeValue, inBefore := beforeMap[k]
+		afterValue, inAfter := afterMap[k]
+		switch {
+		case !inBefore:
+			*changes = append(*changes, StateChange{Path: childPath, Op: ChangeAdded, After: afterValue})
+		case !inAfter:
+			*changes = append(*changes, StateChange{Path: childPath, Op: ChangeRemoved, Before: beforeValue})
+		default:
+			diffValues(changes, childPath, beforeValue, afterValue)
+		}
+	}
+}
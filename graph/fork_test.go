@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+func newTriageGraph() *Graph {
+	routeIs := func(route string) Condition {
+		return func(ctx context.Context, s *State) (bool, error) {
+			return s.Data["route"] == route, nil
+		}
+	}
+	return New("triage").
+		AddNode("load", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["ticket"] = s.Input
+			return nil
+		})).
+		AddNode("classify", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["route"] = "support"
+			if s.Data["priority"] == "high" {
+				s.Data["route"] = "oncall"
+			}
+			return nil
+		})).
+		AddNode("support", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = "support: " + s.Input
+			return nil
+		})).
+		AddNode("oncall", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = "oncall: " + s.Input
+			return nil
+		})).
+		SetStart("load").
+		AddEdge("load", "classify", nil).
+		AddEdge("classify", "support", routeIs("support")).
+		AddEdge("classify", "oncall", routeIs("oncall"))
+}
+
+func TestExecutor_ForkWithData(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	executor, err := NewExecutor(newTriageGraph(), WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	parent, err := executor.Run(ctx, "disk full")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if parent.Output != "support: disk full" {
+		t.Fatalf("unexpected output: %q", parent.Output)
+	}
+
+	// Checkpoint 1 was taken after load, before classify ran.
+	fork, err := executor.Fork(ctx, parent.RunID, 1, WithForkData(map[string]any{"priority": "high"}))
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	if fork.RunID == parent.RunID || fork.Output != "oncall: disk full" {
+		t.Fatalf("unexpected fork result: %+v", fork)
+	}
+	if strings.Join(fork.NodeTrace, ",") != "classify,oncall" {
+		t.Fatalf("unexpected fork node trace: %v", fork.NodeTrace)
+	}
+
+	run, _ := store.LoadRun(ctx, fork.RunID)
+	origin, ok := ForkedFrom(run)
+	if !ok || origin.RunID != parent.RunID || origin.Seq != 1 || run.Status != "completed" {
+		t.Fatalf("expected the fork's lineage to be recorded, got %+v (%s)", run.Metadata, run.Status)
+	}
+	if original, _ := store.LoadRun(ctx, parent.RunID); original.Output != parent.Output {
+		t.Fatalf("expected the parent run to be untouched, got %q", original.Output)
+	}
+
+	from, _ := state.LoadCheckpoint(ctx, store, parent.RunID, 1)
+	to, err := state.LoadCheckpoint(ctx, store, fork.RunID, 1)
+	if err != nil {
+		t.Fatalf("expected the fork's first checkpoint to hold the forked state: %v", err)
+	}
+	diff, err := DiffCheckpoints(from, to)
+	if err != nil {
+		t.Fatalf("diff failed: %v", err)
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Path != "state.data.priority" || diff.Changes[0].Op != ChangeAdded {
+		t.Fatalf("unexpected diff: %+v", diff.Changes)
+	}
+
+	from, _ = state.LoadCheckpoint(ctx, store, parent.RunID, 2)
+	to, _ = state.LoadCheckpoint(ctx, store, fork.RunID, 2)
+	diff, _ = DiffCheckpoints(from, to)
+	var route *StateChange
+	for i := range diff.Changes {
+		if diff.Changes[i].Path == "state.data.route" {
+			route = &diff.Changes[i]
+		}
+	}
+	if route == nil || route.Before != "support" || route.After != "oncall" || diff.To.NextNodeID != "oncall" {
+		t.Fatalf("expected the route to differ after classify, got %+v", diff)
+	}
+}
+
+func TestExecutor_ForkNextNode(t *testing.T) {
+	ctx := context.Background()
+	store := newMemoryStore()
+	executor, err := NewExecutor(newTriageGraph(), WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	parent, err := executor.Run(ctx, "disk full")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+
+	fork, err := executor.Fork(ctx, parent.RunID, 2, WithForkNextNode("oncall"))
+	if err != nil {
+		t.Fatalf("fork failed: %v", err)
+	}
+	if fork.Output != "oncall: disk full" {
+		t.Fatalf("expected the fork to take the other route, got %q", fork.Output)
+	}
+
+	if _, err := executor.Fork(ctx, parent.RunID, 2, WithForkNextNode("missing")); err == nil {
+		t.Fatalf("expected an error for an unknown node")
+	}
+	if _, err := executor.Fork(ctx, parent.RunID, 99); err == nil {
+		t.Fatalf("expected an error for a missing checkpoint")
+	}
+
+	other, err := NewExecutor(New("other").AddNode("a", NewToolNode(func(ctx context.Context, s *State) error { return nil })).SetStart("a"), WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	if _, err := other.Fork(ctx, parent.RunID, 2); err == nil {
+		t.Fatalf("expected an error forking another graph's run")
+	}
+}
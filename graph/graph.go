@@ -18,6 +18,8 @@ type Graph struct {
 	name        string
 	nodes       map[string]Node
 	edges       map[string][]Edge
+	fanOuts     map[string]fanOut
+	policies    map[string]NodePolicy
 	startNodeID string
 	allowCycles bool
 	buildErr    error
@@ -25,9 +27,11 @@ type Graph struct {
 
 func New(name string) *Graph {
 	return &Graph{
-		name:  name,
-		nodes: map[string]Node{},
-		edges: map[string][]Edge{},
+		name:     name,
+		nodes:    map[string]Node{},
+		edges:    map[string][]Edge{},
+		fanOuts:  map[string]fanOut{},
+		policies: map[string]NodePolicy{},
 	}
 }
 
@@ -38,7 +42,9 @@ func (g *Graph) Name() string {
 	return g.name
 }
 
-func (g *Graph) AddNode(id string, node Node) *Graph {
+// AddNode adds a node. Options set how its failures are handled; see
+// NodePolicy.
+func (g *Graph) AddNode(id string, node Node, opts ...NodeOption) *Graph {
 	if g == nil {
 		return g
 	}
@@ -58,6 +64,13 @@ func (g *Graph) AddNode(id string, node Node) *Graph {
 		return g
 	}
 	g.nodes[id] = node
+	var policy NodePolicy
+	for _, opt := range opts {
+		opt(&policy)
+	}
+	if !policy.isZero() {
+		g.policies[id] = policy
+	}
 	return g
 }
 
@@ -104,6 +117,12 @@ func (g *Graph) AllowCycles(allow bool) *Graph {
 }
 
 func (g *Graph) Compile() error {
+	return g.compile(map[*Graph]bool{})
+}
+
+// compile validates the graph and the graphs of its subgraph nodes;
+// visiting holds the graphs being compiled, which no subgraph may embed.
+func (g *Graph) compile(visiting map[*Graph]bool) error {
 	if g == nil {
 		return fmt.Errorf("graph is nil")
 	}
@@ -134,6 +153,37 @@ func (g *Graph) Compile() error {
 		}
 	}
 
+	for id, policy := range g.policies {
+		if policy.ErrorEdge == "" {
+			continue
+		}
+		if _, ok := g.nodes[policy.ErrorEdge]; !ok {
+			return fmt.Errorf("error edge target node %q of %q does not exist", policy.ErrorEdge, id)
+		}
+	}
+
+	if err := g.compileFanOuts(); err != nil {
+		return err
+	}
+
+	visiting[g] = true
+	defer delete(visiting, g)
+	for id, node := range g.nodes {
+		sg, ok := node.(*SubgraphNode)
+		if !ok {
+			continue
+		}
+		if sg.Graph == nil {
+			return fmt.Errorf("subgraph node %q has no graph", id)
+		}
+		if visiting[sg.Graph] {
+			return fmt.Errorf("subgraph node %q embeds graph %q within itself", id, sg.Graph.Name())
+		}
+		if err := sg.Graph.compile(visiting); err != nil {
+			return fmt.Errorf("subgraph node %q: %w", id, err)
+		}
+	}
+
 	unreachable := g.unreachableNodes()
 	if len(unreachable) > 0 {
 		sort.Strings(unreachable)
@@ -155,8 +205,8 @@ func (g *Graph) unreachableNodes() []string {
 			return
 		}
 		visited[nodeID] = true
-		for _, edge := range g.edges[nodeID] {
-			dfs(edge.To)
+		for _, next := range g.successors(nodeID) {
+			dfs(next)
 		}
 	}
 	dfs(g.startNodeID)
@@ -181,12 +231,12 @@ func (g *Graph) hasCycle() bool {
 	var visit func(nodeID string) bool
 	visit = func(nodeID string) bool {
 		color[nodeID] = gray
-		for _, edge := range g.edges[nodeID] {
-			switch color[edge.To] {
+		for _, next := range g.successors(nodeID) {
+			switch color[next] {
 			case gray:
 				return true
 			case white:
-				if visit(edge.To) {
+				if visit(next) {
 					return true
 				}
 			}
@@ -203,12 +253,31 @@ func (g *Graph) hasCycle() bool {
 	return false
 }
 
+// successors returns the nodes a node can continue at, including its error
+// edge and the branches and join of its fan-out.
+func (g *Graph) successors(nodeID string) []string {
+	out := make([]string, 0, len(g.edges[nodeID]))
+	for _, edge := range g.edges[nodeID] {
+		out = append(out, edge.To)
+	}
+	if to := g.policies[nodeID].ErrorEdge; to != "" {
+		out = append(out, to)
+	}
+	if fo, ok := g.fanOuts[nodeID]; ok {
+		out = append(out, fo.branches...)
+		out = append(out, fo.join)
+	}
+	return out
+}
+
 func Always(_ context.Context, _ *State) (bool, error) { return true, nil }
 
 // NodeInfo describes a node in the graph for introspection.
 type NodeInfo struct {
 	ID   string `json:"id"`
-	Kind string `json:"kind"` // "agent", "tool", or "router"
+	Kind string `json:"kind"` // "agent", "tool", "router", "join", "map", "interrupt", or "subgraph"
+	// Graph is the name of a subgraph node's graph.
+	Graph string `json:"graph,omitempty"`
 }
 
 // EdgeInfo describes an edge in the graph for introspection.
@@ -216,6 +285,10 @@ type EdgeInfo struct {
 	From        string `json:"from"`
 	To          string `json:"to"`
 	Conditional bool   `json:"conditional"`
+	// Parallel marks an edge from a fan-out to one of its branches.
+	Parallel bool `json:"parallel,omitempty"`
+	// OnError marks an edge taken when its source node fails.
+	OnError bool `json:"onError,omitempty"`
 }
 
 // NodeInfos returns metadata about all nodes in the graph.
@@ -225,14 +298,22 @@ func (g *Graph) NodeInfos() []NodeInfo {
 	}
 	out := make([]NodeInfo, 0, len(g.nodes))
 	for id, node := range g.nodes {
-		kind := "tool"
-		switch node.(type) {
+		info := NodeInfo{ID: id, Kind: "tool"}
+		switch n := node.(type) {
 		case *AgentNode:
-			kind = "agent"
+			info.Kind = "agent"
 		case *RouterNode:
-			kind = "router"
+			info.Kind = "router"
+		case *JoinNode:
+			info.Kind = "join"
+		case *MapNode:
+			info.Kind = "map"
+		case *InterruptNode:
+			info.Kind = "interrupt"
+		case *SubgraphNode:
+			info.Kind, info.Graph = "subgraph", n.Graph.Name()
 		}
-		out = append(out, NodeInfo{ID: id, Kind: kind})
+		out = append(out, info)
 	}
 	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
 	return out
@@ -244,10 +325,17 @@ func (g *Graph) EdgeInfos() []EdgeInfo {
 		return nil
 	}
 	out := make([]EdgeInfo, 0)
-	keys := make([]string, 0, len(g.edges))
+	keys := make([]string, 0, len(g.edges)+len(g.fanOuts))
 	for k := range g.edges {
 		keys = append(keys, k)
 	}
+	for k := range g.nodes {
+		_, hasEdges := g.edges[k]
+		_, hasFanOut := g.fanOuts[k]
+		if !hasEdges && (hasFanOut || g.policies[k].ErrorEdge != "") {
+			keys = append(keys, k)
+		}
+	}
 	sort.Strings(keys)
 	for _, from := range keys {
 		for _, edge := range g.edges[from] {
@@ -257,6 +345,14 @@ func (g *Graph) EdgeInfos() []EdgeInfo {
 				Conditional: edge.Condition != nil,
 			})
 		}
+		if fo, ok := g.fanOuts[from]; ok {
+			for _, branch := range fo.branches {
+				out = append(out, EdgeInfo{From: from, To: branch, Parallel: true})
+			}
+		}
+		if to := g.policies[from].ErrorEdge; to != "" {
+			out = append(out, EdgeInfo{From: from, To: to, OnError: true})
+		}
 	}
 	return out
 }
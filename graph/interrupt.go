@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+// RunStatusInterrupted is the status of a run paused by an interrupt until
+// it is resumed with input.
+const RunStatusInterrupted = "interrupted"
+
+// ErrInterrupted matches the error returned when a run is interrupted.
+var ErrInterrupted = errors.New("graph run interrupted")
+
+// NodeInterrupt, returned by any node, pauses the run for human input. The
+// run is checkpointed and stored as interrupted until ResumeWithInput is
+// called, which runs the node again with the input available through
+// ResumeInput.
+type NodeInterrupt struct {
+	// Prompt describes the input needed to continue.
+	Prompt string `json:"prompt"`
+	// NodeID is the node that interrupted; the executor sets it.
+	NodeID string `json:"nodeId,omitempty"`
+}
+
+// Interrupt returns a NodeInterrupt asking for input with prompt.
+func Interrupt(prompt string) error {
+	return &NodeInterrupt{Prompt: prompt}
+}
+
+func (e *NodeInterrupt) Error() string {
+	return fmt.Sprintf("node %q interrupted: %s", e.NodeID, e.Prompt)
+}
+
+func (e *NodeInterrupt) Is(target error) bool { return target == ErrInterrupted }
+
+// InterruptedError is returned by Run and Resume when a node interrupts the
+// run.
+type InterruptedError struct {
+	RunID     string
+	SessionID string
+	Interrupt NodeInterrupt
+}
+
+func (e *InterruptedError) Error() string {
+	return fmt.Sprintf("run %s is waiting for input at %q: %s", e.RunID, e.Interrupt.NodeID, e.Interrupt.Prompt)
+}
+
+func (e *InterruptedError) Is(target error) bool { return target == ErrInterrupted }
+
+// InterruptNode pauses the run with Prompt the first time it runs, and lets
+// it continue once it is resumed with input.
+type InterruptNode struct {
+	Prompt string
+	// PromptFunc, when set, builds the prompt from the state instead.
+	PromptFunc func(state *State) string
+}
+
+func NewInterruptNode(prompt string) *InterruptNode {
+	return &InterruptNode{Prompt: prompt}
+}
+
+func (n *InterruptNode) Execute(ctx context.Context, state *State) error {
+	if n == nil {
+		return fmt.Errorf("interrupt node is nil")
+	}
+	if _, ok := ResumeInput(ctx); ok {
+		return nil
+	}
+	if n.PromptFunc != nil {
+		return Interrupt(n.PromptFunc(state))
+	}
+	return Interrupt(n.Prompt)
+}
+
+// PendingInterrupt returns the interrupt an interrupted run is waiting on.
+func PendingInterrupt(run state.RunRecord) (NodeInterrupt, bool) {
+	if run.Status != RunStatusInterrupted || run.Metadata == nil {
+		return NodeInterrupt{}, false
+	}
+	raw, err := json.Marshal(run.Metadata["interrupt"])
+	if err != nil {
+		return NodeInterrupt{}, false
+	}
+	var interrupt NodeInterrupt
+	if err := json.Unmarshal(raw, &interrupt); err != nil || interrupt.NodeID == "" {
+		return NodeInterrupt{}, false
+	}
+	return interrupt, true
+}
+
+type pendingResumeContextKey struct{}
+
+type resumeInputContextKey struct{}
+
+// pendingResume is the input a run was resumed with, for the node it was
+// interrupted at.
+type pendingResume struct {
+	nodeID string
+	input  map[string]any
+	used   atomic.Bool
+}
+
+func withPendingResume(ctx context.Context, nodeID string, input map[string]any) context.Context {
+	return context.WithValue(ctx, pendingResumeContextKey{}, &pendingResume{nodeID: nodeID, input: input})
+}
+
+// nodeContext hands the resume input to the node the run was interrupted
+// at, the first time it runs again.
+func nodeContext(ctx context.Context, nodeID string) context.Context {
+	pending, _ := ctx.Value(pendingResumeContextKey{}).(*pendingResume)
+	if pending == nil || pending.nodeID != nodeID || !pending.used.CompareAndSwap(false, true) {
+		return ctx
+	}
+	return context.WithValue(ctx, resumeInputContextKey{}, pending.input)
+}
+
+// ResumeInput returns the input the run was resumed with when called from
+// the node that interrupted it. The input has also been merged into
+// State.Data.
+func ResumeInput(ctx context.Context) (map[string]any, bool) {
+	input, ok := ctx.Value(resumeInputContextKey{}).(map[string]any)
+	return input, ok
+}
+
+// asInterrupt reports whether err is an interrupt, recording nodeID as the
+// node that raised it.
+func asInterrupt(err error, nodeID string) (*NodeInterrupt, bool) {
+	var interrupt *NodeInterrupt
+	if !errors.As(err, &interrupt) {
+		return nil, false
+	}
+	if interrupt.NodeID == "" {
+		interrupt.NodeID = nodeID
+	}
+	return interrupt, true
+}
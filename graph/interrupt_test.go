@@ -0,0 +1,205 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+func TestExecutor_InterruptNode(t *testing.T) {
+	g := New("deploy").
+		AddNode("plan", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["plan"] = "roll out " + s.Input
+			return nil
+		})).
+		AddNode("approve", NewInterruptNode("Approve the rollout? Reply with {\"approved\": true|false}.")).
+		AddNode("deploy", NewToolNode(func(ctx context.Context, s *State) error {
+			if s.Data["approved"] == true {
+				s.Output = "deployed: " + s.Data["plan"].(string)
+			} else {
+				s.Output = "cancelled"
+			}
+			return nil
+		})).
+		SetStart("plan").
+		AddEdge("plan", "approve", nil).
+		AddEdge("approve", "deploy", nil)
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(g, WithStore(store), WithSessionID("sess-i"))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	_, err = executor.Run(context.Background(), "v2")
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("expected an interrupted error, got %v", err)
+	}
+	if interrupted.Interrupt.NodeID != "approve" {
+		t.Fatalf("unexpected interrupt: %+v", interrupted.Interrupt)
+	}
+
+	runs, _ := store.ListRuns(context.Background(), state.ListRunsQuery{Status: RunStatusInterrupted})
+	if len(runs) != 1 || runs[0].RunID != interrupted.RunID {
+		t.Fatalf("expected the run to be stored as interrupted, got %+v", runs)
+	}
+	pending, ok := PendingInterrupt(runs[0])
+	if !ok || pending.NodeID != "approve" || pending.Prompt == "" {
+		t.Fatalf("unexpected pending interrupt: %+v, %v", pending, ok)
+	}
+
+	if _, err := executor.Resume(context.Background(), interrupted.RunID); !errors.Is(err, ErrInterrupted) {
+		t.Fatalf("expected resuming without input to interrupt again, got %v", err)
+	}
+
+	result, err := executor.ResumeWithInput(context.Background(), interrupted.RunID, map[string]any{"approved": true})
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Output != "deployed: roll out v2" {
+		t.Fatalf("unexpected output: %q", result.Output)
+	}
+	run, _ := store.LoadRun(context.Background(), interrupted.RunID)
+	if run.Status != "completed" {
+		t.Fatalf("expected the run to complete, got %q", run.Status)
+	}
+	if _, ok := PendingInterrupt(run); ok {
+		t.Fatalf("expected no pending interrupt after completion")
+	}
+}
+
+func TestExecutor_NodeInterruptInBranch(t *testing.T) {
+	var steadyCalls atomic.Int32
+	g := New("review").
+		AddNode("start", NewToolNode(func(ctx context.Context, s *State) error { return nil })).
+		AddNode("lint", NewToolNode(func(ctx context.Context, s *State) error {
+			steadyCalls.Add(1)
+			s.Data["lint"] = "clean"
+			return nil
+		}), WithRetry(3, 0)).
+		AddNode("review", NewToolNode(func(ctx context.Context, s *State) error {
+			input, ok := ResumeInput(ctx)
+			if !ok {
+				return Interrupt("Who should review this change?")
+			}
+			s.Data["reviewer"] = input["reviewer"]
+			return nil
+		}), WithRetry(3, 0)).
+		AddNode("done", NewJoinNode(nil)).
+		SetStart("start").
+		AddFanOut("start", "done", "lint", "review")
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(g, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	_, err = executor.Run(context.Background(), "pr")
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Interrupt.NodeID != "review" {
+		t.Fatalf("expected the review branch to interrupt once, got %v", err)
+	}
+
+	result, err := executor.ResumeWithInput(context.Background(), interrupted.RunID, map[string]any{"reviewer": "sam"})
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if steadyCalls.Load() != 1 {
+		t.Fatalf("expected the finished branch not to rerun, ran %d times", steadyCalls.Load())
+	}
+	checkpoints, _ := store.ListCheckpoints(context.Background(), result.RunID, 0)
+	final, _ := restoreCheckpoint(checkpoints[len(checkpoints)-1].State)
+	if final.State.Data["reviewer"] != "sam" || final.State.Data["lint"] != "clean" {
+		t.Fatalf("unexpected final state: %v", final.State.Data)
+	}
+}
+
+func TestExecutor_ResumeClaimsRun(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	var deployed atomic.Int32
+	g := New("claim").
+		AddNode("approve", NewInterruptNode("Deploy?")).
+		AddNode("deploy", NewToolNode(func(ctx context.Context, s *State) error {
+			deployed.Add(1)
+			close(started)
+			<-release
+			s.Output = "deployed"
+			return nil
+		})).
+		SetStart("approve").
+		AddEdge("approve", "deploy", nil)
+
+	executor, err := NewExecutor(g, WithStore(newMemoryStore()))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	_, err = executor.Run(context.Background(), "v2")
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("expected an interrupt, got %v", err)
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := executor.ResumeWithInput(context.Background(), interrupted.RunID, map[string]any{"ok": true})
+		done <- err
+	}()
+	<-started
+	if _, err := executor.ResumeWithInput(context.Background(), interrupted.RunID, map[string]any{"ok": true}); !errors.Is(err, state.ErrConflict) {
+		t.Fatalf("expected a conflict resuming a run being resumed, got %v", err)
+	}
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if deployed.Load() != 1 {
+		t.Fatalf("expected the node to run once, ran %d times", deployed.Load())
+	}
+}
+
+func TestExecutor_SavesKeepRunMetadata(t *testing.T) {
+	g := New("confirm").
+		AddNode("ask", NewInterruptNode("Proceed?")).
+		AddNode("done", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = "done"
+			return nil
+		})).
+		SetStart("ask").
+		AddEdge("ask", "done", nil)
+
+	ctx := context.Background()
+	store := newMemoryStore()
+	executor, err := NewExecutor(g, WithStore(store), WithRunMetadata(map[string]any{"tools": []string{"calculator"}}))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	_, err = executor.Run(ctx, "go")
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) {
+		t.Fatalf("expected an interrupted error, got %v", err)
+	}
+	run, _ := store.LoadRun(ctx, interrupted.RunID)
+	run.Metadata["caller"] = "playground"
+	if err := store.SaveRun(ctx, run); err != nil {
+		t.Fatalf("save run failed: %v", err)
+	}
+
+	// A resume by an executor without the run metadata keeps what is stored.
+	resumer, err := NewExecutor(g, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	if _, err := resumer.ResumeWithInput(ctx, interrupted.RunID, map[string]any{"ok": true}); err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	run, _ = store.LoadRun(ctx, interrupted.RunID)
+	if run.Status != "completed" || run.Metadata["caller"] != "playground" || run.Metadata["tools"] == nil {
+		t.Fatalf("expected the stored metadata to survive the resume, got %+v (%s)", run.Metadata, run.Status)
+	}
+	if _, ok := PendingInterrupt(run); ok {
+		t.Fatalf("expected no pending interrupt after completion")
+	}
+}
@@ -0,0 +1,454 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefaultMaxConcurrency is how many branches of a fan-out or items of a map
+// node run at once, unless WithMaxConcurrency or MapNode.MaxConcurrency
+// set another limit.
+const DefaultMaxConcurrency = 10
+
+// fanOut runs branches concurrently after a node, then continues at join.
+type fanOut struct {
+	from     string
+	branches []string
+	join     string
+}
+
+// AddFanOut runs the given branches concurrently once from completes. Each
+// branch starts at its node with a copy of the state and follows edges
+// until it reaches join or a node with no next node. The branch states are
+// then merged, using the reducers of join when it is a JoinNode, and the
+// run continues at join. from must not have edges of its own.
+func (g *Graph) AddFanOut(from, join string, branches ...string) *Graph {
+	if g == nil {
+		return g
+	}
+	if g.buildErr != nil {
+		return g
+	}
+	if from == "" || join == "" {
+		g.buildErr = fmt.Errorf("fan-out source and join are required")
+		return g
+	}
+	if from == join {
+		g.buildErr = fmt.Errorf("fan-out from %q cannot join at itself", from)
+		return g
+	}
+	if len(branches) == 0 {
+		g.buildErr = fmt.Errorf("fan-out from %q has no branches", from)
+		return g
+	}
+	if _, exists := g.fanOuts[from]; exists {
+		g.buildErr = fmt.Errorf("node %q already has a fan-out", from)
+		return g
+	}
+	seen := map[string]bool{}
+	for _, b := range branches {
+		if b == "" || b == join || seen[b] {
+			g.buildErr = fmt.Errorf("fan-out from %q has an empty, duplicate or join branch %q", from, b)
+			return g
+		}
+		seen[b] = true
+	}
+	g.fanOuts[from] = fanOut{from: from, branches: append([]string(nil), branches...), join: join}
+	return g
+}
+
+// compileFanOuts checks that fan-outs refer to existing nodes, do not
+// compete with ordinary edges and are not nested in another's branches.
+func (g *Graph) compileFanOuts() error {
+	for from, fo := range g.fanOuts {
+		if _, ok := g.nodes[from]; !ok {
+			return fmt.Errorf("fan-out source node %q does not exist", from)
+		}
+		if _, ok := g.nodes[fo.join]; !ok {
+			return fmt.Errorf("fan-out join node %q does not exist", fo.join)
+		}
+		if len(g.edges[from]) > 0 {
+			return fmt.Errorf("node %q has both edges and a fan-out", from)
+		}
+		for _, b := range fo.branches {
+			if _, ok := g.nodes[b]; !ok {
+				return fmt.Errorf("fan-out branch node %q does not exist", b)
+			}
+			visited := map[string]bool{}
+			var walk func(nodeID string) error
+			walk = func(nodeID string) error {
+				if nodeID == fo.join || visited[nodeID] {
+					return nil
+				}
+				visited[nodeID] = true
+				if _, nested := g.fanOuts[nodeID]; nested {
+					return fmt.Errorf("fan-out from %q is nested in a branch of the fan-out from %q", nodeID, from)
+				}
+				for _, next := range g.successors(nodeID) {
+					if err := walk(next); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+			if err := walk(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Reducer folds one branch's value for a Data key into the merged value.
+// It is called for each branch that changed the key, in branch order,
+// starting from the key's value before the fan-out (nil if it had none).
+type Reducer func(current, update any) (any, error)
+
+// LastValue keeps the value of the last branch that changed the key. Keys
+// without a reducer use it.
+func LastValue(_, update any) (any, error) { return update, nil }
+
+// Collect gathers the branches' values into a list, added to the key's
+// value before the fan-out when that was already a list.
+func Collect(current, update any) (any, error) {
+	list, _ := current.([]any)
+	return append(append([]any(nil), list...), update), nil
+}
+
+// MergeMaps merges the branches' maps key by key; later branches win.
+func MergeMaps(current, update any) (any, error) {
+	merged := map[string]any{}
+	if m, ok := current.(map[string]any); ok {
+		for k, v := range m {
+			merged[k] = v
+		}
+	}
+	m, ok := update.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("MergeMaps: expected a map, got %T", update)
+	}
+	for k, v := range m {
+		merged[k] = v
+	}
+	return merged, nil
+}
+
+// JoinStrings concatenates the branches' strings with sep.
+func JoinStrings(sep string) Reducer {
+	return func(current, update any) (any, error) {
+		s, ok := update.(string)
+		if !ok {
+			return nil, fmt.Errorf("JoinStrings: expected a string, got %T", update)
+		}
+		if prev, _ := current.(string); prev != "" {
+			return prev + sep + s, nil
+		}
+		return s, nil
+	}
+}
+
+// JoinNode is the node a fan-out continues at. Before it runs, the branch
+// states are merged: Reducers combine the Data keys the branches changed,
+// keys without one keep the last branch's value, and Output is taken from
+// the last branch that set it. Then, if set, runs afterwards.
+type JoinNode struct {
+	Reducers map[string]Reducer
+	Then     ToolFunc
+}
+
+func NewJoinNode(reducers map[string]Reducer) *JoinNode {
+	return &JoinNode{Reducers: reducers}
+}
+
+func (n *JoinNode) Execute(ctx context.Context, state *State) error {
+	if n == nil || n.Then == nil {
+		return nil
+	}
+	return n.Then(ctx, state)
+}
+
+// mergeBranches merges the branch states into base; see JoinNode.
+func mergeBranches(base *State, branches []State, reducers map[string]Reducer) error {
+	base.ensureData()
+	changed := map[string]bool{}
+	for _, b := range branches {
+		for k, v := range b.Data {
+			if before, ok := base.Data[k]; !ok || !reflect.DeepEqual(before, v) {
+				changed[k] = true
+			}
+		}
+	}
+	keys := make([]string, 0, len(changed))
+	for k := range changed {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	merged := make(map[string]any, len(keys))
+	for _, k := range keys {
+		reduce := reducers[k]
+		if reduce == nil {
+			reduce = LastValue
+		}
+		before, hadBefore := base.Data[k]
+		current := before
+		for _, b := range branches {
+			v, ok := b.Data[k]
+			if !ok || (hadBefore && reflect.DeepEqual(before, v)) {
+				continue
+			}
+			var err error
+			if current, err = reduce(current, v); err != nil {
+				return fmt.Errorf("merge %q: %w", k, err)
+			}
+		}
+		merged[k] = current
+	}
+	for k, v := range merged {
+		base.Data[k] = v
+	}
+
+	output := base.Output
+	for _, b := range branches {
+		if b.Output != base.Output {
+			output = b.Output
+		}
+	}
+	base.Output = output
+	return nil
+}
+
+// MapNode runs Body once for each item of the list in Data[ItemsKey],
+// concurrently, on copies of the state holding the item in Data[ItemKey]
+// and its position in Data[IndexKey]. Each run's result, Data[ResultKey]
+// or the run's Output when ResultKey is empty, is stored in item order as
+// a list in Data[OutputKey]. Other changes the runs make are discarded.
+// Run by an Executor, finished items are checkpointed so a resumed run
+// only processes the rest.
+type MapNode struct {
+	ItemsKey  string
+	Body      Node
+	ItemKey   string
+	IndexKey  string
+	ResultKey string
+	OutputKey string
+	// MaxConcurrency overrides the executor's limit when positive.
+	MaxConcurrency int
+}
+
+func NewMapNode(itemsKey string, body Node) *MapNode {
+	return &MapNode{ItemsKey: itemsKey, Body: body}
+}
+
+func (n *MapNode) Execute(ctx context.Context, state *State) error {
+	return n.run(ctx, state, DefaultMaxConcurrency, nil, nil)
+}
+
+func (n *MapNode) itemKey() string   { return keyOr(n.ItemKey, "item") }
+func (n *MapNode) indexKey() string  { return keyOr(n.IndexKey, "index") }
+func (n *MapNode) outputKey() string { return keyOr(n.OutputKey, "results") }
+
+func keyOr(key, fallback string) string {
+	if strings.TrimSpace(key) == "" {
+		return fallback
+	}
+	return key
+}
+
+// run processes the items not already in progress, calling checkpoint after
+// each one when progress is given.
+func (n *MapNode) run(ctx context.Context, state *State, limit int, progress *branchProgress, checkpoint func() error) error {
+	if n == nil || n.Body == nil {
+		return fmt.Errorf("map node body is required")
+	}
+	if state == nil {
+		return fmt.Errorf("state is required")
+	}
+	if n.MaxConcurrency > 0 {
+		limit = n.MaxConcurrency
+	}
+	items, err := listItems(state.Data[n.ItemsKey])
+	if err != nil {
+		return fmt.Errorf("map items %q: %w", n.ItemsKey, err)
+	}
+
+	results := make([]any, len(items))
+	done := make([]bool, len(items))
+	if progress != nil {
+		for k, v := range progress.Results {
+			if i, err := strconv.Atoi(k); err == nil && i >= 0 && i < len(items) {
+				results[i], done[i] = v, true
+			}
+		}
+	}
+	err = runBranches(ctx, len(items), limit,
+		func(i int) bool { return done[i] },
+		func(ctx context.Context, i int) error {
+			branch := state.branch()
+			branch.Data[n.itemKey()] = items[i]
+			branch.Data[n.indexKey()] = i
+			if err := n.Body.Execute(ctx, &branch); err != nil {
+				return fmt.Errorf("item %d: %w", i, err)
+			}
+			if n.ResultKey != "" {
+				results[i] = branch.Data[n.ResultKey]
+			} else {
+				results[i] = branch.Output
+			}
+			return nil
+		},
+		func(i int) error {
+			if progress == nil {
+				return nil
+			}
+			if progress.Results == nil {
+				progress.Results = map[string]any{}
+			}
+			progress.Results[strconv.Itoa(i)] = results[i]
+			return checkpoint()
+		})
+	if err != nil {
+		return err
+	}
+	state.ensureData()
+	state.Data[n.outputKey()] = results
+	return nil
+}
+
+func listItems(raw any) ([]any, error) {
+	if raw == nil {
+		return nil, nil
+	}
+	if items, ok := raw.([]any); ok {
+		return items, nil
+	}
+	v := reflect.ValueOf(raw)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return nil, fmt.Errorf("expected a list, got %T", raw)
+	}
+	items := make([]any, v.Len())
+	for i := range items {
+		items[i] = v.Index(i).Interface()
+	}
+	return items, nil
+}
+
+// branchProgress records the finished branches of a fan-out or items of a
+// map node in a checkpoint, so a resumed run skips them, or the child run
+// of a subgraph node, so a resumed run continues it.
+type branchProgress struct {
+	// Node is the fan-out's source, the map node or the subgraph node.
+	Node string `json:"node"`
+	// States are the finished fan-out branches' states by branch node.
+	States map[string]State `json:"states,omitempty"`
+	// Results are the finished map items' results by item index.
+	Results map[string]any `json:"results,omitempty"`
+	// RunID is the subgraph node's child run.
+	RunID string `json:"runId,omitempty"`
+}
+
+// runBranches calls run for each of n branches not already done, with at
+// most limit at once. onDone is called after each branch that finished,
+// one at a time. The first error cancels the branches still running.
+func runBranches(ctx context.Context, n, limit int, done func(int) bool, run func(context.Context, int) error, onDone func(int) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	if limit <= 0 || limit > n {
+		limit = n
+	}
+	if limit == 0 {
+		return nil
+	}
+	sem := make(chan struct{}, limit)
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	// An interrupted branch lets the others finish, so that only it runs
+	// again when the run is resumed.
+	fail := func(err error) {
+		if errors.Is(err, ErrInterrupted) {
+			if firstErr == nil {
+				firstErr = err
+			}
+			return
+		}
+		if firstErr == nil || errors.Is(firstErr, ErrInterrupted) {
+			firstErr = err
+			cancel()
+		}
+	}
+	for i := 0; i < n; i++ {
+		if done(i) {
+			continue
+		}
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			err := run(ctx, i)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				fail(err)
+				return
+			}
+			if err := onDone(i); err != nil {
+				fail(err)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+// branch returns a copy of the state for a branch to change independently.
+func (s State) branch() State {
+	s.Data, _ = cloneValue(s.Data).(map[string]any)
+	if s.Data == nil {
+		s.Data = map[string]any{}
+	}
+	return s
+}
+
+func cloneValue(v any) any {
+	switch t := v.(type) {
+	case map[string]any:
+		if t == nil {
+			return t
+		}
+		out := make(map[string]any, len(t))
+		for k, item := range t {
+			out[k] = cloneValue(item)
+		}
+		return out
+	case []any:
+		if t == nil {
+			return t
+		}
+		out := make([]any, len(t))
+		for i, item := range t {
+			out[i] = cloneValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
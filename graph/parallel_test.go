@@ -0,0 +1,296 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+// concurrencyProbe records how many calls are in flight at once.
+type concurrencyProbe struct {
+	active  atomic.Int32
+	highest atomic.Int32
+}
+
+func (p *concurrencyProbe) enter() func() {
+	n := p.active.Add(1)
+	for {
+		h := p.highest.Load()
+		if n <= h || p.highest.CompareAndSwap(h, n) {
+			break
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	return func() { p.active.Add(-1) }
+}
+
+func TestExecutor_FanOut_MergesBranches(t *testing.T) {
+	probe := &concurrencyProbe{}
+	branch := func(name string) *ToolNode {
+		return NewToolNode(func(ctx context.Context, s *State) error {
+			defer probe.enter()()
+			s.Data["findings"] = name + " on " + s.Data["topic"].(string)
+			s.Data["last"] = name
+			return nil
+		})
+	}
+	join := NewJoinNode(map[string]Reducer{"findings": Collect})
+	join.Then = func(ctx context.Context, s *State) error {
+		s.Output = fmt.Sprint(s.Data["findings"], " ", s.Data["last"])
+		return nil
+	}
+
+	g := New("fan-out").
+		AddNode("plan", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["topic"] = s.Input
+			return nil
+		})).
+		AddNode("logs", branch("logs")).
+		AddNode("metrics", branch("metrics")).
+		AddNode("traces", branch("traces")).
+		AddNode("traces-summary", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["summary"] = "summarized " + s.Data["findings"].(string)
+			return nil
+		})).
+		AddNode("report", join).
+		SetStart("plan").
+		AddFanOut("plan", "report", "logs", "metrics", "traces").
+		AddEdge("traces", "traces-summary", nil).
+		AddEdge("traces-summary", "report", nil)
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(g, WithStore(store), WithMaxConcurrency(2))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	result, err := executor.Run(context.Background(), "db")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Output != "[logs on db metrics on db traces on db] traces" {
+		t.Fatalf("unexpected output: %q", result.Output)
+	}
+	if highest := probe.highest.Load(); highest != 2 {
+		t.Fatalf("expected branches to run two at a time, got %d", highest)
+	}
+	if len(result.NodeTrace) != 6 {
+		t.Fatalf("expected 6 nodes in the trace, got %v", result.NodeTrace)
+	}
+
+	infos := g.EdgeInfos()
+	parallel := 0
+	for _, edge := range infos {
+		if edge.Parallel {
+			parallel++
+		}
+	}
+	if parallel != 3 {
+		t.Fatalf("expected 3 parallel edges, got %+v", infos)
+	}
+}
+
+func TestExecutor_FanOut_ResumesPartialBranches(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	branch := func(name string) *ToolNode {
+		return NewToolNode(func(ctx context.Context, s *State) error {
+			mu.Lock()
+			calls[name]++
+			n := calls[name]
+			mu.Unlock()
+			if name == "flaky" && n == 1 {
+				return errors.New("transient branch error")
+			}
+			s.Data["notes"] = name
+			return nil
+		})
+	}
+	g := New("resume-fan-out").
+		AddNode("start", NewToolNode(func(ctx context.Context, s *State) error { return nil })).
+		AddNode("a", branch("a")).
+		AddNode("b", branch("b")).
+		AddNode("flaky", branch("flaky")).
+		AddNode("join", &JoinNode{
+			Reducers: map[string]Reducer{"notes": JoinStrings(",")},
+			Then: func(ctx context.Context, s *State) error {
+				s.Output = s.Data["notes"].(string)
+				return nil
+			},
+		}).
+		SetStart("start").
+		AddFanOut("start", "join", "a", "flaky", "b")
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(g, WithStore(store), WithSessionID("sess-f"), WithMaxConcurrency(1))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	if _, err := executor.Run(context.Background(), "go"); err == nil || !strings.Contains(err.Error(), "transient branch error") {
+		t.Fatalf("expected the branch error, got %v", err)
+	}
+	runs, _ := store.ListRuns(context.Background(), state.ListRunsQuery{SessionID: "sess-f"})
+	if len(runs) != 1 || runs[0].Status != "failed" {
+		t.Fatalf("expected one failed run, got %+v", runs)
+	}
+
+	resumed, err := executor.Resume(context.Background(), runs[0].RunID)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if resumed.Output != "a,flaky,b" {
+		t.Fatalf("unexpected resumed output: %q", resumed.Output)
+	}
+	if calls["a"] != 1 || calls["flaky"] != 2 || calls["b"] != 1 {
+		t.Fatalf("expected only the failed branch to rerun, got %v", calls)
+	}
+}
+
+func TestExecutor_MapNode(t *testing.T) {
+	var mu sync.Mutex
+	calls := map[string]int{}
+	body := NewToolNode(func(ctx context.Context, s *State) error {
+		item := s.Data["item"].(string)
+		mu.Lock()
+		calls[item]++
+		n := calls[item]
+		mu.Unlock()
+		if item == "flaky" && n == 1 {
+			return errors.New("transient item error")
+		}
+		s.Output = fmt.Sprintf("%d:%s", s.Data["index"], strings.ToUpper(item))
+		s.Data["scratch"] = "discarded"
+		return nil
+	})
+	g := New("map").
+		AddNode("split", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["items"] = strings.Fields(s.Input)
+			return nil
+		})).
+		AddNode("each", NewMapNode("items", body)).
+		AddNode("reduce", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = fmt.Sprint(s.Data["results"])
+			return nil
+		})).
+		SetStart("split").
+		AddEdge("split", "each", nil).
+		AddEdge("each", "reduce", nil)
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(g, WithStore(store), WithSessionID("sess-m"))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	if _, err := executor.Run(context.Background(), "a flaky b c"); err == nil {
+		t.Fatalf("expected the first run to fail")
+	}
+	runs, _ := store.ListRuns(context.Background(), state.ListRunsQuery{SessionID: "sess-m"})
+	if len(runs) != 1 {
+		t.Fatalf("expected one run, got %d", len(runs))
+	}
+
+	resumed, err := executor.Resume(context.Background(), runs[0].RunID)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if resumed.Output != "[0:A 1:FLAKY 2:B 3:C]" {
+		t.Fatalf("unexpected output: %q", resumed.Output)
+	}
+	if calls["flaky"] != 2 {
+		t.Fatalf("expected the failed item to rerun once, got %v", calls)
+	}
+	for _, item := range []string{"a", "b", "c"} {
+		if calls[item] != 1 {
+			t.Fatalf("expected finished items not to rerun, got %v", calls)
+		}
+	}
+}
+
+func TestMapNode_Execute(t *testing.T) {
+	node := &MapNode{
+		ItemsKey:  "numbers",
+		ItemKey:   "n",
+		ResultKey: "square",
+		OutputKey: "squares",
+		Body: NewToolNode(func(ctx context.Context, s *State) error {
+			n := s.Data["n"].(int)
+			s.Data["square"] = n * n
+			return nil
+		}),
+	}
+	s := &State{Data: map[string]any{"numbers": []int{1, 2, 3}}}
+	if err := node.Execute(context.Background(), s); err != nil {
+		t.Fatalf("execute failed: %v", err)
+	}
+	if !reflect.DeepEqual(s.Data["squares"], []any{1, 4, 9}) {
+		t.Fatalf("unexpected results: %v", s.Data["squares"])
+	}
+	if _, ok := s.Data["square"]; ok {
+		t.Fatalf("expected item state to be discarded")
+	}
+
+	s.Data["numbers"] = "not a list"
+	if err := node.Execute(context.Background(), s); err == nil {
+		t.Fatalf("expected an error for a non-list")
+	}
+}
+
+func TestMergeBranches(t *testing.T) {
+	base := &State{Output: "base", Data: map[string]any{"tags": []any{"seed"}, "unchanged": 1}}
+	branches := []State{
+		{Output: "base", Data: map[string]any{"tags": []any{"seed"}, "unchanged": 1, "meta": map[string]any{"a": 1}}},
+		{Output: "second", Data: map[string]any{"tags": []any{"seed", "x"}, "unchanged": 1, "meta": map[string]any{"b": 2}}},
+	}
+	if err := mergeBranches(base, branches, map[string]Reducer{"meta": MergeMaps, "tags": Collect}); err != nil {
+		t.Fatalf("merge failed: %v", err)
+	}
+	if base.Output != "second" {
+		t.Fatalf("expected the changed output, got %q", base.Output)
+	}
+	if !reflect.DeepEqual(base.Data["meta"], map[string]any{"a": 1, "b": 2}) {
+		t.Fatalf("unexpected merged map: %v", base.Data["meta"])
+	}
+	if !reflect.DeepEqual(base.Data["tags"], []any{"seed", []any{"seed", "x"}}) {
+		t.Fatalf("expected only the changing branch to be collected: %v", base.Data["tags"])
+	}
+
+	if err := mergeBranches(base, []State{{Data: map[string]any{"meta": "oops"}}}, map[string]Reducer{"meta": MergeMaps}); err == nil {
+		t.Fatalf("expected a reducer error")
+	}
+}
+
+func TestGraphCompile_FanOutValidation(t *testing.T) {
+	noop := NewToolNode(func(ctx context.Context, s *State) error { return nil })
+	build := func() *Graph {
+		return New("fan-out").
+			AddNode("start", noop).
+			AddNode("a", noop).
+			AddNode("b", noop).
+			AddNode("join", noop).
+			SetStart("start")
+	}
+
+	if err := build().AddFanOut("start", "join", "a", "b").Compile(); err != nil {
+		t.Fatalf("expected a valid fan-out: %v", err)
+	}
+	if err := build().AddFanOut("start", "join", "a", "missing").Compile(); err == nil {
+		t.Fatalf("expected an error for a missing branch")
+	}
+	if err := build().AddFanOut("start", "join", "a", "a").Compile(); err == nil {
+		t.Fatalf("expected an error for a duplicate branch")
+	}
+	if err := build().AddFanOut("start", "join", "a").AddEdge("start", "b", nil).Compile(); err == nil {
+		t.Fatalf("expected an error for a fan-out source with edges")
+	}
+	nested := build().AddFanOut("start", "join", "a").AddFanOut("a", "join", "b")
+	if err := nested.Compile(); err == nil || !strings.Contains(err.Error(), "nested") {
+		t.Fatalf("expected a nested fan-out error, got %v", err)
+	}
+}
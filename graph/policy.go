@@ -0,0 +1,190 @@
+package graph
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// ErrorKey is the Data key a node's error is written to when its policy
+// continues on error or follows an error edge.
+const ErrorKey = "error"
+
+// maxNodeBackoff caps the doubling of a node's retry backoff.
+const maxNodeBackoff = 30 * time.Second
+
+// NodePolicy controls how a node's failures are handled. Without one, a
+// failing node fails the run.
+type NodePolicy struct {
+	// MaxAttempts is how many times the node is tried before it fails.
+	MaxAttempts int
+	// Backoff is the wait before the first retry, doubled for each one after.
+	Backoff time.Duration
+	// Timeout bounds each attempt.
+	Timeout time.Duration
+	// ContinueOnError records the error in Data[ErrorKey] and continues at
+	// the node's next node as if it had succeeded.
+	ContinueOnError bool
+	// ErrorEdge is the node to continue at, with the error recorded in
+	// Data[ErrorKey], when the node fails. It takes precedence over
+	// ContinueOnError.
+	ErrorEdge string
+}
+
+// NodeOption configures the policy of a node added with AddNode.
+type NodeOption func(*NodePolicy)
+
+// WithRetry tries the node up to maxAttempts times, waiting backoff before
+// the first retry and twice as long before each one after.
+func WithRetry(maxAttempts int, backoff time.Duration) NodeOption {
+	return func(p *NodePolicy) {
+		p.MaxAttempts = maxAttempts
+		p.Backoff = backoff
+	}
+}
+
+// WithTimeout fails an attempt of the node that runs longer than timeout.
+// The attempt's context is cancelled; a map or subgraph node is waited for
+// until it returns, so its nodes should stop when their context is done.
+func WithTimeout(timeout time.Duration) NodeOption {
+	return func(p *NodePolicy) { p.Timeout = timeout }
+}
+
+// WithContinueOnError lets the run continue past the node when it fails;
+// see NodePolicy.ContinueOnError.
+func WithContinueOnError() NodeOption {
+	return func(p *NodePolicy) { p.ContinueOnError = true }
+}
+
+// WithErrorEdge routes the run to a fallback or compensation node when the
+// node fails; see NodePolicy.ErrorEdge.
+func WithErrorEdge(to string) NodeOption {
+	return func(p *NodePolicy) { p.ErrorEdge = to }
+}
+
+func (p NodePolicy) isZero() bool {
+	return p == NodePolicy{}
+}
+
+func (p NodePolicy) attempts() int {
+	if p.MaxAttempts < 1 {
+		return 1
+	}
+	return p.MaxAttempts
+}
+
+func (p NodePolicy) backoff(retry int) time.Duration {
+	delay := p.Backoff
+	for i := 1; i < retry && delay < maxNodeBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxNodeBackoff {
+		return maxNodeBackoff
+	}
+	return delay
+}
+
+// runNode executes a node under its policy. Each attempt runs on a copy of
+// the state, kept only if it succeeds, and checkpoint, when set, is called
+// after each failed attempt that will be retried. It returns the node's
+// error when the policy handles it, or an error that fails the run.
+// Interrupts are never retried or handled.
+func (e *Executor) runNode(ctx context.Context, run *graphRun, runtimeState *State, nodeID string, exec func(context.Context, *State) error, checkpoint func(attempt int) error) (nodeErr error, err error) {
+	ctx = nodeContext(ctx, nodeID)
+	policy := e.graph.policies[nodeID]
+	if policy.isZero() {
+		err := exec(ctx, runtimeState)
+		asInterrupt(err, nodeID)
+		return nil, err
+	}
+
+	attempts := policy.attempts()
+	wait := sharesProgress(e.graph.nodes[nodeID])
+	for attempt := 1; ; attempt++ {
+		attemptState := runtimeState.branch()
+		nodeErr = runAttempt(ctx, policy.Timeout, wait, &attemptState, exec)
+		if nodeErr == nil {
+			*runtimeState = attemptState
+			return nil, nil
+		}
+		if _, ok := asInterrupt(nodeErr, nodeID); ok || ctx.Err() != nil {
+			return nil, nodeErr
+		}
+		if attempt >= attempts {
+			break
+		}
+
+		delay := policy.backoff(attempt)
+		e.emit(ctx, run, types.Event{
+			Type:      types.EventGraphNodeRetrying,
+			Timestamp: time.Now().UTC(),
+			RunID:     runtimeState.RunID,
+			SessionID: runtimeState.SessionID,
+			Provider:  e.graphProviderName(),
+			ToolName:  nodeID,
+			Error:     nodeErr.Error(),
+			Message:   fmt.Sprintf("attempt %d of %d failed; retrying in %s", attempt, attempts, delay),
+		})
+		if checkpoint != nil {
+			if err := checkpoint(attempt); err != nil {
+				return nil, err
+			}
+		}
+		if delay > 0 {
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return nil, ctx.Err()
+			case <-timer.C:
+			}
+		}
+	}
+
+	if policy.ErrorEdge == "" && !policy.ContinueOnError {
+		return nil, nodeErr
+	}
+	runtimeState.ensureData()
+	runtimeState.Data[ErrorKey] = map[string]any{
+		"node":     nodeID,
+		"error":    nodeErr.Error(),
+		"attempts": attempts,
+	}
+	return nodeErr, nil
+}
+
+// runAttempt runs one attempt of a node. An attempt that outlives its
+// timeout is abandoned, as it only has its own copy of the state to change,
+// unless wait is set; then it is waited for once its context is cancelled.
+func runAttempt(ctx context.Context, timeout time.Duration, wait bool, attemptState *State, exec func(context.Context, *State) error) error {
+	if timeout <= 0 {
+		return exec(ctx, attemptState)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	done := make(chan error, 1)
+	go func() { done <- exec(ctx, attemptState) }()
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		if wait {
+			<-done
+		}
+		return fmt.Errorf("timed out after %s: %w", timeout, ctx.Err())
+	}
+}
+
+// sharesProgress reports whether the attempts of a node share more than
+// the state: a map node's finished items and a subgraph node's child run
+// are kept across attempts, so one attempt must stop before the next
+// starts.
+func sharesProgress(node Node) bool {
+	switch node.(type) {
+	case *MapNode, *SubgraphNode:
+		return true
+	}
+	return false
+}
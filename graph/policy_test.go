@@ -0,0 +1,252 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestExecutor_NodeRetry(t *testing.T) {
+	calls := 0
+	g := New("retry").
+		AddNode("fetch", NewToolNode(func(ctx context.Context, s *State) error {
+			calls++
+			s.Data["attempts"] = append(toStrings(s.Data["attempts"]), "tried")
+			if calls < 3 {
+				return errors.New("connection reset")
+			}
+			s.Output = "fetched"
+			return nil
+		}), WithRetry(3, time.Millisecond)).
+		SetStart("fetch")
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(g, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	result, err := executor.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Output != "fetched" || calls != 3 {
+		t.Fatalf("expected success on the third attempt, got %q after %d", result.Output, calls)
+	}
+
+	retries := 0
+	for _, event := range result.Events {
+		if event.Type == types.EventGraphNodeRetrying {
+			retries++
+			if event.ToolName != "fetch" || !strings.Contains(event.Error, "connection reset") {
+				t.Fatalf("unexpected retry event: %+v", event)
+			}
+		}
+	}
+	if retries != 2 {
+		t.Fatalf("expected 2 retry events, got %d", retries)
+	}
+
+	checkpoints, _ := store.ListCheckpoints(context.Background(), result.RunID, 0)
+	if len(checkpoints) != 3 {
+		t.Fatalf("expected 2 retry checkpoints and 1 node checkpoint, got %d", len(checkpoints))
+	}
+	if checkpoints[0].State["attempt"] != float64(1) || checkpoints[0].State["nextNodeId"] != "fetch" {
+		t.Fatalf("unexpected retry checkpoint: %v", checkpoints[0].State)
+	}
+	data := checkpoints[2].State["state"].(map[string]any)["data"].(map[string]any)
+	if len(data["attempts"].([]any)) != 1 {
+		t.Fatalf("expected failed attempts' changes to be discarded, got %v", data["attempts"])
+	}
+}
+
+func TestExecutor_NodeTimeoutContinueOnError(t *testing.T) {
+	release := make(chan struct{})
+	defer close(release)
+	g := New("timeout").
+		AddNode("slow", NewToolNode(func(ctx context.Context, s *State) error {
+			<-release
+			return nil
+		}), WithTimeout(20*time.Millisecond), WithContinueOnError()).
+		AddNode("next", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = "continued"
+			return nil
+		})).
+		SetStart("slow").
+		AddEdge("slow", "next", nil)
+
+	executor, err := NewExecutor(g)
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	result, err := executor.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Output != "continued" {
+		t.Fatalf("unexpected output: %q", result.Output)
+	}
+	failed := false
+	for _, event := range result.Events {
+		if event.Type == types.EventGraphNodeFailed && event.ToolName == "slow" {
+			failed = strings.Contains(event.Error, "timed out")
+		}
+	}
+	if !failed {
+		t.Fatalf("expected a node failed event for the timeout")
+	}
+}
+
+func TestExecutor_ErrorEdge(t *testing.T) {
+	g := New("compensate").
+		AddNode("charge", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["charged"] = true
+			return errors.New("card declined")
+		}), WithErrorEdge("refund")).
+		AddNode("ship", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = "shipped"
+			return nil
+		})).
+		AddNode("refund", NewToolNode(func(ctx context.Context, s *State) error {
+			failure := s.Data[ErrorKey].(map[string]any)
+			s.Output = "refunded after " + failure["node"].(string) + ": " + failure["error"].(string)
+			if _, charged := s.Data["charged"]; charged {
+				s.Output = "the failed attempt's changes were kept"
+			}
+			return nil
+		})).
+		SetStart("charge").
+		AddEdge("charge", "ship", nil)
+
+	executor, err := NewExecutor(g)
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	result, err := executor.Run(context.Background(), "order")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Output != "refunded after charge: card declined" {
+		t.Fatalf("unexpected output: %q", result.Output)
+	}
+	if strings.Join(result.NodeTrace, ",") != "charge,refund" {
+		t.Fatalf("unexpected node trace: %v", result.NodeTrace)
+	}
+
+	var onError bool
+	for _, edge := range g.EdgeInfos() {
+		onError = onError || (edge.OnError && edge.From == "charge" && edge.To == "refund")
+	}
+	if !onError {
+		t.Fatalf("expected the error edge in the edge infos: %+v", g.EdgeInfos())
+	}
+}
+
+func TestExecutor_RetriesExhausted(t *testing.T) {
+	g := New("exhausted").
+		AddNode("a", NewToolNode(func(ctx context.Context, s *State) error {
+			return errors.New("still down")
+		}), WithRetry(2, 0)).
+		SetStart("a")
+	executor, err := NewExecutor(g)
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	if _, err := executor.Run(context.Background(), "go"); err == nil || !strings.Contains(err.Error(), "still down") {
+		t.Fatalf("expected the run to fail, got %v", err)
+	}
+
+	bad := New("bad").
+		AddNode("a", NewToolNode(func(ctx context.Context, s *State) error { return nil }), WithErrorEdge("missing")).
+		SetStart("a")
+	if err := bad.Compile(); err == nil {
+		t.Fatalf("expected an error for a missing error edge target")
+	}
+}
+
+func TestNodePolicy_Backoff(t *testing.T) {
+	p := NodePolicy{Backoff: 100 * time.Millisecond}
+	if p.backoff(1) != 100*time.Millisecond || p.backoff(3) != 400*time.Millisecond {
+		t.Fatalf("unexpected backoff: %s, %s", p.backoff(1), p.backoff(3))
+	}
+	if p.backoff(20) != maxNodeBackoff {
+		t.Fatalf("expected the backoff to be capped, got %s", p.backoff(20))
+	}
+}
+
+func toStrings(v any) []string {
+	out, _ := v.([]string)
+	return out
+}
+
+func TestExecutor_NodeTimeoutWaitsForProgress(t *testing.T) {
+	var calls [3]atomic.Int32
+	body := NewToolNode(func(ctx context.Context, s *State) error {
+		i := s.Data["index"].(int)
+		if calls[i].Add(1) == 1 && i == 2 {
+			// Finishes late, after its attempt has timed out.
+			<-ctx.Done()
+			time.Sleep(10 * time.Millisecond)
+		}
+		s.Output = fmt.Sprintf("item %v", s.Data["item"])
+		return nil
+	})
+	g := New("map-timeout").
+		AddNode("each", NewMapNode("items", body), WithTimeout(30*time.Millisecond), WithRetry(3, 0)).
+		AddNode("setup", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["items"] = []any{"a", "b", "c"}
+			return nil
+		})).
+		SetStart("setup").
+		AddEdge("setup", "each", nil)
+
+	executor, err := NewExecutor(g, WithStore(newMemoryStore()))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	result, err := executor.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if calls[0].Load() != 1 || calls[1].Load() != 1 || calls[2].Load() != 1 {
+		t.Fatalf("expected the retry to keep the late item's result, got %d %d %d", calls[0].Load(), calls[1].Load(), calls[2].Load())
+	}
+	if len(result.NodeTrace) != 2 {
+		t.Fatalf("unexpected node trace: %v", result.NodeTrace)
+	}
+}
+
+func TestExecutor_SubgraphTimeoutRetry(t *testing.T) {
+	var calls atomic.Int32
+	child := New("slow-child").
+		AddNode("work", NewToolNode(func(ctx context.Context, s *State) error {
+			if calls.Add(1) == 1 {
+				<-ctx.Done()
+				return ctx.Err()
+			}
+			s.Output = "done"
+			return nil
+		})).
+		SetStart("work")
+	parent := New("slow-parent").
+		AddNode("child", NewSubgraphNode(child), WithTimeout(30*time.Millisecond), WithRetry(3, 0)).
+		SetStart("child")
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(parent, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	result, err := executor.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Output != "done" || calls.Load() != 2 {
+		t.Fatalf("unexpected result %q after %d calls", result.Output, calls.Load())
+	}
+}
@@ -15,11 +15,20 @@ type State struct {
 	Data       map[string]any `json:"data,omitempty"`
 	StartedAt  time.Time      `json:"startedAt"`
 	UpdatedAt  time.Time      `json:"updatedAt"`
+	// ForkedFrom is set on runs started with Executor.Fork.
+	ForkedFrom *ForkOrigin `json:"forkedFrom,omitempty"`
 }
 
 type checkpointSnapshot struct {
 	State      State  `json:"state"`
 	NextNodeID string `json:"nextNodeId,omitempty"`
+	// Branches records a fan-out or map node that is partway through.
+	Branches *branchProgress `json:"branches,omitempty"`
+	// Attempt is the failed attempt of NextNodeID a retry checkpoint was
+	// taken after.
+	Attempt int `json:"attempt,omitempty"`
+	// Interrupt is set when the run was interrupted at NextNodeID.
+	Interrupt *NodeInterrupt `json:"interrupt,omitempty"`
 }
 
 func newState(runID, sessionID, input string, now time.Time) State {
@@ -43,12 +52,8 @@ func (s *State) EnsureData() {
 	s.ensureData()
 }
 
-func (s State) snapshot(nextNodeID string) (map[string]any, error) {
-	payload := checkpointSnapshot{
-		State:      s,
-		NextNodeID: nextNodeID,
-	}
-	raw, err := json.Marshal(payload)
+func (c checkpointSnapshot) toMap() (map[string]any, error) {
+	raw, err := json.Marshal(c)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal checkpoint snapshot: %w", err)
 	}
@@ -59,18 +64,18 @@ func (s State) snapshot(nextNodeID string) (map[string]any, error) {
 	return out, nil
 }
 
-func restoreStateFromCheckpoint(raw map[string]any) (State, string, error) {
+func restoreCheckpoint(raw map[string]any) (checkpointSnapshot, error) {
 	if len(raw) == 0 {
-		return State{}, "", fmt.Errorf("checkpoint state is empty")
+		return checkpointSnapshot{}, fmt.Errorf("checkpoint state is empty")
 	}
 	payloadRaw, err := json.Marshal(raw)
 	if err != nil {
-		return State{}, "", fmt.Errorf("failed to marshal checkpoint state: %w", err)
+		return checkpointSnapshot{}, fmt.Errorf("failed to marshal checkpoint state: %w", err)
 	}
 	var snapshot checkpointSnapshot
 	if err := json.Unmarshal(payloadRaw, &snapshot); err != nil {
-		return State{}, "", fmt.Errorf("failed to decode checkpoint state: %w", err)
+		return checkpointSnapshot{}, fmt.Errorf("failed to decode checkpoint state: %w", err)
 	}
 	snapshot.State.ensureData()
-	return snapshot.State, snapshot.NextNodeID, nil
+	return snapshot, nil
 }
@@ -0,0 +1,140 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/delivery"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// SubgraphNode runs another graph as a child run. The child's input is
+// Data[InputKey], or the parent's input when InputKey is empty, and it
+// starts with the parent Data keys in Inputs copied to the child keys they
+// map to. When it completes, its output is stored in Output and
+// Data[OutputKey] ("subgraph_output" by default), its run ID in
+// Data["subgraphRunID"], and the child Data keys in Outputs are copied to
+// the parent keys they map to.
+//
+// Run by an Executor, the child is stored as a run of its own with the
+// parent run as its parent_run_id, and its run ID is kept in the parent's
+// checkpoints, so resuming the parent continues the child where it
+// stopped. An interrupt in the child interrupts the parent at this node;
+// resuming the parent with input resumes the child with it.
+type SubgraphNode struct {
+	Graph     *Graph
+	InputKey  string
+	Inputs    map[string]string
+	OutputKey string
+	Outputs   map[string]string
+}
+
+func NewSubgraphNode(g *Graph) *SubgraphNode {
+	return &SubgraphNode{Graph: g}
+}
+
+func (n *SubgraphNode) Execute(ctx context.Context, state *State) error {
+	return n.run(ctx, state, nil, "")
+}
+
+// run runs the child graph with the store and settings of parent, when
+// given, as run runID. A child run with checkpoints is resumed rather
+// than started.
+func (n *SubgraphNode) run(ctx context.Context, parentState *State, parent *Executor, runID string) error {
+	if n == nil || n.Graph == nil {
+		return fmt.Errorf("subgraph node graph is required")
+	}
+	if parentState == nil {
+		return fmt.Errorf("state is required")
+	}
+	opts := []ExecutorOption{WithSessionID(parentState.SessionID)}
+	if parent != nil {
+		opts = append(opts,
+			WithStore(parent.store),
+			WithObserver(parent.observer),
+			WithExecutionMode(parent.mode),
+			WithMaxConcurrency(parent.maxConcurrency),
+		)
+	}
+	child, err := NewExecutor(n.Graph, opts...)
+	if err != nil {
+		return fmt.Errorf("subgraph %q: %w", n.Graph.Name(), err)
+	}
+	var final *State
+	child.onComplete = func(s State) { final = &s }
+
+	input, resumed := ResumeInput(ctx)
+	ctx = delivery.WithParentRunID(withoutResume(ctx), parentState.RunID)
+
+	var result types.RunResult
+	stored := false
+	if runID != "" && child.store != nil {
+		// A child that failed before its first checkpoint starts over.
+		_, err := child.store.LoadLatestCheckpoint(ctx, runID)
+		if err != nil && !errors.Is(err, state.ErrNotFound) {
+			return err
+		}
+		stored = err == nil
+	}
+	if stored {
+		if !resumed {
+			input = nil
+		}
+		result, err = child.ResumeWithInput(ctx, runID, input)
+	} else {
+		result, err = child.start(ctx, runID, n.childInput(parentState), n.childData(parentState))
+	}
+	if err != nil {
+		var interrupted *InterruptedError
+		if errors.As(err, &interrupted) {
+			return Interrupt(interrupted.Interrupt.Prompt)
+		}
+		return fmt.Errorf("subgraph %q: %w", n.Graph.Name(), err)
+	}
+
+	parentState.ensureData()
+	parentState.Output = result.Output
+	parentState.Data[keyOr(n.OutputKey, "subgraph_output")] = result.Output
+	parentState.Data["subgraphRunID"] = result.RunID
+	if final != nil {
+		for from, to := range n.Outputs {
+			if v, ok := final.Data[from]; ok {
+				parentState.Data[to] = v
+			}
+		}
+	}
+	return nil
+}
+
+func (n *SubgraphNode) childInput(s *State) string {
+	if n.InputKey == "" {
+		return s.Input
+	}
+	switch v := s.Data[n.InputKey].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	default:
+		return fmt.Sprintf("%v", v)
+	}
+}
+
+func (n *SubgraphNode) childData(s *State) map[string]any {
+	data := map[string]any{}
+	for from, to := range n.Inputs {
+		if v, ok := s.Data[from]; ok {
+			data[to] = cloneValue(v)
+		}
+	}
+	return data
+}
+
+// withoutResume keeps the input a parent run was resumed with from the
+// nodes of a child run, which get their own.
+func withoutResume(ctx context.Context) context.Context {
+	ctx = context.WithValue(ctx, pendingResumeContextKey{}, (*pendingResume)(nil))
+	return context.WithValue(ctx, resumeInputContextKey{}, nil)
+}
@@ -0,0 +1,178 @@
+package graph
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+)
+
+func TestExecutor_SubgraphNode(t *testing.T) {
+	child := New("summarize").
+		AddNode("draft", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["summary"] = fmt.Sprintf("summary of %s in %v", s.Input, s.Data["lang"])
+			s.Output = "done"
+			return nil
+		})).
+		SetStart("draft")
+
+	sub := NewSubgraphNode(child)
+	sub.InputKey = "doc"
+	sub.Inputs = map[string]string{"language": "lang"}
+	sub.Outputs = map[string]string{"summary": "childSummary"}
+	parent := New("pipeline").
+		AddNode("fetch", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Data["doc"] = "report.pdf"
+			s.Data["language"] = "french"
+			return nil
+		})).
+		AddNode("summarize", sub).
+		AddNode("publish", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = fmt.Sprintf("published %v (%v)", s.Data["childSummary"], s.Data["subgraph_output"])
+			return nil
+		})).
+		SetStart("fetch").
+		AddEdge("fetch", "summarize", nil).
+		AddEdge("summarize", "publish", nil)
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(parent, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	result, err := executor.Run(context.Background(), "go")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if result.Output != "published summary of report.pdf in french (done)" {
+		t.Fatalf("unexpected output: %q", result.Output)
+	}
+
+	checkpoints, _ := store.ListCheckpoints(context.Background(), result.RunID, 0)
+	last, _ := restoreCheckpoint(checkpoints[len(checkpoints)-1].State)
+	childRunID, _ := last.State.Data["subgraphRunID"].(string)
+	run, err := store.LoadRun(context.Background(), childRunID)
+	if err != nil {
+		t.Fatalf("expected the child run to be stored: %v", err)
+	}
+	if run.Metadata["parent_run_id"] != result.RunID || run.Metadata["graph"] != "summarize" || run.Status != "completed" {
+		t.Fatalf("unexpected child run: %+v", run)
+	}
+
+	var kind, graphName string
+	for _, info := range parent.NodeInfos() {
+		if info.ID == "summarize" {
+			kind, graphName = info.Kind, info.Graph
+		}
+	}
+	if kind != "subgraph" || graphName != "summarize" {
+		t.Fatalf("unexpected node info: %s %s", kind, graphName)
+	}
+}
+
+func TestExecutor_SubgraphResume(t *testing.T) {
+	prepared, fail := 0, true
+	child := New("steps").
+		AddNode("prepare", NewToolNode(func(ctx context.Context, s *State) error {
+			prepared++
+			return nil
+		})).
+		AddNode("apply", NewToolNode(func(ctx context.Context, s *State) error {
+			if fail {
+				return errors.New("lock held")
+			}
+			s.Output = "applied"
+			return nil
+		})).
+		SetStart("prepare").
+		AddEdge("prepare", "apply", nil)
+	parent := New("outer").
+		AddNode("steps", NewSubgraphNode(child)).
+		SetStart("steps")
+
+	store := newMemoryStore()
+	executor, err := NewExecutor(parent, WithStore(store))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	_, err = executor.Run(context.Background(), "go")
+	if err == nil {
+		t.Fatalf("expected the child failure to fail the run")
+	}
+	runs, _ := store.ListRuns(context.Background(), state.ListRunsQuery{Status: "failed"})
+	var parentRunID string
+	for _, run := range runs {
+		if run.Metadata["graph"] == "outer" {
+			parentRunID = run.RunID
+		}
+	}
+	if parentRunID == "" {
+		t.Fatalf("expected the parent run to be stored as failed, got %+v", runs)
+	}
+
+	fail = false
+	result, err := executor.Resume(context.Background(), parentRunID)
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Output != "applied" || prepared != 1 {
+		t.Fatalf("expected the child to continue at apply, got %q with prepare run %d times", result.Output, prepared)
+	}
+	childRuns, _ := store.ListRuns(context.Background(), state.ListRunsQuery{})
+	children := 0
+	for _, run := range childRuns {
+		if run.Metadata["graph"] == "steps" {
+			children++
+		}
+	}
+	if children != 1 {
+		t.Fatalf("expected the same child run to be resumed, got %d child runs", children)
+	}
+}
+
+func TestExecutor_SubgraphInterrupt(t *testing.T) {
+	child := New("approval").
+		AddNode("confirm", NewInterruptNode("Ship it?")).
+		AddNode("ship", NewToolNode(func(ctx context.Context, s *State) error {
+			s.Output = fmt.Sprintf("shipped=%v", s.Data["ok"])
+			return nil
+		})).
+		SetStart("confirm").
+		AddEdge("confirm", "ship", nil)
+	parent := New("release").
+		AddNode("approve", NewSubgraphNode(child)).
+		SetStart("approve")
+
+	executor, err := NewExecutor(parent, WithStore(newMemoryStore()))
+	if err != nil {
+		t.Fatalf("failed to build executor: %v", err)
+	}
+	_, err = executor.Run(context.Background(), "v3")
+	var interrupted *InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Interrupt.NodeID != "approve" || interrupted.Interrupt.Prompt != "Ship it?" {
+		t.Fatalf("expected the parent to be interrupted at the subgraph node, got %v", err)
+	}
+	result, err := executor.ResumeWithInput(context.Background(), interrupted.RunID, map[string]any{"ok": true})
+	if err != nil {
+		t.Fatalf("resume failed: %v", err)
+	}
+	if result.Output != "shipped=true" {
+		t.Fatalf("unexpected output: %q", result.Output)
+	}
+}
+
+func TestGraphCompile_Subgraph(t *testing.T) {
+	g := New("loop")
+	g.AddNode("self", NewSubgraphNode(g)).SetStart("self")
+	if err := g.Compile(); err == nil {
+		t.Fatalf("expected an error for a graph embedding itself")
+	}
+
+	broken := New("broken").AddNode("a", NewToolNode(func(ctx context.Context, s *State) error { return nil }))
+	outer := New("outer").AddNode("sub", NewSubgraphNode(broken)).SetStart("sub")
+	if err := outer.Compile(); err == nil {
+		t.Fatalf("expected the child graph to be validated")
+	}
+}
@@ -16,7 +16,7 @@ type Builder struct{}
 
 func (Builder) Name() string { return Name }
 func (Builder) Description() string {
-	return "Map-reduce: split input → process parts → combine results."
+	return "Map-reduce: split input → process parts in parallel → combine results."
 }
 
 func (Builder) NewExecutor(runner graph.AgentRunner, store state.Store, sessionID string) (*graph.Executor, error) {
@@ -41,20 +41,35 @@ Request: %s`, strings.TrimSpace(s.Input)), nil
 		OutputKey: "subtasks",
 	})
 
-	// Map — process all sub-tasks sequentially (the agent handles them as a batch)
-	g.AddNode("map", &graph.AgentNode{
-		Runner: runner,
-		Input: func(s *graph.State) (string, error) {
-			s.EnsureData()
-			subtasks, _ := s.Data["subtasks"].(string)
-			return fmt.Sprintf(`Complete each of the following sub-tasks. For each one, provide a clear, thorough response. Label each response with its sub-task number.
+	// Parse — turn the numbered list into one item per sub-task
+	g.AddNode("parse", graph.NewToolNode(func(ctx context.Context, s *graph.State) error {
+		_ = ctx
+		s.EnsureData()
+		subtasks, _ := s.Data["subtasks"].(string)
+		items := parseSubtasks(subtasks)
+		if len(items) == 0 {
+			items = []string{strings.TrimSpace(s.Input)}
+		}
+		s.Data["subtask_list"] = items
+		return nil
+	}))
+
+	// Map — work on the sub-tasks concurrently, one agent run each
+	g.AddNode("map", &graph.MapNode{
+		ItemsKey:  "subtask_list",
+		ItemKey:   "subtask",
+		OutputKey: "mapped_results",
+		Body: &graph.AgentNode{
+			Runner: runner,
+			Input: func(s *graph.State) (string, error) {
+				subtask, _ := s.Data["subtask"].(string)
+				return fmt.Sprintf(`Complete the following sub-task of a larger request. Provide a clear, thorough response.
 
 Original request: %s
 
-Sub-tasks:
-%s`, strings.TrimSpace(s.Input), subtasks), nil
+Sub-task: %s`, strings.TrimSpace(s.Input), subtask), nil
+			},
 		},
-		OutputKey: "mapped_results",
 	})
 
 	// Reduce — combine all results into a coherent final output
@@ -62,7 +77,7 @@ Sub-tasks:
 		Runner: runner,
 		Input: func(s *graph.State) (string, error) {
 			s.EnsureData()
-			results, _ := s.Data["mapped_results"].(string)
+			results := formatResults(s.Data["subtask_list"], s.Data["mapped_results"])
 			return fmt.Sprintf(`Combine the following sub-task results into a single coherent, well-structured response. Remove redundancy, ensure consistency, and present the final answer clearly.
 
 Original request: %s
@@ -84,7 +99,8 @@ Sub-task results:
 	}))
 
 	g.SetStart("split")
-	g.AddEdge("split", "map", nil)
+	g.AddEdge("split", "parse", nil)
+	g.AddEdge("parse", "map", nil)
 	g.AddEdge("map", "reduce", nil)
 	g.AddEdge("reduce", "finalize", nil)
 
@@ -95,6 +111,43 @@ Sub-task results:
 	return graph.NewExecutor(g, opts...)
 }
 
+// parseSubtasks reads one sub-task per line of a numbered or bulleted list.
+func parseSubtasks(list string) []string {
+	var out []string
+	for _, line := range strings.Split(list, "\n") {
+		line = strings.TrimSpace(line)
+		line = strings.TrimLeft(line, "0123456789")
+		line = strings.TrimSpace(strings.TrimLeft(line, ".)-*"))
+		if line != "" {
+			out = append(out, line)
+		}
+	}
+	return out
+}
+
+func formatResults(subtasks, results any) string {
+	// Lists restored from a checkpoint are decoded as []any.
+	var tasks []any
+	switch v := subtasks.(type) {
+	case []string:
+		for _, task := range v {
+			tasks = append(tasks, task)
+		}
+	case []any:
+		tasks = v
+	}
+	outputs, _ := results.([]any)
+	var b strings.Builder
+	for i, out := range outputs {
+		var task any
+		if i < len(tasks) {
+			task = tasks[i]
+		}
+		fmt.Fprintf(&b, "### Sub-task %d: %s\n%v\n\n", i+1, task, out)
+	}
+	return strings.TrimSpace(b.String())
+}
+
 func init() {
 	workflow.MustRegister(Builder{})
 }
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	agentfw "github.com/PipeOpsHQ/agent-sdk-go/agent"
+	"github.com/PipeOpsHQ/agent-sdk-go/cost"
 	"github.com/PipeOpsHQ/agent-sdk-go/graph"
 	"github.com/PipeOpsHQ/agent-sdk-go/llm"
 	"github.com/PipeOpsHQ/agent-sdk-go/observe"
@@ -75,6 +76,39 @@ func buildAgent(provider llm.Provider, store state.Store, observer observe.Sink,
 		agentfw.WithStore(store),
 		agentfw.WithMaxIterations(maxIterationsFromEnv()),
 	}
+	pricing, err := cost.TableFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	budget, err := cost.BudgetFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	agentOpts = append(agentOpts, agentfw.WithPricing(pricing), agentfw.WithBudget(budget))
+	tok, err := providerfactory.TokenizerFromEnv(context.Background())
+	if err != nil {
+		return nil, err
+	}
+	agentOpts = append(agentOpts, agentfw.WithTokenizer(tok))
+	outputBudget, err := agentfw.ToolOutputBudgetFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if outputBudget != nil {
+		agentOpts = append(agentOpts, agentfw.WithToolOutputBudget(*outputBudget))
+	}
+	approval, err := agentfw.ApprovalPolicyFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	if approval != nil {
+		// Without a store a suspended run could never be resumed, so refuse
+		// to run rather than execute the tools unattended.
+		if store == nil {
+			return nil, fmt.Errorf("tool approval is configured but this command runs without a state store")
+		}
+		agentOpts = append(agentOpts, agentfw.WithToolApproval(*approval))
+	}
 	if len(opts.conversation) > 0 {
 		agentOpts = append(agentOpts, agentfw.WithConversationHistory(opts.conversation))
 	}
@@ -0,0 +1,258 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	agentfw "github.com/PipeOpsHQ/agent-sdk-go/agent"
+	devuiapi "github.com/PipeOpsHQ/agent-sdk-go/devui/api"
+	providerfactory "github.com/PipeOpsHQ/agent-sdk-go/providers/factory"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	statefactory "github.com/PipeOpsHQ/agent-sdk-go/state/factory"
+)
+
+// Run metadata keys holding the agent configuration of a suspended run, so
+// it can be rebuilt to resume. "tools" and "workflow" match the keys
+// distributed workers record.
+const (
+	resumeToolsKey        = "tools"
+	resumeWorkflowKey     = "workflow"
+	resumeSystemPromptKey = "system_prompt"
+)
+
+func runApprovalsCLI(ctx context.Context, args []string) {
+	var (
+		callID     string
+		editedArgs string
+		reason     string
+		positional []string
+	)
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--call="):
+			callID = strings.TrimSpace(strings.TrimPrefix(arg, "--call="))
+		case strings.HasPrefix(arg, "--args="):
+			editedArgs = strings.TrimPrefix(arg, "--args=")
+		case strings.HasPrefix(arg, "--reason="):
+			reason = strings.TrimSpace(strings.TrimPrefix(arg, "--reason="))
+		default:
+			positional = append(positional, arg)
+		}
+	}
+
+	if len(positional) == 0 {
+		printApprovalsUsage()
+		os.Exit(1)
+	}
+
+	store, err := statefactory.FromEnv(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeStore(store)
+
+	command := positional[0]
+	if command == "list" || command == "ls" {
+		listApprovals(ctx, store)
+		return
+	}
+	if len(positional) < 2 {
+		printApprovalsUsage()
+		os.Exit(1)
+	}
+	runID := strings.TrimSpace(positional[1])
+	run, err := store.LoadRun(ctx, runID)
+	if err != nil {
+		log.Fatalf("load run failed: %v", err)
+	}
+
+	var action agentfw.ApprovalAction
+	switch command {
+	case "show":
+		printPendingApprovals(run)
+		return
+	case "approve":
+		action = agentfw.ApprovalApprove
+	case "reject":
+		action = agentfw.ApprovalReject
+	case "edit":
+		action = agentfw.ApprovalEdit
+		if callID == "" || strings.TrimSpace(editedArgs) == "" {
+			log.Fatal("edit requires --call=ID and --args=JSON")
+		}
+	default:
+		log.Fatalf("unknown approvals command %q", command)
+	}
+
+	// Without --call the decision applies to every pending call.
+	pending := agentfw.PendingApprovals(run)
+	decidedBy := strings.TrimSpace(os.Getenv("USER"))
+	var decisions []agentfw.ApprovalDecision
+	for _, p := range pending {
+		if callID != "" && p.ToolCallID != callID {
+			continue
+		}
+		d := agentfw.ApprovalDecision{ToolCallID: p.ToolCallID, Action: action, Reason: reason, DecidedBy: decidedBy}
+		if action == agentfw.ApprovalEdit {
+			d.Arguments = json.RawMessage(editedArgs)
+		}
+		decisions = append(decisions, d)
+	}
+	if len(decisions) == 0 {
+		log.Fatalf("run %s has no pending tool call %q", runID, callID)
+	}
+	remaining, err := agentfw.RecordApprovalDecisions(ctx, store, runID, decisions...)
+	if err != nil {
+		log.Fatalf("recording decision failed: %v", err)
+	}
+	if len(remaining) > 0 {
+		fmt.Printf("recorded; %d tool call(s) still pending\n", len(remaining))
+		return
+	}
+
+	observer, closeObserver := buildObserver()
+	defer closeObserver()
+	runner := &localPlaygroundRunner{store: store, observer: observer}
+	resp, err := runner.Resume(ctx, runID)
+	if err != nil {
+		log.Fatalf("resume failed: %v", err)
+	}
+	if resp.Status == agentfw.RunStatusAwaitingApproval {
+		fmt.Printf("run %s is awaiting approval again; see: approvals show %s\n", runID, runID)
+		return
+	}
+	fmt.Println(resp.Output)
+}
+
+func listApprovals(ctx context.Context, store state.Store) {
+	runs, err := store.ListRuns(ctx, state.ListRunsQuery{Status: agentfw.RunStatusAwaitingApproval, Limit: 100})
+	if err != nil {
+		log.Fatalf("list approvals failed: %v", err)
+	}
+	for _, run := range runs {
+		updated := "-"
+		if run.UpdatedAt != nil {
+			updated = run.UpdatedAt.UTC().Format(time.RFC3339)
+		}
+		names := []string{}
+		for _, p := range agentfw.PendingApprovals(run) {
+			names = append(names, p.ToolName)
+		}
+		fmt.Printf("%s\t%s\t%s\t%s\n", run.RunID, run.SessionID, strings.Join(names, ","), updated)
+	}
+}
+
+func printPendingApprovals(run state.RunRecord) {
+	fmt.Printf("run %s (%s)\n", run.RunID, run.Status)
+	for _, p := range agentfw.PendingApprovals(run) {
+		fmt.Printf("  %s\t%s\t%s\n    %s\n", p.ToolCallID, p.ToolName, p.Reason, string(p.Arguments))
+	}
+}
+
+func printApprovalsUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  go run ./framework approvals list")
+	fmt.Println("  go run ./framework approvals show <run-id>")
+	fmt.Println("  go run ./framework approvals approve <run-id> [--call=ID] [--reason=TEXT]")
+	fmt.Println("  go run ./framework approvals reject <run-id> [--call=ID] [--reason=TEXT]")
+	fmt.Println("  go run ./framework approvals edit <run-id> --call=ID --args='{\"key\":\"value\"}' [--reason=TEXT]")
+}
+
+// Resume continues a run suspended for tool approval, rebuilding its agent
+// from the configuration recorded when it was suspended.
+func (r *localPlaygroundRunner) Resume(ctx context.Context, runID string) (devuiapi.PlaygroundResponse, error) {
+	if r.store == nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("state store is required to resume runs")
+	}
+	run, err := r.store.LoadRun(ctx, runID)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	opts := resumeOptions(run)
+	if opts.workflow != "" {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("run %s used workflow %q; only direct agent runs can be resumed", runID, opts.workflow)
+	}
+	provider, err := providerfactory.FromEnv(ctx)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("provider setup failed: %w", err)
+	}
+	agent, err := buildAgent(provider, r.store, r.observer, opts)
+	if err != nil {
+		return devuiapi.PlaygroundResponse{}, fmt.Errorf("agent create failed: %w", err)
+	}
+	result, err := agent.ResumeRun(ctx, runID)
+	if err != nil {
+		if resp, ok := r.awaitingApproval(ctx, err, opts, provider.Name()); ok {
+			return resp, nil
+		}
+		return devuiapi.PlaygroundResponse{}, err
+	}
+	return devuiapi.PlaygroundResponse{
+		Status:    "completed",
+		Output:    result.Output,
+		RunID:     result.RunID,
+		SessionID: result.SessionID,
+		Provider:  provider.Name(),
+	}, nil
+}
+
+// awaitingApproval turns an approval error into a response, recording the
+// run's configuration so it can be resumed later.
+func (r *localPlaygroundRunner) awaitingApproval(ctx context.Context, err error, opts cliOptions, providerName string) (devuiapi.PlaygroundResponse, bool) {
+	var approvalErr *agentfw.ApprovalRequiredError
+	if !errors.As(err, &approvalErr) {
+		return devuiapi.PlaygroundResponse{}, false
+	}
+	if saveErr := saveResumeOptions(ctx, r.store, approvalErr.RunID, opts); saveErr != nil {
+		log.Printf("failed to record resume options for %s: %v", approvalErr.RunID, saveErr)
+	}
+	return devuiapi.PlaygroundResponse{
+		Status:    agentfw.RunStatusAwaitingApproval,
+		Output:    approvalErr.Error(),
+		RunID:     approvalErr.RunID,
+		SessionID: approvalErr.SessionID,
+		Provider:  providerName,
+	}, true
+}
+
+func saveResumeOptions(ctx context.Context, store state.Store, runID string, opts cliOptions) error {
+	if store == nil {
+		return nil
+	}
+	run, err := store.LoadRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if run.Metadata == nil {
+		run.Metadata = map[string]any{}
+	}
+	run.Metadata[resumeToolsKey] = opts.tools
+	run.Metadata[resumeWorkflowKey] = opts.workflow
+	run.Metadata[resumeSystemPromptKey] = opts.systemPrompt
+	return store.SaveRun(ctx, run)
+}
+
+// resumeOptions reads the configuration saveResumeOptions recorded. Stores
+// that round-trip metadata through JSON return the tools as []any.
+func resumeOptions(run state.RunRecord) cliOptions {
+	opts := cliOptions{sessionID: run.SessionID}
+	switch tools := run.Metadata[resumeToolsKey].(type) {
+	case []string:
+		opts.tools = append([]string(nil), tools...)
+	case []any:
+		for _, t := range tools {
+			if name, ok := t.(string); ok {
+				opts.tools = append(opts.tools, name)
+			}
+		}
+	}
+	opts.workflow, _ = run.Metadata[resumeWorkflowKey].(string)
+	opts.systemPrompt, _ = run.Metadata[resumeSystemPromptKey].(string)
+	return opts
+}
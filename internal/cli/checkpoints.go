@@ -0,0 +1,181 @@
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/graph"
+	"github.com/PipeOpsHQ/agent-sdk-go/state"
+	statefactory "github.com/PipeOpsHQ/agent-sdk-go/state/factory"
+)
+
+func runCheckpointsCLI(ctx context.Context, args []string) {
+	var (
+		toRunID    string
+		positional []string
+	)
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--to-run="):
+			toRunID = strings.TrimSpace(strings.TrimPrefix(arg, "--to-run="))
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) < 2 {
+		printCheckpointsUsage()
+		os.Exit(1)
+	}
+
+	store, err := statefactory.FromEnv(ctx)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer closeStore(store)
+
+	runID := strings.TrimSpace(positional[1])
+	switch positional[0] {
+	case "list", "ls":
+		checkpoints, err := store.ListCheckpoints(ctx, runID, 1000)
+		if err != nil {
+			log.Fatalf("list checkpoints failed: %v", err)
+		}
+		for _, checkpoint := range checkpoints {
+			next, _ := checkpoint.State["nextNodeId"].(string)
+			if next == "" {
+				next = "-"
+			}
+			fmt.Printf("%d\t%s\t%s\t%s\n", checkpoint.Seq, checkpoint.NodeID, next, checkpoint.CreatedAt.UTC().Format(time.RFC3339))
+		}
+	case "show":
+		if len(positional) < 3 {
+			printCheckpointsUsage()
+			os.Exit(1)
+		}
+		checkpoint, err := state.LoadCheckpoint(ctx, store, runID, parseSeq(positional[2]))
+		if err != nil {
+			log.Fatalf("load checkpoint failed: %v", err)
+		}
+		printJSON(checkpoint)
+	case "diff":
+		if len(positional) < 4 {
+			printCheckpointsUsage()
+			os.Exit(1)
+		}
+		if toRunID == "" {
+			toRunID = runID
+		}
+		from, err := state.LoadCheckpoint(ctx, store, runID, parseSeq(positional[2]))
+		if err != nil {
+			log.Fatalf("load checkpoint failed: %v", err)
+		}
+		to, err := state.LoadCheckpoint(ctx, store, toRunID, parseSeq(positional[3]))
+		if err != nil {
+			log.Fatalf("load checkpoint failed: %v", err)
+		}
+		diff, err := graph.DiffCheckpoints(from, to)
+		if err != nil {
+			log.Fatalf("diff failed: %v", err)
+		}
+		for _, change := range diff.Changes {
+			switch change.Op {
+			case graph.ChangeAdded:
+				fmt.Printf("+ %s = %s\n", change.Path, compactJSON(change.After))
+			case graph.ChangeRemoved:
+				fmt.Printf("- %s = %s\n", change.Path, compactJSON(change.Before))
+			default:
+				fmt.Printf("~ %s: %s -> %s\n", change.Path, compactJSON(change.Before), compactJSON(change.After))
+			}
+		}
+	default:
+		log.Fatalf("unknown checkpoints command %q", positional[0])
+	}
+}
+
+func forkGraph(ctx context.Context, args []string) {
+	var (
+		data     map[string]any
+		nextNode string
+		rest     []string
+	)
+	for _, arg := range args {
+		switch {
+		case strings.HasPrefix(arg, "--data="):
+			if err := json.Unmarshal([]byte(strings.TrimPrefix(arg, "--data=")), &data); err != nil {
+				log.Fatalf("invalid --data: %v", err)
+			}
+		case strings.HasPrefix(arg, "--next="):
+			nextNode = strings.TrimSpace(strings.TrimPrefix(arg, "--next="))
+		default:
+			rest = append(rest, arg)
+		}
+	}
+	opts, positional := parseArgs(rest)
+	if len(positional) < 2 {
+		log.Fatal("usage: graph-fork [--workflow=name] [--tools=list] [--data=JSON] [--next=node] <run-id> <seq>")
+	}
+	runID := strings.TrimSpace(positional[0])
+	seq := parseSeq(positional[1])
+
+	provider, store := buildRuntimeDeps(ctx)
+	defer closeStore(store)
+	observer, closeObserver := buildObserver()
+	defer closeObserver()
+
+	if opts.workflow == "" {
+		// Fork with the run's own graph unless told otherwise.
+		if run, err := store.LoadRun(ctx, runID); err == nil {
+			opts.workflow, _ = run.Metadata["graph"].(string)
+		}
+	}
+	agent, err := buildAgent(provider, nil, observer, opts)
+	if err != nil {
+		log.Fatalf("failed to create agent: %v", err)
+	}
+	exec, err := buildExecutor(agent, store, observer, opts)
+	if err != nil {
+		log.Fatalf("failed to create graph executor: %v", err)
+	}
+	result, err := exec.Fork(ctx, runID, seq, graph.WithForkData(data), graph.WithForkNextNode(nextNode))
+	if err != nil {
+		log.Fatalf("graph fork failed: %v", err)
+	}
+	fmt.Printf("forked run %s\n%s\n", result.RunID, result.Output)
+}
+
+func parseSeq(raw string) int {
+	seq, err := strconv.Atoi(strings.TrimSpace(raw))
+	if err != nil {
+		log.Fatalf("invalid checkpoint seq %q", raw)
+	}
+	return seq
+}
+
+func printJSON(v any) {
+	raw, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		log.Fatalf("encode failed: %v", err)
+	}
+	fmt.Println(string(raw))
+}
+
+func compactJSON(v any) string {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(raw)
+}
+
+func printCheckpointsUsage() {
+	fmt.Println("Usage:")
+	fmt.Println("  go run ./framework checkpoints list <run-id>")
+	fmt.Println("  go run ./framework checkpoints show <run-id> <seq>")
+	fmt.Println("  go run ./framework checkpoints diff <run-id> <from-seq> <to-seq> [--to-run=RUN-ID]")
+}
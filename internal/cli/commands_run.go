@@ -30,6 +30,11 @@ func runSingle(ctx context.Context, args []string) {
 	}
 	output, err := agent.Run(ctx, input)
 	if err != nil {
+		runner := &localPlaygroundRunner{store: store, observer: observer}
+		if resp, ok := runner.awaitingApproval(ctx, err, opts, provider.Name()); ok {
+			fmt.Printf("%s\nreview with: approvals show %s\n", resp.Output, resp.RunID)
+			return
+		}
 		log.Fatalf("run failed: %v", err)
 	}
 	fmt.Println(output)
@@ -11,6 +11,7 @@ import (
 
 	evalfw "github.com/PipeOpsHQ/agent-sdk-go/eval"
 	"github.com/PipeOpsHQ/agent-sdk-go/observe"
+	providerfactory "github.com/PipeOpsHQ/agent-sdk-go/providers/factory"
 )
 
 type evalCLIOptions struct {
@@ -58,7 +59,11 @@ func runEvalCLI(ctx context.Context, args []string) {
 		judge = j
 	}
 
-	runner, err := evalfw.NewRunner(evalfw.RunnerConfig{Agent: agent, Judge: judge})
+	tok, err := providerfactory.TokenizerFromEnv(ctx)
+	if err != nil {
+		log.Fatalf("failed to create tokenizer: %v", err)
+	}
+	runner, err := evalfw.NewRunner(evalfw.RunnerConfig{Agent: agent, Judge: judge, Tokenizer: tok})
 	if err != nil {
 		log.Fatalf("failed to create eval runner: %v", err)
 	}
@@ -159,6 +159,9 @@ func (r *localPlaygroundRunner) Run(ctx context.Context, req devuiapi.Playground
 		if parentRunID != "" {
 			turnCtx = delivery.WithParentRunID(turnCtx, parentRunID)
 		}
+		if turn == 0 && strings.TrimSpace(req.RunID) != "" {
+			turnCtx = agentfw.ContextWithRunID(turnCtx, req.RunID)
+		}
 
 		if strings.TrimSpace(turnOpts.workflow) == "" {
 			result, err = agent.RunDetailed(turnCtx, currentInput)
@@ -170,6 +173,13 @@ func (r *localPlaygroundRunner) Run(ctx context.Context, req devuiapi.Playground
 			result, err = exec.Run(turnCtx, currentInput)
 		}
 		if err != nil {
+			if strings.TrimSpace(turnOpts.workflow) == "" {
+				if resp, ok := r.awaitingApproval(ctx, err, turnOpts, provider.Name()); ok {
+					resp.AppliedSkills = appliedSkills
+					resp.ReplyTo = req.ReplyTo
+					return resp, nil
+				}
+			}
 			return devuiapi.PlaygroundResponse{}, err
 		}
 
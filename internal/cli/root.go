@@ -21,8 +21,14 @@ func Run(ctx context.Context, args []string) {
 		runGraph(ctx, args[1:])
 	case "graph-resume":
 		resumeGraph(ctx, args[1:])
+	case "graph-fork":
+		forkGraph(ctx, args[1:])
+	case "checkpoints":
+		runCheckpointsCLI(ctx, args[1:])
 	case "sessions":
 		listSessions(ctx, args[1:])
+	case "approvals":
+		runApprovalsCLI(ctx, args[1:])
 	case "ui":
 		runUI(ctx, args[1:], false)
 	case "ui-api":
@@ -13,6 +13,7 @@ import (
 	"strings"
 	"syscall"
 
+	agentfw "github.com/PipeOpsHQ/agent-sdk-go/agent"
 	devuiapi "github.com/PipeOpsHQ/agent-sdk-go/devui/api"
 	devuiauth "github.com/PipeOpsHQ/agent-sdk-go/devui/auth"
 	authsqlite "github.com/PipeOpsHQ/agent-sdk-go/devui/auth/sqlite"
@@ -134,16 +135,29 @@ func runUI(ctx context.Context, args []string, remoteMode bool) {
 
 	if rtComponents != nil {
 		processor := func(ctx context.Context, task queue.Task) (distributed.ProcessResult, error) {
-			resp, err := playground.Run(ctx, devuiapi.PlaygroundRequest{
-				Input:        task.Input,
-				Workflow:     task.Workflow,
-				Tools:        task.Tools,
-				SystemPrompt: task.SystemPrompt,
-			})
+			var (
+				resp devuiapi.PlaygroundResponse
+				err  error
+			)
+			if distributed.IsResume(task) {
+				resp, err = playground.Resume(ctx, task.RunID)
+			} else {
+				resp, err = playground.Run(ctx, devuiapi.PlaygroundRequest{
+					RunID:        task.RunID,
+					Input:        task.Input,
+					Workflow:     task.Workflow,
+					Tools:        task.Tools,
+					SystemPrompt: task.SystemPrompt,
+				})
+			}
 			if err != nil {
 				return distributed.ProcessResult{}, err
 			}
-			return distributed.ProcessResult{Output: resp.Output, Provider: resp.Provider}, nil
+			result := distributed.ProcessResult{Output: resp.Output, Provider: resp.Provider}
+			if resp.Status == agentfw.RunStatusAwaitingApproval {
+				result.Status = resp.Status
+			}
+			return result, nil
 		}
 
 		inlineWorker, wErr := distributed.NewWorker(
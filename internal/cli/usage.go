@@ -14,7 +14,10 @@ func printUsage() {
 	fmt.Println("  go run ./framework run [--tools=@default] -- \"your prompt\"")
 	fmt.Println("  go run ./framework graph-run [--workflow=basic] [--tools=@default] -- \"your prompt\"")
 	fmt.Println("  go run ./framework graph-resume [--workflow=basic] [--tools=@default] <run-id>")
+	fmt.Println("  go run ./framework graph-fork [--workflow=basic] [--data=JSON] [--next=NODE] <run-id> <seq>")
+	fmt.Println("  go run ./framework checkpoints list|show|diff <run-id> [seq] [to-seq] [--to-run=RUN-ID]")
 	fmt.Println("  go run ./framework sessions [session-id]")
+	fmt.Println("  go run ./framework approvals list|show|approve|reject|edit [run-id] [--call=ID] [--args=JSON] [--reason=TEXT]")
 	fmt.Println("  go run ./framework ui [--ui-addr=127.0.0.1:7070] [--ui-open=true]")
 	fmt.Println("  go run ./framework ui-api [--ui-addr=0.0.0.0:7070]")
 	fmt.Println("  go run ./framework ui-admin create-key [--role=admin]")
@@ -43,4 +46,7 @@ func printUsage() {
 	fmt.Println("  AGENT_PROMPT_TEMPLATE        Prompt template name")
 	fmt.Println("  AGENT_TOOLS                  Tool selection (comma-separated)")
 	fmt.Println("  AGENT_WORKFLOW               Graph workflow name")
+	fmt.Println("  AGENT_APPROVAL_TOOLS         Tools that need human approval (comma-separated)")
+	fmt.Println("  AGENT_APPROVAL_RULES         tool=regexp pairs over tool arguments (semicolon-separated)")
+	fmt.Println("  AGENT_APPROVAL_MIN_RISK      Approve tools at or above this risk (low, medium, high)")
 }
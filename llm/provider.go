@@ -13,6 +13,38 @@ type Capabilities struct {
 	Tools            bool
 	Streaming        bool
 	StructuredOutput bool
+
+	// Sampling and generation controls honoured from types.Request.
+	Temperature       bool
+	TopP              bool
+	TopK              bool
+	StopSequences     bool
+	Seed              bool
+	PresencePenalty   bool
+	FrequencyPenalty  bool
+	ToolChoice        bool
+	ParallelToolCalls bool
+}
+
+// Unsupported returns the names of generation controls set on req that a
+// provider with these capabilities ignores.
+func (c Capabilities) Unsupported(req types.Request) []string {
+	var out []string
+	check := func(set, supported bool, name string) {
+		if set && !supported {
+			out = append(out, name)
+		}
+	}
+	check(req.Temperature != nil, c.Temperature, "temperature")
+	check(req.TopP != nil, c.TopP, "topP")
+	check(req.TopK != nil, c.TopK, "topK")
+	check(len(req.StopSequences) > 0, c.StopSequences, "stopSequences")
+	check(req.Seed != nil, c.Seed, "seed")
+	check(req.PresencePenalty != nil, c.PresencePenalty, "presencePenalty")
+	check(req.FrequencyPenalty != nil, c.FrequencyPenalty, "frequencyPenalty")
+	check(req.ToolChoice != nil, c.ToolChoice, "toolChoice")
+	check(req.ParallelToolCalls != nil, c.ParallelToolCalls, "parallelToolCalls")
+	return out
 }
 
 type Provider interface {
@@ -26,3 +58,32 @@ type Provider interface {
 type StreamProvider interface {
 	GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error)
 }
+
+// EmitResponse replays a complete response to onChunk as the chunks a
+// streaming provider would have emitted: reasoning, text, a delta for each
+// tool call and usage. It leaves the final done chunk to the caller.
+func EmitResponse(resp types.Response, onChunk func(types.StreamChunk) error) error {
+	if resp.Message.Reasoning != "" {
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventReasoning, Reasoning: resp.Message.Reasoning}); err != nil {
+			return err
+		}
+	}
+	if resp.Message.Content != "" {
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventText, Text: resp.Message.Content}); err != nil {
+			return err
+		}
+	}
+	for i, call := range resp.Message.ToolCalls {
+		delta := &types.ToolCallDelta{Index: i, ID: call.ID, Name: call.Name, ArgumentsDelta: string(call.Arguments)}
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventToolCallDelta, ToolCallDelta: delta}); err != nil {
+			return err
+		}
+	}
+	if resp.Usage != nil {
+		usage := *resp.Usage
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventUsage, Usage: &usage}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package observe
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -26,15 +27,30 @@ func FromRuntimeEvent(in types.Event) Event {
 	if in.ToolCallID != "" {
 		e.Attributes["toolCallId"] = in.ToolCallID
 	}
+	if in.Model != "" {
+		e.Attributes["model"] = in.Model
+	}
+	if in.Usage != nil {
+		e.Attributes["inputTokens"] = in.Usage.InputTokens
+		e.Attributes["outputTokens"] = in.Usage.OutputTokens
+		e.Attributes["totalTokens"] = in.Usage.TotalTokens
+		e.Attributes["costUsd"] = in.Usage.CostUSD
+	}
 
 	eventType := string(in.Type)
 	switch {
 	case strings.Contains(eventType, "before_generate"), strings.Contains(eventType, "after_generate"):
 		e.Kind = KindProvider
-	case strings.Contains(eventType, "before_tool"), strings.Contains(eventType, "after_tool"):
+	case strings.Contains(eventType, "before_tool"), strings.Contains(eventType, "after_tool"),
+		strings.Contains(eventType, "tool_validation"):
 		e.Kind = KindTool
 	case strings.Contains(eventType, "graph.node"):
 		e.Kind = KindGraph
+	case isRunControlEvent(in.Type):
+		// Approvals, pauses and steering happen within a run; counting them
+		// as run events would skew the run metrics.
+		e.Kind = KindCustom
+		e.Name = eventType
 	case strings.HasPrefix(eventType, "run."):
 		e.Kind = KindRun
 	default:
@@ -51,7 +67,7 @@ func FromRuntimeEvent(in types.Event) Event {
 	if strings.Contains(string(in.Type), "after") || strings.Contains(string(in.Type), "completed") {
 		e.Status = StatusCompleted
 	}
-	if strings.Contains(string(in.Type), "failed") {
+	if strings.Contains(string(in.Type), "failed") || in.Type == types.EventRunCanceled {
 		e.Status = StatusFailed
 	}
 	if e.Status == "" {
@@ -64,12 +80,46 @@ func FromRuntimeEvent(in types.Event) Event {
 	return e
 }
 
+type parentSpanContextKey struct{}
+
+// WithParentSpanID nests the top-level spans of runs started with ctx under
+// spanID, such as the span of the tool call that started a sub-run.
+func WithParentSpanID(ctx context.Context, spanID string) context.Context {
+	return context.WithValue(ctx, parentSpanContextKey{}, spanID)
+}
+
+// FromRuntimeEventContext is FromRuntimeEvent for an event of a run started
+// with ctx, which may be nested under a parent span.
+func FromRuntimeEventContext(ctx context.Context, in types.Event) Event {
+	e := FromRuntimeEvent(in)
+	if e.ParentSpanID == "" && e.RunID != "" {
+		e.ParentSpanID, _ = ctx.Value(parentSpanContextKey{}).(string)
+	}
+	return e
+}
+
+// ToolSpanID is the span ID of a tool call in a run's trace.
+func ToolSpanID(runID string, iteration int, toolCallID string) string {
+	return fmt.Sprintf("%s:tool:%d:%s", runID, iteration, toolCallID)
+}
+
+func isRunControlEvent(t types.EventType) bool {
+	switch t {
+	case types.EventApprovalRequested, types.EventApprovalResolved,
+		types.EventRunPaused, types.EventRunResumed, types.EventRunSteered,
+		types.EventRunInterrupted:
+		return true
+	default:
+		return false
+	}
+}
+
 func spanIDForRuntimeEvent(in types.Event) string {
 	if in.RunID == "" {
 		return ""
 	}
 	if in.ToolCallID != "" {
-		return fmt.Sprintf("%s:tool:%d:%s", in.RunID, in.Iteration, in.ToolCallID)
+		return ToolSpanID(in.RunID, in.Iteration, in.ToolCallID)
 	}
 	if in.ToolName != "" {
 		return fmt.Sprintf("%s:node:%s", in.RunID, in.ToolName)
@@ -12,6 +12,7 @@ const (
 	KindTool       Kind = "tool"
 	KindGraph      Kind = "graph"
 	KindCheckpoint Kind = "checkpoint"
+	KindCache      Kind = "cache"
 	KindCustom     Kind = "custom"
 )
 
@@ -13,6 +13,7 @@ import (
 
 	"github.com/PipeOpsHQ/agent-sdk-go/observe"
 	observestore "github.com/PipeOpsHQ/agent-sdk-go/observe/store"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
 	"github.com/google/uuid"
 	_ "modernc.org/sqlite"
 )
@@ -239,10 +240,70 @@ func (s *Store) AggregateMetrics(ctx context.Context, query observestore.Metrics
 	if metrics.ToolFailures, err = counter(observe.KindTool, observe.StatusFailed); err != nil {
 		return observestore.MetricsSummary{}, fmt.Errorf("metrics tool failures: %w", err)
 	}
+	if metrics.ToolValidationFailures, err = s.countEventType(ctx, where, args, string(types.EventToolValidationFailed)); err != nil {
+		return observestore.MetricsSummary{}, fmt.Errorf("metrics tool validation failures: %w", err)
+	}
+	if metrics.Spend, err = s.spendByModel(ctx, where, args); err != nil {
+		return observestore.MetricsSummary{}, fmt.Errorf("metrics spend: %w", err)
+	}
+	for _, spend := range metrics.Spend {
+		metrics.TotalCostUSD += spend.CostUSD
+	}
 
 	return metrics, nil
 }
 
+func (s *Store) countEventType(ctx context.Context, where string, args []any, eventType string) (int64, error) {
+	filter := " WHERE"
+	if where != "" {
+		filter = where + " AND"
+	}
+	q := "SELECT COUNT(*) FROM trace_events" + filter + " json_extract(attributes, '$.eventType') = ?"
+	qArgs := append([]any{}, args...)
+	qArgs = append(qArgs, eventType)
+	var n int64
+	if err := s.db.QueryRowContext(ctx, q, qArgs...).Scan(&n); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+// spendByModel groups completed provider calls that reported usage by
+// provider and model, most expensive first.
+func (s *Store) spendByModel(ctx context.Context, where string, args []any) ([]observestore.SpendSummary, error) {
+	filter := " WHERE"
+	if where != "" {
+		filter = where + " AND"
+	}
+	q := `
+SELECT COALESCE(provider, ''),
+       COALESCE(json_extract(attributes, '$.model'), ''),
+       COUNT(*),
+       COALESCE(SUM(json_extract(attributes, '$.inputTokens')), 0),
+       COALESCE(SUM(json_extract(attributes, '$.outputTokens')), 0),
+       COALESCE(SUM(json_extract(attributes, '$.costUsd')), 0)
+FROM trace_events` + filter + ` kind = ? AND status = ? AND json_extract(attributes, '$.totalTokens') IS NOT NULL
+GROUP BY 1, 2
+ORDER BY 6 DESC, 1, 2`
+	qArgs := append([]any{}, args...)
+	qArgs = append(qArgs, string(observe.KindProvider), string(observe.StatusCompleted))
+	rows, err := s.db.QueryContext(ctx, q, qArgs...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []observestore.SpendSummary
+	for rows.Next() {
+		var item observestore.SpendSummary
+		if err := rows.Scan(&item.Provider, &item.Model, &item.Calls, &item.InputTokens, &item.OutputTokens, &item.CostUSD); err != nil {
+			return nil, err
+		}
+		out = append(out, item)
+	}
+	return out, rows.Err()
+}
+
 func (s *Store) Close() error {
 	if s == nil || s.db == nil {
 		return nil
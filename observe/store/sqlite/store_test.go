@@ -8,6 +8,7 @@ import (
 
 	"github.com/PipeOpsHQ/agent-sdk-go/observe"
 	observestore "github.com/PipeOpsHQ/agent-sdk-go/observe/store"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
 )
 
 func TestStore_SaveListAndMetrics(t *testing.T) {
@@ -24,7 +25,8 @@ func TestStore_SaveListAndMetrics(t *testing.T) {
 		{RunID: "r1", SessionID: "s1", Kind: observe.KindRun, Status: observe.StatusStarted, Timestamp: now},
 		{RunID: "r1", SessionID: "s1", Kind: observe.KindProvider, Status: observe.StatusCompleted, Timestamp: now.Add(time.Millisecond)},
 		{RunID: "r1", SessionID: "s1", Kind: observe.KindTool, Status: observe.StatusCompleted, Timestamp: now.Add(2 * time.Millisecond)},
-		{RunID: "r1", SessionID: "s1", Kind: observe.KindRun, Status: observe.StatusCompleted, Timestamp: now.Add(3 * time.Millisecond)},
+		{RunID: "r1", SessionID: "s1", Kind: observe.KindTool, Status: observe.StatusFailed, Attributes: map[string]any{"eventType": string(types.EventToolValidationFailed)}, Timestamp: now.Add(3 * time.Millisecond)},
+		{RunID: "r1", SessionID: "s1", Kind: observe.KindRun, Status: observe.StatusCompleted, Timestamp: now.Add(4 * time.Millisecond)},
 	}
 	for _, in := range inputs {
 		if err := store.SaveEvent(ctx, in); err != nil {
@@ -44,7 +46,52 @@ func TestStore_SaveListAndMetrics(t *testing.T) {
 	if err != nil {
 		t.Fatalf("aggregate metrics: %v", err)
 	}
-	if metrics.RunsStarted != 1 || metrics.RunsCompleted != 1 || metrics.ToolCalls != 1 || metrics.ProviderCalls != 1 {
+	if metrics.RunsStarted != 1 || metrics.RunsCompleted != 1 || metrics.ToolCalls != 1 || metrics.ProviderCalls != 1 ||
+		metrics.ToolFailures != 1 || metrics.ToolValidationFailures != 1 {
 		t.Fatalf("unexpected metrics: %+v", metrics)
 	}
 }
+
+func TestStore_AggregateMetricsSpend(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "trace.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	usage := func(model string, in, out int, cost float64) map[string]any {
+		return map[string]any{"model": model, "inputTokens": in, "outputTokens": out, "totalTokens": in + out, "costUsd": cost}
+	}
+	inputs := []observe.Event{
+		{RunID: "r1", Kind: observe.KindProvider, Status: observe.StatusCompleted, Provider: "openai", Attributes: usage("gpt-4o", 100, 10, 0.25), Timestamp: now},
+		{RunID: "r1", Kind: observe.KindProvider, Status: observe.StatusCompleted, Provider: "openai", Attributes: usage("gpt-4o", 200, 20, 0.5), Timestamp: now},
+		{RunID: "r2", Kind: observe.KindProvider, Status: observe.StatusCompleted, Provider: "anthropic", Attributes: usage("claude-3-5-haiku", 50, 5, 1), Timestamp: now},
+		{RunID: "r2", Kind: observe.KindProvider, Status: observe.StatusStarted, Provider: "anthropic", Timestamp: now},
+		{RunID: "r2", Kind: observe.KindRun, Status: observe.StatusCompleted, Attributes: usage("", 50, 5, 1), Timestamp: now},
+	}
+	for _, in := range inputs {
+		if err := store.SaveEvent(ctx, in); err != nil {
+			t.Fatalf("save event: %v", err)
+		}
+	}
+
+	metrics, err := store.AggregateMetrics(ctx, observestore.MetricsQuery{})
+	if err != nil {
+		t.Fatalf("aggregate metrics: %v", err)
+	}
+	if metrics.TotalCostUSD != 1.75 {
+		t.Fatalf("expected total cost 1.75, got %v", metrics.TotalCostUSD)
+	}
+	if len(metrics.Spend) != 2 {
+		t.Fatalf("expected 2 spend rows, got %+v", metrics.Spend)
+	}
+	first, second := metrics.Spend[0], metrics.Spend[1]
+	if first.Provider != "anthropic" || first.Model != "claude-3-5-haiku" || first.Calls != 1 {
+		t.Fatalf("unexpected first spend row: %+v", first)
+	}
+	if second.Provider != "openai" || second.Calls != 2 || second.InputTokens != 300 || second.OutputTokens != 30 || second.CostUSD != 0.75 {
+		t.Fatalf("unexpected second spend row: %+v", second)
+	}
+}
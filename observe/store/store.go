@@ -24,6 +24,22 @@ type MetricsSummary struct {
 	ProviderFailures int64 `json:"providerFailures"`
 	ToolCalls        int64 `json:"toolCalls"`
 	ToolFailures     int64 `json:"toolFailures"`
+	// ToolValidationFailures counts tool calls rejected because their
+	// arguments did not match the tool's schema; they are also tool failures.
+	ToolValidationFailures int64 `json:"toolValidationFailures"`
+
+	TotalCostUSD float64        `json:"totalCostUsd"`
+	Spend        []SpendSummary `json:"spend,omitempty"`
+}
+
+// SpendSummary aggregates completed provider calls for one provider and model.
+type SpendSummary struct {
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model"`
+	Calls        int64   `json:"calls"`
+	InputTokens  int64   `json:"inputTokens"`
+	OutputTokens int64   `json:"outputTokens"`
+	CostUSD      float64 `json:"costUsd"`
 }
 
 type Store interface {
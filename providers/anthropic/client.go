@@ -68,54 +68,25 @@ func (c *Client) Name() string { return "anthropic" }
 
 func (c *Client) Capabilities() llm.Capabilities {
 	return llm.Capabilities{
-		Tools:            true,
-		Streaming:        false,
-		StructuredOutput: true,
+		Tools:             true,
+		Streaming:         true,
+		StructuredOutput:  true,
+		Temperature:       true,
+		TopP:              true,
+		TopK:              true,
+		StopSequences:     true,
+		ToolChoice:        true,
+		ParallelToolCalls: true,
 	}
 }
 
 func (c *Client) Generate(ctx context.Context, req types.Request) (types.Response, error) {
-	model := c.model
-	if req.Model != "" {
-		model = req.Model
-	}
-
-	maxTokens := req.MaxOutputTokens
-	if maxTokens <= 0 {
-		maxTokens = defaultMaxTokens
-	}
-
-	payload := anthropicRequest{
-		Model:     model,
-		System:    req.SystemPrompt,
-		MaxTokens: maxTokens,
-		Messages:  toAnthropicMessages(req.Messages),
-	}
-
-	// Structured output: inject schema instruction (Claude has no native schema enforcement)
-	if len(req.ResponseSchema) > 0 {
-		schemaJSON, _ := json.Marshal(req.ResponseSchema)
-		payload.System += "\n\nYou MUST respond with valid JSON matching this schema:\n```json\n" + string(schemaJSON) + "\n```\nRespond ONLY with the JSON object, no other text."
-	}
-
-	if len(req.Tools) > 0 {
-		payload.Tools = toAnthropicTools(req.Tools)
-		payload.ToolChoice = &anthropicToolChoice{Type: "auto"}
-	}
-
-	raw, err := json.Marshal(payload)
+	payload := c.buildRequest(req)
+	httpReq, err := c.newHTTPRequest(ctx, payload)
 	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to marshal anthropic request: %w", err)
+		return types.Response{}, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(raw))
-	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to create anthropic request: %w", err)
-	}
-	httpReq.Header.Set("x-api-key", c.apiKey)
-	httpReq.Header.Set("anthropic-version", anthropicVersion)
-	httpReq.Header.Set("content-type", "application/json")
-
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return types.Response{}, fmt.Errorf("anthropic request failed: %w", err)
@@ -167,9 +138,84 @@ func (c *Client) Generate(ctx context.Context, req types.Request) (types.Respons
 	return types.Response{
 		Message: out,
 		Usage:   usage,
+		Model:   payload.Model,
 	}, nil
 }
 
+func (c *Client) buildRequest(req types.Request) anthropicRequest {
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
+	}
+
+	maxTokens := req.MaxOutputTokens
+	if maxTokens <= 0 {
+		maxTokens = defaultMaxTokens
+	}
+
+	payload := anthropicRequest{
+		Model:     model,
+		System:    req.SystemPrompt,
+		MaxTokens: maxTokens,
+		Messages:  toAnthropicMessages(req.Messages),
+
+		Temperature:   req.Temperature,
+		TopP:          req.TopP,
+		TopK:          req.TopK,
+		StopSequences: req.StopSequences,
+	}
+
+	// Structured output: inject schema instruction (Claude has no native schema enforcement)
+	if len(req.ResponseSchema) > 0 {
+		schemaJSON, _ := json.Marshal(req.ResponseSchema)
+		payload.System += "\n\nYou MUST respond with valid JSON matching this schema:\n```json\n" + string(schemaJSON) + "\n```\nRespond ONLY with the JSON object, no other text."
+	}
+
+	if len(req.Tools) > 0 {
+		payload.Tools = toAnthropicTools(req.Tools)
+		payload.ToolChoice = toAnthropicToolChoice(req.ToolChoice, req.ParallelToolCalls)
+	}
+	return payload
+}
+
+// toAnthropicToolChoice maps a tool choice onto the Messages API modes. The
+// parallel-call hint is expressed as disable_parallel_tool_use, which the API
+// rejects when tools are disabled.
+func toAnthropicToolChoice(choice *types.ToolChoice, parallel *bool) *anthropicToolChoice {
+	out := &anthropicToolChoice{Type: "auto"}
+	if choice != nil {
+		switch choice.Mode {
+		case types.ToolChoiceRequired:
+			out.Type = "any"
+		case types.ToolChoiceNone:
+			return &anthropicToolChoice{Type: "none"}
+		case types.ToolChoiceTool:
+			out.Type = "tool"
+			out.Name = choice.Name
+		}
+	}
+	if parallel != nil && !*parallel {
+		out.DisableParallelToolUse = true
+	}
+	return out
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, payload anthropicRequest) (*http.Request, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal anthropic request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create anthropic request: %w", err)
+	}
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	httpReq.Header.Set("content-type", "application/json")
+	return httpReq, nil
+}
+
 func toAnthropicTools(in []types.ToolDefinition) []anthropicTool {
 	tools := make([]anthropicTool, 0, len(in))
 	for _, t := range in {
@@ -195,10 +241,8 @@ func toAnthropicMessages(in []types.Message) []anthropicMessage {
 		switch m.Role {
 		case types.RoleUser:
 			msgs = append(msgs, anthropicMessage{
-				Role: "user",
-				Content: []anthropicContentBlock{
-					{Type: "text", Text: m.Content},
-				},
+				Role:    "user",
+				Content: toAnthropicContent(m),
 			})
 		case types.RoleAssistant:
 			blocks := make([]anthropicContentBlock, 0, len(m.ToolCalls)+1)
@@ -243,16 +287,23 @@ func toAnthropicMessages(in []types.Message) []anthropicMessage {
 }
 
 type anthropicRequest struct {
-	Model      string               `json:"model"`
-	System     string               `json:"system,omitempty"`
-	MaxTokens  int                  `json:"max_tokens"`
-	Messages   []anthropicMessage   `json:"messages"`
-	Tools      []anthropicTool      `json:"tools,omitempty"`
-	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Model         string               `json:"model"`
+	System        string               `json:"system,omitempty"`
+	MaxTokens     int                  `json:"max_tokens"`
+	Messages      []anthropicMessage   `json:"messages"`
+	Tools         []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice    *anthropicToolChoice `json:"tool_choice,omitempty"`
+	Temperature   *float64             `json:"temperature,omitempty"`
+	TopP          *float64             `json:"top_p,omitempty"`
+	TopK          *int                 `json:"top_k,omitempty"`
+	StopSequences []string             `json:"stop_sequences,omitempty"`
+	Stream        bool                 `json:"stream,omitempty"`
 }
 
 type anthropicToolChoice struct {
-	Type string `json:"type"`
+	Type                   string `json:"type"`
+	Name                   string `json:"name,omitempty"`
+	DisableParallelToolUse bool   `json:"disable_parallel_tool_use,omitempty"`
 }
 
 type anthropicMessage struct {
@@ -261,13 +312,15 @@ type anthropicMessage struct {
 }
 
 type anthropicContentBlock struct {
-	Type      string         `json:"type"`
-	Text      string         `json:"text,omitempty"`
-	ID        string         `json:"id,omitempty"`
-	Name      string         `json:"name,omitempty"`
-	Input     map[string]any `json:"-"` // custom marshal: required for tool_use, omitted otherwise
-	ToolUseID string         `json:"tool_use_id,omitempty"`
-	Content   any            `json:"content,omitempty"`
+	Type      string           `json:"type"`
+	Text      string           `json:"text,omitempty"`
+	ID        string           `json:"id,omitempty"`
+	Name      string           `json:"name,omitempty"`
+	Input     map[string]any   `json:"-"` // custom marshal: required for tool_use, omitted otherwise
+	ToolUseID string           `json:"tool_use_id,omitempty"`
+	Content   any              `json:"content,omitempty"`
+	Source    *anthropicSource `json:"source,omitempty"`
+	Title     string           `json:"title,omitempty"`
 }
 
 // MarshalJSON ensures the "input" field is always present for tool_use blocks
@@ -0,0 +1,47 @@
+package anthropic
+
+import (
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestBuildRequest_GenerationControls(t *testing.T) {
+	c, err := New("test-key")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	temp, topK, parallel := 0.5, 20, false
+	payload := c.buildRequest(types.Request{
+		Messages:          []types.Message{{Role: types.RoleUser, Content: "hi"}},
+		Tools:             []types.ToolDefinition{{Name: "lookup"}},
+		Temperature:       &temp,
+		TopK:              &topK,
+		StopSequences:     []string{"</answer>"},
+		ToolChoice:        &types.ToolChoice{Mode: types.ToolChoiceRequired},
+		ParallelToolCalls: &parallel,
+	})
+	if payload.Temperature == nil || *payload.Temperature != 0.5 || payload.TopK == nil || *payload.TopK != 20 {
+		t.Fatalf("unexpected sampling fields: temperature=%v topK=%v", payload.Temperature, payload.TopK)
+	}
+	if len(payload.StopSequences) != 1 || payload.StopSequences[0] != "</answer>" {
+		t.Fatalf("unexpected stop sequences: %v", payload.StopSequences)
+	}
+	if payload.ToolChoice == nil || payload.ToolChoice.Type != "any" || !payload.ToolChoice.DisableParallelToolUse {
+		t.Fatalf("unexpected tool choice: %+v", payload.ToolChoice)
+	}
+
+	payload = c.buildRequest(types.Request{
+		Tools:             []types.ToolDefinition{{Name: "lookup"}},
+		ToolChoice:        &types.ToolChoice{Mode: types.ToolChoiceNone},
+		ParallelToolCalls: &parallel,
+	})
+	if payload.ToolChoice.Type != "none" || payload.ToolChoice.DisableParallelToolUse {
+		t.Fatalf("expected bare none tool choice, got %+v", payload.ToolChoice)
+	}
+
+	seed := int64(1)
+	if got := c.Capabilities().Unsupported(types.Request{Seed: &seed, TopK: &topK}); len(got) != 1 || got[0] != "seed" {
+		t.Fatalf("expected seed reported unsupported, got %v", got)
+	}
+}
@@ -0,0 +1,57 @@
+package anthropic
+
+import (
+	"fmt"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// toAnthropicContent converts message content into Messages API blocks.
+// Images map to image blocks and files to document blocks (PDFs natively,
+// textual files as plain-text documents). Audio input is not supported by
+// the API and is replaced with a short note the model can see.
+func toAnthropicContent(m types.Message) []anthropicContentBlock {
+	if len(m.Parts) == 0 {
+		return []anthropicContentBlock{{Type: "text", Text: m.Content}}
+	}
+	parts := m.ContentParts()
+	out := make([]anthropicContentBlock, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case types.ContentPartImage:
+			out = append(out, anthropicContentBlock{Type: "image", Source: toAnthropicSource(p)})
+		case types.ContentPartFile:
+			source := toAnthropicSource(p)
+			if len(p.Data) > 0 && p.IsTextual() {
+				source = &anthropicSource{Type: "text", MediaType: "text/plain", Data: string(p.Data)}
+			}
+			out = append(out, anthropicContentBlock{Type: "document", Source: source, Title: p.Filename})
+		case types.ContentPartAudio:
+			ref := p.Filename
+			if ref == "" {
+				ref = p.URL
+			}
+			out = append(out, anthropicContentBlock{
+				Type: "text",
+				Text: fmt.Sprintf("[audio attachment %q (%s) could not be sent to this model]", ref, p.MIMEType),
+			})
+		default:
+			out = append(out, anthropicContentBlock{Type: "text", Text: p.Text})
+		}
+	}
+	return out
+}
+
+func toAnthropicSource(p types.ContentPart) *anthropicSource {
+	if len(p.Data) == 0 {
+		return &anthropicSource{Type: "url", URL: p.URL}
+	}
+	return &anthropicSource{Type: "base64", MediaType: p.MIMEType, Data: p.Base64()}
+}
+
+type anthropicSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type,omitempty"`
+	Data      string `json:"data,omitempty"`
+	URL       string `json:"url,omitempty"`
+}
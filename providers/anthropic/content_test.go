@@ -0,0 +1,39 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestToAnthropicMessages_MultimodalParts(t *testing.T) {
+	msgs := toAnthropicMessages([]types.Message{{
+		Role:    types.RoleUser,
+		Content: "review",
+		Parts: []types.ContentPart{
+			types.ImagePart([]byte("png"), "image/png"),
+			types.ImageURLPart("https://example.com/a.png"),
+			types.FilePart([]byte("%PDF"), "application/pdf", "report.pdf"),
+			types.FilePart([]byte("a,b\n1,2"), "text/csv", "data.csv"),
+		},
+	}})
+
+	raw, err := json.Marshal(msgs[0])
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	got := string(raw)
+	for _, want := range []string{
+		`{"type":"text","text":"review"}`,
+		`{"type":"image","source":{"type":"base64","media_type":"image/png","data":"cG5n"}}`,
+		`{"type":"image","source":{"type":"url","url":"https://example.com/a.png"}}`,
+		`{"type":"document","source":{"type":"base64","media_type":"application/pdf","data":"JVBERg=="},"title":"report.pdf"}`,
+		`{"type":"document","source":{"type":"text","media_type":"text/plain","data":"a,b\n1,2"},"title":"data.csv"}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %s in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package anthropic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// countTokensRequest is the subset of a Messages request the count_tokens
+// endpoint accepts; sampling fields and max_tokens are rejected.
+type countTokensRequest struct {
+	Model      string               `json:"model"`
+	System     string               `json:"system,omitempty"`
+	Messages   []anthropicMessage   `json:"messages"`
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type countTokensResponse struct {
+	InputTokens int `json:"input_tokens"`
+}
+
+// CountTokens returns the input tokens req would use, as counted by the
+// Messages API. It implements tokenizer.RequestCounter.
+func (c *Client) CountTokens(ctx context.Context, req types.Request) (int, error) {
+	full := c.buildRequest(req)
+	raw, err := json.Marshal(countTokensRequest{
+		Model:      full.Model,
+		System:     full.System,
+		Messages:   full.Messages,
+		Tools:      full.Tools,
+		ToolChoice: full.ToolChoice,
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal anthropic count request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages/count_tokens", bytes.NewReader(raw))
+	if err != nil {
+		return 0, fmt.Errorf("failed to create anthropic count request: %w", err)
+	}
+	httpReq.Header.Set("x-api-key", c.apiKey)
+	httpReq.Header.Set("anthropic-version", anthropicVersion)
+	httpReq.Header.Set("content-type", "application/json")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return 0, fmt.Errorf("anthropic count request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read anthropic count response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("anthropic API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var out countTokensResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		return 0, fmt.Errorf("failed to decode anthropic count response: %w", err)
+	}
+	return out.InputTokens, nil
+}
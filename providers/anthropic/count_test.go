@@ -0,0 +1,65 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestClientCountTokens(t *testing.T) {
+	var body map[string]any
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages/count_tokens" || r.Header.Get("x-api-key") != "test-key" {
+			t.Errorf("unexpected request %s with key %q", r.URL.Path, r.Header.Get("x-api-key"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"input_tokens":17}`))
+	}))
+	defer ts.Close()
+
+	c, err := New("test-key", WithBaseURL(ts.URL), WithModel("claude-test"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	temp := 0.2
+	n, err := c.CountTokens(context.Background(), types.Request{
+		SystemPrompt:    "be brief",
+		Messages:        []types.Message{{Role: types.RoleUser, Content: "hi"}},
+		Tools:           []types.ToolDefinition{{Name: "lookup"}},
+		MaxOutputTokens: 100,
+		Temperature:     &temp,
+	})
+	if err != nil {
+		t.Fatalf("CountTokens() error = %v", err)
+	}
+	if n != 17 {
+		t.Fatalf("expected 17 tokens, got %d", n)
+	}
+	if body["model"] != "claude-test" || body["system"] != "be brief" || body["tools"] == nil {
+		t.Fatalf("unexpected count payload %v", body)
+	}
+	if _, ok := body["max_tokens"]; ok {
+		t.Fatalf("count payload must not carry max_tokens: %v", body)
+	}
+	if _, ok := body["temperature"]; ok {
+		t.Fatalf("count payload must not carry sampling fields: %v", body)
+	}
+}
+
+func TestClientCountTokens_APIError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error":"overloaded"}`, http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	c, _ := New("test-key", WithBaseURL(ts.URL))
+	if _, err := c.CountTokens(context.Background(), types.Request{Messages: []types.Message{{Role: types.RoleUser, Content: "hi"}}}); err == nil {
+		t.Fatalf("expected an API error")
+	}
+}
@@ -0,0 +1,212 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/providers/internal/sse"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// GenerateStream implements llm.StreamProvider using the Messages API event
+// stream. Text, thinking and tool-use input deltas are forwarded as they
+// arrive; usage is reported once the message completes.
+func (c *Client) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	if onChunk == nil {
+		return types.Response{}, fmt.Errorf("onChunk is required")
+	}
+	payload := c.buildRequest(req)
+	payload.Stream = true
+
+	httpReq, err := c.newHTTPRequest(ctx, payload)
+	if err != nil {
+		return types.Response{}, err
+	}
+	httpReq.Header.Set("accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return types.Response{}, fmt.Errorf("anthropic request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return types.Response{}, fmt.Errorf("anthropic API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	acc := &streamAccumulator{blocks: map[int]*streamBlock{}}
+	err = sse.Read(resp.Body, func(event string, data []byte) error {
+		var ev streamEvent
+		if err := json.Unmarshal(data, &ev); err != nil {
+			return fmt.Errorf("failed to decode anthropic stream event: %w", err)
+		}
+		if ev.Type == "" {
+			ev.Type = event
+		}
+		return acc.add(ev, onChunk)
+	})
+	if err != nil {
+		return types.Response{}, err
+	}
+
+	out := acc.response()
+	out.Model = payload.Model
+	if out.Usage != nil {
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventUsage, Usage: out.Usage}); err != nil {
+			return types.Response{}, err
+		}
+	}
+	if err := onChunk(types.StreamChunk{Type: types.StreamEventDone, Done: true}); err != nil {
+		return types.Response{}, err
+	}
+	return out, nil
+}
+
+type streamBlock struct {
+	kind      string
+	id        string
+	name      string
+	text      strings.Builder
+	input     strings.Builder
+	toolIndex int
+}
+
+// streamAccumulator assembles content-block events into a complete response.
+type streamAccumulator struct {
+	blocks       map[int]*streamBlock
+	order        []int
+	toolCount    int
+	inputTokens  int
+	outputTokens int
+}
+
+func (a *streamAccumulator) add(ev streamEvent, onChunk func(types.StreamChunk) error) error {
+	switch ev.Type {
+	case "message_start":
+		if ev.Message != nil {
+			a.inputTokens = ev.Message.Usage.InputTokens
+			a.outputTokens = ev.Message.Usage.OutputTokens
+		}
+	case "content_block_start":
+		if ev.ContentBlock == nil {
+			return nil
+		}
+		block := &streamBlock{kind: ev.ContentBlock.Type, id: ev.ContentBlock.ID, name: ev.ContentBlock.Name}
+		a.blocks[ev.Index] = block
+		a.order = append(a.order, ev.Index)
+		switch block.kind {
+		case "text":
+			block.text.WriteString(ev.ContentBlock.Text)
+		case "tool_use":
+			block.toolIndex = a.toolCount
+			a.toolCount++
+			return onChunk(types.StreamChunk{
+				Type:          types.StreamEventToolCallDelta,
+				ToolCallDelta: &types.ToolCallDelta{Index: block.toolIndex, ID: block.id, Name: block.name},
+			})
+		}
+	case "content_block_delta":
+		block, ok := a.blocks[ev.Index]
+		if !ok || ev.Delta == nil {
+			return nil
+		}
+		switch ev.Delta.Type {
+		case "text_delta":
+			block.text.WriteString(ev.Delta.Text)
+			return onChunk(types.StreamChunk{Type: types.StreamEventText, Text: ev.Delta.Text})
+		case "thinking_delta":
+			block.text.WriteString(ev.Delta.Thinking)
+			return onChunk(types.StreamChunk{Type: types.StreamEventReasoning, Reasoning: ev.Delta.Thinking})
+		case "input_json_delta":
+			block.input.WriteString(ev.Delta.PartialJSON)
+			return onChunk(types.StreamChunk{
+				Type:          types.StreamEventToolCallDelta,
+				ToolCallDelta: &types.ToolCallDelta{Index: block.toolIndex, ArgumentsDelta: ev.Delta.PartialJSON},
+			})
+		}
+	case "message_delta":
+		if ev.Usage != nil {
+			a.outputTokens = ev.Usage.OutputTokens
+			if ev.Usage.InputTokens > 0 {
+				a.inputTokens = ev.Usage.InputTokens
+			}
+		}
+	case "message_stop":
+		return sse.ErrDone
+	case "error":
+		if ev.Error != nil {
+			return fmt.Errorf("anthropic stream error (%s): %s", ev.Error.Type, ev.Error.Message)
+		}
+		return fmt.Errorf("anthropic stream error")
+	}
+	return nil
+}
+
+func (a *streamAccumulator) response() types.Response {
+	out := types.Message{Role: types.RoleAssistant}
+	for _, idx := range a.order {
+		block := a.blocks[idx]
+		switch block.kind {
+		case "text":
+			out.Content += block.text.String()
+		case "thinking":
+			out.Reasoning += block.text.String()
+		case "tool_use":
+			args := strings.TrimSpace(block.input.String())
+			if args == "" || !json.Valid([]byte(args)) {
+				args = "{}"
+			}
+			out.ToolCalls = append(out.ToolCalls, types.ToolCall{
+				ID:        block.id,
+				Name:      block.name,
+				Arguments: json.RawMessage(args),
+			})
+		}
+	}
+	out.Content = strings.TrimSpace(out.Content)
+	out.Reasoning = strings.TrimSpace(out.Reasoning)
+
+	var usage *types.Usage
+	if a.inputTokens > 0 || a.outputTokens > 0 {
+		usage = &types.Usage{
+			InputTokens:  a.inputTokens,
+			OutputTokens: a.outputTokens,
+			TotalTokens:  a.inputTokens + a.outputTokens,
+		}
+	}
+	return types.Response{Message: out, Usage: usage}
+}
+
+type streamUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type streamEvent struct {
+	Type    string `json:"type"`
+	Index   int    `json:"index"`
+	Message *struct {
+		Usage streamUsage `json:"usage"`
+	} `json:"message"`
+	ContentBlock *struct {
+		Type string `json:"type"`
+		ID   string `json:"id"`
+		Name string `json:"name"`
+		Text string `json:"text"`
+	} `json:"content_block"`
+	Delta *struct {
+		Type        string `json:"type"`
+		Text        string `json:"text"`
+		Thinking    string `json:"thinking"`
+		PartialJSON string `json:"partial_json"`
+	} `json:"delta"`
+	Usage *streamUsage `json:"usage"`
+	Error *struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
@@ -0,0 +1,132 @@
+package anthropic
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestClientGenerateStream_ReplaysTranscript(t *testing.T) {
+	transcript, err := os.ReadFile("testdata/stream_tool_use.sse")
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("x-api-key") != "test-key" {
+			t.Fatalf("expected x-api-key header")
+		}
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req["stream"] != true {
+			t.Fatalf("expected stream=true, got %#v", req["stream"])
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write(transcript)
+	}))
+	defer ts.Close()
+
+	client, err := New("test-key", WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var (
+		text strings.Builder
+		args strings.Builder
+		done bool
+	)
+	resp, err := client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "what is 2+2"}},
+		Tools:    []types.ToolDefinition{{Name: "calc", Description: "calculator"}},
+	}, func(chunk types.StreamChunk) error {
+		text.WriteString(chunk.Text)
+		if chunk.ToolCallDelta != nil {
+			args.WriteString(chunk.ToolCallDelta.ArgumentsDelta)
+		}
+		done = done || chunk.Done
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	if text.String() != "Let me calculate." || resp.Message.Content != "Let me calculate." {
+		t.Fatalf("unexpected content: streamed=%q final=%q", text.String(), resp.Message.Content)
+	}
+	if len(resp.Message.ToolCalls) != 1 {
+		t.Fatalf("expected one tool call, got %#v", resp.Message.ToolCalls)
+	}
+	call := resp.Message.ToolCalls[0]
+	if call.ID != "toolu_01" || call.Name != "calc" || string(call.Arguments) != `{"expr": "2+2"}` {
+		t.Fatalf("unexpected tool call: %#v (args %s)", call, call.Arguments)
+	}
+	if args.String() != `{"expr": "2+2"}` {
+		t.Fatalf("unexpected streamed arguments: %q", args.String())
+	}
+	if resp.Usage == nil || resp.Usage.InputTokens != 25 || resp.Usage.OutputTokens != 40 {
+		t.Fatalf("unexpected usage: %#v", resp.Usage)
+	}
+	if !done {
+		t.Fatalf("expected a done chunk")
+	}
+}
+
+func TestClientGenerateStream_ErrorEvent(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: error\ndata: {\"type\":\"error\",\"error\":{\"type\":\"overloaded_error\",\"message\":\"Overloaded\"}}\n\n"))
+	}))
+	defer ts.Close()
+
+	client, err := New("test-key", WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_, err = client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "hi"}},
+	}, func(types.StreamChunk) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "overloaded_error") {
+		t.Fatalf("expected overloaded error, got %v", err)
+	}
+}
+
+func TestClientGenerateStream_TruncatedBody(t *testing.T) {
+	transcript, err := os.ReadFile("testdata/stream_tool_use.sse")
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	// The connection drops before the final event.
+	cut := strings.Index(string(transcript), "event: message_stop")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write(transcript[:cut])
+	}))
+	defer ts.Close()
+
+	client, err := New("test-key", WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	done := false
+	_, err = client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "hi"}},
+	}, func(chunk types.StreamChunk) error {
+		done = done || chunk.Done
+		return nil
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || done {
+		t.Fatalf("expected a truncated stream to fail without a done chunk, got %v (done=%v)", err, done)
+	}
+}
@@ -80,48 +80,27 @@ func (c *Client) Name() string { return "azureopenai" }
 
 func (c *Client) Capabilities() llm.Capabilities {
 	return llm.Capabilities{
-		Tools:            true,
-		Streaming:        false,
-		StructuredOutput: true,
+		Tools:             true,
+		Streaming:         true,
+		StructuredOutput:  true,
+		Temperature:       true,
+		TopP:              true,
+		StopSequences:     true,
+		Seed:              true,
+		PresencePenalty:   true,
+		FrequencyPenalty:  true,
+		ToolChoice:        true,
+		ParallelToolCalls: true,
 	}
 }
 
 func (c *Client) Generate(ctx context.Context, req types.Request) (types.Response, error) {
-	model := c.model
-	if req.Model != "" {
-		model = req.Model
-	}
-
-	payload := azureChatRequest{
-		Model:    model,
-		Messages: make([]azureChatMessage, 0, len(req.Messages)+1),
-	}
-	if req.MaxOutputTokens > 0 {
-		payload.MaxTokens = req.MaxOutputTokens
-	}
-
-	if req.SystemPrompt != "" {
-		payload.Messages = append(payload.Messages, azureChatMessage{Role: "system", Content: req.SystemPrompt})
-	}
-	payload.Messages = append(payload.Messages, toAzureMessages(req.Messages)...)
-
-	if len(req.Tools) > 0 {
-		payload.ToolChoice = "auto"
-		payload.Tools = toAzureTools(req.Tools)
-	}
-
-	raw, err := json.Marshal(payload)
+	payload := c.buildRequest(req)
+	httpReq, err := c.newHTTPRequest(ctx, payload)
 	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to marshal azure openai request: %w", err)
+		return types.Response{}, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpointForDeployment(), bytes.NewReader(raw))
-	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to create azure openai request: %w", err)
-	}
-	httpReq.Header.Set("api-key", c.apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return types.Response{}, fmt.Errorf("azure openai request failed: %w", err)
@@ -169,7 +148,71 @@ func (c *Client) Generate(ctx context.Context, req types.Request) (types.Respons
 		}
 	}
 
-	return types.Response{Message: out, Usage: usage}, nil
+	return types.Response{Message: out, Usage: usage, Model: payload.Model}, nil
+}
+
+func (c *Client) buildRequest(req types.Request) azureChatRequest {
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
+	}
+
+	payload := azureChatRequest{
+		Model:    model,
+		Messages: make([]azureChatMessage, 0, len(req.Messages)+1),
+	}
+	if req.MaxOutputTokens > 0 {
+		payload.MaxTokens = req.MaxOutputTokens
+	}
+
+	if req.SystemPrompt != "" {
+		payload.Messages = append(payload.Messages, azureChatMessage{Role: "system", Content: req.SystemPrompt})
+	}
+	payload.Messages = append(payload.Messages, toAzureMessages(req.Messages)...)
+
+	payload.Temperature = req.Temperature
+	payload.TopP = req.TopP
+	payload.Stop = req.StopSequences
+	payload.Seed = req.Seed
+	payload.PresencePenalty = req.PresencePenalty
+	payload.FrequencyPenalty = req.FrequencyPenalty
+
+	if len(req.Tools) > 0 {
+		payload.ToolChoice = toAzureToolChoice(req.ToolChoice)
+		payload.Tools = toAzureTools(req.Tools)
+		payload.ParallelToolCalls = req.ParallelToolCalls
+	}
+	return payload
+}
+
+// toAzureToolChoice maps a tool choice to the tool_choice field, which is
+// either a mode string or a named function object.
+func toAzureToolChoice(choice *types.ToolChoice) any {
+	if choice == nil || choice.Mode == "" {
+		return "auto"
+	}
+	if choice.Mode == types.ToolChoiceTool {
+		return map[string]any{
+			"type":     "function",
+			"function": map[string]any{"name": choice.Name},
+		}
+	}
+	return string(choice.Mode)
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, payload azureChatRequest) (*http.Request, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal azure openai request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpointForDeployment(), bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create azure openai request: %w", err)
+	}
+	httpReq.Header.Set("api-key", c.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	return httpReq, nil
 }
 
 func (c *Client) endpointForDeployment() string {
@@ -183,7 +226,7 @@ func toAzureMessages(in []types.Message) []azureChatMessage {
 	for _, m := range in {
 		switch m.Role {
 		case types.RoleUser:
-			msgs = append(msgs, azureChatMessage{Role: "user", Content: m.Content})
+			msgs = append(msgs, azureChatMessage{Role: "user", Content: toAzureContent(m)})
 		case types.RoleAssistant:
 			out := azureChatMessage{Role: "assistant", Content: m.Content}
 			if len(m.ToolCalls) > 0 {
@@ -263,11 +306,20 @@ func normalizeJSONArgs(raw string) json.RawMessage {
 }
 
 type azureChatRequest struct {
-	Model      string             `json:"model,omitempty"`
-	Messages   []azureChatMessage `json:"messages"`
-	Tools      []azureTool        `json:"tools,omitempty"`
-	ToolChoice string             `json:"tool_choice,omitempty"`
-	MaxTokens  int                `json:"max_tokens,omitempty"`
+	Model             string             `json:"model,omitempty"`
+	Messages          []azureChatMessage `json:"messages"`
+	Tools             []azureTool        `json:"tools,omitempty"`
+	ToolChoice        any                `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool              `json:"parallel_tool_calls,omitempty"`
+	MaxTokens         int                `json:"max_tokens,omitempty"`
+	Temperature       *float64           `json:"temperature,omitempty"`
+	TopP              *float64           `json:"top_p,omitempty"`
+	Stop              []string           `json:"stop,omitempty"`
+	Seed              *int64             `json:"seed,omitempty"`
+	PresencePenalty   *float64           `json:"presence_penalty,omitempty"`
+	FrequencyPenalty  *float64           `json:"frequency_penalty,omitempty"`
+	Stream            bool               `json:"stream,omitempty"`
+	StreamOptions     *streamOptions     `json:"stream_options,omitempty"`
 }
 
 type azureChatMessage struct {
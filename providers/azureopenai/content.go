@@ -0,0 +1,97 @@
+package azureopenai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// toAzureContent returns the message content in chat completions form: a
+// plain string for text-only messages, or a content-part array when the
+// message carries multimodal parts.
+func toAzureContent(m types.Message) any {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	parts := m.ContentParts()
+	out := make([]azureContentPart, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case types.ContentPartImage:
+			out = append(out, azureContentPart{
+				Type:     "image_url",
+				ImageURL: &azureImageURL{URL: p.DataURL()},
+			})
+		case types.ContentPartFile:
+			if len(p.Data) == 0 {
+				out = append(out, azureContentPart{Type: "text", Text: attachmentNote(p)})
+				continue
+			}
+			filename := p.Filename
+			if filename == "" {
+				filename = "attachment"
+			}
+			out = append(out, azureContentPart{
+				Type: "file",
+				File: &azureFile{Filename: filename, FileData: p.DataURL()},
+			})
+		case types.ContentPartAudio:
+			format := audioFormat(p.MIMEType)
+			if len(p.Data) == 0 || format == "" {
+				out = append(out, azureContentPart{Type: "text", Text: attachmentNote(p)})
+				continue
+			}
+			out = append(out, azureContentPart{
+				Type:       "input_audio",
+				InputAudio: &azureInputAudio{Data: p.Base64(), Format: format},
+			})
+		default:
+			out = append(out, azureContentPart{Type: "text", Text: p.Text})
+		}
+	}
+	return out
+}
+
+// audioFormat maps an audio MIME type to the input_audio format names the
+// API accepts.
+func audioFormat(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return "wav"
+	case "audio/mpeg", "audio/mp3":
+		return "mp3"
+	default:
+		return ""
+	}
+}
+
+func attachmentNote(p types.ContentPart) string {
+	ref := p.Filename
+	if ref == "" {
+		ref = p.URL
+	}
+	return fmt.Sprintf("[%s attachment %q (%s) could not be sent to this model]", p.Type, ref, p.MIMEType)
+}
+
+type azureContentPart struct {
+	Type       string           `json:"type"`
+	Text       string           `json:"text,omitempty"`
+	ImageURL   *azureImageURL   `json:"image_url,omitempty"`
+	File       *azureFile       `json:"file,omitempty"`
+	InputAudio *azureInputAudio `json:"input_audio,omitempty"`
+}
+
+type azureImageURL struct {
+	URL string `json:"url"`
+}
+
+type azureFile struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data"`
+}
+
+type azureInputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
@@ -0,0 +1,55 @@
+package azureopenai
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestClientGenerateStream_ReplaysTranscript(t *testing.T) {
+	transcript, err := os.ReadFile("testdata/stream_text.sse")
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/openai/deployments/dep/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("api-key") != "azure-key" {
+			t.Fatalf("expected api-key header")
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write(transcript)
+	}))
+	defer ts.Close()
+
+	client, err := New("azure-key", WithEndpoint(ts.URL), WithDeployment("dep"), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var text strings.Builder
+	resp, err := client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "hello"}},
+	}, func(chunk types.StreamChunk) error {
+		text.WriteString(chunk.Text)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	if text.String() != "Hello from Azure" || resp.Message.Content != "Hello from Azure" {
+		t.Fatalf("unexpected content: streamed=%q final=%q", text.String(), resp.Message.Content)
+	}
+	if len(resp.Message.ToolCalls) != 0 {
+		t.Fatalf("unexpected tool calls: %#v", resp.Message.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 13 {
+		t.Fatalf("unexpected usage: %#v", resp.Usage)
+	}
+}
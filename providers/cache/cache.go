@@ -0,0 +1,253 @@
+// Package cache implements an llm.Provider that memoizes responses of
+// another provider. Identical requests, such as repeated evals or scheduled
+// jobs, are answered from a Store instead of calling the model again.
+package cache
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/observe"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const defaultTTL = 24 * time.Hour
+
+// Store persists cached responses. Get reports false for missing or expired
+// entries. A ttl of zero stores the entry without expiry.
+type Store interface {
+	Get(ctx context.Context, key string) (types.Response, bool, error)
+	Set(ctx context.Context, key string, resp types.Response, ttl time.Duration) error
+}
+
+type Provider struct {
+	inner    llm.Provider
+	store    Store
+	ttl      time.Duration
+	observer observe.Sink
+}
+
+type Option func(*Provider)
+
+// WithTTL sets how long responses stay cached. Zero keeps them until the
+// store evicts them. Defaults to 24h.
+func WithTTL(ttl time.Duration) Option {
+	return func(p *Provider) {
+		if ttl >= 0 {
+			p.ttl = ttl
+		}
+	}
+}
+
+// WithObserver receives a cache hit or miss event for every request.
+func WithObserver(observer observe.Sink) Option {
+	return func(p *Provider) { p.observer = observer }
+}
+
+func New(inner llm.Provider, store Store, opts ...Option) (*Provider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("cache requires a provider")
+	}
+	if store == nil {
+		return nil, fmt.Errorf("cache requires a store")
+	}
+	p := &Provider{inner: inner, store: store, ttl: defaultTTL}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+func (p *Provider) Name() string { return p.inner.Name() }
+
+// Capabilities reports the wrapped provider's capabilities. Streaming is
+// always available because cached responses are replayed as chunks.
+func (p *Provider) Capabilities() llm.Capabilities {
+	caps := p.inner.Capabilities()
+	caps.Streaming = true
+	return caps
+}
+
+func (p *Provider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	key, ok := p.lookupKey(req)
+	if ok {
+		if resp, hit := p.get(ctx, key, req); hit {
+			return resp, nil
+		}
+	}
+	resp, err := p.inner.Generate(ctx, req)
+	if err != nil {
+		return types.Response{}, err
+	}
+	if ok {
+		p.set(ctx, key, resp)
+	}
+	return resp, nil
+}
+
+// GenerateStream implements llm.StreamProvider. A hit replays the cached
+// response as reasoning, text, tool-call, usage and done chunks; a miss
+// streams from the wrapped provider and caches the final response.
+func (p *Provider) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	if onChunk == nil {
+		return types.Response{}, fmt.Errorf("onChunk is required")
+	}
+	key, ok := p.lookupKey(req)
+	if ok {
+		if resp, hit := p.get(ctx, key, req); hit {
+			if err := replay(resp, onChunk); err != nil {
+				return types.Response{}, err
+			}
+			return resp, nil
+		}
+	}
+
+	var (
+		resp types.Response
+		err  error
+	)
+	if sp, streams := p.inner.(llm.StreamProvider); streams {
+		resp, err = sp.GenerateStream(ctx, req, onChunk)
+	} else {
+		resp, err = p.inner.Generate(ctx, req)
+		if err == nil {
+			err = replay(resp, onChunk)
+		}
+	}
+	if err != nil {
+		return types.Response{}, err
+	}
+	if ok {
+		p.set(ctx, key, resp)
+	}
+	return resp, nil
+}
+
+func (p *Provider) lookupKey(req types.Request) (string, bool) {
+	if req.NoCache {
+		return "", false
+	}
+	key, err := Key(p.inner.Name(), req)
+	if err != nil {
+		return "", false
+	}
+	return key, true
+}
+
+// get treats store failures as misses so a broken cache never fails a
+// generation.
+func (p *Provider) get(ctx context.Context, key string, req types.Request) (types.Response, bool) {
+	resp, hit, err := p.store.Get(ctx, key)
+	if err != nil {
+		p.emit(ctx, "cache.miss", key, req.Model, err)
+		return types.Response{}, false
+	}
+	if !hit {
+		p.emit(ctx, "cache.miss", key, req.Model, nil)
+		return types.Response{}, false
+	}
+	resp.Cached = true
+	p.emit(ctx, "cache.hit", key, req.Model, nil)
+	return resp, true
+}
+
+func (p *Provider) set(ctx context.Context, key string, resp types.Response) {
+	// An empty answer is usually a transient provider failure; caching it
+	// would make the failure permanent.
+	if resp.Message.Content == "" && len(resp.Message.ToolCalls) == 0 {
+		return
+	}
+	resp.Cached = false
+	if err := p.store.Set(ctx, key, resp, p.ttl); err != nil {
+		p.emit(ctx, "cache.store", key, resp.Model, err)
+	}
+}
+
+func (p *Provider) emit(ctx context.Context, name, key, model string, err error) {
+	if p.observer == nil {
+		return
+	}
+	event := observe.Event{
+		Kind:     observe.KindCache,
+		Status:   observe.StatusCompleted,
+		Name:     name,
+		Provider: p.inner.Name(),
+		Attributes: map[string]any{
+			"key": key,
+		},
+	}
+	if model != "" {
+		event.Attributes["model"] = model
+	}
+	if err != nil {
+		event.Status = observe.StatusFailed
+		event.Error = err.Error()
+	}
+	_ = p.observer.Emit(ctx, event)
+}
+
+// cacheKey lists everything about a request that can change the answer.
+// Tools are sorted by name because callers often build them from maps.
+type cacheKey struct {
+	Provider          string                 `json:"provider"`
+	Model             string                 `json:"model,omitempty"`
+	SystemPrompt      string                 `json:"systemPrompt,omitempty"`
+	Messages          []types.Message        `json:"messages"`
+	Tools             []types.ToolDefinition `json:"tools,omitempty"`
+	MaxOutputTokens   int                    `json:"maxOutputTokens,omitempty"`
+	ResponseSchema    map[string]any         `json:"responseSchema,omitempty"`
+	Temperature       *float64               `json:"temperature,omitempty"`
+	TopP              *float64               `json:"topP,omitempty"`
+	TopK              *int                   `json:"topK,omitempty"`
+	StopSequences     []string               `json:"stopSequences,omitempty"`
+	Seed              *int64                 `json:"seed,omitempty"`
+	PresencePenalty   *float64               `json:"presencePenalty,omitempty"`
+	FrequencyPenalty  *float64               `json:"frequencyPenalty,omitempty"`
+	ToolChoice        *types.ToolChoice      `json:"toolChoice,omitempty"`
+	ParallelToolCalls *bool                  `json:"parallelToolCalls,omitempty"`
+}
+
+// Key returns the canonical hash of req as sent to the named provider. JSON
+// encoding sorts map keys, so equal requests always hash the same.
+func Key(provider string, req types.Request) (string, error) {
+	tools := append([]types.ToolDefinition(nil), req.Tools...)
+	sort.SliceStable(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+
+	raw, err := json.Marshal(cacheKey{
+		Provider:          provider,
+		Model:             req.Model,
+		SystemPrompt:      req.SystemPrompt,
+		Messages:          req.Messages,
+		Tools:             tools,
+		MaxOutputTokens:   req.MaxOutputTokens,
+		ResponseSchema:    req.ResponseSchema,
+		Temperature:       req.Temperature,
+		TopP:              req.TopP,
+		TopK:              req.TopK,
+		StopSequences:     req.StopSequences,
+		Seed:              req.Seed,
+		PresencePenalty:   req.PresencePenalty,
+		FrequencyPenalty:  req.FrequencyPenalty,
+		ToolChoice:        req.ToolChoice,
+		ParallelToolCalls: req.ParallelToolCalls,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to encode cache key: %w", err)
+	}
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// replay streams a whole response, as a provider's stream would end it.
+func replay(resp types.Response, onChunk func(types.StreamChunk) error) error {
+	if err := llm.EmitResponse(resp, onChunk); err != nil {
+		return err
+	}
+	return onChunk(types.StreamChunk{Type: types.StreamEventDone, Done: true})
+}
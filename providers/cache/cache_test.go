@@ -0,0 +1,290 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/observe"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+type countingProvider struct {
+	calls int
+}
+
+func (p *countingProvider) Name() string { return "counting" }
+
+func (p *countingProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true}
+}
+
+func (p *countingProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.calls++
+	return types.Response{
+		Message: types.Message{
+			Role:      types.RoleAssistant,
+			Content:   "answer to " + req.Messages[len(req.Messages)-1].Content,
+			Reasoning: "thinking",
+			ToolCalls: []types.ToolCall{{ID: "call-1", Name: "lookup", Arguments: json.RawMessage(`{"q":"x"}`)}},
+		},
+		Usage: &types.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15},
+		Model: "m1",
+	}, nil
+}
+
+type recordingSink struct {
+	mu     sync.Mutex
+	events []observe.Event
+}
+
+func (s *recordingSink) Emit(ctx context.Context, event observe.Event) error {
+	_ = ctx
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, event)
+	return nil
+}
+
+func (s *recordingSink) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]string, 0, len(s.events))
+	for _, e := range s.events {
+		out = append(out, e.Name)
+	}
+	return out
+}
+
+func userRequest(text string) types.Request {
+	return types.Request{Messages: []types.Message{{Role: types.RoleUser, Content: text}}}
+}
+
+func TestProvider_GenerateCachesIdenticalRequests(t *testing.T) {
+	inner := &countingProvider{}
+	sink := &recordingSink{}
+	p, err := New(inner, NewMemoryStore(10), WithObserver(sink))
+	if err != nil {
+		t.Fatalf("new cache: %v", err)
+	}
+	ctx := context.Background()
+
+	first, err := p.Generate(ctx, userRequest("hi"))
+	if err != nil {
+		t.Fatalf("first generate: %v", err)
+	}
+	second, err := p.Generate(ctx, userRequest("hi"))
+	if err != nil {
+		t.Fatalf("second generate: %v", err)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected 1 provider call, got %d", inner.calls)
+	}
+	if first.Cached || !second.Cached {
+		t.Fatalf("expected only the second response to be cached: %v, %v", first.Cached, second.Cached)
+	}
+	if second.Message.Content != first.Message.Content || second.Model != "m1" {
+		t.Fatalf("unexpected cached response: %+v", second)
+	}
+
+	if _, err := p.Generate(ctx, userRequest("other")); err != nil {
+		t.Fatalf("third generate: %v", err)
+	}
+	if inner.calls != 2 {
+		t.Fatalf("expected a different request to miss, got %d calls", inner.calls)
+	}
+
+	names := sink.names()
+	want := []string{"cache.miss", "cache.hit", "cache.miss"}
+	if len(names) != len(want) {
+		t.Fatalf("expected events %v, got %v", want, names)
+	}
+	for i := range want {
+		if names[i] != want[i] {
+			t.Fatalf("expected events %v, got %v", want, names)
+		}
+	}
+	if sink.events[1].Kind != observe.KindCache || sink.events[1].Provider != "counting" {
+		t.Fatalf("unexpected hit event: %+v", sink.events[1])
+	}
+}
+
+func TestProvider_NoCacheSkipsCache(t *testing.T) {
+	inner := &countingProvider{}
+	store := NewMemoryStore(10)
+	p, _ := New(inner, store)
+
+	req := userRequest("hi")
+	req.NoCache = true
+	for i := 0; i < 2; i++ {
+		if _, err := p.Generate(context.Background(), req); err != nil {
+			t.Fatalf("generate: %v", err)
+		}
+	}
+	if inner.calls != 2 {
+		t.Fatalf("expected opt-out requests to reach the provider, got %d calls", inner.calls)
+	}
+	if store.Len() != 0 {
+		t.Fatalf("expected opt-out responses not to be stored, got %d", store.Len())
+	}
+}
+
+func TestProvider_TTLExpires(t *testing.T) {
+	inner := &countingProvider{}
+	store := NewMemoryStore(10)
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	p, _ := New(inner, store, WithTTL(time.Minute))
+	ctx := context.Background()
+
+	_, _ = p.Generate(ctx, userRequest("hi"))
+	now = now.Add(30 * time.Second)
+	_, _ = p.Generate(ctx, userRequest("hi"))
+	if inner.calls != 1 {
+		t.Fatalf("expected hit within ttl, got %d calls", inner.calls)
+	}
+	now = now.Add(time.Minute)
+	_, _ = p.Generate(ctx, userRequest("hi"))
+	if inner.calls != 2 {
+		t.Fatalf("expected miss after ttl, got %d calls", inner.calls)
+	}
+}
+
+func TestProvider_GenerateStreamReplaysCachedResponse(t *testing.T) {
+	inner := &countingProvider{}
+	p, _ := New(inner, NewMemoryStore(10))
+	ctx := context.Background()
+
+	collect := func() ([]types.StreamChunk, types.Response) {
+		var chunks []types.StreamChunk
+		resp, err := p.GenerateStream(ctx, userRequest("hi"), func(c types.StreamChunk) error {
+			chunks = append(chunks, c)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("generate stream: %v", err)
+		}
+		return chunks, resp
+	}
+
+	missChunks, _ := collect()
+	hitChunks, resp := collect()
+	if inner.calls != 1 {
+		t.Fatalf("expected 1 provider call, got %d", inner.calls)
+	}
+	if !resp.Cached {
+		t.Fatalf("expected replayed response to be marked cached")
+	}
+	if len(hitChunks) != len(missChunks) {
+		t.Fatalf("expected replay to match the original stream: %d vs %d chunks", len(hitChunks), len(missChunks))
+	}
+	wantTypes := []types.StreamEventType{
+		types.StreamEventReasoning,
+		types.StreamEventText,
+		types.StreamEventToolCallDelta,
+		types.StreamEventUsage,
+		types.StreamEventDone,
+	}
+	for i, want := range wantTypes {
+		if hitChunks[i].Type != want {
+			t.Fatalf("chunk %d: expected %s, got %s", i, want, hitChunks[i].Type)
+		}
+	}
+	if hitChunks[2].ToolCallDelta.Name != "lookup" || hitChunks[2].ToolCallDelta.ArgumentsDelta != `{"q":"x"}` {
+		t.Fatalf("unexpected replayed tool call: %+v", hitChunks[2].ToolCallDelta)
+	}
+}
+
+type failingStore struct{}
+
+func (failingStore) Get(ctx context.Context, key string) (types.Response, bool, error) {
+	return types.Response{}, false, errors.New("store down")
+}
+
+func (failingStore) Set(ctx context.Context, key string, resp types.Response, ttl time.Duration) error {
+	return errors.New("store down")
+}
+
+func TestProvider_StoreFailuresFallThrough(t *testing.T) {
+	inner := &countingProvider{}
+	sink := &recordingSink{}
+	p, _ := New(inner, failingStore{}, WithObserver(sink))
+
+	resp, err := p.Generate(context.Background(), userRequest("hi"))
+	if err != nil {
+		t.Fatalf("expected store failure not to fail generation: %v", err)
+	}
+	if resp.Message.Content == "" || inner.calls != 1 {
+		t.Fatalf("expected provider response, got %+v after %d calls", resp, inner.calls)
+	}
+	for _, e := range sink.events {
+		if e.Status != observe.StatusFailed || e.Error == "" {
+			t.Fatalf("expected failed cache events, got %+v", e)
+		}
+	}
+}
+
+func TestKey_IsCanonical(t *testing.T) {
+	a := types.Request{
+		SystemPrompt: "sys",
+		Messages:     []types.Message{{Role: types.RoleUser, Content: "hi"}},
+		Tools: []types.ToolDefinition{
+			{Name: "b", JSONSchema: map[string]any{"type": "object", "required": []string{"x"}}},
+			{Name: "a"},
+		},
+		ResponseSchema: map[string]any{"type": "object", "title": "T"},
+	}
+	b := a
+	b.Tools = []types.ToolDefinition{a.Tools[1], a.Tools[0]}
+	b.ResponseSchema = map[string]any{"title": "T", "type": "object"}
+	b.NoCache = true
+
+	ka, err := Key("p", a)
+	if err != nil {
+		t.Fatalf("key: %v", err)
+	}
+	kb, _ := Key("p", b)
+	if ka != kb {
+		t.Fatalf("expected tool order and opt-out to be ignored")
+	}
+
+	changed := a
+	changed.SystemPrompt = "other"
+	if kc, _ := Key("p", changed); kc == ka {
+		t.Fatalf("expected system prompt to change the key")
+	}
+	if kp, _ := Key("q", a); kp == ka {
+		t.Fatalf("expected provider to change the key")
+	}
+	temp := 0.5
+	changed = a
+	changed.Temperature = &temp
+	if kt, _ := Key("p", changed); kt == ka {
+		t.Fatalf("expected sampling controls to change the key")
+	}
+}
+
+func TestMemoryStore_EvictsLeastRecentlyUsed(t *testing.T) {
+	store := NewMemoryStore(2)
+	ctx := context.Background()
+	_ = store.Set(ctx, "a", types.Response{Model: "a"}, 0)
+	_ = store.Set(ctx, "b", types.Response{Model: "b"}, 0)
+	if _, ok, _ := store.Get(ctx, "a"); !ok {
+		t.Fatalf("expected a to be cached")
+	}
+	_ = store.Set(ctx, "c", types.Response{Model: "c"}, 0)
+
+	if _, ok, _ := store.Get(ctx, "b"); ok {
+		t.Fatalf("expected b to be evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok, _ := store.Get(ctx, key); !ok {
+			t.Fatalf("expected %s to be cached", key)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const defaultMemoryCapacity = 1000
+
+// MemoryStore is an in-process LRU Store. It is lost on restart.
+type MemoryStore struct {
+	mu       sync.Mutex
+	capacity int
+	now      func() time.Time
+	order    *list.List // front is most recently used
+	entries  map[string]*list.Element
+}
+
+type memoryEntry struct {
+	key       string
+	resp      types.Response
+	expiresAt time.Time
+}
+
+// NewMemoryStore returns an LRU holding at most capacity responses. A
+// capacity of zero or less uses 1000.
+func NewMemoryStore(capacity int) *MemoryStore {
+	if capacity <= 0 {
+		capacity = defaultMemoryCapacity
+	}
+	return &MemoryStore{
+		capacity: capacity,
+		now:      time.Now,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+func (m *MemoryStore) Get(ctx context.Context, key string) (types.Response, bool, error) {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	el, ok := m.entries[key]
+	if !ok {
+		return types.Response{}, false, nil
+	}
+	entry := el.Value.(*memoryEntry)
+	if !entry.expiresAt.IsZero() && !m.now().Before(entry.expiresAt) {
+		m.order.Remove(el)
+		delete(m.entries, key)
+		return types.Response{}, false, nil
+	}
+	m.order.MoveToFront(el)
+	return entry.resp, true, nil
+}
+
+func (m *MemoryStore) Set(ctx context.Context, key string, resp types.Response, ttl time.Duration) error {
+	_ = ctx
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry := &memoryEntry{key: key, resp: resp}
+	if ttl > 0 {
+		entry.expiresAt = m.now().Add(ttl)
+	}
+	if el, ok := m.entries[key]; ok {
+		el.Value = entry
+		m.order.MoveToFront(el)
+		return nil
+	}
+	m.entries[key] = m.order.PushFront(entry)
+	for m.order.Len() > m.capacity {
+		oldest := m.order.Back()
+		m.order.Remove(oldest)
+		delete(m.entries, oldest.Value.(*memoryEntry).key)
+	}
+	return nil
+}
+
+// Len returns the number of cached responses, including expired ones not
+// yet evicted.
+func (m *MemoryStore) Len() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.order.Len()
+}
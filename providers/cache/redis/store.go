@@ -0,0 +1,118 @@
+// Package redis stores cached LLM responses in Redis, using the same
+// connection settings as the redis state store.
+package redis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	goredis "github.com/redis/go-redis/v9"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/providers/cache"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const defaultPrefix = "aiag"
+
+type Store struct {
+	client   *goredis.Client
+	prefix   string
+	addr     string
+	db       int
+	password string
+}
+
+type Option func(*Store)
+
+func WithPassword(password string) Option {
+	return func(s *Store) {
+		s.password = password
+	}
+}
+
+func WithDB(db int) Option {
+	return func(s *Store) {
+		s.db = db
+	}
+}
+
+func WithPrefix(prefix string) Option {
+	return func(s *Store) {
+		if strings.TrimSpace(prefix) != "" {
+			s.prefix = strings.TrimSpace(prefix)
+		}
+	}
+}
+
+func WithClient(client *goredis.Client) Option {
+	return func(s *Store) {
+		if client != nil {
+			s.client = client
+		}
+	}
+}
+
+func New(addr string, opts ...Option) (*Store, error) {
+	if strings.TrimSpace(addr) == "" {
+		return nil, fmt.Errorf("redis addr is required")
+	}
+	s := &Store{prefix: defaultPrefix, addr: addr}
+	for _, opt := range opts {
+		opt(s)
+	}
+	if s.client == nil {
+		s.client = goredis.NewClient(&goredis.Options{
+			Addr:     s.addr,
+			Password: s.password,
+			DB:       s.db,
+		})
+	}
+	if err := s.client.Ping(context.Background()).Err(); err != nil {
+		return nil, fmt.Errorf("redis ping failed: %w", err)
+	}
+	return s, nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (types.Response, bool, error) {
+	raw, err := s.client.Get(ctx, s.key(key)).Bytes()
+	if errors.Is(err, goredis.Nil) {
+		return types.Response{}, false, nil
+	}
+	if err != nil {
+		return types.Response{}, false, fmt.Errorf("failed to load cached response: %w", err)
+	}
+	var resp types.Response
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return types.Response{}, false, fmt.Errorf("failed to decode cached response: %w", err)
+	}
+	return resp, true, nil
+}
+
+// Set stores resp with ttl as the key's expiry, so Redis evicts it.
+func (s *Store) Set(ctx context.Context, key string, resp types.Response, ttl time.Duration) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached response: %w", err)
+	}
+	if err := s.client.Set(ctx, s.key(key), raw, ttl).Err(); err != nil {
+		return fmt.Errorf("failed to save cached response: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) Close() error {
+	if s.client == nil {
+		return nil
+	}
+	return s.client.Close()
+}
+
+func (s *Store) key(key string) string {
+	return fmt.Sprintf("%s:llmcache:%s", s.prefix, key)
+}
+
+var _ cache.Store = (*Store)(nil)
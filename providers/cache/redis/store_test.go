@@ -0,0 +1,61 @@
+package redis
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func newTestRedisStore(t *testing.T) *Store {
+	t.Helper()
+
+	addr := os.Getenv("TEST_REDIS_ADDR")
+	if addr == "" {
+		addr = "127.0.0.1:6379"
+	}
+	prefix := "aiag-test-" + uuid.NewString()
+
+	s, err := New(addr, WithPrefix(prefix))
+	if err != nil {
+		t.Skipf("redis unavailable at %s: %v", addr, err)
+	}
+	t.Cleanup(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		defer cancel()
+		keys, _ := s.client.Keys(ctx, prefix+":*").Result()
+		if len(keys) > 0 {
+			_ = s.client.Del(ctx, keys...).Err()
+		}
+		_ = s.Close()
+	})
+	return s
+}
+
+func TestRedisStore_SetGetAndTTL(t *testing.T) {
+	s := newTestRedisStore(t)
+	ctx := context.Background()
+
+	resp := types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "cached"}, Model: "m1"}
+	if err := s.Set(ctx, "k1", resp, time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	got, ok, err := s.Get(ctx, "k1")
+	if err != nil || !ok {
+		t.Fatalf("expected hit, got ok=%v err=%v", ok, err)
+	}
+	if got.Message.Content != "cached" || got.Model != "m1" {
+		t.Fatalf("unexpected cached response: %+v", got)
+	}
+	ttl, err := s.client.TTL(ctx, s.key("k1")).Result()
+	if err != nil || ttl <= 0 || ttl > time.Minute {
+		t.Fatalf("expected ttl to be set, got %v (%v)", ttl, err)
+	}
+	if _, ok, _ := s.Get(ctx, "missing"); ok {
+		t.Fatalf("expected miss for unknown key")
+	}
+}
@@ -0,0 +1,133 @@
+// Package sqlite stores cached LLM responses in SQLite. It can share the
+// database file used by the sqlite state store.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/providers/cache"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+//go:embed schema.sql
+var schemaSQL string
+
+const defaultBusyTimeout = 5 * time.Second
+
+// timeLayout has fixed-width fractions so stored times compare as strings.
+const timeLayout = "2006-01-02T15:04:05.000000000Z"
+
+type Store struct {
+	db  *sql.DB
+	now func() time.Time
+}
+
+func New(path string) (*Store, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("sqlite path is required")
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create sqlite directory: %w", err)
+	}
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite db: %w", err)
+	}
+	db.SetMaxOpenConns(1)
+	db.SetMaxIdleConns(1)
+
+	ctx := context.Background()
+	ms := int(defaultBusyTimeout / time.Millisecond)
+	if _, err := db.ExecContext(ctx, fmt.Sprintf("PRAGMA busy_timeout=%d;", ms)); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to set busy_timeout: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, "PRAGMA journal_mode=WAL;"); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to enable wal: %w", err)
+	}
+	if _, err := db.ExecContext(ctx, schemaSQL); err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("failed to initialize cache schema: %w", err)
+	}
+	return &Store{db: db, now: time.Now}, nil
+}
+
+func (s *Store) Get(ctx context.Context, key string) (types.Response, bool, error) {
+	var (
+		raw       string
+		expiresAt sql.NullString
+	)
+	err := s.db.QueryRowContext(ctx, `SELECT response, expires_at FROM llm_cache WHERE cache_key = ?`, key).Scan(&raw, &expiresAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return types.Response{}, false, nil
+	}
+	if err != nil {
+		return types.Response{}, false, fmt.Errorf("failed to load cached response: %w", err)
+	}
+	if expiresAt.Valid {
+		expiry, err := time.Parse(timeLayout, expiresAt.String)
+		if err != nil || !s.now().Before(expiry) {
+			_, _ = s.db.ExecContext(ctx, `DELETE FROM llm_cache WHERE cache_key = ?`, key)
+			return types.Response{}, false, nil
+		}
+	}
+	var resp types.Response
+	if err := json.Unmarshal([]byte(raw), &resp); err != nil {
+		return types.Response{}, false, fmt.Errorf("failed to decode cached response: %w", err)
+	}
+	return resp, true, nil
+}
+
+func (s *Store) Set(ctx context.Context, key string, resp types.Response, ttl time.Duration) error {
+	raw, err := json.Marshal(resp)
+	if err != nil {
+		return fmt.Errorf("failed to marshal cached response: %w", err)
+	}
+	now := s.now().UTC()
+	var expiresAt any
+	if ttl > 0 {
+		expiresAt = now.Add(ttl).Format(timeLayout)
+	}
+	const q = `
+INSERT INTO llm_cache (cache_key, response, created_at, expires_at)
+VALUES (?, ?, ?, ?)
+ON CONFLICT(cache_key) DO UPDATE SET
+  response = excluded.response,
+  created_at = excluded.created_at,
+  expires_at = excluded.expires_at;
+`
+	if _, err := s.db.ExecContext(ctx, q, key, string(raw), now.Format(timeLayout), expiresAt); err != nil {
+		return fmt.Errorf("failed to save cached response: %w", err)
+	}
+	return nil
+}
+
+// Purge deletes expired entries and returns how many were removed.
+func (s *Store) Purge(ctx context.Context) (int64, error) {
+	res, err := s.db.ExecContext(ctx, `DELETE FROM llm_cache WHERE expires_at IS NOT NULL AND expires_at <= ?`, s.now().UTC().Format(timeLayout))
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge cache: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+func (s *Store) Close() error {
+	if s.db == nil {
+		return nil
+	}
+	return s.db.Close()
+}
+
+var _ cache.Store = (*Store)(nil)
@@ -0,0 +1,77 @@
+package sqlite
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestStore_SetGetAndExpire(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	resp := types.Response{
+		Message: types.Message{Role: types.RoleAssistant, Content: "cached"},
+		Usage:   &types.Usage{InputTokens: 3, OutputTokens: 2, TotalTokens: 5},
+		Model:   "m1",
+	}
+	if err := store.Set(ctx, "k1", resp, time.Minute); err != nil {
+		t.Fatalf("set: %v", err)
+	}
+	if err := store.Set(ctx, "k2", resp, 0); err != nil {
+		t.Fatalf("set without ttl: %v", err)
+	}
+
+	got, ok, err := store.Get(ctx, "k1")
+	if err != nil || !ok {
+		t.Fatalf("expected hit, got ok=%v err=%v", ok, err)
+	}
+	if got.Message.Content != "cached" || got.Usage == nil || got.Usage.TotalTokens != 5 || got.Model != "m1" {
+		t.Fatalf("unexpected cached response: %+v", got)
+	}
+	if _, ok, _ := store.Get(ctx, "missing"); ok {
+		t.Fatalf("expected miss for unknown key")
+	}
+
+	now = now.Add(2 * time.Minute)
+	if _, ok, _ := store.Get(ctx, "k1"); ok {
+		t.Fatalf("expected k1 to have expired")
+	}
+	if _, ok, _ := store.Get(ctx, "k2"); !ok {
+		t.Fatalf("expected entry without ttl to remain")
+	}
+}
+
+func TestStore_Purge(t *testing.T) {
+	store, err := New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("new store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	resp := types.Response{Message: types.Message{Content: "x"}}
+	_ = store.Set(ctx, "short", resp, time.Second)
+	_ = store.Set(ctx, "long", resp, time.Hour)
+
+	now = now.Add(time.Minute)
+	n, err := store.Purge(ctx)
+	if err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if n != 1 {
+		t.Fatalf("expected 1 purged entry, got %d", n)
+	}
+}
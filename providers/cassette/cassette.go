@@ -0,0 +1,426 @@
+// Package cassette records the requests and responses of an llm.Provider to
+// a file and replays them later, so agent runs and evals can be
+// regression-tested offline and deterministically.
+package cassette
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const fileVersion = 1
+
+// Mode selects whether a cassette is being written or read.
+type Mode string
+
+const (
+	ModeRecord Mode = "record"
+	ModeReplay Mode = "replay"
+)
+
+// ErrNoInteraction matches every *MismatchError with errors.Is.
+var ErrNoInteraction = errors.New("no recorded interaction matches request")
+
+// MismatchError is returned during replay when no unused interaction has the
+// request's fingerprint, typically because the prompt, tools or conversation
+// changed since the cassette was recorded.
+type MismatchError struct {
+	Path        string
+	Fingerprint string
+	LastMessage string
+	Recorded    int
+	Remaining   int
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("cassette %s: no recorded interaction for request %s (last message %q; %d recorded, %d unused); re-record the cassette if the change is intended",
+		e.Path, shortFingerprint(e.Fingerprint), e.LastMessage, e.Recorded, e.Remaining)
+}
+
+func (e *MismatchError) Is(target error) bool { return target == ErrNoInteraction }
+
+// Interaction is one recorded generation. Chunks are only present for
+// streamed generations; Error holds the provider error, if any.
+type Interaction struct {
+	Fingerprint string              `json:"fingerprint"`
+	Request     types.Request       `json:"request"`
+	Response    *types.Response     `json:"response,omitempty"`
+	Chunks      []types.StreamChunk `json:"chunks,omitempty"`
+	Error       string              `json:"error,omitempty"`
+}
+
+type cassetteFile struct {
+	Version      int              `json:"version"`
+	Provider     string           `json:"provider"`
+	Capabilities llm.Capabilities `json:"capabilities"`
+	Interactions []Interaction    `json:"interactions"`
+}
+
+// Provider records or replays generations. It implements llm.Provider and
+// llm.StreamProvider, so it can be passed to agent.New directly.
+type Provider struct {
+	mode        Mode
+	path        string
+	inner       llm.Provider
+	fingerprint Fingerprint
+
+	mu   sync.Mutex
+	file cassetteFile
+	used []bool
+}
+
+type Option func(*Provider)
+
+// WithFingerprint sets how requests are matched. It must be the same when
+// recording and replaying. Defaults to DefaultFingerprint.
+func WithFingerprint(fingerprint Fingerprint) Option {
+	return func(p *Provider) {
+		if fingerprint != nil {
+			p.fingerprint = fingerprint
+		}
+	}
+}
+
+// Record returns a provider that forwards to inner and writes every
+// interaction to path, replacing any existing cassette. The file is
+// rewritten after each interaction, so no explicit close is needed.
+func Record(inner llm.Provider, path string, opts ...Option) (*Provider, error) {
+	if inner == nil {
+		return nil, fmt.Errorf("cassette recording requires a provider")
+	}
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("cassette path is required")
+	}
+	p := &Provider{
+		mode:        ModeRecord,
+		path:        path,
+		inner:       inner,
+		fingerprint: DefaultFingerprint,
+		file: cassetteFile{
+			Version:      fileVersion,
+			Provider:     inner.Name(),
+			Capabilities: inner.Capabilities(),
+			Interactions: []Interaction{},
+		},
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	if err := p.save(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Replay returns a provider that answers requests from the cassette at path
+// without contacting any model. Each recorded interaction is used once.
+func Replay(path string, opts ...Option) (*Provider, error) {
+	if strings.TrimSpace(path) == "" {
+		return nil, fmt.Errorf("cassette path is required")
+	}
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read cassette: %w", err)
+	}
+	var file cassetteFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("failed to decode cassette %s: %w", path, err)
+	}
+	if file.Version != fileVersion {
+		return nil, fmt.Errorf("unsupported cassette version %d in %s", file.Version, path)
+	}
+	p := &Provider{
+		mode:        ModeReplay,
+		path:        path,
+		fingerprint: DefaultFingerprint,
+		file:        file,
+		used:        make([]bool, len(file.Interactions)),
+	}
+	for _, opt := range opts {
+		opt(p)
+	}
+	return p, nil
+}
+
+// Open records or replays depending on mode. inner is only used when
+// recording.
+func Open(mode Mode, path string, inner llm.Provider, opts ...Option) (*Provider, error) {
+	switch mode {
+	case ModeRecord:
+		return Record(inner, path, opts...)
+	case ModeReplay:
+		return Replay(path, opts...)
+	default:
+		return nil, fmt.Errorf("unsupported cassette mode %q", mode)
+	}
+}
+
+// Name reports the recorded provider's name so replayed runs look like the
+// original ones.
+func (p *Provider) Name() string { return p.file.Provider }
+
+// Capabilities reports the recorded provider's capabilities. Streaming is
+// always available because unstreamed interactions are replayed as chunks.
+func (p *Provider) Capabilities() llm.Capabilities {
+	caps := p.file.Capabilities
+	caps.Streaming = true
+	return caps
+}
+
+func (p *Provider) Mode() Mode { return p.mode }
+
+// Remaining returns how many recorded interactions have not been replayed.
+// A non-zero value after a replayed run means the run made fewer calls than
+// the recording.
+func (p *Provider) Remaining() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := 0
+	for _, used := range p.used {
+		if !used {
+			n++
+		}
+	}
+	return n
+}
+
+func (p *Provider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	fingerprint, err := p.fingerprint(req)
+	if err != nil {
+		return types.Response{}, err
+	}
+	if p.mode == ModeReplay {
+		it, err := p.match(fingerprint, req)
+		if err != nil {
+			return types.Response{}, err
+		}
+		return replayResult(it)
+	}
+
+	resp, genErr := p.inner.Generate(ctx, req)
+	if err := p.record(fingerprint, req, resp, nil, genErr); err != nil {
+		return types.Response{}, err
+	}
+	return resp, genErr
+}
+
+// GenerateStream implements llm.StreamProvider. Recorded chunks are replayed
+// exactly; interactions recorded without streaming are replayed as one chunk
+// per part of the response.
+func (p *Provider) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	if onChunk == nil {
+		return types.Response{}, fmt.Errorf("onChunk is required")
+	}
+	fingerprint, err := p.fingerprint(req)
+	if err != nil {
+		return types.Response{}, err
+	}
+	if p.mode == ModeReplay {
+		it, err := p.match(fingerprint, req)
+		if err != nil {
+			return types.Response{}, err
+		}
+		chunks := it.Chunks
+		if len(chunks) == 0 && it.Response != nil {
+			chunks = responseChunks(*it.Response)
+		}
+		for _, chunk := range chunks {
+			if err := onChunk(chunk); err != nil {
+				return types.Response{}, err
+			}
+		}
+		return replayResult(it)
+	}
+
+	sp, ok := p.inner.(llm.StreamProvider)
+	if !ok {
+		resp, genErr := p.inner.Generate(ctx, req)
+		if err := p.record(fingerprint, req, resp, nil, genErr); err != nil {
+			return types.Response{}, err
+		}
+		if genErr != nil {
+			return types.Response{}, genErr
+		}
+		for _, chunk := range responseChunks(resp) {
+			if err := onChunk(chunk); err != nil {
+				return types.Response{}, err
+			}
+		}
+		return resp, nil
+	}
+
+	var (
+		chunks      []types.StreamChunk
+		callbackErr error
+	)
+	resp, genErr := sp.GenerateStream(ctx, req, func(chunk types.StreamChunk) error {
+		chunks = append(chunks, chunk)
+		if err := onChunk(chunk); err != nil {
+			callbackErr = err
+			return err
+		}
+		return nil
+	})
+	// A failing callback is the caller's problem, not the provider's, and
+	// must not end up in the cassette.
+	if callbackErr != nil {
+		return types.Response{}, callbackErr
+	}
+	if err := p.record(fingerprint, req, resp, chunks, genErr); err != nil {
+		return types.Response{}, err
+	}
+	return resp, genErr
+}
+
+func (p *Provider) match(fingerprint string, req types.Request) (Interaction, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	remaining := 0
+	for i, it := range p.file.Interactions {
+		if p.used[i] {
+			continue
+		}
+		remaining++
+		if it.Fingerprint == fingerprint {
+			p.used[i] = true
+			return it, nil
+		}
+	}
+	return Interaction{}, &MismatchError{
+		Path:        p.path,
+		Fingerprint: fingerprint,
+		LastMessage: lastMessagePreview(req),
+		Recorded:    len(p.file.Interactions),
+		Remaining:   remaining,
+	}
+}
+
+func (p *Provider) record(fingerprint string, req types.Request, resp types.Response, chunks []types.StreamChunk, genErr error) error {
+	it := Interaction{Fingerprint: fingerprint, Request: req, Chunks: chunks}
+	if genErr != nil {
+		it.Error = genErr.Error()
+	} else {
+		it.Response = &resp
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.file.Interactions = append(p.file.Interactions, it)
+	return p.saveLocked()
+}
+
+func (p *Provider) save() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.saveLocked()
+}
+
+// saveLocked writes through a temporary file so an interrupted recording
+// never leaves a truncated cassette behind.
+func (p *Provider) saveLocked() error {
+	raw, err := encodeFile(p.file)
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+	if dir := filepath.Dir(p.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+	tmp := p.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	if err := os.Rename(tmp, p.path); err != nil {
+		return fmt.Errorf("failed to write cassette: %w", err)
+	}
+	return nil
+}
+
+// encodeFile writes one interaction per line so cassettes diff well under
+// version control. Indenting would reformat raw tool-call arguments, which
+// must replay byte for byte.
+func encodeFile(file cassetteFile) ([]byte, error) {
+	caps, err := json.Marshal(file.Capabilities)
+	if err != nil {
+		return nil, err
+	}
+	provider, err := json.Marshal(file.Provider)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "{\n\"version\": %d,\n\"provider\": %s,\n\"capabilities\": %s,\n\"interactions\": [", file.Version, provider, caps)
+	for i, it := range file.Interactions {
+		raw, err := json.Marshal(it)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		buf.WriteString("\n")
+		buf.Write(raw)
+	}
+	buf.WriteString("\n]}\n")
+	return buf.Bytes(), nil
+}
+
+func replayResult(it Interaction) (types.Response, error) {
+	if it.Error != "" {
+		return types.Response{}, errors.New(it.Error)
+	}
+	if it.Response == nil {
+		return types.Response{}, fmt.Errorf("cassette interaction %s has no response", shortFingerprint(it.Fingerprint))
+	}
+	return *it.Response, nil
+}
+
+func responseChunks(resp types.Response) []types.StreamChunk {
+	var chunks []types.StreamChunk
+	_ = llm.EmitResponse(resp, func(chunk types.StreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	return append(chunks, types.StreamChunk{Type: types.StreamEventDone, Done: true})
+}
+
+func lastMessagePreview(req types.Request) string {
+	if len(req.Messages) == 0 {
+		return ""
+	}
+	last := req.Messages[len(req.Messages)-1]
+	text := strings.TrimSpace(last.Content)
+	if len(text) > 80 {
+		text = text[:80] + "..."
+	}
+	return string(last.Role) + ": " + text
+}
+
+func shortFingerprint(fingerprint string) string {
+	if len(fingerprint) > 12 {
+		return fingerprint[:12]
+	}
+	return fingerprint
+}
+
+// ParseMode maps a configuration string to a Mode.
+func ParseMode(s string) (Mode, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "replay", "playback":
+		return ModeReplay, nil
+	case "record":
+		return ModeRecord, nil
+	default:
+		return "", fmt.Errorf("unsupported cassette mode %q (use record or replay)", s)
+	}
+}
@@ -0,0 +1,282 @@
+package cassette
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/agent"
+	"github.com/PipeOpsHQ/agent-sdk-go/eval"
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/tools"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// scriptedProvider calls the echo tool once and then answers with the tool
+// result, streaming when asked to.
+type scriptedProvider struct {
+	calls int
+}
+
+func (p *scriptedProvider) Name() string { return "scripted" }
+
+func (p *scriptedProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true, Streaming: true}
+}
+
+func (p *scriptedProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	p.calls++
+	last := req.Messages[len(req.Messages)-1]
+	if last.Role != types.RoleTool {
+		return types.Response{
+			Message: types.Message{
+				Role:      types.RoleAssistant,
+				ToolCalls: []types.ToolCall{{ID: "call-1", Name: "echo", Arguments: json.RawMessage(`{"text":"` + last.Content + `"}`)}},
+			},
+			Usage: &types.Usage{InputTokens: 7, OutputTokens: 3, TotalTokens: 10},
+		}, nil
+	}
+	return types.Response{
+		Message: types.Message{Role: types.RoleAssistant, Content: "echoed " + last.Content},
+		Usage:   &types.Usage{InputTokens: 9, OutputTokens: 4, TotalTokens: 13},
+	}, nil
+}
+
+func (p *scriptedProvider) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	resp, err := p.Generate(ctx, req)
+	if err != nil {
+		return types.Response{}, err
+	}
+	for _, part := range strings.SplitAfter(resp.Message.Content, " ") {
+		if part == "" {
+			continue
+		}
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventText, Text: part}); err != nil {
+			return types.Response{}, err
+		}
+	}
+	return resp, onChunk(types.StreamChunk{Type: types.StreamEventDone, Done: true})
+}
+
+type failingProvider struct{}
+
+func (failingProvider) Name() string                   { return "failing" }
+func (failingProvider) Capabilities() llm.Capabilities { return llm.Capabilities{} }
+func (failingProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	return types.Response{}, errors.New("rate limited")
+}
+
+func echoTool() tools.Tool {
+	return tools.NewFuncTool("echo", "echoes text", map[string]any{"type": "object"}, func(ctx context.Context, args json.RawMessage) (any, error) {
+		var in struct {
+			Text string `json:"text"`
+		}
+		_ = json.Unmarshal(args, &in)
+		return in.Text, nil
+	})
+}
+
+func userRequest(text string) types.Request {
+	return types.Request{SystemPrompt: "be brief", Messages: []types.Message{{Role: types.RoleUser, Content: text}}}
+}
+
+func TestRecordThenReplay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "cassettes", "generate.json")
+	inner := &scriptedProvider{}
+	rec, err := Record(inner, path)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	want, err := rec.Generate(context.Background(), userRequest("hi"))
+	if err != nil {
+		t.Fatalf("recorded generate: %v", err)
+	}
+
+	replay, err := Replay(path)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if replay.Name() != "scripted" || !replay.Capabilities().Tools {
+		t.Fatalf("expected recorded provider identity, got %q %+v", replay.Name(), replay.Capabilities())
+	}
+	got, err := replay.Generate(context.Background(), userRequest("hi"))
+	if err != nil {
+		t.Fatalf("replayed generate: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("replayed response differs:\n got %+v\nwant %+v", got, want)
+	}
+	if inner.calls != 1 {
+		t.Fatalf("expected replay not to call the provider, got %d calls", inner.calls)
+	}
+	if replay.Remaining() != 0 {
+		t.Fatalf("expected every interaction to be used, %d remaining", replay.Remaining())
+	}
+
+	// Each interaction answers one request only.
+	_, err = replay.Generate(context.Background(), userRequest("hi"))
+	var mismatch *MismatchError
+	if !errors.Is(err, ErrNoInteraction) || !errors.As(err, &mismatch) {
+		t.Fatalf("expected mismatch error, got %v", err)
+	}
+	if !strings.Contains(err.Error(), `user: hi`) {
+		t.Fatalf("expected mismatch error to describe the request, got %v", err)
+	}
+}
+
+func TestReplay_FailsOnChangedRequest(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	rec, _ := Record(&scriptedProvider{}, path)
+	_, _ = rec.Generate(context.Background(), userRequest("hi"))
+
+	replay, _ := Replay(path)
+	changed := userRequest("hi")
+	changed.SystemPrompt = "be verbose"
+	if _, err := replay.Generate(context.Background(), changed); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("expected a changed system prompt to miss, got %v", err)
+	}
+
+	tolerant, _ := Replay(path, WithFingerprint(FingerprintOf(FieldMessages)))
+	if _, err := tolerant.Generate(context.Background(), changed); !errors.Is(err, ErrNoInteraction) {
+		t.Fatalf("expected fingerprints recorded with a different function to miss, got %v", err)
+	}
+
+	rec, _ = Record(&scriptedProvider{}, path, WithFingerprint(FingerprintOf(FieldMessages)))
+	_, _ = rec.Generate(context.Background(), userRequest("hi"))
+	tolerant, _ = Replay(path, WithFingerprint(FingerprintOf(FieldMessages)))
+	if _, err := tolerant.Generate(context.Background(), changed); err != nil {
+		t.Fatalf("expected messages-only fingerprint to ignore the system prompt, got %v", err)
+	}
+}
+
+func TestRecordThenReplay_Stream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "stream.json")
+	rec, _ := Record(&scriptedProvider{}, path)
+	req := userRequest("hi")
+	req.Messages = append(req.Messages, types.Message{Role: types.RoleTool, Content: "hi there"})
+
+	var recorded []types.StreamChunk
+	if _, err := rec.GenerateStream(context.Background(), req, func(c types.StreamChunk) error {
+		recorded = append(recorded, c)
+		return nil
+	}); err != nil {
+		t.Fatalf("recorded stream: %v", err)
+	}
+
+	replay, _ := Replay(path)
+	var replayed []types.StreamChunk
+	resp, err := replay.GenerateStream(context.Background(), req, func(c types.StreamChunk) error {
+		replayed = append(replayed, c)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("replayed stream: %v", err)
+	}
+	if !reflect.DeepEqual(replayed, recorded) {
+		t.Fatalf("replayed chunks differ:\n got %+v\nwant %+v", replayed, recorded)
+	}
+	if resp.Message.Content != "echoed hi there" {
+		t.Fatalf("unexpected replayed response: %+v", resp)
+	}
+}
+
+func TestReplay_StreamsUnstreamedInteraction(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	rec, _ := Record(&scriptedProvider{}, path)
+	_, _ = rec.Generate(context.Background(), userRequest("hi"))
+
+	replay, _ := Replay(path)
+	var kinds []types.StreamEventType
+	if _, err := replay.GenerateStream(context.Background(), userRequest("hi"), func(c types.StreamChunk) error {
+		kinds = append(kinds, c.Type)
+		return nil
+	}); err != nil {
+		t.Fatalf("replayed stream: %v", err)
+	}
+	want := []types.StreamEventType{types.StreamEventToolCallDelta, types.StreamEventUsage, types.StreamEventDone}
+	if !reflect.DeepEqual(kinds, want) {
+		t.Fatalf("expected chunks %v, got %v", want, kinds)
+	}
+}
+
+func TestReplay_ReproducesProviderErrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "c.json")
+	rec, _ := Record(failingProvider{}, path)
+	if _, err := rec.Generate(context.Background(), userRequest("hi")); err == nil {
+		t.Fatalf("expected recorded provider error")
+	}
+
+	replay, _ := Replay(path)
+	_, err := replay.Generate(context.Background(), userRequest("hi"))
+	if err == nil || err.Error() != "rate limited" {
+		t.Fatalf("expected replayed provider error, got %v", err)
+	}
+}
+
+func TestReplay_AgentRunThroughEvalRunner(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eval.json")
+	cases := []eval.Case{
+		{ID: "c1", Input: "ping", ExpectedOutput: "ping", RequiredTools: []string{"echo"}},
+		{ID: "c2", Input: "pong", ExpectedOutput: "pong", RequiredTools: []string{"echo"}},
+	}
+	runEval := func(p llm.Provider) eval.Report {
+		t.Helper()
+		a, err := agent.New(p, agent.WithTool(echoTool()), agent.WithMaxIterations(3))
+		if err != nil {
+			t.Fatalf("new agent: %v", err)
+		}
+		runner, err := eval.NewRunner(eval.RunnerConfig{Agent: a})
+		if err != nil {
+			t.Fatalf("new runner: %v", err)
+		}
+		report, err := runner.Run(context.Background(), cases, eval.RunOptions{Workers: 2})
+		if err != nil {
+			t.Fatalf("eval run: %v", err)
+		}
+		return report
+	}
+
+	inner := &scriptedProvider{}
+	rec, err := Record(inner, path)
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if report := runEval(rec); report.Passed != 2 {
+		t.Fatalf("expected recorded eval to pass, got %+v", report.Results)
+	}
+	if inner.calls != 4 {
+		t.Fatalf("expected 4 recorded generations, got %d", inner.calls)
+	}
+
+	replay, err := Replay(path)
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	report := runEval(replay)
+	if report.Passed != 2 {
+		t.Fatalf("expected replayed eval to pass, got %+v", report.Results)
+	}
+	if report.TotalTokens != 46 {
+		t.Fatalf("expected recorded usage to be replayed, got %d tokens", report.TotalTokens)
+	}
+	if inner.calls != 4 || replay.Remaining() != 0 {
+		t.Fatalf("expected a fully offline replay, got %d calls and %d unused interactions", inner.calls, replay.Remaining())
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": ModeReplay, "Replay": ModeReplay, "record": ModeRecord} {
+		got, err := ParseMode(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMode("rewind"); err == nil {
+		t.Fatalf("expected unsupported mode error")
+	}
+}
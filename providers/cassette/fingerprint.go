@@ -0,0 +1,82 @@
+package cassette
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// Fingerprint identifies the requests that a recorded response may answer.
+type Fingerprint func(req types.Request) (string, error)
+
+// Field is a part of a request that FingerprintOf can include.
+type Field string
+
+const (
+	FieldModel          Field = "model"
+	FieldSystemPrompt   Field = "systemPrompt"
+	FieldMessages       Field = "messages"
+	FieldTools          Field = "tools"
+	FieldResponseSchema Field = "responseSchema"
+	// FieldSampling covers max output tokens, sampling controls and tool
+	// choice.
+	FieldSampling Field = "sampling"
+)
+
+// DefaultFingerprint matches requests that are identical in every field.
+var DefaultFingerprint = FingerprintOf(FieldModel, FieldSystemPrompt, FieldMessages, FieldTools, FieldResponseSchema, FieldSampling)
+
+// FingerprintOf hashes only the given fields, so replay tolerates changes to
+// the rest. For example FingerprintOf(FieldMessages) keeps a cassette valid
+// while the system prompt is being tuned. Tools are sorted by name because
+// agents build them from maps.
+func FingerprintOf(fields ...Field) Fingerprint {
+	include := make(map[Field]bool, len(fields))
+	for _, f := range fields {
+		include[f] = true
+	}
+	return func(req types.Request) (string, error) {
+		parts := map[string]any{}
+		if include[FieldModel] {
+			parts["model"] = req.Model
+		}
+		if include[FieldSystemPrompt] {
+			parts["systemPrompt"] = req.SystemPrompt
+		}
+		if include[FieldMessages] {
+			parts["messages"] = req.Messages
+		}
+		if include[FieldTools] {
+			tools := append([]types.ToolDefinition(nil), req.Tools...)
+			sort.SliceStable(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+			parts["tools"] = tools
+		}
+		if include[FieldResponseSchema] {
+			parts["responseSchema"] = req.ResponseSchema
+		}
+		if include[FieldSampling] {
+			parts["sampling"] = map[string]any{
+				"maxOutputTokens":   req.MaxOutputTokens,
+				"temperature":       req.Temperature,
+				"topP":              req.TopP,
+				"topK":              req.TopK,
+				"stopSequences":     req.StopSequences,
+				"seed":              req.Seed,
+				"presencePenalty":   req.PresencePenalty,
+				"frequencyPenalty":  req.FrequencyPenalty,
+				"toolChoice":        req.ToolChoice,
+				"parallelToolCalls": req.ParallelToolCalls,
+			}
+		}
+		raw, err := json.Marshal(parts)
+		if err != nil {
+			return "", fmt.Errorf("failed to encode request fingerprint: %w", err)
+		}
+		sum := sha256.Sum256(raw)
+		return hex.EncodeToString(sum[:]), nil
+	}
+}
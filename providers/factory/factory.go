@@ -4,18 +4,55 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/PipeOpsHQ/agent-sdk-go/llm"
 	anthropicprov "github.com/PipeOpsHQ/agent-sdk-go/providers/anthropic"
 	azureopenaiprov "github.com/PipeOpsHQ/agent-sdk-go/providers/azureopenai"
+	cacheprov "github.com/PipeOpsHQ/agent-sdk-go/providers/cache"
+	cacheredis "github.com/PipeOpsHQ/agent-sdk-go/providers/cache/redis"
+	cachesqlite "github.com/PipeOpsHQ/agent-sdk-go/providers/cache/sqlite"
+	cassetteprov "github.com/PipeOpsHQ/agent-sdk-go/providers/cassette"
 	geminiprov "github.com/PipeOpsHQ/agent-sdk-go/providers/gemini"
 	ollamaprov "github.com/PipeOpsHQ/agent-sdk-go/providers/ollama"
 	openaiprov "github.com/PipeOpsHQ/agent-sdk-go/providers/openai"
+	openaicompatprov "github.com/PipeOpsHQ/agent-sdk-go/providers/openaicompat"
+	routerprov "github.com/PipeOpsHQ/agent-sdk-go/providers/router"
 )
 
 func FromEnv(ctx context.Context) (llm.Provider, error) {
+	cassettePath := strings.TrimSpace(os.Getenv("AGENT_CASSETTE"))
+	mode, err := cassetteprov.ParseMode(os.Getenv("AGENT_CASSETTE_MODE"))
+	if err != nil {
+		return nil, err
+	}
+	// Replay never reaches a model, so it needs no provider credentials.
+	if cassettePath != "" && mode == cassetteprov.ModeReplay {
+		return cassetteprov.Replay(cassettePath)
+	}
+
 	provider := strings.ToLower(strings.TrimSpace(getenv("AGENT_PROVIDER", "gemini")))
+	var p llm.Provider
+	if provider == "router" {
+		p, err = routerFromEnv(ctx)
+	} else {
+		p, err = newProvider(ctx, provider)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if cassettePath != "" {
+		// Record below the cache so cassettes hold real model responses.
+		if p, err = cassetteprov.Record(p, cassettePath); err != nil {
+			return nil, err
+		}
+	}
+	return cacheFromEnv(p)
+}
+
+func newProvider(ctx context.Context, provider string) (llm.Provider, error) {
 	switch provider {
 	case "openai":
 		key := strings.TrimSpace(os.Getenv("OPENAI_API_KEY"))
@@ -86,9 +123,195 @@ func FromEnv(ctx context.Context) (llm.Provider, error) {
 			azureopenaiprov.WithModel(model),
 			azureopenaiprov.WithAPIVersion(apiVersion),
 		)
+
+	case "openaicompat", "openai-compatible":
+		return openAICompatFromEnv()
+	}
+
+	return nil, fmt.Errorf("unsupported AGENT_PROVIDER %q (use gemini, openai, anthropic, ollama, azureopenai, openaicompat, or router)", provider)
+}
+
+// openAICompatFromEnv builds an OpenAI-compatible client. The quirk profile
+// is a built-in name in OPENAI_COMPAT_PROFILE or a JSON file in
+// OPENAI_COMPAT_PROFILE_FILE; the file wins when both are set.
+func openAICompatFromEnv() (llm.Provider, error) {
+	var (
+		profile openaicompatprov.Profile
+		err     error
+	)
+	if path := strings.TrimSpace(os.Getenv("OPENAI_COMPAT_PROFILE_FILE")); path != "" {
+		profile, err = openaicompatprov.LoadProfile(path)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		name := getenv("OPENAI_COMPAT_PROFILE", "generic")
+		var ok bool
+		profile, ok = openaicompatprov.LookupProfile(name)
+		if !ok {
+			return nil, fmt.Errorf("unknown OPENAI_COMPAT_PROFILE %q (use %s)", name, strings.Join(openaicompatprov.ProfileNames(), ", "))
+		}
+	}
+
+	model := strings.TrimSpace(os.Getenv("OPENAI_COMPAT_MODEL"))
+	if model == "" {
+		return nil, fmt.Errorf("OPENAI_COMPAT_MODEL is required when AGENT_PROVIDER=openaicompat")
+	}
+	opts := []openaicompatprov.Option{
+		openaicompatprov.WithProfile(profile),
+		openaicompatprov.WithModel(model),
+		openaicompatprov.WithAPIKey(strings.TrimSpace(os.Getenv("OPENAI_COMPAT_API_KEY"))),
+	}
+	if baseURL := strings.TrimSpace(os.Getenv("OPENAI_COMPAT_BASE_URL")); baseURL != "" {
+		opts = append(opts, openaicompatprov.WithBaseURL(baseURL))
+	}
+	if name := strings.TrimSpace(os.Getenv("OPENAI_COMPAT_NAME")); name != "" {
+		opts = append(opts, openaicompatprov.WithName(name))
 	}
+	return openaicompatprov.New(opts...)
+}
 
-	return nil, fmt.Errorf("unsupported AGENT_PROVIDER %q (use gemini, openai, anthropic, ollama, or azureopenai)", provider)
+// routerFromEnv builds a router over the providers listed in
+// AGENT_ROUTER_BACKENDS, each configured by its usual environment variables.
+// Weights and costs are given as name=value lists, e.g.
+// AGENT_ROUTER_WEIGHTS=gemini=3,openai=1.
+func routerFromEnv(ctx context.Context) (llm.Provider, error) {
+	names := splitList(os.Getenv("AGENT_ROUTER_BACKENDS"))
+	if len(names) == 0 {
+		return nil, fmt.Errorf("AGENT_ROUTER_BACKENDS is required when AGENT_PROVIDER=router")
+	}
+	strategy, err := routerprov.ParseStrategy(os.Getenv("AGENT_ROUTER_STRATEGY"))
+	if err != nil {
+		return nil, err
+	}
+	weights, err := parseNamedValues("AGENT_ROUTER_WEIGHTS")
+	if err != nil {
+		return nil, err
+	}
+	costs, err := parseNamedValues("AGENT_ROUTER_COSTS")
+	if err != nil {
+		return nil, err
+	}
+
+	var breaker routerprov.BreakerConfig
+	if raw := strings.TrimSpace(os.Getenv("AGENT_ROUTER_FAILURE_THRESHOLD")); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AGENT_ROUTER_FAILURE_THRESHOLD %q: %w", raw, err)
+		}
+		breaker.FailureThreshold = n
+	}
+	if raw := strings.TrimSpace(os.Getenv("AGENT_ROUTER_COOLDOWN")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AGENT_ROUTER_COOLDOWN %q: %w", raw, err)
+		}
+		breaker.Cooldown = d
+	}
+
+	backends := make([]routerprov.Backend, 0, len(names))
+	for _, name := range names {
+		if name == "router" {
+			return nil, fmt.Errorf("AGENT_ROUTER_BACKENDS cannot contain router")
+		}
+		p, err := newProvider(ctx, name)
+		if err != nil {
+			return nil, fmt.Errorf("router backend %q: %w", name, err)
+		}
+		backends = append(backends, routerprov.Backend{
+			Provider: p,
+			Name:     name,
+			Weight:   int(weights[name]),
+			Cost:     costs[name],
+		})
+	}
+	return routerprov.New(backends,
+		routerprov.WithStrategy(strategy),
+		routerprov.WithBreaker(breaker),
+	)
+}
+
+// cacheFromEnv wraps p in a response cache when AGENT_CACHE names a backend.
+// The sqlite backend shares AGENT_SQLITE_PATH and the redis backend the
+// AGENT_REDIS_* settings with the state store.
+func cacheFromEnv(p llm.Provider) (llm.Provider, error) {
+	backend := strings.ToLower(strings.TrimSpace(os.Getenv("AGENT_CACHE")))
+	var store cacheprov.Store
+	switch backend {
+	case "", "off", "none":
+		return p, nil
+	case "memory":
+		size := 0
+		if raw := strings.TrimSpace(os.Getenv("AGENT_CACHE_SIZE")); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid AGENT_CACHE_SIZE %q: %w", raw, err)
+			}
+			size = n
+		}
+		store = cacheprov.NewMemoryStore(size)
+	case "sqlite":
+		s, err := cachesqlite.New(getenv("AGENT_SQLITE_PATH", "./.ai-agent/state.db"))
+		if err != nil {
+			return nil, err
+		}
+		store = s
+	case "redis":
+		db := 0
+		if raw := strings.TrimSpace(os.Getenv("AGENT_REDIS_DB")); raw != "" {
+			n, err := strconv.Atoi(raw)
+			if err != nil {
+				return nil, fmt.Errorf("invalid AGENT_REDIS_DB %q: %w", raw, err)
+			}
+			db = n
+		}
+		s, err := cacheredis.New(getenv("AGENT_REDIS_ADDR", "127.0.0.1:6379"),
+			cacheredis.WithPassword(strings.TrimSpace(os.Getenv("AGENT_REDIS_PASSWORD"))),
+			cacheredis.WithDB(db),
+		)
+		if err != nil {
+			return nil, err
+		}
+		store = s
+	default:
+		return nil, fmt.Errorf("unsupported AGENT_CACHE %q (use memory, sqlite, or redis)", backend)
+	}
+
+	var opts []cacheprov.Option
+	if raw := strings.TrimSpace(os.Getenv("AGENT_CACHE_TTL")); raw != "" {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AGENT_CACHE_TTL %q: %w", raw, err)
+		}
+		opts = append(opts, cacheprov.WithTTL(d))
+	}
+	return cacheprov.New(p, store, opts...)
+}
+
+func splitList(raw string) []string {
+	var out []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.ToLower(strings.TrimSpace(item)); item != "" {
+			out = append(out, item)
+		}
+	}
+	return out
+}
+
+func parseNamedValues(key string) (map[string]float64, error) {
+	out := map[string]float64{}
+	for _, item := range splitList(os.Getenv(key)) {
+		name, raw, ok := strings.Cut(item, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s entry %q (want name=value)", key, item)
+		}
+		v, err := strconv.ParseFloat(strings.TrimSpace(raw), 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s entry %q: %w", key, item, err)
+		}
+		out[strings.TrimSpace(name)] = v
+	}
+	return out, nil
 }
 
 func getenv(key, fallback string) string {
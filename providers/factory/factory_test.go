@@ -2,7 +2,13 @@ package factory
 
 import (
 	"context"
+	"os"
+	"path/filepath"
 	"testing"
+
+	cacheprov "github.com/PipeOpsHQ/agent-sdk-go/providers/cache"
+	cassetteprov "github.com/PipeOpsHQ/agent-sdk-go/providers/cassette"
+	"github.com/PipeOpsHQ/agent-sdk-go/tokenizer"
 )
 
 func TestFromEnv_OpenAI(t *testing.T) {
@@ -70,3 +76,167 @@ func TestFromEnv_AzureOpenAI(t *testing.T) {
 		t.Fatalf("expected azureopenai provider, got %q", p.Name())
 	}
 }
+
+func TestFromEnv_OpenAICompatProfile(t *testing.T) {
+	t.Setenv("AGENT_PROVIDER", "openaicompat")
+	t.Setenv("OPENAI_COMPAT_PROFILE", "groq")
+	t.Setenv("OPENAI_COMPAT_MODEL", "llama-3.3-70b-versatile")
+	t.Setenv("OPENAI_COMPAT_API_KEY", "test-groq-key")
+
+	p, err := FromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if p.Name() != "groq" {
+		t.Fatalf("expected groq provider, got %q", p.Name())
+	}
+	if p.Capabilities().TopK {
+		t.Fatalf("expected groq profile to report topK unsupported")
+	}
+}
+
+func TestFromEnv_OpenAICompatProfileFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profile.json")
+	if err := os.WriteFile(path, []byte(`{"name":"internal-vllm","extends":"vllm","toolCallIDs":"alnum9"}`), 0o600); err != nil {
+		t.Fatalf("failed to write profile: %v", err)
+	}
+	t.Setenv("AGENT_PROVIDER", "openai-compatible")
+	t.Setenv("OPENAI_COMPAT_PROFILE_FILE", path)
+	t.Setenv("OPENAI_COMPAT_MODEL", "mistral-small")
+	t.Setenv("OPENAI_COMPAT_BASE_URL", "http://vllm.internal:8000")
+
+	p, err := FromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if p.Name() != "internal-vllm" {
+		t.Fatalf("expected internal-vllm provider, got %q", p.Name())
+	}
+}
+
+func TestFromEnv_OpenAICompatRequiresModel(t *testing.T) {
+	t.Setenv("AGENT_PROVIDER", "openaicompat")
+	t.Setenv("OPENAI_COMPAT_BASE_URL", "http://127.0.0.1:8000")
+	t.Setenv("OPENAI_COMPAT_MODEL", "")
+
+	if _, err := FromEnv(context.Background()); err == nil {
+		t.Fatalf("expected missing model error")
+	}
+}
+
+func TestFromEnv_Router(t *testing.T) {
+	t.Setenv("AGENT_PROVIDER", "router")
+	t.Setenv("AGENT_ROUTER_BACKENDS", "anthropic, openai")
+	t.Setenv("AGENT_ROUTER_STRATEGY", "cheapest")
+	t.Setenv("AGENT_ROUTER_COSTS", "anthropic=3,openai=0.6")
+	t.Setenv("AGENT_ROUTER_COOLDOWN", "10s")
+	t.Setenv("OPENAI_API_KEY", "test-openai-key")
+	t.Setenv("ANTHROPIC_API_KEY", "test-anthropic-key")
+
+	p, err := FromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if p.Name() != "router" {
+		t.Fatalf("expected router provider, got %q", p.Name())
+	}
+}
+
+func TestFromEnv_RouterBackendError(t *testing.T) {
+	t.Setenv("AGENT_PROVIDER", "router")
+	t.Setenv("AGENT_ROUTER_BACKENDS", "openai")
+	t.Setenv("OPENAI_API_KEY", "")
+
+	if _, err := FromEnv(context.Background()); err == nil {
+		t.Fatalf("expected missing backend credentials error")
+	}
+}
+
+func TestFromEnv_Cache(t *testing.T) {
+	t.Setenv("AGENT_PROVIDER", "openai")
+	t.Setenv("OPENAI_API_KEY", "test-openai-key")
+	t.Setenv("AGENT_CACHE", "sqlite")
+	t.Setenv("AGENT_SQLITE_PATH", filepath.Join(t.TempDir(), "state.db"))
+	t.Setenv("AGENT_CACHE_TTL", "1h")
+
+	p, err := FromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	if _, ok := p.(*cacheprov.Provider); !ok {
+		t.Fatalf("expected cache wrapper, got %T", p)
+	}
+	if p.Name() != "openai" {
+		t.Fatalf("expected wrapped provider name, got %q", p.Name())
+	}
+}
+
+func TestFromEnv_CacheUnsupportedBackend(t *testing.T) {
+	t.Setenv("AGENT_PROVIDER", "openai")
+	t.Setenv("OPENAI_API_KEY", "test-openai-key")
+	t.Setenv("AGENT_CACHE", "memcached")
+
+	if _, err := FromEnv(context.Background()); err == nil {
+		t.Fatalf("expected unsupported cache backend error")
+	}
+}
+
+func TestFromEnv_Cassette(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "run.json")
+	t.Setenv("AGENT_PROVIDER", "openai")
+	t.Setenv("OPENAI_API_KEY", "test-openai-key")
+	t.Setenv("AGENT_CASSETTE", path)
+	t.Setenv("AGENT_CASSETTE_MODE", "record")
+
+	p, err := FromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	rec, ok := p.(*cassetteprov.Provider)
+	if !ok || rec.Mode() != cassetteprov.ModeRecord {
+		t.Fatalf("expected recording cassette, got %T", p)
+	}
+
+	// Replay must not need provider credentials.
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("AGENT_CASSETTE_MODE", "")
+	p, err = FromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("FromEnv returned error: %v", err)
+	}
+	replay, ok := p.(*cassetteprov.Provider)
+	if !ok || replay.Mode() != cassetteprov.ModeReplay || replay.Name() != "openai" {
+		t.Fatalf("expected replaying openai cassette, got %T", p)
+	}
+}
+
+func TestTokenizerFromEnv(t *testing.T) {
+	t.Setenv("AGENT_PROVIDER", "anthropic")
+	t.Setenv("ANTHROPIC_API_KEY", "test-anthropic-key")
+
+	tok, err := TokenizerFromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("TokenizerFromEnv returned error: %v", err)
+	}
+	if _, ok := tok.(*tokenizer.Estimator); !ok {
+		t.Fatalf("expected an offline estimator, got %T", tok)
+	}
+
+	t.Setenv("AGENT_TOKEN_COUNTING", "remote")
+	tok, err = TokenizerFromEnv(context.Background())
+	if err != nil {
+		t.Fatalf("TokenizerFromEnv returned error: %v", err)
+	}
+	if _, ok := tok.(*tokenizer.Remote); !ok {
+		t.Fatalf("expected remote counting, got %T", tok)
+	}
+
+	t.Setenv("AGENT_PROVIDER", "ollama")
+	if _, err := TokenizerFromEnv(context.Background()); err == nil {
+		t.Fatalf("expected error for a provider without a count-tokens endpoint")
+	}
+	t.Setenv("AGENT_TOKEN_COUNTING", "exact")
+	if _, err := TokenizerFromEnv(context.Background()); err == nil {
+		t.Fatalf("expected error for unsupported AGENT_TOKEN_COUNTING")
+	}
+}
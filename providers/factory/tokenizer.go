@@ -0,0 +1,60 @@
+package factory
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/tokenizer"
+)
+
+// TokenizerFromEnv returns the tokenizer for the provider and model FromEnv
+// would build. With AGENT_TOKEN_COUNTING=remote, providers that expose a
+// count-tokens endpoint (anthropic, gemini) count each request through it,
+// with the offline tokenizer counting when the endpoint is unavailable.
+func TokenizerFromEnv(ctx context.Context) (tokenizer.Tokenizer, error) {
+	provider := strings.ToLower(strings.TrimSpace(getenv("AGENT_PROVIDER", "gemini")))
+	local := tokenizer.For(provider, modelFromEnv(provider))
+
+	counting := strings.ToLower(strings.TrimSpace(os.Getenv("AGENT_TOKEN_COUNTING")))
+	switch counting {
+	case "", "local":
+		return local, nil
+	case "remote":
+	default:
+		return nil, fmt.Errorf("unsupported AGENT_TOKEN_COUNTING %q (use local or remote)", counting)
+	}
+	if provider == "router" {
+		return nil, fmt.Errorf("AGENT_TOKEN_COUNTING=remote is not supported with AGENT_PROVIDER=router")
+	}
+	p, err := newProvider(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	counter, ok := p.(tokenizer.RequestCounter)
+	if !ok {
+		return nil, fmt.Errorf("provider %q has no count-tokens endpoint for AGENT_TOKEN_COUNTING=remote", provider)
+	}
+	return tokenizer.NewRemote(counter, local), nil
+}
+
+// modelFromEnv returns the model newProvider configures for provider.
+func modelFromEnv(provider string) string {
+	switch provider {
+	case "openai":
+		return getenv("OPENAI_MODEL", "gpt-4o-mini")
+	case "gemini":
+		return getenv("GEMINI_MODEL", "gemini-2.5-flash")
+	case "anthropic":
+		return getenv("ANTHROPIC_MODEL", "claude-3-5-sonnet-latest")
+	case "ollama":
+		return getenv("OLLAMA_MODEL", "llama3.1:8b")
+	case "azureopenai":
+		return getenv("AZURE_OPENAI_MODEL", strings.TrimSpace(os.Getenv("AZURE_OPENAI_DEPLOYMENT")))
+	case "openaicompat", "openai-compatible":
+		return strings.TrimSpace(os.Getenv("OPENAI_COMPAT_MODEL"))
+	default:
+		return ""
+	}
+}
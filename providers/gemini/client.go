@@ -53,68 +53,131 @@ func (c *Client) Capabilities() llm.Capabilities {
 		Tools:            true,
 		Streaming:        true,
 		StructuredOutput: true,
+		Temperature:      true,
+		TopP:             true,
+		TopK:             true,
+		StopSequences:    true,
+		Seed:             true,
+		PresencePenalty:  true,
+		FrequencyPenalty: true,
+		ToolChoice:       true,
 	}
 }
 
-func (c *Client) Generate(ctx context.Context, req types.Request) (types.Response, error) {
-	model := c.model
-	if req.Model != "" {
-		model = req.Model
+func buildConfig(req types.Request) *genai.GenerateContentConfig {
+	config := &genai.GenerateContentConfig{
+		Temperature:      toFloat32(req.Temperature),
+		TopP:             toFloat32(req.TopP),
+		PresencePenalty:  toFloat32(req.PresencePenalty),
+		FrequencyPenalty: toFloat32(req.FrequencyPenalty),
+		StopSequences:    req.StopSequences,
 	}
-
-	config := &genai.GenerateContentConfig{}
 	if req.SystemPrompt != "" {
 		config.SystemInstruction = genai.NewContentFromText(req.SystemPrompt, genai.RoleUser)
 	}
 	if req.MaxOutputTokens > 0 {
 		config.MaxOutputTokens = clampInt32(req.MaxOutputTokens)
 	}
+	if req.TopK != nil {
+		topK := float32(*req.TopK)
+		config.TopK = &topK
+	}
+	if req.Seed != nil {
+		seed := int32(*req.Seed)
+		config.Seed = &seed
+	}
+	if len(req.ResponseSchema) > 0 {
+		// ResponseSchema uses the OpenAI json_schema envelope; Gemini takes
+		// the schema itself.
+		config.ResponseMIMEType = "application/json"
+		if schema, ok := req.ResponseSchema["schema"].(map[string]any); ok {
+			config.ResponseJsonSchema = schema
+		} else {
+			config.ResponseJsonSchema = req.ResponseSchema
+		}
+	}
 	if len(req.Tools) > 0 {
 		config.Tools = []*genai.Tool{
 			{FunctionDeclarations: toGeminiFunctionDeclarations(req.Tools)},
 		}
 		config.ToolConfig = &genai.ToolConfig{
-			FunctionCallingConfig: &genai.FunctionCallingConfig{
-				Mode: genai.FunctionCallingConfigModeAuto,
-			},
+			FunctionCallingConfig: toGeminiFunctionCallingConfig(req.ToolChoice),
 		}
 	}
+	return config
+}
+
+func toGeminiFunctionCallingConfig(choice *types.ToolChoice) *genai.FunctionCallingConfig {
+	out := &genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAuto}
+	if choice == nil {
+		return out
+	}
+	switch choice.Mode {
+	case types.ToolChoiceRequired:
+		out.Mode = genai.FunctionCallingConfigModeAny
+	case types.ToolChoiceNone:
+		out.Mode = genai.FunctionCallingConfigModeNone
+	case types.ToolChoiceTool:
+		out.Mode = genai.FunctionCallingConfigModeAny
+		out.AllowedFunctionNames = []string{choice.Name}
+	}
+	return out
+}
+
+func (c *Client) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
+	}
+
+	config := buildConfig(req)
 
 	resp, err := c.client.Models.GenerateContent(ctx, model, toGeminiContents(req.Messages), config)
 	if err != nil {
 		return types.Response{}, fmt.Errorf("gemini generation failed: %w", err)
 	}
-	return parseGeminiResponse(resp), nil
+	out := parseGeminiResponse(resp)
+	out.Model = model
+	return out, nil
 }
 
-func (c *Client) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
-	if onChunk == nil {
-		return types.Response{}, fmt.Errorf("onChunk is required")
-	}
+// CountTokens returns the input tokens req would use, as counted by the
+// Gemini API. It implements tokenizer.RequestCounter. The Gemini API does not
+// accept a system instruction or tools when counting, so the system prompt is
+// counted as a leading user turn and tool declarations are not counted.
+func (c *Client) CountTokens(ctx context.Context, req types.Request) (int, error) {
 	model := c.model
 	if req.Model != "" {
 		model = req.Model
 	}
 
-	config := &genai.GenerateContentConfig{}
+	contents := toGeminiContents(req.Messages)
 	if req.SystemPrompt != "" {
-		config.SystemInstruction = genai.NewContentFromText(req.SystemPrompt, genai.RoleUser)
+		contents = append([]*genai.Content{genai.NewContentFromText(req.SystemPrompt, genai.RoleUser)}, contents...)
 	}
-	if req.MaxOutputTokens > 0 {
-		config.MaxOutputTokens = clampInt32(req.MaxOutputTokens)
+	resp, err := c.client.Models.CountTokens(ctx, model, contents, nil)
+	if err != nil {
+		return 0, fmt.Errorf("gemini token count failed: %w", err)
 	}
-	if len(req.Tools) > 0 {
-		config.Tools = []*genai.Tool{
-			{FunctionDeclarations: toGeminiFunctionDeclarations(req.Tools)},
-		}
-		config.ToolConfig = &genai.ToolConfig{
-			FunctionCallingConfig: &genai.FunctionCallingConfig{
-				Mode: genai.FunctionCallingConfigModeAuto,
-			},
-		}
+	return int(resp.TotalTokens), nil
+}
+
+func (c *Client) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	if onChunk == nil {
+		return types.Response{}, fmt.Errorf("onChunk is required")
+	}
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
 	}
 
-	var last *genai.GenerateContentResponse
+	config := buildConfig(req)
+
+	var (
+		last      *genai.GenerateContentResponse
+		parts     []*genai.Part
+		toolIndex int
+	)
 	stream := c.client.Models.GenerateContentStream(ctx, model, toGeminiContents(req.Messages), config)
 	for chunk, err := range stream {
 		if err != nil {
@@ -130,10 +193,36 @@ func (c *Client) GenerateStream(ctx context.Context, req types.Request, onChunk
 		}
 		candidate := chunk.Candidates[0].Content
 		for _, part := range candidate.Parts {
-			if part == nil || part.Text == "" || part.Thought {
+			if part == nil {
 				continue
 			}
-			if err := onChunk(types.StreamChunk{Text: part.Text}); err != nil {
+			parts = append(parts, part)
+			var event types.StreamChunk
+			switch {
+			case part.FunctionCall != nil:
+				args := part.FunctionCall.Args
+				if args == nil {
+					args = map[string]any{}
+				}
+				rawArgs, _ := json.Marshal(args)
+				event = types.StreamChunk{
+					Type: types.StreamEventToolCallDelta,
+					ToolCallDelta: &types.ToolCallDelta{
+						Index:          toolIndex,
+						ID:             part.FunctionCall.ID,
+						Name:           part.FunctionCall.Name,
+						ArgumentsDelta: string(rawArgs),
+					},
+				}
+				toolIndex++
+			case part.Text == "":
+				continue
+			case part.Thought:
+				event = types.StreamChunk{Type: types.StreamEventReasoning, Reasoning: part.Text}
+			default:
+				event = types.StreamChunk{Type: types.StreamEventText, Text: part.Text}
+			}
+			if err := onChunk(event); err != nil {
 				return types.Response{}, err
 			}
 		}
@@ -142,8 +231,21 @@ func (c *Client) GenerateStream(ctx context.Context, req types.Request, onChunk
 	if last == nil {
 		return types.Response{}, fmt.Errorf("gemini generation failed: empty stream")
 	}
+	// Each streamed chunk only carries its own parts; rebuild the complete
+	// candidate so the final response contains all text and tool calls.
+	if len(parts) > 0 {
+		merged := *last
+		merged.Candidates = []*genai.Candidate{{Content: &genai.Content{Role: genai.RoleModel, Parts: parts}}}
+		last = &merged
+	}
 	resp := parseGeminiResponse(last)
-	if err := onChunk(types.StreamChunk{Done: true}); err != nil {
+	resp.Model = model
+	if resp.Usage != nil {
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventUsage, Usage: resp.Usage}); err != nil {
+			return types.Response{}, err
+		}
+	}
+	if err := onChunk(types.StreamChunk{Type: types.StreamEventDone, Done: true}); err != nil {
 		return types.Response{}, err
 	}
 	return resp, nil
@@ -199,6 +301,14 @@ func parseGeminiResponse(resp *genai.GenerateContentResponse) types.Response {
 	return types.Response{Message: out, Usage: usage}
 }
 
+func toFloat32(v *float64) *float32 {
+	if v == nil {
+		return nil
+	}
+	f := float32(*v)
+	return &f
+}
+
 func clampInt32(v int) int32 {
 	if v <= 0 {
 		return 0
@@ -233,7 +343,11 @@ func toGeminiContents(messages []types.Message) []*genai.Content {
 	for _, m := range messages {
 		switch m.Role {
 		case types.RoleUser:
-			contents = append(contents, genai.NewContentFromText(m.Content, genai.RoleUser))
+			if len(m.Parts) == 0 {
+				contents = append(contents, genai.NewContentFromText(m.Content, genai.RoleUser))
+				continue
+			}
+			contents = append(contents, genai.NewContentFromParts(toGeminiParts(m.ContentParts()), genai.RoleUser))
 
 		case types.RoleAssistant:
 			parts := make([]*genai.Part, 0, len(m.ToolCalls)+1)
@@ -269,3 +383,21 @@ func toGeminiContents(messages []types.Message) []*genai.Content {
 	}
 	return contents
 }
+
+// toGeminiParts maps multimodal content parts to Gemini parts. Inline data is
+// sent as blobs and URLs as file data, which Gemini accepts for images,
+// documents and audio alike.
+func toGeminiParts(parts []types.ContentPart) []*genai.Part {
+	out := make([]*genai.Part, 0, len(parts))
+	for _, p := range parts {
+		switch {
+		case p.Type == types.ContentPartText:
+			out = append(out, genai.NewPartFromText(p.Text))
+		case len(p.Data) > 0:
+			out = append(out, genai.NewPartFromBytes(p.Data, p.MIMEType))
+		case p.URL != "":
+			out = append(out, genai.NewPartFromURI(p.URL, p.MIMEType))
+		}
+	}
+	return out
+}
@@ -0,0 +1,188 @@
+package sse
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// ChatChunk is one event of a chat completions stream.
+type ChatChunk struct {
+	Choices []struct {
+		Delta ChatDelta `json:"delta"`
+	} `json:"choices"`
+	Usage *struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// ChatDelta is the increment of the assistant message carried by a chunk.
+// Servers that stream reasoning use one of the two reasoning fields.
+type ChatDelta struct {
+	Content          string `json:"content"`
+	ReasoningContent string `json:"reasoning_content"`
+	Reasoning        string `json:"reasoning"`
+	ToolCalls        []struct {
+		Index    int    `json:"index"`
+		ID       string `json:"id"`
+		Function struct {
+			Name      string `json:"name"`
+			Arguments string `json:"arguments"`
+		} `json:"function"`
+	} `json:"tool_calls"`
+}
+
+// ChatAccumulator assembles the deltas of a chat completions stream into a
+// complete response. The zero value ignores reasoning and keeps tool calls
+// as streamed.
+type ChatAccumulator struct {
+	// Reasoning returns the reasoning text of a delta.
+	Reasoning func(ChatDelta) string
+	// ToolCallID returns the ID of an assembled tool call from the streamed
+	// one, which may be empty.
+	ToolCallID func(id string) string
+	// Arguments turns the assembled arguments of a tool call into JSON.
+	Arguments func(raw string) json.RawMessage
+
+	content   strings.Builder
+	reasoning strings.Builder
+	toolCalls []chatToolCall
+	toolIndex map[int]int
+	usage     *types.Usage
+}
+
+type chatToolCall struct {
+	id        string
+	name      string
+	arguments strings.Builder
+}
+
+// ReadChat reads a chat completions stream from r into acc, forwarding the
+// deltas to onChunk as they arrive, and returns the assembled response
+// after the usage and done chunks. name identifies the provider in errors.
+func ReadChat(r io.Reader, name string, acc *ChatAccumulator, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	err := Read(r, func(_ string, data []byte) error {
+		if string(data) == "[DONE]" {
+			return ErrDone
+		}
+		var chunk ChatChunk
+		if err := json.Unmarshal(data, &chunk); err != nil {
+			return fmt.Errorf("failed to decode %s stream chunk: %w", name, err)
+		}
+		if chunk.Error != nil {
+			return fmt.Errorf("%s stream error: %s", name, chunk.Error.Message)
+		}
+		return acc.Add(chunk, onChunk)
+	})
+	if err != nil {
+		return types.Response{}, err
+	}
+
+	out := acc.Response()
+	if out.Usage != nil {
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventUsage, Usage: out.Usage}); err != nil {
+			return types.Response{}, err
+		}
+	}
+	if err := onChunk(types.StreamChunk{Type: types.StreamEventDone, Done: true}); err != nil {
+		return types.Response{}, err
+	}
+	return out, nil
+}
+
+// Add folds chunk into the response and forwards its deltas to onChunk.
+func (a *ChatAccumulator) Add(chunk ChatChunk, onChunk func(types.StreamChunk) error) error {
+	if chunk.Usage != nil && chunk.Usage.TotalTokens > 0 {
+		a.usage = &types.Usage{
+			InputTokens:  chunk.Usage.PromptTokens,
+			OutputTokens: chunk.Usage.CompletionTokens,
+			TotalTokens:  chunk.Usage.TotalTokens,
+		}
+	}
+	if len(chunk.Choices) == 0 {
+		return nil
+	}
+	delta := chunk.Choices[0].Delta
+	if a.Reasoning != nil {
+		if reasoning := a.Reasoning(delta); reasoning != "" {
+			a.reasoning.WriteString(reasoning)
+			if err := onChunk(types.StreamChunk{Type: types.StreamEventReasoning, Reasoning: reasoning}); err != nil {
+				return err
+			}
+		}
+	}
+	if delta.Content != "" {
+		a.content.WriteString(delta.Content)
+		if err := onChunk(types.StreamChunk{Type: types.StreamEventText, Text: delta.Content}); err != nil {
+			return err
+		}
+	}
+	for _, tc := range delta.ToolCalls {
+		if a.toolIndex == nil {
+			a.toolIndex = make(map[int]int)
+		}
+		pos, ok := a.toolIndex[tc.Index]
+		// Some compatible servers send every complete call at index 0; a new
+		// call ID at a known index starts a new call.
+		if ok && tc.ID != "" && a.toolCalls[pos].id != "" && a.toolCalls[pos].id != tc.ID {
+			ok = false
+		}
+		if !ok {
+			pos = len(a.toolCalls)
+			a.toolIndex[tc.Index] = pos
+			a.toolCalls = append(a.toolCalls, chatToolCall{})
+		}
+		call := &a.toolCalls[pos]
+		if tc.ID != "" {
+			call.id = tc.ID
+		}
+		if tc.Function.Name != "" {
+			call.name = tc.Function.Name
+		}
+		call.arguments.WriteString(tc.Function.Arguments)
+		if err := onChunk(types.StreamChunk{
+			Type: types.StreamEventToolCallDelta,
+			ToolCallDelta: &types.ToolCallDelta{
+				Index:          pos,
+				ID:             tc.ID,
+				Name:           tc.Function.Name,
+				ArgumentsDelta: tc.Function.Arguments,
+			},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Response returns the response assembled so far.
+func (a *ChatAccumulator) Response() types.Response {
+	out := types.Message{
+		Role:      types.RoleAssistant,
+		Content:   a.content.String(),
+		Reasoning: a.reasoning.String(),
+	}
+	if len(a.toolCalls) > 0 {
+		out.ToolCalls = make([]types.ToolCall, 0, len(a.toolCalls))
+		for i := range a.toolCalls {
+			tc := &a.toolCalls[i]
+			id, args := tc.id, json.RawMessage(tc.arguments.String())
+			if a.ToolCallID != nil {
+				id = a.ToolCallID(id)
+			}
+			if a.Arguments != nil {
+				args = a.Arguments(tc.arguments.String())
+			}
+			out.ToolCalls = append(out.ToolCalls, types.ToolCall{ID: id, Name: tc.name, Arguments: args})
+		}
+	}
+	return types.Response{Message: out, Usage: a.usage}
+}
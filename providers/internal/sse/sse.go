@@ -0,0 +1,70 @@
+// Package sse decodes the server-sent event streams of the model providers.
+// Read splits a stream into events; ReadChat assembles the events of an
+// OpenAI-style chat completions stream into a response.
+package sse
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrDone is returned by an event handler to stop reading at the stream's
+// terminal event. Read then returns nil.
+var ErrDone = errors.New("stream done")
+
+// Read decodes a server-sent event stream and invokes fn with the event
+// name, empty when the event has none, and data payload of every event.
+// A stream that ends before fn returns ErrDone was cut off, and Read
+// returns an error wrapping io.ErrUnexpectedEOF rather than let a partial
+// response pass for a complete one.
+func Read(r io.Reader, fn func(event string, data []byte) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	var (
+		event string
+		data  bytes.Buffer
+	)
+	flush := func() error {
+		if data.Len() == 0 {
+			event = ""
+			return nil
+		}
+		name, payload := event, bytes.Clone(data.Bytes())
+		event = ""
+		data.Reset()
+		return fn(name, payload)
+	}
+	err := func() error {
+		for scanner.Scan() {
+			line := scanner.Bytes()
+			switch {
+			case len(line) == 0:
+				if err := flush(); err != nil {
+					return err
+				}
+			case bytes.HasPrefix(line, []byte("event:")):
+				event = strings.TrimSpace(string(bytes.TrimPrefix(line, []byte("event:"))))
+			case bytes.HasPrefix(line, []byte("data:")):
+				if data.Len() > 0 {
+					data.WriteByte('\n')
+				}
+				data.Write(bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" ")))
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return fmt.Errorf("failed to read event stream: %w", err)
+		}
+		if err := flush(); err != nil {
+			return err
+		}
+		return fmt.Errorf("event stream ended before its final event: %w", io.ErrUnexpectedEOF)
+	}()
+	if errors.Is(err, ErrDone) {
+		return nil
+	}
+	return err
+}
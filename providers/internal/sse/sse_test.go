@@ -0,0 +1,91 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestRead(t *testing.T) {
+	stream := "event: ping\ndata: {\"a\":1}\n\n: comment\ndata: line one\ndata: line two\n\ndata: stop\n\ndata: never\n\n"
+	type event struct{ name, data string }
+	var got []event
+	err := Read(strings.NewReader(stream), func(name string, data []byte) error {
+		if string(data) == "stop" {
+			return ErrDone
+		}
+		got = append(got, event{name, string(data)})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	want := []event{{"ping", `{"a":1}`}, {"", "line one\nline two"}}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Fatalf("unexpected events: %+v", got)
+	}
+}
+
+func TestRead_Truncated(t *testing.T) {
+	err := Read(strings.NewReader("data: one\n\ndata: tw"), func(string, []byte) error { return nil })
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected a truncated stream to fail, got %v", err)
+	}
+}
+
+func TestReadChat(t *testing.T) {
+	// Both calls arrive at index 0, as some compatible servers send them.
+	stream := strings.Join([]string{
+		`data: {"choices":[{"delta":{"reasoning_content":"think"}}]}`,
+		`data: {"choices":[{"delta":{"content":"Hi"}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"a","function":{"name":"one","arguments":"{\"x\":"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"function":{"arguments":"1}"}}]}}]}`,
+		`data: {"choices":[{"delta":{"tool_calls":[{"index":0,"id":"b","function":{"name":"two"}}]}}]}`,
+		`data: {"choices":[],"usage":{"prompt_tokens":3,"completion_tokens":4,"total_tokens":7}}`,
+		`data: [DONE]`,
+	}, "\n\n") + "\n\n"
+
+	acc := &ChatAccumulator{
+		Reasoning: func(delta ChatDelta) string { return delta.ReasoningContent },
+		Arguments: func(raw string) json.RawMessage {
+			if raw == "" {
+				return json.RawMessage(`{}`)
+			}
+			return json.RawMessage(raw)
+		},
+	}
+	var chunks []types.StreamChunk
+	resp, err := ReadChat(strings.NewReader(stream), "test", acc, func(chunk types.StreamChunk) error {
+		chunks = append(chunks, chunk)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadChat failed: %v", err)
+	}
+	msg := resp.Message
+	if msg.Content != "Hi" || msg.Reasoning != "think" {
+		t.Fatalf("unexpected message: %+v", msg)
+	}
+	if len(msg.ToolCalls) != 2 || msg.ToolCalls[0].ID != "a" || string(msg.ToolCalls[0].Arguments) != `{"x":1}` ||
+		msg.ToolCalls[1].Name != "two" || string(msg.ToolCalls[1].Arguments) != `{}` {
+		t.Fatalf("unexpected tool calls: %+v", msg.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 7 {
+		t.Fatalf("unexpected usage: %+v", resp.Usage)
+	}
+	if last := chunks[len(chunks)-1]; !last.Done || chunks[len(chunks)-2].Type != types.StreamEventUsage {
+		t.Fatalf("expected usage then done, got %+v", chunks)
+	}
+}
+
+func TestReadChat_StreamError(t *testing.T) {
+	stream := "data: {\"error\":{\"message\":\"overloaded\"}}\n\n"
+	_, err := ReadChat(strings.NewReader(stream), "test", &ChatAccumulator{}, func(types.StreamChunk) error { return nil })
+	if err == nil || err.Error() != "test stream error: overloaded" {
+		t.Fatalf("expected stream error, got %v", err)
+	}
+}
@@ -64,47 +64,22 @@ func (c *Client) Name() string { return "ollama" }
 func (c *Client) Capabilities() llm.Capabilities {
 	return llm.Capabilities{
 		Tools:            true,
-		Streaming:        false,
+		Streaming:        true,
 		StructuredOutput: true,
+		Temperature:      true,
+		TopP:             true,
+		StopSequences:    true,
+		Seed:             true,
+		PresencePenalty:  true,
+		FrequencyPenalty: true,
 	}
 }
 
 func (c *Client) Generate(ctx context.Context, req types.Request) (types.Response, error) {
-	model := c.model
-	if req.Model != "" {
-		model = req.Model
-	}
-
-	payload := chatRequest{
-		Model:    model,
-		Messages: make([]chatMessage, 0, len(req.Messages)+1),
-	}
-	if req.MaxOutputTokens > 0 {
-		payload.MaxTokens = req.MaxOutputTokens
-	}
-
-	if req.SystemPrompt != "" {
-		payload.Messages = append(payload.Messages, chatMessage{Role: "system", Content: req.SystemPrompt})
-	}
-	payload.Messages = append(payload.Messages, toChatMessages(req.Messages)...)
-
-	if len(req.Tools) > 0 {
-		payload.ToolChoice = "auto"
-		payload.Tools = toChatTools(req.Tools)
-	}
-
-	raw, err := json.Marshal(payload)
-	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to marshal ollama request: %w", err)
-	}
-
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/chat/completions", bytes.NewReader(raw))
+	payload := c.buildRequest(req)
+	httpReq, err := c.newHTTPRequest(ctx, payload)
 	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to create ollama request: %w", err)
-	}
-	httpReq.Header.Set("Content-Type", "application/json")
-	if c.apiKey != "" {
-		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+		return types.Response{}, err
 	}
 
 	resp, err := c.httpClient.Do(httpReq)
@@ -154,7 +129,62 @@ func (c *Client) Generate(ctx context.Context, req types.Request) (types.Respons
 		}
 	}
 
-	return types.Response{Message: out, Usage: usage}, nil
+	return types.Response{Message: out, Usage: usage, Model: payload.Model}, nil
+}
+
+func (c *Client) buildRequest(req types.Request) chatRequest {
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
+	}
+
+	payload := chatRequest{
+		Model:    model,
+		Messages: make([]chatMessage, 0, len(req.Messages)+1),
+	}
+	if req.MaxOutputTokens > 0 {
+		payload.MaxTokens = req.MaxOutputTokens
+	}
+
+	if req.SystemPrompt != "" {
+		payload.Messages = append(payload.Messages, chatMessage{Role: "system", Content: req.SystemPrompt})
+	}
+	payload.Messages = append(payload.Messages, toChatMessages(req.Messages)...)
+
+	payload.Temperature = req.Temperature
+	payload.TopP = req.TopP
+	payload.Stop = req.StopSequences
+	payload.Seed = req.Seed
+	payload.PresencePenalty = req.PresencePenalty
+	payload.FrequencyPenalty = req.FrequencyPenalty
+
+	// The compatibility endpoint ignores tool_choice beyond "auto", so tool
+	// choice and the parallel-call hint are reported as unsupported instead.
+	if len(req.Tools) > 0 {
+		payload.ToolChoice = "auto"
+		payload.Tools = toChatTools(req.Tools)
+	}
+	if len(req.ResponseSchema) > 0 {
+		payload.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: req.ResponseSchema}
+	}
+	return payload
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, payload chatRequest) (*http.Request, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal ollama request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/chat/completions", bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ollama request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	return httpReq, nil
 }
 
 func toChatMessages(in []types.Message) []chatMessage {
@@ -162,7 +192,7 @@ func toChatMessages(in []types.Message) []chatMessage {
 	for _, m := range in {
 		switch m.Role {
 		case types.RoleUser:
-			msgs = append(msgs, chatMessage{Role: "user", Content: m.Content})
+			msgs = append(msgs, chatMessage{Role: "user", Content: toChatContent(m)})
 		case types.RoleAssistant:
 			out := chatMessage{Role: "assistant", Content: m.Content}
 			if len(m.ToolCalls) > 0 {
@@ -242,11 +272,25 @@ func normalizeJSONArgs(raw string) json.RawMessage {
 }
 
 type chatRequest struct {
-	Model      string        `json:"model"`
-	Messages   []chatMessage `json:"messages"`
-	Tools      []chatTool    `json:"tools,omitempty"`
-	ToolChoice string        `json:"tool_choice,omitempty"`
-	MaxTokens  int           `json:"max_tokens,omitempty"`
+	Model            string          `json:"model"`
+	Messages         []chatMessage   `json:"messages"`
+	Tools            []chatTool      `json:"tools,omitempty"`
+	ToolChoice       string          `json:"tool_choice,omitempty"`
+	MaxTokens        int             `json:"max_tokens,omitempty"`
+	Temperature      *float64        `json:"temperature,omitempty"`
+	TopP             *float64        `json:"top_p,omitempty"`
+	Stop             []string        `json:"stop,omitempty"`
+	Seed             *int64          `json:"seed,omitempty"`
+	PresencePenalty  *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64        `json:"frequency_penalty,omitempty"`
+	Stream           bool            `json:"stream,omitempty"`
+	StreamOptions    *streamOptions  `json:"stream_options,omitempty"`
+	ResponseFormat   *responseFormat `json:"response_format,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string         `json:"type"`
+	JSONSchema map[string]any `json:"json_schema,omitempty"`
 }
 
 type chatMessage struct {
@@ -25,6 +25,9 @@ func TestClientGenerate_OpenAICompatibleRoundTrip(t *testing.T) {
 		if req["model"] != "llama3.2" {
 			t.Fatalf("unexpected model: %#v", req["model"])
 		}
+		if format, _ := req["response_format"].(map[string]any); format["type"] != "json_schema" || format["json_schema"] == nil {
+			t.Fatalf("unexpected response format: %#v", req["response_format"])
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		_, _ = w.Write([]byte(`{
@@ -62,6 +65,7 @@ func TestClientGenerate_OpenAICompatibleRoundTrip(t *testing.T) {
 			Description: "calculator",
 			JSONSchema:  map[string]any{"type": "object"},
 		}},
+		ResponseSchema: map[string]any{"name": "answer", "schema": map[string]any{"type": "object"}},
 	})
 	if err != nil {
 		t.Fatalf("Generate failed: %v", err)
@@ -0,0 +1,57 @@
+package ollama
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestClientGenerateStream_ReplaysTranscript(t *testing.T) {
+	transcript, err := os.ReadFile("testdata/stream_tool_calls.sse")
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write(transcript)
+	}))
+	defer ts.Close()
+
+	client, err := New(WithBaseURL(ts.URL), WithModel("llama3.2"), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var deltas int
+	resp, err := client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "calc"}},
+		Tools:    []types.ToolDefinition{{Name: "calc", Description: "calculator"}},
+	}, func(chunk types.StreamChunk) error {
+		if chunk.ToolCallDelta != nil {
+			deltas++
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	if resp.Message.Content != "Checking both." {
+		t.Fatalf("unexpected content: %q", resp.Message.Content)
+	}
+	if deltas != 2 || len(resp.Message.ToolCalls) != 2 {
+		t.Fatalf("expected two separate tool calls, got deltas=%d calls=%#v", deltas, resp.Message.ToolCalls)
+	}
+	if resp.Message.ToolCalls[0].ID != "call_x1" || string(resp.Message.ToolCalls[1].Arguments) != `{"x":2}` {
+		t.Fatalf("unexpected tool calls: %#v", resp.Message.ToolCalls)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 42 {
+		t.Fatalf("unexpected usage: %#v", resp.Usage)
+	}
+}
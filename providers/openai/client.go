@@ -63,59 +63,27 @@ func (c *Client) Name() string { return "openai" }
 
 func (c *Client) Capabilities() llm.Capabilities {
 	return llm.Capabilities{
-		Tools:            true,
-		Streaming:        false,
-		StructuredOutput: true,
+		Tools:             true,
+		Streaming:         true,
+		StructuredOutput:  true,
+		Temperature:       true,
+		TopP:              true,
+		StopSequences:     true,
+		Seed:              true,
+		PresencePenalty:   true,
+		FrequencyPenalty:  true,
+		ToolChoice:        true,
+		ParallelToolCalls: true,
 	}
 }
 
 func (c *Client) Generate(ctx context.Context, req types.Request) (types.Response, error) {
-	model := c.model
-	if req.Model != "" {
-		model = req.Model
-	}
-
-	payload := openAIRequest{
-		Model:    model,
-		Messages: make([]openAIMessage, 0, len(req.Messages)+1),
-	}
-	if req.MaxOutputTokens > 0 {
-		payload.MaxTokens = req.MaxOutputTokens
-	}
-
-	if req.SystemPrompt != "" {
-		payload.Messages = append(payload.Messages, openAIMessage{
-			Role:    "system",
-			Content: req.SystemPrompt,
-		})
-	}
-	payload.Messages = append(payload.Messages, toOpenAIMessages(req.Messages)...)
-
-	if len(req.Tools) > 0 {
-		payload.ToolChoice = "auto"
-		payload.Tools = toOpenAITools(req.Tools)
-	}
-
-	// Structured output via response_format
-	if len(req.ResponseSchema) > 0 {
-		payload.ResponseFormat = &openAIRespFmt{
-			Type:       "json_schema",
-			JSONSchema: req.ResponseSchema,
-		}
-	}
-
-	raw, err := json.Marshal(payload)
+	payload := c.buildRequest(req)
+	httpReq, err := c.newHTTPRequest(ctx, payload)
 	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to marshal openai request: %w", err)
+		return types.Response{}, err
 	}
 
-	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/chat/completions", bytes.NewReader(raw))
-	if err != nil {
-		return types.Response{}, fmt.Errorf("failed to create openai request: %w", err)
-	}
-	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
-	httpReq.Header.Set("Content-Type", "application/json")
-
 	resp, err := c.httpClient.Do(httpReq)
 	if err != nil {
 		return types.Response{}, fmt.Errorf("openai request failed: %w", err)
@@ -166,9 +134,70 @@ func (c *Client) Generate(ctx context.Context, req types.Request) (types.Respons
 	return types.Response{
 		Message: out,
 		Usage:   usage,
+		Model:   payload.Model,
 	}, nil
 }
 
+func (c *Client) buildRequest(req types.Request) openAIRequest {
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
+	}
+
+	payload := openAIRequest{
+		Model:    model,
+		Messages: make([]openAIMessage, 0, len(req.Messages)+1),
+	}
+	if req.MaxOutputTokens > 0 {
+		payload.MaxTokens = req.MaxOutputTokens
+	}
+
+	if req.SystemPrompt != "" {
+		payload.Messages = append(payload.Messages, openAIMessage{
+			Role:    "system",
+			Content: req.SystemPrompt,
+		})
+	}
+	payload.Messages = append(payload.Messages, toOpenAIMessages(req.Messages)...)
+
+	payload.Temperature = req.Temperature
+	payload.TopP = req.TopP
+	payload.Stop = req.StopSequences
+	payload.Seed = req.Seed
+	payload.PresencePenalty = req.PresencePenalty
+	payload.FrequencyPenalty = req.FrequencyPenalty
+
+	if len(req.Tools) > 0 {
+		payload.ToolChoice = toOpenAIToolChoice(req.ToolChoice)
+		payload.Tools = toOpenAITools(req.Tools)
+		payload.ParallelToolCalls = req.ParallelToolCalls
+	}
+
+	// Structured output via response_format
+	if len(req.ResponseSchema) > 0 {
+		payload.ResponseFormat = &openAIRespFmt{
+			Type:       "json_schema",
+			JSONSchema: req.ResponseSchema,
+		}
+	}
+	return payload
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, payload openAIRequest) (*http.Request, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal openai request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/chat/completions", bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create openai request: %w", err)
+	}
+	httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	httpReq.Header.Set("Content-Type", "application/json")
+	return httpReq, nil
+}
+
 func toOpenAIMessages(in []types.Message) []openAIMessage {
 	msgs := make([]openAIMessage, 0, len(in))
 	for _, m := range in {
@@ -176,7 +205,7 @@ func toOpenAIMessages(in []types.Message) []openAIMessage {
 		case types.RoleUser:
 			msgs = append(msgs, openAIMessage{
 				Role:    "user",
-				Content: m.Content,
+				Content: toOpenAIContent(m),
 			})
 		case types.RoleAssistant:
 			out := openAIMessage{
@@ -235,6 +264,21 @@ func toOpenAITools(in []types.ToolDefinition) []openAITool {
 	return tools
 }
 
+// toOpenAIToolChoice maps a tool choice to the tool_choice field, which is
+// either a mode string or a named function object.
+func toOpenAIToolChoice(choice *types.ToolChoice) any {
+	if choice == nil || choice.Mode == "" {
+		return "auto"
+	}
+	if choice.Mode == types.ToolChoiceTool {
+		return map[string]any{
+			"type":     "function",
+			"function": map[string]any{"name": choice.Name},
+		}
+	}
+	return string(choice.Mode)
+}
+
 func messageContentToString(content any) string {
 	switch c := content.(type) {
 	case string:
@@ -263,12 +307,21 @@ func normalizeJSONArgs(raw string) json.RawMessage {
 }
 
 type openAIRequest struct {
-	Model          string          `json:"model"`
-	Messages       []openAIMessage `json:"messages"`
-	Tools          []openAITool    `json:"tools,omitempty"`
-	ToolChoice     string          `json:"tool_choice,omitempty"`
-	MaxTokens      int             `json:"max_tokens,omitempty"`
-	ResponseFormat *openAIRespFmt  `json:"response_format,omitempty"`
+	Model             string          `json:"model"`
+	Messages          []openAIMessage `json:"messages"`
+	Tools             []openAITool    `json:"tools,omitempty"`
+	ToolChoice        any             `json:"tool_choice,omitempty"`
+	ParallelToolCalls *bool           `json:"parallel_tool_calls,omitempty"`
+	MaxTokens         int             `json:"max_tokens,omitempty"`
+	Temperature       *float64        `json:"temperature,omitempty"`
+	TopP              *float64        `json:"top_p,omitempty"`
+	Stop              []string        `json:"stop,omitempty"`
+	Seed              *int64          `json:"seed,omitempty"`
+	PresencePenalty   *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty  *float64        `json:"frequency_penalty,omitempty"`
+	ResponseFormat    *openAIRespFmt  `json:"response_format,omitempty"`
+	Stream            bool            `json:"stream,omitempty"`
+	StreamOptions     *streamOptions  `json:"stream_options,omitempty"`
 }
 
 type openAIRespFmt struct {
@@ -0,0 +1,56 @@
+package openai
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestBuildRequest_GenerationControls(t *testing.T) {
+	c, err := New("test-key")
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	temp, seed, parallel := 0.3, int64(42), false
+	payload := c.buildRequest(types.Request{
+		Messages:          []types.Message{{Role: types.RoleUser, Content: "hi"}},
+		Tools:             []types.ToolDefinition{{Name: "lookup"}},
+		Temperature:       &temp,
+		StopSequences:     []string{"###"},
+		Seed:              &seed,
+		ToolChoice:        &types.ToolChoice{Mode: types.ToolChoiceTool, Name: "lookup"},
+		ParallelToolCalls: &parallel,
+	})
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		t.Fatalf("marshal error = %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(raw, &got); err != nil {
+		t.Fatalf("unmarshal error = %v", err)
+	}
+	if got["temperature"] != 0.3 || got["seed"] != float64(42) || got["parallel_tool_calls"] != false {
+		t.Fatalf("unexpected sampling fields: %v", got)
+	}
+	if stop, _ := got["stop"].([]any); len(stop) != 1 || stop[0] != "###" {
+		t.Fatalf("unexpected stop: %v", got["stop"])
+	}
+	choice, _ := got["tool_choice"].(map[string]any)
+	fn, _ := choice["function"].(map[string]any)
+	if choice["type"] != "function" || fn["name"] != "lookup" {
+		t.Fatalf("unexpected tool_choice: %v", got["tool_choice"])
+	}
+	if _, ok := got["top_p"]; ok {
+		t.Fatalf("expected unset top_p to be omitted")
+	}
+
+	payload = c.buildRequest(types.Request{
+		Tools:      []types.ToolDefinition{{Name: "lookup"}},
+		ToolChoice: &types.ToolChoice{Mode: types.ToolChoiceRequired},
+	})
+	if payload.ToolChoice != "required" {
+		t.Fatalf("expected required tool_choice, got %v", payload.ToolChoice)
+	}
+}
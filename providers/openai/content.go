@@ -0,0 +1,97 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// toOpenAIContent returns the message content in chat completions form: a
+// plain string for text-only messages, or a content-part array when the
+// message carries multimodal parts.
+func toOpenAIContent(m types.Message) any {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	parts := m.ContentParts()
+	out := make([]openAIContentPart, 0, len(parts))
+	for _, p := range parts {
+		switch p.Type {
+		case types.ContentPartImage:
+			out = append(out, openAIContentPart{
+				Type:     "image_url",
+				ImageURL: &openAIImageURL{URL: p.DataURL()},
+			})
+		case types.ContentPartFile:
+			if len(p.Data) == 0 {
+				out = append(out, openAIContentPart{Type: "text", Text: attachmentNote(p)})
+				continue
+			}
+			filename := p.Filename
+			if filename == "" {
+				filename = "attachment"
+			}
+			out = append(out, openAIContentPart{
+				Type: "file",
+				File: &openAIFile{Filename: filename, FileData: p.DataURL()},
+			})
+		case types.ContentPartAudio:
+			format := audioFormat(p.MIMEType)
+			if len(p.Data) == 0 || format == "" {
+				out = append(out, openAIContentPart{Type: "text", Text: attachmentNote(p)})
+				continue
+			}
+			out = append(out, openAIContentPart{
+				Type:       "input_audio",
+				InputAudio: &openAIInputAudio{Data: p.Base64(), Format: format},
+			})
+		default:
+			out = append(out, openAIContentPart{Type: "text", Text: p.Text})
+		}
+	}
+	return out
+}
+
+// audioFormat maps an audio MIME type to the input_audio format names the
+// API accepts.
+func audioFormat(mimeType string) string {
+	switch strings.ToLower(mimeType) {
+	case "audio/wav", "audio/x-wav", "audio/wave":
+		return "wav"
+	case "audio/mpeg", "audio/mp3":
+		return "mp3"
+	default:
+		return ""
+	}
+}
+
+func attachmentNote(p types.ContentPart) string {
+	ref := p.Filename
+	if ref == "" {
+		ref = p.URL
+	}
+	return fmt.Sprintf("[%s attachment %q (%s) could not be sent to this model]", p.Type, ref, p.MIMEType)
+}
+
+type openAIContentPart struct {
+	Type       string            `json:"type"`
+	Text       string            `json:"text,omitempty"`
+	ImageURL   *openAIImageURL   `json:"image_url,omitempty"`
+	File       *openAIFile       `json:"file,omitempty"`
+	InputAudio *openAIInputAudio `json:"input_audio,omitempty"`
+}
+
+type openAIImageURL struct {
+	URL string `json:"url"`
+}
+
+type openAIFile struct {
+	Filename string `json:"filename,omitempty"`
+	FileData string `json:"file_data"`
+}
+
+type openAIInputAudio struct {
+	Data   string `json:"data"`
+	Format string `json:"format"`
+}
@@ -0,0 +1,45 @@
+package openai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestToOpenAIMessages_MultimodalParts(t *testing.T) {
+	msgs := toOpenAIMessages([]types.Message{
+		{Role: types.RoleUser, Content: "plain"},
+		{
+			Role:    types.RoleUser,
+			Content: "describe these",
+			Parts: []types.ContentPart{
+				types.ImagePart([]byte("png"), "image/png"),
+				types.ImageURLPart("https://example.com/diagram.png"),
+				types.FilePart([]byte("%PDF"), "application/pdf", "report.pdf"),
+				types.AudioPart([]byte("RIFF"), "audio/wav"),
+			},
+		},
+	})
+	if msgs[0].Content != "plain" {
+		t.Fatalf("text-only message should keep string content, got %#v", msgs[0].Content)
+	}
+
+	raw, err := json.Marshal(msgs[1])
+	if err != nil {
+		t.Fatalf("marshal failed: %v", err)
+	}
+	got := string(raw)
+	for _, want := range []string{
+		`{"type":"text","text":"describe these"}`,
+		`{"type":"image_url","image_url":{"url":"data:image/png;base64,cG5n"}}`,
+		`{"type":"image_url","image_url":{"url":"https://example.com/diagram.png"}}`,
+		`{"type":"file","file":{"filename":"report.pdf","file_data":"data:application/pdf;base64,JVBERg=="}}`,
+		`{"type":"input_audio","input_audio":{"data":"UklGRg==","format":"wav"}}`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Fatalf("expected %s in %s", want, got)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/providers/internal/sse"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// GenerateStream implements llm.StreamProvider using server-sent events from
+// the chat completions endpoint. Tool-call arguments are assembled from their
+// incremental fragments and usage is requested for the final event.
+func (c *Client) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	if onChunk == nil {
+		return types.Response{}, fmt.Errorf("onChunk is required")
+	}
+	payload := c.buildRequest(req)
+	payload.Stream = true
+	payload.StreamOptions = &streamOptions{IncludeUsage: true}
+
+	httpReq, err := c.newHTTPRequest(ctx, payload)
+	if err != nil {
+		return types.Response{}, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return types.Response{}, fmt.Errorf("openai request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return types.Response{}, fmt.Errorf("openai API error (%d): %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	acc := &sse.ChatAccumulator{Arguments: normalizeJSONArgs}
+	out, err := sse.ReadChat(resp.Body, "openai", acc, onChunk)
+	if err != nil {
+		return types.Response{}, err
+	}
+	out.Model = payload.Model
+	return out, nil
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
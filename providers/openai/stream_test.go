@@ -0,0 +1,133 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestClientGenerateStream_ReplaysTranscript(t *testing.T) {
+	transcript, err := os.ReadFile("testdata/stream_tool_call.sse")
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		var req map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Fatalf("failed to decode request: %v", err)
+		}
+		if req["stream"] != true {
+			t.Fatalf("expected stream=true, got %#v", req["stream"])
+		}
+		opts, _ := req["stream_options"].(map[string]any)
+		if opts["include_usage"] != true {
+			t.Fatalf("expected include_usage stream option, got %#v", req["stream_options"])
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write(transcript)
+	}))
+	defer ts.Close()
+
+	client, err := New("test-key", WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var (
+		text  strings.Builder
+		args  strings.Builder
+		kinds []types.StreamEventType
+	)
+	resp, err := client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "what is 2+2"}},
+		Tools:    []types.ToolDefinition{{Name: "calc", Description: "calculator"}},
+	}, func(chunk types.StreamChunk) error {
+		kinds = append(kinds, chunk.Type)
+		text.WriteString(chunk.Text)
+		if chunk.ToolCallDelta != nil {
+			args.WriteString(chunk.ToolCallDelta.ArgumentsDelta)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	if text.String() != "Let me check." || resp.Message.Content != "Let me check." {
+		t.Fatalf("unexpected content: streamed=%q final=%q", text.String(), resp.Message.Content)
+	}
+	if len(resp.Message.ToolCalls) != 1 {
+		t.Fatalf("expected one tool call, got %#v", resp.Message.ToolCalls)
+	}
+	call := resp.Message.ToolCalls[0]
+	if call.ID != "call_abc" || call.Name != "calc" || string(call.Arguments) != `{"expr":"2+2"}` {
+		t.Fatalf("unexpected tool call: %#v (args %s)", call, call.Arguments)
+	}
+	if args.String() != `{"expr":"2+2"}` {
+		t.Fatalf("unexpected streamed arguments: %q", args.String())
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 59 {
+		t.Fatalf("unexpected usage: %#v", resp.Usage)
+	}
+	if n := len(kinds); n < 2 || kinds[n-2] != types.StreamEventUsage || kinds[n-1] != types.StreamEventDone {
+		t.Fatalf("expected usage then done at end of stream, got %v", kinds)
+	}
+}
+
+func TestClientGenerateStream_ErrorStatus(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTooManyRequests)
+		_, _ = w.Write([]byte(`{"error":{"message":"rate limited"}}`))
+	}))
+	defer ts.Close()
+
+	client, err := New("test-key", WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_, err = client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "hi"}},
+	}, func(types.StreamChunk) error { return nil })
+	if err == nil || !strings.Contains(err.Error(), "429") {
+		t.Fatalf("expected 429 error, got %v", err)
+	}
+}
+
+func TestClientGenerateStream_TruncatedBody(t *testing.T) {
+	transcript, err := os.ReadFile("testdata/stream_tool_call.sse")
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	// The connection drops before the final event.
+	cut := strings.Index(string(transcript), "data: [DONE]")
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write(transcript[:cut])
+	}))
+	defer ts.Close()
+
+	client, err := New("test-key", WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	done := false
+	_, err = client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "hi"}},
+	}, func(chunk types.StreamChunk) error {
+		done = done || chunk.Done
+		return nil
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) || done {
+		t.Fatalf("expected a truncated stream to fail without a done chunk, got %v (done=%v)", err, done)
+	}
+}
@@ -0,0 +1,503 @@
+// Package openaicompat implements llm.Provider for servers that speak the
+// OpenAI chat-completions dialect (vLLM, LM Studio, LiteLLM, OpenRouter, Groq,
+// Together and similar). Differences between backends are described by a
+// Profile rather than by code.
+package openaicompat
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+	"github.com/google/uuid"
+)
+
+type Client struct {
+	apiKey     string
+	model      string
+	baseURL    string
+	name       string
+	profile    Profile
+	httpClient *http.Client
+}
+
+type Option func(*Client)
+
+func WithModel(model string) Option {
+	return func(c *Client) { c.model = model }
+}
+
+// WithBaseURL overrides the profile's base URL.
+func WithBaseURL(baseURL string) Option {
+	return func(c *Client) { c.baseURL = strings.TrimRight(baseURL, "/") }
+}
+
+// WithAPIKey sets the bearer token. Local servers usually need none.
+func WithAPIKey(apiKey string) Option {
+	return func(c *Client) { c.apiKey = apiKey }
+}
+
+// WithProfile selects the quirk profile. Defaults to the generic profile.
+func WithProfile(profile Profile) Option {
+	return func(c *Client) { c.profile = profile }
+}
+
+// WithName overrides the provider name reported by Name.
+func WithName(name string) Option {
+	return func(c *Client) { c.name = name }
+}
+
+func WithHTTPClient(h *http.Client) Option {
+	return func(c *Client) {
+		if h != nil {
+			c.httpClient = h
+		}
+	}
+}
+
+func New(opts ...Option) (*Client, error) {
+	generic, _ := LookupProfile("generic")
+	c := &Client{
+		profile: generic,
+		httpClient: &http.Client{
+			Timeout: 120 * time.Second,
+		},
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if err := c.profile.validate(); err != nil {
+		return nil, err
+	}
+	if c.baseURL == "" {
+		c.baseURL = strings.TrimRight(c.profile.BaseURL, "/")
+	}
+	if c.baseURL == "" {
+		return nil, fmt.Errorf("base URL is required for openai-compatible provider")
+	}
+	if strings.TrimSpace(c.model) == "" {
+		return nil, fmt.Errorf("model is required for openai-compatible provider")
+	}
+	if c.name == "" {
+		c.name = c.profile.Name
+	}
+	if c.name == "" {
+		c.name = "openaicompat"
+	}
+	return c, nil
+}
+
+func (c *Client) Name() string { return c.name }
+
+// Profile returns the quirk profile the client was built with.
+func (c *Client) Profile() Profile { return c.profile }
+
+func (c *Client) Capabilities() llm.Capabilities {
+	p := c.profile
+	return llm.Capabilities{
+		Tools:             true,
+		Streaming:         true,
+		StructuredOutput:  p.jsonMode() != JSONModeNone,
+		Temperature:       p.supports("temperature"),
+		TopP:              p.supports("topP"),
+		TopK:              p.supports("topK"),
+		StopSequences:     p.supports("stopSequences"),
+		Seed:              p.supports("seed"),
+		PresencePenalty:   p.supports("presencePenalty"),
+		FrequencyPenalty:  p.supports("frequencyPenalty"),
+		ToolChoice:        p.supports("toolChoice"),
+		ParallelToolCalls: p.supports("parallelToolCalls"),
+	}
+}
+
+func (c *Client) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	payload := c.buildRequest(req)
+	httpReq, err := c.newHTTPRequest(ctx, payload)
+	if err != nil {
+		return types.Response{}, err
+	}
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return types.Response{}, fmt.Errorf("%s request failed: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return types.Response{}, fmt.Errorf("failed to read %s response: %w", c.name, err)
+	}
+	if resp.StatusCode >= 300 {
+		return types.Response{}, fmt.Errorf("%s API error (%d): %s", c.name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var apiResp chatResponse
+	if err := json.Unmarshal(body, &apiResp); err != nil {
+		return types.Response{}, fmt.Errorf("failed to decode %s response: %w", c.name, err)
+	}
+	if len(apiResp.Choices) == 0 {
+		return types.Response{}, fmt.Errorf("%s response had no choices", c.name)
+	}
+
+	msg := apiResp.Choices[0].Message
+	out := types.Message{
+		Role:      types.RoleAssistant,
+		Content:   messageContentToString(msg.Content),
+		Reasoning: c.reasoning(msg.ReasoningContent, msg.Reasoning),
+	}
+	if len(msg.ToolCalls) > 0 {
+		out.ToolCalls = make([]types.ToolCall, 0, len(msg.ToolCalls))
+		for _, tc := range msg.ToolCalls {
+			out.ToolCalls = append(out.ToolCalls, types.ToolCall{
+				ID:        c.responseToolCallID(tc.ID),
+				Name:      tc.Function.Name,
+				Arguments: normalizeJSONArgs(tc.Function.Arguments),
+			})
+		}
+	}
+
+	var usage *types.Usage
+	if apiResp.Usage.TotalTokens > 0 {
+		usage = &types.Usage{
+			InputTokens:  apiResp.Usage.PromptTokens,
+			OutputTokens: apiResp.Usage.CompletionTokens,
+			TotalTokens:  apiResp.Usage.TotalTokens,
+		}
+	}
+	return types.Response{Message: out, Usage: usage, Model: payload.Model}, nil
+}
+
+func (c *Client) buildRequest(req types.Request) chatRequest {
+	p := c.profile
+	model := c.model
+	if req.Model != "" {
+		model = req.Model
+	}
+
+	payload := chatRequest{
+		Model:    model,
+		Messages: make([]chatMessage, 0, len(req.Messages)+1),
+	}
+	if req.MaxOutputTokens > 0 {
+		if p.MaxTokensField == "max_completion_tokens" {
+			payload.MaxCompletionTokens = req.MaxOutputTokens
+		} else {
+			payload.MaxTokens = req.MaxOutputTokens
+		}
+	}
+
+	systemPrompt := req.SystemPrompt
+	if len(req.ResponseSchema) > 0 {
+		switch p.jsonMode() {
+		case JSONModeSchema:
+			payload.ResponseFormat = &responseFormat{Type: "json_schema", JSONSchema: req.ResponseSchema}
+		case JSONModeObject:
+			payload.ResponseFormat = &responseFormat{Type: "json_object"}
+			systemPrompt = withSchemaInstruction(systemPrompt, req.ResponseSchema)
+		default:
+			systemPrompt = withSchemaInstruction(systemPrompt, req.ResponseSchema)
+		}
+	}
+	if systemPrompt != "" {
+		payload.Messages = append(payload.Messages, chatMessage{Role: "system", Content: systemPrompt})
+	}
+	payload.Messages = append(payload.Messages, c.toChatMessages(req.Messages)...)
+
+	if p.supports("temperature") {
+		payload.Temperature = req.Temperature
+	}
+	if p.supports("topP") {
+		payload.TopP = req.TopP
+	}
+	if p.supports("topK") {
+		payload.TopK = req.TopK
+	}
+	if p.supports("stopSequences") {
+		payload.Stop = req.StopSequences
+	}
+	if p.supports("seed") {
+		payload.Seed = req.Seed
+	}
+	if p.supports("presencePenalty") {
+		payload.PresencePenalty = req.PresencePenalty
+	}
+	if p.supports("frequencyPenalty") {
+		payload.FrequencyPenalty = req.FrequencyPenalty
+	}
+
+	if len(req.Tools) > 0 {
+		payload.Tools = toChatTools(req.Tools)
+		if p.supports("toolChoice") {
+			payload.ToolChoice = toChatToolChoice(req.ToolChoice)
+		}
+		if p.supports("parallelToolCalls") {
+			payload.ParallelToolCalls = req.ParallelToolCalls
+		}
+	}
+	return payload
+}
+
+func (c *Client) newHTTPRequest(ctx context.Context, payload chatRequest) (*http.Request, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s request: %w", c.name, err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+c.profile.chatPath(), bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s request: %w", c.name, err)
+	}
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	for k, v := range c.profile.Headers {
+		httpReq.Header.Set(k, v)
+	}
+	return httpReq, nil
+}
+
+func (c *Client) toChatMessages(in []types.Message) []chatMessage {
+	msgs := make([]chatMessage, 0, len(in))
+	for _, m := range in {
+		switch m.Role {
+		case types.RoleUser:
+			msgs = append(msgs, chatMessage{Role: "user", Content: c.toChatContent(m)})
+		case types.RoleAssistant:
+			out := chatMessage{Role: "assistant", Content: m.Content}
+			if len(m.ToolCalls) > 0 {
+				out.ToolCalls = make([]chatToolCall, 0, len(m.ToolCalls))
+				for _, tc := range m.ToolCalls {
+					args := "{}"
+					if len(tc.Arguments) > 0 {
+						args = string(tc.Arguments)
+					}
+					out.ToolCalls = append(out.ToolCalls, chatToolCall{
+						ID:   c.requestToolCallID(tc.ID),
+						Type: "function",
+						Function: chatFunctionCall{
+							Name:      tc.Name,
+							Arguments: args,
+						},
+					})
+				}
+			}
+			msgs = append(msgs, out)
+		case types.RoleTool:
+			msgs = append(msgs, chatMessage{
+				Role:       "tool",
+				Name:       m.Name,
+				ToolCallID: c.requestToolCallID(m.ToolCallID),
+				Content:    m.Content,
+			})
+		}
+	}
+	return msgs
+}
+
+// reasoning picks the reasoning text from the field the profile names.
+func (c *Client) reasoning(reasoningContent, reasoning string) string {
+	switch c.profile.ReasoningField {
+	case "reasoning_content":
+		return reasoningContent
+	case "reasoning":
+		return reasoning
+	default:
+		return ""
+	}
+}
+
+// requestToolCallID rewrites an ID into the format the backend accepts. The
+// mapping is deterministic so calls and their results stay paired across
+// turns without keeping state.
+func (c *Client) requestToolCallID(id string) string {
+	if c.profile.ToolCallIDs != ToolCallIDAlnum9 || isAlnum9(id) {
+		return id
+	}
+	sum := sha256.Sum256([]byte(id))
+	return alnum9(sum[:])
+}
+
+// responseToolCallID fills in IDs that some backends omit; the agent needs
+// them to pair tool results with calls.
+func (c *Client) responseToolCallID(id string) string {
+	if id != "" {
+		return id
+	}
+	if c.profile.ToolCallIDs == ToolCallIDAlnum9 {
+		buf := make([]byte, 9)
+		_, _ = rand.Read(buf)
+		return alnum9(buf)
+	}
+	return "call_" + strings.ReplaceAll(uuid.NewString(), "-", "")
+}
+
+const alnumChars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+func alnum9(seed []byte) string {
+	out := make([]byte, 9)
+	for i := range out {
+		out[i] = alnumChars[int(seed[i%len(seed)])%len(alnumChars)]
+	}
+	return string(out)
+}
+
+func isAlnum9(id string) bool {
+	if len(id) != 9 {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if !strings.ContainsRune(alnumChars, rune(id[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+func withSchemaInstruction(systemPrompt string, schema map[string]any) string {
+	schemaJSON, _ := json.Marshal(schema)
+	instruction := "You MUST respond with valid JSON matching this schema:\n```json\n" + string(schemaJSON) + "\n```\nRespond ONLY with the JSON object, no other text."
+	if systemPrompt == "" {
+		return instruction
+	}
+	return systemPrompt + "\n\n" + instruction
+}
+
+func toChatTools(in []types.ToolDefinition) []chatTool {
+	tools := make([]chatTool, 0, len(in))
+	for _, t := range in {
+		params := t.JSONSchema
+		if len(params) == 0 {
+			params = map[string]any{
+				"type":       "object",
+				"properties": map[string]any{},
+			}
+		}
+		tools = append(tools, chatTool{
+			Type: "function",
+			Function: chatToolFunction{
+				Name:        t.Name,
+				Description: t.Description,
+				Parameters:  params,
+			},
+		})
+	}
+	return tools
+}
+
+func toChatToolChoice(choice *types.ToolChoice) any {
+	if choice == nil || choice.Mode == "" {
+		return "auto"
+	}
+	if choice.Mode == types.ToolChoiceTool {
+		return map[string]any{
+			"type":     "function",
+			"function": map[string]any{"name": choice.Name},
+		}
+	}
+	return string(choice.Mode)
+}
+
+func messageContentToString(content any) string {
+	switch c := content.(type) {
+	case string:
+		return c
+	case nil:
+		return ""
+	default:
+		b, err := json.Marshal(c)
+		if err != nil {
+			return fmt.Sprintf("%v", c)
+		}
+		return string(b)
+	}
+}
+
+func normalizeJSONArgs(raw string) json.RawMessage {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return json.RawMessage(`{}`)
+	}
+	if json.Valid([]byte(raw)) {
+		return json.RawMessage(raw)
+	}
+	escaped, _ := json.Marshal(raw)
+	return json.RawMessage(fmt.Sprintf(`{"raw":%s}`, string(escaped)))
+}
+
+type chatRequest struct {
+	Model               string          `json:"model"`
+	Messages            []chatMessage   `json:"messages"`
+	Tools               []chatTool      `json:"tools,omitempty"`
+	ToolChoice          any             `json:"tool_choice,omitempty"`
+	ParallelToolCalls   *bool           `json:"parallel_tool_calls,omitempty"`
+	MaxTokens           int             `json:"max_tokens,omitempty"`
+	MaxCompletionTokens int             `json:"max_completion_tokens,omitempty"`
+	Temperature         *float64        `json:"temperature,omitempty"`
+	TopP                *float64        `json:"top_p,omitempty"`
+	TopK                *int            `json:"top_k,omitempty"`
+	Stop                []string        `json:"stop,omitempty"`
+	Seed                *int64          `json:"seed,omitempty"`
+	PresencePenalty     *float64        `json:"presence_penalty,omitempty"`
+	FrequencyPenalty    *float64        `json:"frequency_penalty,omitempty"`
+	ResponseFormat      *responseFormat `json:"response_format,omitempty"`
+	Stream              bool            `json:"stream,omitempty"`
+	StreamOptions       *streamOptions  `json:"stream_options,omitempty"`
+}
+
+type responseFormat struct {
+	Type       string         `json:"type"`
+	JSONSchema map[string]any `json:"json_schema,omitempty"`
+}
+
+type chatMessage struct {
+	Role             string         `json:"role"`
+	Name             string         `json:"name,omitempty"`
+	Content          any            `json:"content"`
+	ReasoningContent string         `json:"reasoning_content,omitempty"`
+	Reasoning        string         `json:"reasoning,omitempty"`
+	ToolCallID       string         `json:"tool_call_id,omitempty"`
+	ToolCalls        []chatToolCall `json:"tool_calls,omitempty"`
+}
+
+type chatTool struct {
+	Type     string           `json:"type"`
+	Function chatToolFunction `json:"function"`
+}
+
+type chatToolFunction struct {
+	Name        string         `json:"name"`
+	Description string         `json:"description,omitempty"`
+	Parameters  map[string]any `json:"parameters,omitempty"`
+}
+
+type chatToolCall struct {
+	ID       string           `json:"id,omitempty"`
+	Type     string           `json:"type,omitempty"`
+	Function chatFunctionCall `json:"function"`
+}
+
+type chatFunctionCall struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage struct {
+		PromptTokens     int `json:"prompt_tokens"`
+		CompletionTokens int `json:"completion_tokens"`
+		TotalTokens      int `json:"total_tokens"`
+	} `json:"usage"`
+}
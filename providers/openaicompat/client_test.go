@@ -0,0 +1,117 @@
+package openaicompat
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestParseProfile_Extends(t *testing.T) {
+	p, err := ParseProfile([]byte(`{"name":"router","extends":"openrouter","headers":{"X-Title":"agents"},"unsupported":["seed"]}`))
+	if err != nil {
+		t.Fatalf("ParseProfile() error = %v", err)
+	}
+	if p.Name != "router" || p.BaseURL != "https://openrouter.ai/api" || p.ReasoningField != "reasoning" {
+		t.Fatalf("expected openrouter settings to be inherited, got %+v", p)
+	}
+	if p.Headers["X-Title"] != "agents" || p.supports("seed") {
+		t.Fatalf("expected overrides to apply, got %+v", p)
+	}
+
+	if _, err := ParseProfile([]byte(`{"extends":"nope"}`)); err == nil {
+		t.Fatalf("expected unknown base profile error")
+	}
+	if _, err := ParseProfile([]byte(`{"maxTokensField":"tokens"}`)); err == nil {
+		t.Fatalf("expected invalid maxTokensField error")
+	}
+}
+
+func TestBuildRequest_AppliesProfileQuirks(t *testing.T) {
+	profile, _ := LookupProfile("groq")
+	profile.ToolCallIDs = ToolCallIDAlnum9
+	c, err := New(WithProfile(profile), WithModel("llama"))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	topK, penalty, temp := 5, 0.5, 0.1
+	payload := c.buildRequest(types.Request{
+		MaxOutputTokens: 256,
+		ResponseSchema:  map[string]any{"type": "object"},
+		Temperature:     &temp,
+		TopK:            &topK,
+		PresencePenalty: &penalty,
+		Messages: []types.Message{
+			{Role: types.RoleUser, Content: "hi"},
+			{Role: types.RoleAssistant, ToolCalls: []types.ToolCall{{ID: "call_abc123def456", Name: "calc"}}},
+			{Role: types.RoleTool, ToolCallID: "call_abc123def456", Name: "calc", Content: "4"},
+		},
+	})
+
+	if payload.MaxCompletionTokens != 256 || payload.MaxTokens != 0 {
+		t.Fatalf("expected max_completion_tokens, got max_tokens=%d max_completion_tokens=%d", payload.MaxTokens, payload.MaxCompletionTokens)
+	}
+	if payload.TopK != nil || payload.PresencePenalty != nil || payload.Temperature == nil {
+		t.Fatalf("expected unsupported controls dropped and supported kept: %+v", payload)
+	}
+	if payload.ResponseFormat == nil || payload.ResponseFormat.Type != "json_object" || payload.Messages[0].Role != "system" {
+		t.Fatalf("expected json_object mode with schema in system prompt, got %+v", payload.ResponseFormat)
+	}
+	callID := payload.Messages[2].ToolCalls[0].ID
+	if !isAlnum9(callID) || payload.Messages[3].ToolCallID != callID {
+		t.Fatalf("expected paired alnum9 tool call IDs, got %q and %q", callID, payload.Messages[3].ToolCallID)
+	}
+}
+
+func TestClientGenerate_ReasoningAndMissingToolCallID(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Fatalf("unexpected path: %s", r.URL.Path)
+		}
+		if r.Header.Get("Authorization") != "" {
+			t.Fatalf("expected no Authorization header without api key")
+		}
+		var req map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if _, ok := req["response_format"].(map[string]any)["json_schema"]; !ok {
+			t.Fatalf("expected json_schema response format for vllm, got %v", req["response_format"])
+		}
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":"","reasoning_content":"thinking","tool_calls":[{"type":"function","function":{"name":"calc","arguments":"{}"}}]}}],"usage":{"prompt_tokens":3,"completion_tokens":2,"total_tokens":5}}`))
+	}))
+	defer ts.Close()
+
+	profile, _ := LookupProfile("vllm")
+	c, err := New(WithProfile(profile), WithModel("qwen3"), WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	resp, err := c.Generate(context.Background(), types.Request{
+		Messages:       []types.Message{{Role: types.RoleUser, Content: "2+2"}},
+		Tools:          []types.ToolDefinition{{Name: "calc"}},
+		ResponseSchema: map[string]any{"type": "object"},
+	})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Message.Reasoning != "thinking" {
+		t.Fatalf("expected reasoning_content to be read, got %q", resp.Message.Reasoning)
+	}
+	if len(resp.Message.ToolCalls) != 1 || resp.Message.ToolCalls[0].ID == "" {
+		t.Fatalf("expected generated tool call ID, got %+v", resp.Message.ToolCalls)
+	}
+	if c.Name() != "vllm" {
+		t.Fatalf("expected provider name from profile, got %q", c.Name())
+	}
+}
+
+func TestNew_RequiresBaseURLAndModel(t *testing.T) {
+	if _, err := New(WithModel("m")); err == nil {
+		t.Fatalf("expected base URL error for generic profile")
+	}
+	if _, err := New(WithBaseURL("http://localhost:8000")); err == nil {
+		t.Fatalf("expected model error")
+	}
+}
@@ -0,0 +1,63 @@
+package openaicompat
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// toChatContent returns the message content in chat completions form. Images
+// are sent as image_url parts and textual files are inlined, since file and
+// audio parts are rarely implemented by compatible servers. Profiles with
+// TextOnlyContent get a single string instead of a part array.
+func (c *Client) toChatContent(m types.Message) any {
+	if len(m.Parts) == 0 {
+		return m.Content
+	}
+	parts := m.ContentParts()
+	out := make([]chatContentPart, 0, len(parts))
+	for _, p := range parts {
+		switch {
+		case p.Type == types.ContentPartImage && !c.profile.TextOnlyContent:
+			out = append(out, chatContentPart{
+				Type:     "image_url",
+				ImageURL: &chatImageURL{URL: p.DataURL()},
+			})
+		case p.Type == types.ContentPartText:
+			out = append(out, chatContentPart{Type: "text", Text: p.Text})
+		case p.Type == types.ContentPartFile && len(p.Data) > 0 && p.IsTextual():
+			out = append(out, chatContentPart{
+				Type: "text",
+				Text: fmt.Sprintf("<file name=%q>\n%s\n</file>", p.Filename, string(p.Data)),
+			})
+		default:
+			ref := p.Filename
+			if ref == "" {
+				ref = p.URL
+			}
+			out = append(out, chatContentPart{
+				Type: "text",
+				Text: fmt.Sprintf("[%s attachment %q (%s) could not be sent to this model]", p.Type, ref, p.MIMEType),
+			})
+		}
+	}
+	if c.profile.TextOnlyContent {
+		texts := make([]string, 0, len(out))
+		for _, part := range out {
+			texts = append(texts, part.Text)
+		}
+		return strings.Join(texts, "\n\n")
+	}
+	return out
+}
+
+type chatContentPart struct {
+	Type     string        `json:"type"`
+	Text     string        `json:"text,omitempty"`
+	ImageURL *chatImageURL `json:"image_url,omitempty"`
+}
+
+type chatImageURL struct {
+	URL string `json:"url"`
+}
@@ -0,0 +1,249 @@
+package openaicompat
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// JSONMode describes how a backend enforces structured output.
+type JSONMode string
+
+const (
+	// JSONModeSchema sends response_format with a json_schema.
+	JSONModeSchema JSONMode = "json_schema"
+	// JSONModeObject sends response_format {"type":"json_object"} and puts the
+	// schema in the system prompt.
+	JSONModeObject JSONMode = "json_object"
+	// JSONModeNone only describes the schema in the system prompt.
+	JSONModeNone JSONMode = "none"
+)
+
+// ToolCallIDFormat describes the tool-call IDs a backend accepts.
+type ToolCallIDFormat string
+
+const (
+	// ToolCallIDPassthrough sends IDs unchanged.
+	ToolCallIDPassthrough ToolCallIDFormat = "passthrough"
+	// ToolCallIDAlnum9 rewrites IDs to nine alphanumeric characters, as
+	// required by Mistral-family chat templates.
+	ToolCallIDAlnum9 ToolCallIDFormat = "alnum9"
+)
+
+// Profile declares the dialect differences of an OpenAI-compatible backend.
+// Zero values fall back to the behaviour of the OpenAI API itself, so a
+// profile only lists what differs.
+type Profile struct {
+	// Name identifies the profile and is reported as the provider name.
+	Name string `json:"name"`
+	// Extends names a built-in profile whose settings this one overrides.
+	Extends string `json:"extends,omitempty"`
+	// BaseURL is used when the client is not given one explicitly.
+	BaseURL string `json:"baseURL,omitempty"`
+	// ChatPath is appended to the base URL. Defaults to /v1/chat/completions.
+	ChatPath string `json:"chatPath,omitempty"`
+	// MaxTokensField is max_tokens (default) or max_completion_tokens.
+	MaxTokensField string `json:"maxTokensField,omitempty"`
+	// ReasoningField is the message field carrying reasoning output:
+	// reasoning_content, reasoning, or empty when the backend has none.
+	ReasoningField string `json:"reasoningField,omitempty"`
+	// JSONMode selects how ResponseSchema is enforced. Defaults to json_object.
+	JSONMode JSONMode `json:"jsonMode,omitempty"`
+	// ToolCallIDs selects the tool-call ID format sent back to the backend.
+	ToolCallIDs ToolCallIDFormat `json:"toolCallIDs,omitempty"`
+	// Unsupported lists generation controls the backend rejects, using the
+	// names reported by llm.Capabilities.Unsupported (e.g. "topK", "seed").
+	// They are dropped from requests.
+	Unsupported []string `json:"unsupported,omitempty"`
+	// NoStreamUsage disables stream_options.include_usage for backends that
+	// reject it.
+	NoStreamUsage bool `json:"noStreamUsage,omitempty"`
+	// TextOnlyContent flattens multimodal parts into text for backends that
+	// only accept string message content.
+	TextOnlyContent bool `json:"textOnlyContent,omitempty"`
+	// Headers are added to every request.
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+var builtinProfiles = map[string]Profile{
+	"generic": {
+		Name:        "openaicompat",
+		Unsupported: []string{"topK"},
+	},
+	"vllm": {
+		Name:           "vllm",
+		BaseURL:        "http://127.0.0.1:8000",
+		ReasoningField: "reasoning_content",
+		JSONMode:       JSONModeSchema,
+	},
+	"lmstudio": {
+		Name:           "lmstudio",
+		BaseURL:        "http://127.0.0.1:1234",
+		ReasoningField: "reasoning_content",
+		JSONMode:       JSONModeSchema,
+		Unsupported:    []string{"parallelToolCalls"},
+	},
+	"litellm": {
+		Name:           "litellm",
+		BaseURL:        "http://127.0.0.1:4000",
+		ReasoningField: "reasoning_content",
+		JSONMode:       JSONModeSchema,
+		Unsupported:    []string{"topK"},
+	},
+	"openrouter": {
+		Name:           "openrouter",
+		BaseURL:        "https://openrouter.ai/api",
+		ReasoningField: "reasoning",
+		JSONMode:       JSONModeSchema,
+	},
+	"groq": {
+		Name:           "groq",
+		BaseURL:        "https://api.groq.com/openai",
+		MaxTokensField: "max_completion_tokens",
+		ReasoningField: "reasoning",
+		Unsupported:    []string{"topK", "presencePenalty", "frequencyPenalty"},
+	},
+	"together": {
+		Name:    "together",
+		BaseURL: "https://api.together.xyz",
+	},
+}
+
+// ProfileNames returns the names of the built-in profiles.
+func ProfileNames() []string {
+	names := make([]string, 0, len(builtinProfiles))
+	for name := range builtinProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupProfile returns a built-in profile by name.
+func LookupProfile(name string) (Profile, bool) {
+	p, ok := builtinProfiles[strings.ToLower(strings.TrimSpace(name))]
+	if !ok {
+		return Profile{}, false
+	}
+	p.Unsupported = append([]string(nil), p.Unsupported...)
+	return p, true
+}
+
+// ParseProfile decodes a JSON profile and resolves its Extends reference.
+func ParseProfile(data []byte) (Profile, error) {
+	var p Profile
+	if err := json.Unmarshal(data, &p); err != nil {
+		return Profile{}, fmt.Errorf("failed to decode openai-compatible profile: %w", err)
+	}
+	if p.Extends != "" {
+		base, ok := LookupProfile(p.Extends)
+		if !ok {
+			return Profile{}, fmt.Errorf("profile extends unknown profile %q (available: %s)", p.Extends, strings.Join(ProfileNames(), ", "))
+		}
+		p = base.merge(p)
+	}
+	if err := p.validate(); err != nil {
+		return Profile{}, err
+	}
+	return p, nil
+}
+
+// LoadProfile reads a JSON profile from path.
+func LoadProfile(path string) (Profile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Profile{}, fmt.Errorf("failed to read openai-compatible profile: %w", err)
+	}
+	return ParseProfile(data)
+}
+
+// merge returns p with every non-zero field of override applied.
+func (p Profile) merge(override Profile) Profile {
+	if override.Name != "" {
+		p.Name = override.Name
+	}
+	if override.BaseURL != "" {
+		p.BaseURL = override.BaseURL
+	}
+	if override.ChatPath != "" {
+		p.ChatPath = override.ChatPath
+	}
+	if override.MaxTokensField != "" {
+		p.MaxTokensField = override.MaxTokensField
+	}
+	if override.ReasoningField != "" {
+		p.ReasoningField = override.ReasoningField
+	}
+	if override.JSONMode != "" {
+		p.JSONMode = override.JSONMode
+	}
+	if override.ToolCallIDs != "" {
+		p.ToolCallIDs = override.ToolCallIDs
+	}
+	if override.Unsupported != nil {
+		p.Unsupported = override.Unsupported
+	}
+	p.NoStreamUsage = p.NoStreamUsage || override.NoStreamUsage
+	p.TextOnlyContent = p.TextOnlyContent || override.TextOnlyContent
+	if len(override.Headers) > 0 {
+		headers := make(map[string]string, len(p.Headers)+len(override.Headers))
+		for k, v := range p.Headers {
+			headers[k] = v
+		}
+		for k, v := range override.Headers {
+			headers[k] = v
+		}
+		p.Headers = headers
+	}
+	p.Extends = ""
+	return p
+}
+
+func (p Profile) validate() error {
+	switch p.MaxTokensField {
+	case "", "max_tokens", "max_completion_tokens":
+	default:
+		return fmt.Errorf("unsupported maxTokensField %q", p.MaxTokensField)
+	}
+	switch p.ReasoningField {
+	case "", "reasoning_content", "reasoning":
+	default:
+		return fmt.Errorf("unsupported reasoningField %q", p.ReasoningField)
+	}
+	switch p.JSONMode {
+	case "", JSONModeSchema, JSONModeObject, JSONModeNone:
+	default:
+		return fmt.Errorf("unsupported jsonMode %q", p.JSONMode)
+	}
+	switch p.ToolCallIDs {
+	case "", ToolCallIDPassthrough, ToolCallIDAlnum9:
+	default:
+		return fmt.Errorf("unsupported toolCallIDs format %q", p.ToolCallIDs)
+	}
+	return nil
+}
+
+func (p Profile) supports(control string) bool {
+	for _, name := range p.Unsupported {
+		if strings.EqualFold(name, control) {
+			return false
+		}
+	}
+	return true
+}
+
+func (p Profile) jsonMode() JSONMode {
+	if p.JSONMode == "" {
+		return JSONModeObject
+	}
+	return p.JSONMode
+}
+
+func (p Profile) chatPath() string {
+	if p.ChatPath == "" {
+		return "/v1/chat/completions"
+	}
+	return "/" + strings.TrimLeft(p.ChatPath, "/")
+}
@@ -0,0 +1,60 @@
+package openaicompat
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/providers/internal/sse"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// GenerateStream implements llm.StreamProvider using server-sent events from
+// the chat completions endpoint. Reasoning deltas are forwarded when the
+// profile names a reasoning field.
+func (c *Client) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	if onChunk == nil {
+		return types.Response{}, fmt.Errorf("onChunk is required")
+	}
+	payload := c.buildRequest(req)
+	payload.Stream = true
+	if !c.profile.NoStreamUsage {
+		payload.StreamOptions = &streamOptions{IncludeUsage: true}
+	}
+
+	httpReq, err := c.newHTTPRequest(ctx, payload)
+	if err != nil {
+		return types.Response{}, err
+	}
+	httpReq.Header.Set("Accept", "text/event-stream")
+
+	resp, err := c.httpClient.Do(httpReq)
+	if err != nil {
+		return types.Response{}, fmt.Errorf("%s request failed: %w", c.name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		body, _ := io.ReadAll(resp.Body)
+		return types.Response{}, fmt.Errorf("%s API error (%d): %s", c.name, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	acc := &sse.ChatAccumulator{
+		Reasoning: func(delta sse.ChatDelta) string {
+			return c.reasoning(delta.ReasoningContent, delta.Reasoning)
+		},
+		ToolCallID: c.responseToolCallID,
+		Arguments:  normalizeJSONArgs,
+	}
+	out, err := sse.ReadChat(resp.Body, c.name, acc, onChunk)
+	if err != nil {
+		return types.Response{}, err
+	}
+	out.Model = payload.Model
+	return out, nil
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
@@ -0,0 +1,55 @@
+package openaicompat
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+func TestClientGenerateStream_ReplaysTranscript(t *testing.T) {
+	transcript, err := os.ReadFile("testdata/stream_reasoning_tool_call.sse")
+	if err != nil {
+		t.Fatalf("failed to read transcript: %v", err)
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write(transcript)
+	}))
+	defer ts.Close()
+
+	profile, _ := LookupProfile("vllm")
+	client, err := New(WithProfile(profile), WithModel("qwen3"), WithBaseURL(ts.URL), WithHTTPClient(ts.Client()))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	var reasoning strings.Builder
+	resp, err := client.GenerateStream(context.Background(), types.Request{
+		Messages: []types.Message{{Role: types.RoleUser, Content: "what is 2+2"}},
+		Tools:    []types.ToolDefinition{{Name: "calc"}},
+	}, func(chunk types.StreamChunk) error {
+		reasoning.WriteString(chunk.Reasoning)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream failed: %v", err)
+	}
+	if reasoning.String() != "Need the calculator." || resp.Message.Reasoning != "Need the calculator." {
+		t.Fatalf("unexpected reasoning: streamed=%q final=%q", reasoning.String(), resp.Message.Reasoning)
+	}
+	if len(resp.Message.ToolCalls) != 1 {
+		t.Fatalf("expected one tool call, got %#v", resp.Message.ToolCalls)
+	}
+	call := resp.Message.ToolCalls[0]
+	if call.ID == "" || call.Name != "calc" || string(call.Arguments) != `{"expr":"2+2"}` {
+		t.Fatalf("unexpected tool call: %#v (args %s)", call, call.Arguments)
+	}
+	if resp.Usage == nil || resp.Usage.TotalTokens != 42 {
+		t.Fatalf("unexpected usage: %#v", resp.Usage)
+	}
+}
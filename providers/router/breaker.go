@@ -0,0 +1,133 @@
+package router
+
+import (
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a backend's circuit breaker.
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerOpen     BreakerState = "open"
+	BreakerHalfOpen BreakerState = "half_open"
+)
+
+// BreakerConfig controls when a failing backend is taken out of rotation.
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the
+	// breaker. Defaults to 3.
+	FailureThreshold int
+	// Cooldown is how long an open breaker rejects requests before letting a
+	// single trial request through. Defaults to 30s.
+	Cooldown time.Duration
+}
+
+func normalizeBreakerConfig(cfg BreakerConfig) BreakerConfig {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = 30 * time.Second
+	}
+	return cfg
+}
+
+// latencyAlpha weights the newest sample in the latency moving average.
+const latencyAlpha = 0.3
+
+// backendState tracks breaker and health statistics for one backend.
+type backendState struct {
+	mu                  sync.Mutex
+	state               BreakerState
+	consecutiveFailures int
+	openUntil           time.Time
+	trialInFlight       bool
+	successes           int64
+	failures            int64
+	latency             time.Duration
+	lastError           string
+	lastErrorAt         time.Time
+}
+
+// available reports whether a request may be sent now. A half-open breaker
+// admits one trial request at a time.
+func (s *backendState) available(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.state {
+	case BreakerOpen:
+		return !now.Before(s.openUntil)
+	case BreakerHalfOpen:
+		return !s.trialInFlight
+	default:
+		return true
+	}
+}
+
+// acquire claims the right to send a request, moving an expired open breaker
+// to half-open. It returns false when another caller took the trial slot.
+func (s *backendState) acquire(now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch s.state {
+	case BreakerOpen:
+		if now.Before(s.openUntil) {
+			return false
+		}
+		s.state = BreakerHalfOpen
+		s.trialInFlight = true
+		return true
+	case BreakerHalfOpen:
+		if s.trialInFlight {
+			return false
+		}
+		s.trialInFlight = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (s *backendState) recordSuccess(latency time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.state = BreakerClosed
+	s.consecutiveFailures = 0
+	s.trialInFlight = false
+	s.successes++
+	if s.latency == 0 {
+		s.latency = latency
+	} else {
+		s.latency = time.Duration(latencyAlpha*float64(latency) + (1-latencyAlpha)*float64(s.latency))
+	}
+}
+
+func (s *backendState) recordFailure(err error, now time.Time, cfg BreakerConfig) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures++
+	s.consecutiveFailures++
+	s.lastError = err.Error()
+	s.lastErrorAt = now
+	if s.state == BreakerHalfOpen || s.consecutiveFailures >= cfg.FailureThreshold {
+		s.state = BreakerOpen
+		s.openUntil = now.Add(cfg.Cooldown)
+	}
+	s.trialInFlight = false
+}
+
+// release gives back a trial slot without recording an outcome, e.g. when
+// the caller's context was cancelled.
+func (s *backendState) release() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.trialInFlight = false
+}
+
+func (s *backendState) averageLatency() time.Duration {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.latency
+}
@@ -0,0 +1,356 @@
+// Package router implements an llm.Provider that spreads requests over
+// several backend providers and fails over between them. Each backend has a
+// circuit breaker so a rate-limited or failing provider is skipped until it
+// recovers.
+package router
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// Strategy decides the order in which backends are tried.
+type Strategy string
+
+const (
+	// StrategyFallback tries backends in the order they were given.
+	StrategyFallback Strategy = "fallback"
+	// StrategyRoundRobin spreads requests by backend weight.
+	StrategyRoundRobin Strategy = "round_robin"
+	// StrategyCheapest prefers the backend with the lowest cost.
+	StrategyCheapest Strategy = "cheapest"
+	// StrategyLatency prefers the backend with the lowest average latency.
+	// Backends without samples are tried first so they get measured.
+	StrategyLatency Strategy = "latency"
+)
+
+// ErrNoBackendAvailable is returned when every backend's breaker is open.
+var ErrNoBackendAvailable = errors.New("no backend available")
+
+// Backend is a provider the router can send requests to.
+type Backend struct {
+	Provider llm.Provider
+	// Name identifies the backend in responses and health reports. Defaults
+	// to Provider.Name().
+	Name string
+	// Weight is the relative share of requests under StrategyRoundRobin.
+	// Defaults to 1.
+	Weight int
+	// Cost is the relative price used by StrategyCheapest, e.g. USD per
+	// million tokens.
+	Cost float64
+}
+
+// BackendHealth is a snapshot of a backend's breaker and statistics.
+type BackendHealth struct {
+	Name                string        `json:"name"`
+	State               BreakerState  `json:"state"`
+	ConsecutiveFailures int           `json:"consecutiveFailures"`
+	Successes           int64         `json:"successes"`
+	Failures            int64         `json:"failures"`
+	AverageLatency      time.Duration `json:"averageLatency"`
+	OpenUntil           *time.Time    `json:"openUntil,omitempty"`
+	LastError           string        `json:"lastError,omitempty"`
+	LastErrorAt         *time.Time    `json:"lastErrorAt,omitempty"`
+}
+
+type Router struct {
+	name     string
+	strategy Strategy
+	breaker  BreakerConfig
+	now      func() time.Time
+	backends []*backend
+
+	mu sync.Mutex // guards round-robin weights
+}
+
+type backend struct {
+	Backend
+	state         *backendState
+	currentWeight int
+}
+
+type Option func(*Router)
+
+func WithStrategy(strategy Strategy) Option {
+	return func(r *Router) {
+		if strategy != "" {
+			r.strategy = strategy
+		}
+	}
+}
+
+func WithName(name string) Option {
+	return func(r *Router) {
+		if name != "" {
+			r.name = name
+		}
+	}
+}
+
+func WithBreaker(cfg BreakerConfig) Option {
+	return func(r *Router) { r.breaker = normalizeBreakerConfig(cfg) }
+}
+
+// WithClock replaces time.Now, mainly for tests.
+func WithClock(now func() time.Time) Option {
+	return func(r *Router) {
+		if now != nil {
+			r.now = now
+		}
+	}
+}
+
+func New(backends []Backend, opts ...Option) (*Router, error) {
+	if len(backends) == 0 {
+		return nil, fmt.Errorf("router requires at least one backend")
+	}
+	r := &Router{
+		name:     "router",
+		strategy: StrategyFallback,
+		breaker:  normalizeBreakerConfig(BreakerConfig{}),
+		now:      time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch r.strategy {
+	case StrategyFallback, StrategyRoundRobin, StrategyCheapest, StrategyLatency:
+	default:
+		return nil, fmt.Errorf("unsupported router strategy %q", r.strategy)
+	}
+
+	seen := make(map[string]bool, len(backends))
+	for i, b := range backends {
+		if b.Provider == nil {
+			return nil, fmt.Errorf("router backend %d has no provider", i)
+		}
+		if b.Name == "" {
+			b.Name = b.Provider.Name()
+		}
+		if seen[b.Name] {
+			return nil, fmt.Errorf("duplicate router backend %q", b.Name)
+		}
+		seen[b.Name] = true
+		if b.Weight <= 0 {
+			b.Weight = 1
+		}
+		r.backends = append(r.backends, &backend{Backend: b, state: &backendState{state: BreakerClosed}})
+	}
+	return r, nil
+}
+
+func (r *Router) Name() string { return r.name }
+
+// Capabilities reports what every backend supports, since any of them may
+// serve a request. Streaming is always available: backends without native
+// streaming have their full response forwarded as a single chunk.
+func (r *Router) Capabilities() llm.Capabilities {
+	caps := r.backends[0].Provider.Capabilities()
+	for _, b := range r.backends[1:] {
+		c := b.Provider.Capabilities()
+		caps.Tools = caps.Tools && c.Tools
+		caps.StructuredOutput = caps.StructuredOutput && c.StructuredOutput
+		caps.Temperature = caps.Temperature && c.Temperature
+		caps.TopP = caps.TopP && c.TopP
+		caps.TopK = caps.TopK && c.TopK
+		caps.StopSequences = caps.StopSequences && c.StopSequences
+		caps.Seed = caps.Seed && c.Seed
+		caps.PresencePenalty = caps.PresencePenalty && c.PresencePenalty
+		caps.FrequencyPenalty = caps.FrequencyPenalty && c.FrequencyPenalty
+		caps.ToolChoice = caps.ToolChoice && c.ToolChoice
+		caps.ParallelToolCalls = caps.ParallelToolCalls && c.ParallelToolCalls
+	}
+	caps.Streaming = true
+	return caps
+}
+
+func (r *Router) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	return r.route(ctx, func(ctx context.Context, b *backend) (types.Response, bool, error) {
+		resp, err := b.Provider.Generate(ctx, req)
+		return resp, true, err
+	})
+}
+
+// GenerateStream implements llm.StreamProvider. A request fails over to the
+// next backend only if the failed one had not emitted any chunk yet.
+func (r *Router) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	if onChunk == nil {
+		return types.Response{}, fmt.Errorf("onChunk is required")
+	}
+	return r.route(ctx, func(ctx context.Context, b *backend) (types.Response, bool, error) {
+		sp, ok := b.Provider.(llm.StreamProvider)
+		if !ok {
+			resp, err := b.Provider.Generate(ctx, req)
+			if err != nil {
+				return types.Response{}, true, err
+			}
+			if err := llm.EmitResponse(resp, onChunk); err != nil {
+				return types.Response{}, false, &callbackError{err: err}
+			}
+			if err := onChunk(types.StreamChunk{Type: types.StreamEventDone, Done: true}); err != nil {
+				return types.Response{}, false, &callbackError{err: err}
+			}
+			return resp, true, nil
+		}
+		emitted := false
+		resp, err := sp.GenerateStream(ctx, req, func(chunk types.StreamChunk) error {
+			emitted = true
+			if err := onChunk(chunk); err != nil {
+				return &callbackError{err: err}
+			}
+			return nil
+		})
+		return resp, !emitted, err
+	})
+}
+
+// Health returns a snapshot of every backend in configuration order.
+func (r *Router) Health() []BackendHealth {
+	out := make([]BackendHealth, 0, len(r.backends))
+	now := r.now()
+	for _, b := range r.backends {
+		s := b.state
+		s.mu.Lock()
+		h := BackendHealth{
+			Name:                b.Name,
+			State:               s.state,
+			ConsecutiveFailures: s.consecutiveFailures,
+			Successes:           s.successes,
+			Failures:            s.failures,
+			AverageLatency:      s.latency,
+			LastError:           s.lastError,
+		}
+		if s.state == BreakerOpen && now.Before(s.openUntil) {
+			until := s.openUntil
+			h.OpenUntil = &until
+		}
+		if !s.lastErrorAt.IsZero() {
+			at := s.lastErrorAt
+			h.LastErrorAt = &at
+		}
+		s.mu.Unlock()
+		out = append(out, h)
+	}
+	return out
+}
+
+// route tries backends in strategy order until one succeeds. call reports
+// whether a failure may be retried on another backend.
+func (r *Router) route(ctx context.Context, call func(context.Context, *backend) (types.Response, bool, error)) (types.Response, error) {
+	var errs []error
+	attempted := 0
+	for _, b := range r.order() {
+		if err := ctx.Err(); err != nil {
+			return types.Response{}, err
+		}
+		if !b.state.acquire(r.now()) {
+			continue
+		}
+		attempted++
+		started := r.now()
+		resp, failover, err := call(ctx, b)
+		if err == nil {
+			b.state.recordSuccess(r.now().Sub(started))
+			if resp.Provider == "" {
+				resp.Provider = b.Name
+			}
+			return resp, nil
+		}
+		var cbErr *callbackError
+		if errors.As(err, &cbErr) {
+			b.state.release()
+			return types.Response{}, cbErr.err
+		}
+		if ctx.Err() != nil {
+			b.state.release()
+			return types.Response{}, err
+		}
+		b.state.recordFailure(err, r.now(), r.breaker)
+		errs = append(errs, fmt.Errorf("%s: %w", b.Name, err))
+		if !failover {
+			return types.Response{}, errors.Join(errs...)
+		}
+	}
+	if attempted == 0 {
+		return types.Response{}, fmt.Errorf("%s: %w", r.name, ErrNoBackendAvailable)
+	}
+	return types.Response{}, fmt.Errorf("%s: all backends failed: %w", r.name, errors.Join(errs...))
+}
+
+// order returns backends in the order the strategy wants them tried.
+// Backends whose breaker is open sort last so they are only tried once their
+// cooldown has passed.
+func (r *Router) order() []*backend {
+	out := make([]*backend, len(r.backends))
+	copy(out, r.backends)
+
+	switch r.strategy {
+	case StrategyRoundRobin:
+		first := r.nextWeighted()
+		sort.SliceStable(out, func(i, j int) bool { return out[i] == first && out[j] != first })
+	case StrategyCheapest:
+		sort.SliceStable(out, func(i, j int) bool { return out[i].Cost < out[j].Cost })
+	case StrategyLatency:
+		sort.SliceStable(out, func(i, j int) bool {
+			return out[i].state.averageLatency() < out[j].state.averageLatency()
+		})
+	}
+
+	now := r.now()
+	sort.SliceStable(out, func(i, j int) bool {
+		return out[i].state.available(now) && !out[j].state.available(now)
+	})
+	return out
+}
+
+// nextWeighted picks the next backend with smooth weighted round-robin, so
+// heavier backends are chosen more often without being picked in bursts.
+func (r *Router) nextWeighted() *backend {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var (
+		best  *backend
+		total int
+	)
+	for _, b := range r.backends {
+		b.currentWeight += b.Weight
+		total += b.Weight
+		if best == nil || b.currentWeight > best.currentWeight {
+			best = b
+		}
+	}
+	best.currentWeight -= total
+	return best
+}
+
+// callbackError marks a failure of the caller's chunk callback, which says
+// nothing about the backend's health.
+type callbackError struct{ err error }
+
+func (e *callbackError) Error() string { return e.err.Error() }
+func (e *callbackError) Unwrap() error { return e.err }
+
+// ParseStrategy maps a configuration string to a Strategy.
+func ParseStrategy(s string) (Strategy, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "", "fallback", "ordered":
+		return StrategyFallback, nil
+	case "round_robin", "round-robin", "roundrobin", "weighted":
+		return StrategyRoundRobin, nil
+	case "cheapest", "cheapest_first", "cost":
+		return StrategyCheapest, nil
+	case "latency", "fastest":
+		return StrategyLatency, nil
+	default:
+		return "", fmt.Errorf("unsupported router strategy %q (use fallback, round_robin, cheapest, or latency)", s)
+	}
+}
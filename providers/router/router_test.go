@@ -0,0 +1,221 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/llm"
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+type fakeProvider struct {
+	name  string
+	err   error
+	delay time.Duration
+	clock *fakeClock
+	calls int
+}
+
+func (p *fakeProvider) Name() string { return p.name }
+
+func (p *fakeProvider) Capabilities() llm.Capabilities {
+	return llm.Capabilities{Tools: true, Temperature: true, TopK: p.name == "a"}
+}
+
+func (p *fakeProvider) Generate(ctx context.Context, req types.Request) (types.Response, error) {
+	_ = ctx
+	_ = req
+	p.calls++
+	if p.clock != nil {
+		p.clock.advance(p.delay)
+	}
+	if p.err != nil {
+		return types.Response{}, p.err
+	}
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "from " + p.name}}, nil
+}
+
+type streamingFake struct {
+	fakeProvider
+	failAfterChunk bool
+}
+
+func (p *streamingFake) GenerateStream(ctx context.Context, req types.Request, onChunk func(types.StreamChunk) error) (types.Response, error) {
+	p.calls++
+	if err := onChunk(types.StreamChunk{Type: types.StreamEventText, Text: "partial"}); err != nil {
+		return types.Response{}, err
+	}
+	if p.failAfterChunk {
+		return types.Response{}, errors.New("connection reset")
+	}
+	return types.Response{Message: types.Message{Role: types.RoleAssistant, Content: "partial"}}, nil
+}
+
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+func TestRouter_FallbackReportsServingBackend(t *testing.T) {
+	a := &fakeProvider{name: "a", err: errors.New("429 rate limited")}
+	b := &fakeProvider{name: "b"}
+	r, err := New([]Backend{{Provider: a}, {Provider: b}})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	resp, err := r.Generate(context.Background(), types.Request{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Provider != "b" || resp.Message.Content != "from b" {
+		t.Fatalf("expected response served by b, got %+v", resp)
+	}
+	if caps := r.Capabilities(); !caps.Tools || caps.TopK || !caps.Streaming {
+		t.Fatalf("expected intersected capabilities with streaming, got %+v", caps)
+	}
+}
+
+func TestRouter_BreakerOpensAndRecovers(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	a := &fakeProvider{name: "a", err: errors.New("unavailable")}
+	b := &fakeProvider{name: "b"}
+	r, err := New([]Backend{{Provider: a}, {Provider: b}},
+		WithBreaker(BreakerConfig{FailureThreshold: 2, Cooldown: time.Minute}),
+		WithClock(clock.Now),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	for i := 0; i < 4; i++ {
+		if _, err := r.Generate(context.Background(), types.Request{}); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	if a.calls != 2 {
+		t.Fatalf("expected breaker to stop calls to a after 2 failures, got %d calls", a.calls)
+	}
+	if h := r.Health()[0]; h.State != BreakerOpen || h.OpenUntil == nil || h.LastError != "unavailable" {
+		t.Fatalf("unexpected health for a: %+v", h)
+	}
+
+	clock.advance(time.Minute)
+	a.err = nil
+	resp, err := r.Generate(context.Background(), types.Request{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Provider != "a" || r.Health()[0].State != BreakerClosed {
+		t.Fatalf("expected half-open trial to close breaker, got provider %q health %+v", resp.Provider, r.Health()[0])
+	}
+}
+
+func TestRouter_NoBackendAvailable(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	a := &fakeProvider{name: "a", err: errors.New("down")}
+	r, _ := New([]Backend{{Provider: a}},
+		WithBreaker(BreakerConfig{FailureThreshold: 1, Cooldown: time.Minute}),
+		WithClock(clock.Now),
+	)
+	if _, err := r.Generate(context.Background(), types.Request{}); err == nil {
+		t.Fatalf("expected first call to fail")
+	}
+	if _, err := r.Generate(context.Background(), types.Request{}); !errors.Is(err, ErrNoBackendAvailable) {
+		t.Fatalf("expected ErrNoBackendAvailable, got %v", err)
+	}
+}
+
+func TestRouter_WeightedRoundRobin(t *testing.T) {
+	a := &fakeProvider{name: "a"}
+	b := &fakeProvider{name: "b"}
+	r, _ := New([]Backend{{Provider: a, Weight: 3}, {Provider: b, Weight: 1}}, WithStrategy(StrategyRoundRobin))
+	for i := 0; i < 8; i++ {
+		if _, err := r.Generate(context.Background(), types.Request{}); err != nil {
+			t.Fatalf("Generate() error = %v", err)
+		}
+	}
+	if a.calls != 6 || b.calls != 2 {
+		t.Fatalf("expected 6/2 split, got a=%d b=%d", a.calls, b.calls)
+	}
+}
+
+func TestRouter_CheapestFirst(t *testing.T) {
+	a := &fakeProvider{name: "a"}
+	b := &fakeProvider{name: "b"}
+	r, _ := New([]Backend{{Provider: a, Cost: 5}, {Provider: b, Cost: 0.5}}, WithStrategy(StrategyCheapest))
+	resp, err := r.Generate(context.Background(), types.Request{})
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if resp.Provider != "b" {
+		t.Fatalf("expected cheapest backend b, got %q", resp.Provider)
+	}
+}
+
+func TestRouter_LatencyAware(t *testing.T) {
+	clock := &fakeClock{now: time.Unix(0, 0)}
+	slow := &fakeProvider{name: "slow", delay: 2 * time.Second, clock: clock}
+	fast := &fakeProvider{name: "fast", delay: 100 * time.Millisecond, clock: clock}
+	r, _ := New([]Backend{{Provider: slow}, {Provider: fast}}, WithStrategy(StrategyLatency), WithClock(clock.Now))
+
+	// Unmeasured backends are probed first.
+	first, _ := r.Generate(context.Background(), types.Request{})
+	second, _ := r.Generate(context.Background(), types.Request{})
+	if first.Provider != "slow" || second.Provider != "fast" {
+		t.Fatalf("expected probes of slow then fast, got %q then %q", first.Provider, second.Provider)
+	}
+	third, _ := r.Generate(context.Background(), types.Request{})
+	if third.Provider != "fast" {
+		t.Fatalf("expected fast backend once measured, got %q", third.Provider)
+	}
+}
+
+func TestRouter_StreamDoesNotFailOverAfterOutput(t *testing.T) {
+	a := &streamingFake{fakeProvider: fakeProvider{name: "a"}, failAfterChunk: true}
+	b := &fakeProvider{name: "b"}
+	r, _ := New([]Backend{{Provider: a}, {Provider: b}})
+
+	_, err := r.GenerateStream(context.Background(), types.Request{}, func(types.StreamChunk) error { return nil })
+	if err == nil {
+		t.Fatalf("expected stream error after partial output")
+	}
+	if b.calls != 0 {
+		t.Fatalf("expected no failover once output was emitted, b got %d calls", b.calls)
+	}
+}
+
+func TestRouter_StreamFallsBackToGenerate(t *testing.T) {
+	a := &fakeProvider{name: "a", err: errors.New("down")}
+	b := &fakeProvider{name: "b"}
+	r, _ := New([]Backend{{Provider: a}, {Provider: b}})
+
+	var text string
+	done := false
+	resp, err := r.GenerateStream(context.Background(), types.Request{}, func(chunk types.StreamChunk) error {
+		text += chunk.Text
+		done = done || chunk.Done
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("GenerateStream() error = %v", err)
+	}
+	if text != "from b" || !done || resp.Provider != "b" {
+		t.Fatalf("unexpected stream result: text=%q done=%v provider=%q", text, done, resp.Provider)
+	}
+}
@@ -34,6 +34,7 @@ type Coordinator interface {
 	SubmitRun(ctx context.Context, req SubmitRequest) (SubmitResult, error)
 	CancelRun(ctx context.Context, runID string) error
 	RequeueRun(ctx context.Context, runID string) error
+	ResumeRun(ctx context.Context, runID string) error
 	QueueStats(ctx context.Context) (queue.Stats, error)
 	ListWorkers(ctx context.Context, limit int) ([]WorkerHeartbeat, error)
 	ListRunAttempts(ctx context.Context, runID string, limit int) ([]AttemptRecord, error)
@@ -331,6 +332,81 @@ func (c *coordinator) RequeueRun(ctx context.Context, runID string) error {
 	return nil
 }
 
+// ResumeRun enqueues a task that continues a run suspended for tool
+// approval once its decisions are recorded. The run keeps its status until
+// a worker picks the task up. Resume tasks are not retried, since the
+// approved tools may already have run, and a run is enqueued once however
+// often ResumeRun is called for it.
+func (c *coordinator) ResumeRun(ctx context.Context, runID string) error {
+	runID = strings.TrimSpace(runID)
+	if runID == "" {
+		return fmt.Errorf("runID is required")
+	}
+	unlock, err := state.LockRun(ctx, c.store, "resume:"+runID, time.Minute)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	run, err := c.store.LoadRun(ctx, runID)
+	if err != nil {
+		return err
+	}
+	if queued, _ := run.Metadata[resumeQueuedKey].(bool); queued {
+		return nil
+	}
+	if run.Status != runStatusAwaitingApproval {
+		return fmt.Errorf("run %s is %s, not awaiting approval", runID, run.Status)
+	}
+	attempts, _ := c.attempts.ListAttempts(ctx, runID, 1)
+	nextAttempt := 1
+	if len(attempts) > 0 {
+		nextAttempt = attempts[0].Attempt + 1
+	}
+	task := queue.Task{
+		RunID:        run.RunID,
+		SessionID:    run.SessionID,
+		Input:        run.Input,
+		Mode:         metaString(run.Metadata, "mode"),
+		Workflow:     metaString(run.Metadata, "workflow"),
+		WorkflowFile: metaString(run.Metadata, "workflow_file"),
+		Attempt:      nextAttempt,
+		MaxAttempts:  nextAttempt,
+		Metadata:     map[string]any{resumeMetadataKey: true},
+	}
+	if rawTools, ok := run.Metadata["tools"].([]any); ok {
+		for _, t := range rawTools {
+			if s, ok := t.(string); ok {
+				task.Tools = append(task.Tools, s)
+			}
+		}
+	}
+	msgID, err := c.queue.Enqueue(ctx, task)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue resume: %w", err)
+	}
+	now := time.Now().UTC()
+	// The worker clears the mark once the resume has run, so the run can be
+	// resumed again if it is suspended again.
+	if run.Metadata == nil {
+		run.Metadata = map[string]any{}
+	}
+	run.Metadata[resumeQueuedKey] = true
+	run.UpdatedAt = &now
+	if err := c.store.SaveRun(ctx, run); err != nil {
+		return fmt.Errorf("failed to mark run resumed: %w", err)
+	}
+	_ = c.attempts.SaveQueueEvent(ctx, QueueEvent{RunID: runID, Event: "queue.resumed", At: now, Payload: map[string]any{"messageId": msgID, "attempt": nextAttempt}})
+	c.emit(ctx, observe.Event{
+		RunID:      runID,
+		SessionID:  run.SessionID,
+		Kind:       observe.KindCustom,
+		Status:     observe.StatusStarted,
+		Name:       "queue.resumed",
+		Attributes: map[string]any{"messageId": msgID, "attempt": nextAttempt},
+	})
+	return nil
+}
+
 func (c *coordinator) QueueStats(ctx context.Context) (queue.Stats, error) {
 	return c.queue.Stats(ctx)
 }
@@ -37,10 +37,31 @@ type SubmitResult struct {
 type ProcessResult struct {
 	Output   string
 	Provider string
+	// Status replaces "completed" for runs that stopped without finishing,
+	// such as runs awaiting tool approval. The task is acked, not retried.
+	Status string
 }
 
 type ProcessFunc func(ctx context.Context, task queue.Task) (ProcessResult, error)
 
+const (
+	// resumeMetadataKey marks tasks that continue a suspended run.
+	resumeMetadataKey = "resume"
+	// resumeQueuedKey marks suspended runs whose resume task is enqueued.
+	resumeQueuedKey = "resume_queued"
+	// runStatusAwaitingApproval is the status of runs suspended for tool
+	// approval.
+	runStatusAwaitingApproval = "awaiting_approval"
+)
+
+// IsResume reports whether task continues a run suspended for approval
+// rather than starting it. Processors should resume the run instead of
+// running task.Input again.
+func IsResume(task queue.Task) bool {
+	resume, _ := task.Metadata[resumeMetadataKey].(bool)
+	return resume
+}
+
 type AttemptRecord struct {
 	RunID     string         `json:"runId"`
 	Attempt   int            `json:"attempt"`
@@ -232,9 +232,13 @@ func (w *worker) handleDelivery(ctx context.Context, delivery queue.Delivery) er
 	})
 	_ = w.attempts.SaveQueueEvent(ctx, QueueEvent{RunID: task.RunID, Event: "queue.claimed", At: now, Payload: map[string]any{"workerId": w.cfg.WorkerID, "attempt": task.Attempt}})
 
-	if err := w.updateRunStatus(ctx, task, "running", "", nil); err != nil {
-		_ = w.queue.Ack(ctx, w.cfg.WorkerID, delivery.ID)
-		return err
+	// A resumed run is claimed by the processor, which checks that it is
+	// still suspended.
+	if !IsResume(task) {
+		if err := w.updateRunStatus(ctx, task, "running", "", nil); err != nil {
+			_ = w.queue.Ack(ctx, w.cfg.WorkerID, delivery.ID)
+			return err
+		}
 	}
 	w.emit(ctx, observe.Event{
 		RunID:      task.RunID,
@@ -246,6 +250,14 @@ func (w *worker) handleDelivery(ctx context.Context, delivery queue.Delivery) er
 	})
 
 	result, runErr := w.processor(ctx, task)
+	if runErr == nil && result.Status != "" && result.Status != "completed" {
+		now := time.Now().UTC()
+		_ = w.attempts.FinishAttempt(ctx, task.RunID, task.Attempt, result.Status, "")
+		_ = w.updateRunStatus(ctx, task, result.Status, result.Output, nil)
+		_ = w.attempts.SaveQueueEvent(ctx, QueueEvent{RunID: task.RunID, Event: "run." + result.Status, At: now, Payload: map[string]any{"workerId": w.cfg.WorkerID, "attempt": task.Attempt}})
+		w.emit(ctx, observe.Event{RunID: task.RunID, SessionID: task.SessionID, Kind: observe.KindRun, Status: observe.StatusCompleted, Name: "run." + result.Status})
+		return w.queue.Ack(ctx, w.cfg.WorkerID, delivery.ID)
+	}
 	if runErr == nil {
 		now := time.Now().UTC()
 		_ = w.attempts.FinishAttempt(ctx, task.RunID, task.Attempt, "completed", "")
@@ -318,6 +330,9 @@ func (w *worker) updateRunStatus(ctx context.Context, task queue.Task, status st
 	run.Metadata["workflow_file"] = task.WorkflowFile
 	run.Metadata["tools"] = task.Tools
 	run.Metadata["max_attempts"] = task.MaxAttempts
+	if IsResume(task) {
+		delete(run.Metadata, resumeQueuedKey)
+	}
 	return w.store.SaveRun(ctx, run)
 }
 
@@ -340,6 +355,9 @@ func (w *worker) updateRunStatusFailed(ctx context.Context, task queue.Task, err
 	run.Metadata["attempt"] = task.Attempt
 	run.Metadata["worker_id"] = w.cfg.WorkerID
 	run.Metadata["retry_count"] = task.Attempt - 1
+	if IsResume(task) {
+		delete(run.Metadata, resumeQueuedKey)
+	}
 	return w.store.SaveRun(ctx, run)
 }
 
@@ -20,6 +20,7 @@ type singleDeliveryQueue struct {
 func (s *singleDeliveryQueue) Enqueue(ctx context.Context, task queue.Task) (string, error) {
 	_ = ctx
 	s.delivery = &queue.Delivery{ID: "1-0", Stream: "runs", Task: task, Received: time.Now().UTC()}
+	s.acked = false
 	return "1-0", nil
 }
 func (s *singleDeliveryQueue) Claim(ctx context.Context, consumer string, block time.Duration, count int) ([]queue.Delivery, error) {
@@ -180,3 +181,94 @@ func TestWorkerStopCancelsStartLoop(t *testing.T) {
 		t.Fatalf("worker start loop did not exit after Stop")
 	}
 }
+
+func TestWorkerLeavesSuspendedRunForResume(t *testing.T) {
+	store, err := statesqlite.New(t.TempDir() + "/state.db")
+	if err != nil {
+		t.Fatalf("state store: %v", err)
+	}
+	defer func() { _ = store.Close() }()
+	attempts, err := NewSQLiteAttemptStore(t.TempDir() + "/attempts.db")
+	if err != nil {
+		t.Fatalf("attempt store: %v", err)
+	}
+	defer func() { _ = attempts.Close() }()
+
+	now := time.Now().UTC()
+	if err := store.SaveRun(context.Background(), state.RunRecord{
+		RunID:     "r1",
+		SessionID: "s1",
+		Provider:  "distributed",
+		Status:    "queued",
+		Input:     "deploy",
+		Metadata:  map[string]any{},
+		CreatedAt: &now,
+		UpdatedAt: &now,
+	}); err != nil {
+		t.Fatalf("seed run: %v", err)
+	}
+
+	var resumed []bool
+	q := &singleDeliveryQueue{delivery: &queue.Delivery{ID: "1-0", Stream: "runs", Task: queue.Task{RunID: "r1", SessionID: "s1", Input: "deploy", Tools: []string{"kubectl"}, Attempt: 1, MaxAttempts: 2}, Received: time.Now().UTC()}}
+	policy := DefaultRuntimePolicy()
+	policy.PollInterval = 10 * time.Millisecond
+	policy.ClaimBlock = 10 * time.Millisecond
+	w, err := NewWorker(WorkerConfig{WorkerID: "w1"}, store, attempts, q, nil, policy, func(ctx context.Context, task queue.Task) (ProcessResult, error) {
+		resumed = append(resumed, IsResume(task))
+		if IsResume(task) {
+			run, err := store.LoadRun(ctx, task.RunID)
+			if err != nil || run.Status != "awaiting_approval" {
+				return ProcessResult{}, fmt.Errorf("expected a suspended run, got %q (%v)", run.Status, err)
+			}
+			return ProcessResult{Output: "deployed"}, nil
+		}
+		return ProcessResult{Status: "awaiting_approval"}, nil
+	})
+	if err != nil {
+		t.Fatalf("new worker: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_ = w.Start(ctx)
+	run, err := store.LoadRun(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("load run: %v", err)
+	}
+	if run.Status != "awaiting_approval" || !q.acked {
+		t.Fatalf("expected an acked suspended run, got %s (acked=%v)", run.Status, q.acked)
+	}
+
+	c, err := NewCoordinator(store, attempts, q, nil, DistributedConfig{})
+	if err != nil {
+		t.Fatalf("new coordinator: %v", err)
+	}
+	if err := c.ResumeRun(context.Background(), "r1"); err != nil {
+		t.Fatalf("resume run: %v", err)
+	}
+	q.delivery.ID = "first"
+	if err := c.ResumeRun(context.Background(), "r1"); err != nil || q.delivery.ID != "first" {
+		t.Fatalf("expected a second resume to enqueue nothing, got %v", err)
+	}
+	q.delivery.ID = "1-0"
+	if task := q.delivery.Task; !IsResume(task) || len(task.Tools) != 1 || task.Attempt != 2 || task.MaxAttempts != 2 {
+		t.Fatalf("unexpected resume task %+v", task)
+	}
+
+	ctx, cancel = context.WithTimeout(context.Background(), 500*time.Millisecond)
+	defer cancel()
+	_ = w.Start(ctx)
+	run, err = store.LoadRun(context.Background(), "r1")
+	if err != nil {
+		t.Fatalf("load run: %v", err)
+	}
+	if run.Status != "completed" || run.Output != "deployed" {
+		t.Fatalf("expected the resumed run to complete, got %s %q", run.Status, run.Output)
+	}
+	if len(resumed) != 2 || resumed[0] || !resumed[1] {
+		t.Fatalf("unexpected processor calls %v", resumed)
+	}
+	if _, queued := run.Metadata[resumeQueuedKey]; queued {
+		t.Fatalf("expected the resume mark to be cleared, got %v", run.Metadata)
+	}
+}
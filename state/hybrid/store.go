@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"time"
 
 	"github.com/PipeOpsHQ/agent-sdk-go/state"
 )
@@ -98,10 +99,41 @@ func (h *HybridStore) LoadLatestCheckpoint(ctx context.Context, runID string) (s
 	return checkpoint, nil
 }
 
+// LoadCheckpoint loads from the durable store, which keeps every
+// checkpoint.
+func (h *HybridStore) LoadCheckpoint(ctx context.Context, runID string, seq int) (state.CheckpointRecord, error) {
+	return state.LoadCheckpoint(ctx, h.durable, runID, seq)
+}
+
 func (h *HybridStore) ListCheckpoints(ctx context.Context, runID string, limit int) ([]state.CheckpointRecord, error) {
 	return h.durable.ListCheckpoints(ctx, runID, limit)
 }
 
+// AcquireRunLock delegates to the cache store when it supports locking.
+// Without one there is nothing shared to lock, so the lock is granted.
+func (h *HybridStore) AcquireRunLock(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error) {
+	if locker, ok := h.cache.(state.RunLocker); ok {
+		return locker.AcquireRunLock(ctx, runID, owner, ttl)
+	}
+	return true, nil
+}
+
+func (h *HybridStore) ReleaseRunLock(ctx context.Context, runID, owner string) error {
+	if locker, ok := h.cache.(state.RunLocker); ok {
+		return locker.ReleaseRunLock(ctx, runID, owner)
+	}
+	return nil
+}
+
+// RenewRunLock delegates to the cache store when it supports renewing
+// locks; otherwise the lock is kept as granted.
+func (h *HybridStore) RenewRunLock(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error) {
+	if renewer, ok := h.cache.(state.RunLockRenewer); ok {
+		return renewer.RenewRunLock(ctx, runID, owner, ttl)
+	}
+	return true, nil
+}
+
 func (h *HybridStore) Close() error {
 	var firstErr error
 	if h.cache != nil {
@@ -0,0 +1,148 @@
+package state
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// runLockPoll is how often LockRun retries a lock held by another process.
+const runLockPoll = 100 * time.Millisecond
+
+var localLocks = struct {
+	sync.Mutex
+	held map[string]chan struct{}
+}{held: map[string]chan struct{}{}}
+
+// LockRun holds the lock named key until the returned release function is
+// called. Callers in the same process wait for each other; when store is a
+// RunLocker the lock is also taken through it, so callers in other
+// processes wait too. While held, the lock is renewed every ttl/3 on
+// stores that are a RunLockRenewer, so ttl only bounds how long the lock
+// outlives a holder that never releases it. LockRun gives up when ctx is
+// done.
+func LockRun(ctx context.Context, store Store, key string, ttl time.Duration) (func(), error) {
+	unlock, err := lockLocal(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	locker, ok := store.(RunLocker)
+	if !ok {
+		return unlock, nil
+	}
+
+	owner := uuid.NewString()
+	for {
+		acquired, err := locker.AcquireRunLock(ctx, key, owner, ttl)
+		if err != nil {
+			unlock()
+			return nil, fmt.Errorf("failed to lock %s: %w", key, err)
+		}
+		if acquired {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			unlock()
+			return nil, fmt.Errorf("failed to lock %s: %w", key, ctx.Err())
+		case <-time.After(runLockPoll):
+		}
+	}
+	return holdRunLock(ctx, locker, key, owner, ttl, unlock), nil
+}
+
+// TryLockRun is LockRun without the wait: it reports false when the lock
+// is held.
+func TryLockRun(ctx context.Context, store Store, key string, ttl time.Duration) (func(), bool, error) {
+	unlock, _, ok := tryLockLocal(key)
+	if !ok {
+		return nil, false, nil
+	}
+	locker, isLocker := store.(RunLocker)
+	if !isLocker {
+		return unlock, true, nil
+	}
+	owner := uuid.NewString()
+	acquired, err := locker.AcquireRunLock(ctx, key, owner, ttl)
+	if err != nil || !acquired {
+		unlock()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to lock %s: %w", key, err)
+		}
+		return nil, false, nil
+	}
+	return holdRunLock(ctx, locker, key, owner, ttl, unlock), true, nil
+}
+
+// holdRunLock renews the lock owner acquired through locker until the
+// returned function releases it.
+func holdRunLock(ctx context.Context, locker RunLocker, key, owner string, ttl time.Duration, unlock func()) func() {
+	// Renew and release even if the caller's context was cancelled.
+	ctx = context.WithoutCancel(ctx)
+	stop := make(chan struct{})
+	renewed := make(chan struct{})
+	renewer, ok := locker.(RunLockRenewer)
+	if !ok || ttl <= 0 {
+		close(renewed)
+	} else {
+		go func() {
+			defer close(renewed)
+			ticker := time.NewTicker(max(ttl/3, time.Millisecond))
+			defer ticker.Stop()
+			for {
+				select {
+				case <-stop:
+					return
+				case <-ticker.C:
+					// A failed renewal is retried on the next tick; the lock
+					// holds until its expiry either way.
+					_, _ = renewer.RenewRunLock(ctx, key, owner, ttl)
+				}
+			}
+		}()
+	}
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-renewed
+			_ = locker.ReleaseRunLock(ctx, key, owner)
+			unlock()
+		})
+	}
+}
+
+func lockLocal(ctx context.Context, key string) (func(), error) {
+	for {
+		unlock, held, ok := tryLockLocal(key)
+		if ok {
+			return unlock, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to lock %s: %w", key, ctx.Err())
+		case <-held:
+		}
+	}
+}
+
+// tryLockLocal takes key within the process, or returns a channel closed
+// when its holder releases it.
+func tryLockLocal(key string) (func(), <-chan struct{}, bool) {
+	localLocks.Lock()
+	defer localLocks.Unlock()
+	if held, busy := localLocks.held[key]; busy {
+		return nil, held, false
+	}
+	released := make(chan struct{})
+	localLocks.held[key] = released
+	return func() {
+		localLocks.Lock()
+		delete(localLocks.held, key)
+		localLocks.Unlock()
+		close(released)
+	}, nil, true
+}
@@ -0,0 +1,106 @@
+package state
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+// lockingStore is a Store whose run locks expire unless renewed.
+type lockingStore struct {
+	Store
+
+	mu      sync.Mutex
+	owners  map[string]string
+	expires map[string]time.Time
+	renewed int
+}
+
+func newLockingStore() *lockingStore {
+	return &lockingStore{owners: map[string]string{}, expires: map[string]time.Time{}}
+}
+
+func (s *lockingStore) AcquireRunLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, held := s.owners[key]; held && time.Now().Before(s.expires[key]) {
+		return false, nil
+	}
+	s.owners[key] = owner
+	s.expires[key] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (s *lockingStore) RenewRunLock(ctx context.Context, key, owner string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owners[key] != owner || !time.Now().Before(s.expires[key]) {
+		return false, nil
+	}
+	s.expires[key] = time.Now().Add(ttl)
+	s.renewed++
+	return true, nil
+}
+
+func (s *lockingStore) ReleaseRunLock(ctx context.Context, key, owner string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.owners[key] == owner {
+		delete(s.owners, key)
+		delete(s.expires, key)
+	}
+	return nil
+}
+
+func (s *lockingStore) renewals() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.renewed
+}
+
+func TestLockRun_RenewsWhileHeld(t *testing.T) {
+	store := newLockingStore()
+	ttl := 60 * time.Millisecond
+	release, err := LockRun(context.Background(), store, "session:s1", ttl)
+	if err != nil {
+		t.Fatalf("lock: %v", err)
+	}
+
+	// Held for several ttls, the lock must not expire under its holder.
+	time.Sleep(4 * ttl)
+	if acquired, _ := store.AcquireRunLock(context.Background(), "session:s1", "other", ttl); acquired {
+		t.Fatalf("expected the held lock to be renewed, but it expired")
+	}
+	release()
+
+	renewals := store.renewals()
+	if renewals == 0 {
+		t.Fatalf("expected the lock to be renewed")
+	}
+	time.Sleep(2 * ttl)
+	if store.renewals() != renewals {
+		t.Fatalf("expected renewals to stop on release")
+	}
+	if acquired, _ := store.AcquireRunLock(context.Background(), "session:s1", "other", ttl); !acquired {
+		t.Fatalf("expected the released lock to be free")
+	}
+}
+
+func TestTryLockRun_RenewsWhileHeld(t *testing.T) {
+	store := newLockingStore()
+	ttl := 60 * time.Millisecond
+	release, ok, err := TryLockRun(context.Background(), store, "graph:r1", ttl)
+	if err != nil || !ok {
+		t.Fatalf("expected the lock, got %v, %v", ok, err)
+	}
+	defer release()
+
+	time.Sleep(4 * ttl)
+	if _, ok, _ := TryLockRun(context.Background(), store, "graph:r1", ttl); ok {
+		t.Fatalf("expected the held lock to stay taken")
+	}
+	if acquired, _ := store.AcquireRunLock(context.Background(), "graph:r1", "other", ttl); acquired {
+		t.Fatalf("expected the held lock to be renewed, but it expired")
+	}
+}
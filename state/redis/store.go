@@ -315,6 +315,26 @@ func (s *Store) LoadLatestCheckpoint(ctx context.Context, runID string) (state.C
 	return checkpoint, nil
 }
 
+func (s *Store) LoadCheckpoint(ctx context.Context, runID string, seq int) (state.CheckpointRecord, error) {
+	if runID == "" {
+		return state.CheckpointRecord{}, fmt.Errorf("run_id is required")
+	}
+
+	raw, err := s.client.Get(ctx, s.checkpointSeqKey(runID, seq)).Result()
+	if err != nil {
+		if err == goredis.Nil {
+			return state.CheckpointRecord{}, state.ErrNotFound
+		}
+		return state.CheckpointRecord{}, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+
+	var checkpoint state.CheckpointRecord
+	if err := json.Unmarshal([]byte(raw), &checkpoint); err != nil {
+		return state.CheckpointRecord{}, fmt.Errorf("failed to decode checkpoint: %w", err)
+	}
+	return checkpoint, nil
+}
+
 func (s *Store) ListCheckpoints(ctx context.Context, runID string, limit int) ([]state.CheckpointRecord, error) {
 	if runID == "" {
 		return nil, fmt.Errorf("run_id is required")
@@ -399,6 +419,27 @@ return 0
 	return nil
 }
 
+func (s *Store) RenewRunLock(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error) {
+	if runID == "" || owner == "" {
+		return false, fmt.Errorf("run_id and owner are required")
+	}
+	if ttl <= 0 {
+		ttl = 15 * time.Second
+	}
+
+	script := goredis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+  return redis.call("PEXPIRE", KEYS[1], ARGV[2])
+end
+return 0
+`)
+	renewed, err := script.Run(ctx, s.client, []string{s.lockKey(runID)}, owner, ttl.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to renew run lock: %w", err)
+	}
+	return renewed == 1, nil
+}
+
 func (s *Store) Close() error {
 	if s.client == nil {
 		return nil
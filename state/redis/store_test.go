@@ -129,6 +129,14 @@ func TestRedisStore_SaveCheckpointAndLatest(t *testing.T) {
 	if list[0].Seq != 2 {
 		t.Fatalf("expected descending sequence order, got %#v", list)
 	}
+
+	first, err := s.LoadCheckpoint(ctx, "run-ckpt", 1)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	if first.Seq != 1 || first.State["value"] != "one" {
+		t.Fatalf("unexpected checkpoint: %#v", first)
+	}
 }
 
 func TestRedisStore_PrunesStaleSessionIndexEntries(t *testing.T) {
@@ -210,6 +218,12 @@ func TestRedisStore_LockHelpers(t *testing.T) {
 	if !got {
 		t.Fatalf("expected lock acquisition after release")
 	}
+	if renewed, err := s.RenewRunLock(ctx, runID, "owner-4", 5*time.Second); err != nil || !renewed {
+		t.Fatalf("expected the owner to renew the lock, got %v, %v", renewed, err)
+	}
+	if renewed, err := s.RenewRunLock(ctx, runID, "owner-1", 5*time.Second); err != nil || renewed {
+		t.Fatalf("expected another owner not to renew the lock, got %v, %v", renewed, err)
+	}
 	if err := s.ReleaseRunLock(ctx, runID, "owner-4"); err != nil {
 		t.Fatalf("final release failed: %v", err)
 	}
@@ -401,6 +401,45 @@ LIMIT 1;
 	return record, nil
 }
 
+func (s *Store) LoadCheckpoint(ctx context.Context, runID string, seq int) (state.CheckpointRecord, error) {
+	if runID == "" {
+		return state.CheckpointRecord{}, fmt.Errorf("run_id is required")
+	}
+
+	const q = `
+SELECT run_id, seq, node_id, state, created_at
+FROM checkpoints
+WHERE run_id = ? AND seq = ?;
+`
+
+	var (
+		record       state.CheckpointRecord
+		stateRaw     string
+		createdAtRaw string
+	)
+	err := s.db.QueryRowContext(ctx, q, runID, seq).Scan(
+		&record.RunID,
+		&record.Seq,
+		&record.NodeID,
+		&stateRaw,
+		&createdAtRaw,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return state.CheckpointRecord{}, state.ErrNotFound
+		}
+		return state.CheckpointRecord{}, fmt.Errorf("failed to load checkpoint: %w", err)
+	}
+	record.CreatedAt, err = parseRequiredTime(createdAtRaw)
+	if err != nil {
+		return state.CheckpointRecord{}, fmt.Errorf("failed to parse checkpoint created_at: %w", err)
+	}
+	if err := json.Unmarshal([]byte(stateRaw), &record.State); err != nil {
+		return state.CheckpointRecord{}, fmt.Errorf("failed to decode checkpoint state: %w", err)
+	}
+	return record, nil
+}
+
 func (s *Store) ListCheckpoints(ctx context.Context, runID string, limit int) ([]state.CheckpointRecord, error) {
 	if runID == "" {
 		return nil, fmt.Errorf("run_id is required")
@@ -171,6 +171,17 @@ func TestSQLiteStore_SaveCheckpointAndLatest(t *testing.T) {
 	if all[0].Seq != 2 || all[1].Seq != 1 {
 		t.Fatalf("unexpected checkpoint order: %#v", all)
 	}
+
+	first, err := s.LoadCheckpoint(ctx, "run-ckpt", 1)
+	if err != nil {
+		t.Fatalf("LoadCheckpoint failed: %v", err)
+	}
+	if first.Seq != 1 || first.NodeID != "n1" {
+		t.Fatalf("unexpected checkpoint: %#v", first)
+	}
+	if _, err := s.LoadCheckpoint(ctx, "run-ckpt", 3); !errors.Is(err, state.ErrNotFound) {
+		t.Fatalf("expected ErrNotFound for a missing checkpoint, got %v", err)
+	}
 }
 
 func TestSQLiteStore_NotFound(t *testing.T) {
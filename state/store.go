@@ -3,6 +3,8 @@ package state
 import (
 	"context"
 	"errors"
+	"fmt"
+	"time"
 )
 
 var (
@@ -28,3 +30,48 @@ type Store interface {
 
 	Close() error
 }
+
+// RunLocker is implemented by stores that can coordinate runs across
+// processes, such as the redis store. Locks expire after ttl so a crashed
+// owner cannot hold one forever.
+type RunLocker interface {
+	AcquireRunLock(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error)
+	ReleaseRunLock(ctx context.Context, runID, owner string) error
+}
+
+// RunLockRenewer is implemented by RunLockers that can extend a held lock,
+// such as the redis store. LockRun and TryLockRun renew their locks
+// through it while held, so a run may outlast the lock's ttl.
+type RunLockRenewer interface {
+	// RenewRunLock resets the lock's expiry to ttl and reports whether
+	// owner still holds it.
+	RenewRunLock(ctx context.Context, runID, owner string, ttl time.Duration) (bool, error)
+}
+
+// CheckpointLoader is implemented by stores that can load a checkpoint by
+// its sequence number, such as the sqlite and redis stores.
+type CheckpointLoader interface {
+	LoadCheckpoint(ctx context.Context, runID string, seq int) (CheckpointRecord, error)
+}
+
+// LoadCheckpoint loads checkpoint seq of runID. Stores that are not a
+// CheckpointLoader are searched through ListCheckpoints, which only sees the
+// checkpoints it returns by default.
+func LoadCheckpoint(ctx context.Context, store Store, runID string, seq int) (CheckpointRecord, error) {
+	if store == nil {
+		return CheckpointRecord{}, fmt.Errorf("state store is required")
+	}
+	if loader, ok := store.(CheckpointLoader); ok {
+		return loader.LoadCheckpoint(ctx, runID, seq)
+	}
+	checkpoints, err := store.ListCheckpoints(ctx, runID, 0)
+	if err != nil {
+		return CheckpointRecord{}, err
+	}
+	for _, checkpoint := range checkpoints {
+		if checkpoint.Seq == seq {
+			return checkpoint, nil
+		}
+	}
+	return CheckpointRecord{}, ErrNotFound
+}
@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -86,6 +87,16 @@ func (m *Manager) SaveBytes(ctx context.Context, requestedPath, defaultFileName
 	return result, nil
 }
 
+// ReadBytes reads a file saved under BaseDir. Paths are relative to BaseDir
+// and may not leave it.
+func (m *Manager) ReadBytes(path string) ([]byte, error) {
+	clean := filepath.Clean(strings.TrimPrefix(strings.TrimSpace(path), "./"))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return nil, fmt.Errorf("invalid storage path %q", path)
+	}
+	return os.ReadFile(filepath.Join(m.BaseDir(), clean))
+}
+
 func (m *Manager) resolveOutputPath(requestedPath, defaultFileName string) string {
 	base := m.BaseDir()
 	requested := strings.TrimSpace(requestedPath)
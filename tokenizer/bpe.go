@@ -0,0 +1,141 @@
+package tokenizer
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BPE is an offline byte-pair encoder compatible with OpenAI's tiktoken
+// encodings. Encoding returns one with the bundled merge ranks; LoadBPE and
+// LoadBPEFile read them from an encoding's .tiktoken file.
+type BPE struct {
+	name  string
+	ranks map[string]int
+	split splitter
+}
+
+// NewBPE returns an encoder for the named encoding ("cl100k_base" or
+// "o200k_base") with the given merge ranks, keyed by token bytes.
+func NewBPE(encoding string, ranks map[string]int) (*BPE, error) {
+	split, err := splitterFor(encoding)
+	if err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("bpe ranks for %s are empty", encoding)
+	}
+	return &BPE{name: encoding, ranks: ranks, split: split}, nil
+}
+
+// LoadBPE reads merge ranks in the tiktoken file format: one base64 token
+// and its rank per line.
+func LoadBPE(encoding string, r io.Reader) (*BPE, error) {
+	ranks := make(map[string]int, 200000)
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" {
+			continue
+		}
+		token, rank, ok := strings.Cut(text, " ")
+		if !ok {
+			return nil, fmt.Errorf("invalid %s rank on line %d", encoding, line)
+		}
+		raw, err := base64.StdEncoding.DecodeString(token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s token on line %d: %w", encoding, line, err)
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(rank))
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s rank on line %d: %w", encoding, line, err)
+		}
+		ranks[string(raw)] = n
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read %s ranks: %w", encoding, err)
+	}
+	return NewBPE(encoding, ranks)
+}
+
+func LoadBPEFile(encoding, path string) (*BPE, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open %s ranks: %w", encoding, err)
+	}
+	defer f.Close()
+	return LoadBPE(encoding, f)
+}
+
+func splitterFor(encoding string) (splitter, error) {
+	switch encoding {
+	case EncodingCL100K:
+		return splitCL100K, nil
+	case EncodingO200K:
+		return splitO200K, nil
+	default:
+		return nil, fmt.Errorf("unsupported bpe encoding %q", encoding)
+	}
+}
+
+func (b *BPE) Name() string { return b.name }
+
+// Count returns the exact number of tokens in text. Special tokens are
+// encoded as ordinary text.
+func (b *BPE) Count(text string) int {
+	n := 0
+	for _, piece := range b.split(text) {
+		if _, ok := b.ranks[piece]; ok {
+			n++
+			continue
+		}
+		n += len(b.merge(piece))
+	}
+	return n
+}
+
+// Encode returns the token ids of text.
+func (b *BPE) Encode(text string) []int {
+	var ids []int
+	for _, piece := range b.split(text) {
+		if rank, ok := b.ranks[piece]; ok {
+			ids = append(ids, rank)
+			continue
+		}
+		for _, part := range b.merge(piece) {
+			ids = append(ids, b.ranks[part])
+		}
+	}
+	return ids
+}
+
+// merge applies byte-pair merges to piece, always merging the adjacent pair
+// with the lowest rank first, and returns the resulting tokens.
+func (b *BPE) merge(piece string) []string {
+	parts := make([]string, len(piece))
+	for i := 0; i < len(piece); i++ {
+		parts[i] = piece[i : i+1]
+	}
+	for len(parts) > 1 {
+		best, bestRank := -1, math.MaxInt
+		for i := 0; i < len(parts)-1; i++ {
+			if rank, ok := b.ranks[parts[i]+parts[i+1]]; ok && rank < bestRank {
+				best, bestRank = i, rank
+			}
+		}
+		if best < 0 {
+			break
+		}
+		parts[best] += parts[best+1]
+		parts = append(parts[:best+1], parts[best+2:]...)
+	}
+	return parts
+}
@@ -0,0 +1,14 @@
+//go:build !tokenizer_nobundle
+
+package tokenizer
+
+import "embed"
+
+// bundledRanks holds the gzip-compressed rank files fetched into ranks/ by
+// go generate. Build with -tags tokenizer_nobundle to leave them out.
+//
+//go:generate go run ./internal/fetchranks -out ranks
+//go:embed ranks
+var bundledRanks embed.FS
+
+func init() { bundled = bundledRanks }
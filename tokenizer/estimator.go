@@ -0,0 +1,67 @@
+package tokenizer
+
+import (
+	"math"
+	"sync"
+	"unicode/utf8"
+)
+
+// DefaultCharsPerToken is the rough average for English text across
+// current model families.
+const DefaultCharsPerToken = 4.0
+
+// Estimator approximates token counts from text length. CJK and other wide
+// scripts are counted per character, since they rarely share tokens. It
+// implements Calibrator: provider-reported usage gradually corrects its
+// scale, so estimates converge on the real tokenizer over a session.
+type Estimator struct {
+	charsPerToken float64
+
+	mu    sync.Mutex
+	scale float64
+}
+
+func NewEstimator(charsPerToken float64) *Estimator {
+	if charsPerToken <= 0 {
+		charsPerToken = DefaultCharsPerToken
+	}
+	return &Estimator{charsPerToken: charsPerToken, scale: 1}
+}
+
+func (e *Estimator) Name() string { return "estimate" }
+
+func (e *Estimator) Count(text string) int {
+	if text == "" {
+		return 0
+	}
+	narrow, wide := 0, 0
+	for _, r := range text {
+		if r >= 0x2E80 {
+			wide++
+		} else {
+			narrow += utf8.RuneLen(r)
+		}
+	}
+	raw := float64(narrow)/e.charsPerToken + float64(wide)
+	return int(math.Ceil(raw * e.Scale()))
+}
+
+// Scale returns the current calibration factor.
+func (e *Estimator) Scale() float64 {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.scale
+}
+
+// Calibrate moves the scale a fifth of the way towards the observed ratio,
+// so one unusual request cannot swing it, and keeps it within [0.5, 2].
+func (e *Estimator) Calibrate(estimated, actual int) {
+	if estimated <= 0 || actual <= 0 {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	target := e.scale * float64(actual) / float64(estimated)
+	e.scale += (target - e.scale) / 5
+	e.scale = math.Min(2, math.Max(0.5, e.scale))
+}
@@ -0,0 +1,76 @@
+// Command fetchranks downloads the tiktoken merge ranks bundled with the
+// tokenizer package, checks them against their published digests and
+// writes them gzip-compressed to a directory.
+package main
+
+import (
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const baseURL = "https://openaipublic.blob.core.windows.net/encodings/"
+
+// digests are the SHA-256 digests tiktoken checks the rank files against.
+var digests = map[string]string{
+	"cl100k_base": "223921b76ee99bde995b7ff738513eef100fb51d18c93597a113bcffe865b2a7",
+	"o200k_base":  "446a9538cb6c348e3516120d7c08b09f57c36495e2acfffe59a5bf8b0cfb1a2d",
+}
+
+func main() {
+	out := flag.String("out", "ranks", "directory to write <encoding>.tiktoken.gz to")
+	flag.Parse()
+
+	client := &http.Client{Timeout: 2 * time.Minute}
+	for name, digest := range digests {
+		if err := fetch(client, name, digest, *out); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+func fetch(client *http.Client, name, digest, dir string) error {
+	resp, err := client.Get(baseURL + name + ".tiktoken")
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("failed to download %s: %s", name, resp.Status)
+	}
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to download %s: %w", name, err)
+	}
+	sum := sha256.Sum256(raw)
+	if got := hex.EncodeToString(sum[:]); got != digest {
+		return fmt.Errorf("%s digest mismatch: got %s, want %s", name, got, digest)
+	}
+
+	var buf bytes.Buffer
+	zw, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+	if _, err := zw.Write(raw); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to compress %s: %w", name, err)
+	}
+	path := filepath.Join(dir, name+".tiktoken.gz")
+	if err := os.WriteFile(path, buf.Bytes(), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	log.Printf("wrote %s (%d bytes)", path, buf.Len())
+	return nil
+}
@@ -0,0 +1,89 @@
+package tokenizer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const (
+	remoteTimeout = 5 * time.Second
+	// remoteRetryDelay is how long Remote stops calling an endpoint that
+	// failed before trying it again.
+	remoteRetryDelay = time.Minute
+)
+
+// RequestTokenizer is implemented by tokenizers that count a whole request
+// at once, such as Remote. The agent's context manager counts each request
+// once this way and calibrates the per-message counts it trims with from
+// the result.
+type RequestTokenizer interface {
+	CountRequest(ctx context.Context, req types.Request) (int, error)
+}
+
+// Remote counts requests with a provider's count-tokens endpoint. Text is
+// counted offline by the fallback, which is calibrated from every request
+// the endpoint counts. After the endpoint fails, CountRequest fails without
+// calling it for a minute, so an unavailable endpoint does not stall every
+// turn.
+type Remote struct {
+	counter  RequestCounter
+	fallback Tokenizer
+
+	mu      sync.Mutex
+	retryAt time.Time
+	lastErr error
+}
+
+func NewRemote(counter RequestCounter, fallback Tokenizer) *Remote {
+	if fallback == nil {
+		fallback = NewEstimator(DefaultCharsPerToken)
+	}
+	return &Remote{counter: counter, fallback: fallback}
+}
+
+func (r *Remote) Name() string { return "remote" }
+
+// Count counts text with the fallback.
+func (r *Remote) Count(text string) int {
+	return r.fallback.Count(text)
+}
+
+// Calibrate forwards to the fallback, which Count uses.
+func (r *Remote) Calibrate(estimated, actual int) {
+	if c, ok := r.fallback.(Calibrator); ok {
+		c.Calibrate(estimated, actual)
+	}
+}
+
+// CountRequest counts req with the endpoint, within ctx.
+func (r *Remote) CountRequest(ctx context.Context, req types.Request) (int, error) {
+	if r.counter == nil {
+		return 0, fmt.Errorf("no count-tokens endpoint")
+	}
+	r.mu.Lock()
+	if time.Now().Before(r.retryAt) {
+		err := r.lastErr
+		r.mu.Unlock()
+		return 0, fmt.Errorf("count-tokens endpoint unavailable: %w", err)
+	}
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(ctx, remoteTimeout)
+	defer cancel()
+	n, err := r.counter.CountTokens(ctx, req)
+	if err != nil {
+		// A cancelled run says nothing about the endpoint.
+		if !errors.Is(ctx.Err(), context.Canceled) {
+			r.mu.Lock()
+			r.retryAt, r.lastErr = time.Now().Add(remoteRetryDelay), err
+			r.mu.Unlock()
+		}
+		return 0, err
+	}
+	return n, nil
+}
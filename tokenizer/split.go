@@ -0,0 +1,211 @@
+package tokenizer
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// splitter breaks text into the pieces that are byte-pair encoded
+// independently. Go's regexp has no lookahead, so the tiktoken patterns are
+// implemented as scanners that try the pattern's alternatives in order.
+type splitter func(text string) []string
+
+// splitCL100K implements the cl100k_base pattern:
+//
+//	(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}|
+//	 ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitCL100K(text string) []string {
+	var pieces []string
+	for i := 0; i < len(text); {
+		n := contraction(text, i)
+		if n == 0 {
+			n = letters(text, i)
+		}
+		if n == 0 {
+			n = digits(text, i)
+		}
+		if n == 0 {
+			n = punctuation(text, i, false)
+		}
+		if n == 0 {
+			n = whitespace(text, i)
+		}
+		pieces = append(pieces, text[i:i+n])
+		i += n
+	}
+	return pieces
+}
+
+// splitO200K implements the o200k_base pattern, which splits words at case
+// changes and keeps contractions attached:
+//
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]*[\p{Ll}\p{Lm}\p{Lo}\p{M}]+(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	[^\r\n\p{L}\p{N}]?[\p{Lu}\p{Lt}\p{Lm}\p{Lo}\p{M}]+[\p{Ll}\p{Lm}\p{Lo}\p{M}]*(?i:'s|'t|'re|'ve|'m|'ll|'d)?|
+//	\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n/]*|\s*[\r\n]+|\s+(?!\S)|\s+
+func splitO200K(text string) []string {
+	var pieces []string
+	for i := 0; i < len(text); {
+		n := casedWord(text, i)
+		if n == 0 {
+			n = digits(text, i)
+		}
+		if n == 0 {
+			n = punctuation(text, i, true)
+		}
+		if n == 0 {
+			n = whitespace(text, i)
+		}
+		pieces = append(pieces, text[i:i+n])
+		i += n
+	}
+	return pieces
+}
+
+// contraction matches (?i:'s|'t|'re|'ve|'m|'ll|'d).
+func contraction(text string, i int) int {
+	if i >= len(text) || text[i] != '\'' {
+		return 0
+	}
+	rest := text[i+1:]
+	for _, suffix := range []string{"re", "ve", "ll", "s", "t", "m", "d"} {
+		if len(rest) >= len(suffix) && equalFoldASCII(rest[:len(suffix)], suffix) {
+			return 1 + len(suffix)
+		}
+	}
+	return 0
+}
+
+func equalFoldASCII(a, b string) bool {
+	for i := 0; i < len(a); i++ {
+		ca, cb := a[i], b[i]
+		if 'A' <= ca && ca <= 'Z' {
+			ca += 'a' - 'A'
+		}
+		if ca != cb {
+			return false
+		}
+	}
+	return true
+}
+
+// letters matches [^\r\n\p{L}\p{N}]?\p{L}+.
+func letters(text string, i int) int {
+	j := i
+	if r, size := utf8.DecodeRuneInString(text[j:]); isPrefix(r) {
+		j += size
+	}
+	end := scan(text, j, unicode.IsLetter)
+	if end == j {
+		return 0
+	}
+	return end - i
+}
+
+// casedWord matches the two word alternatives of the o200k pattern.
+func casedWord(text string, i int) int {
+	j := i
+	if r, size := utf8.DecodeRuneInString(text[j:]); isPrefix(r) {
+		j += size
+	}
+	end := 0
+	// [upper]*[lower]+ with backtracking over runes in both classes.
+	upperEnd := scan(text, j, isUpperClass)
+	for k := upperEnd; ; {
+		if lowerEnd := scan(text, k, isLowerClass); lowerEnd > k {
+			end = lowerEnd
+			break
+		}
+		if k == j {
+			break
+		}
+		_, size := utf8.DecodeLastRuneInString(text[j:k])
+		k -= size
+	}
+	if end == 0 && upperEnd > j {
+		// [upper]+[lower]*
+		end = scan(text, upperEnd, isLowerClass)
+	}
+	if end == 0 {
+		return 0
+	}
+	return end + contraction(text, end) - i
+}
+
+func isPrefix(r rune) bool {
+	return r != utf8.RuneError && r != '\r' && r != '\n' && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isUpperClass(r rune) bool {
+	return unicode.In(r, unicode.Lu, unicode.Lt, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+func isLowerClass(r rune) bool {
+	return unicode.In(r, unicode.Ll, unicode.Lm, unicode.Lo, unicode.M)
+}
+
+// digits matches \p{N}{1,3}.
+func digits(text string, i int) int {
+	j := i
+	for count := 0; count < 3 && j < len(text); count++ {
+		r, size := utf8.DecodeRuneInString(text[j:])
+		if !unicode.IsNumber(r) {
+			break
+		}
+		j += size
+	}
+	return j - i
+}
+
+// punctuation matches  ?[^\s\p{L}\p{N}]+[\r\n]* (with / in the trailing
+// class for o200k).
+func punctuation(text string, i int, slash bool) int {
+	j := i
+	if text[j] == ' ' {
+		j++
+	}
+	end := scan(text, j, func(r rune) bool {
+		return !unicode.IsSpace(r) && !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	if end == j {
+		return 0
+	}
+	for end < len(text) && (text[end] == '\r' || text[end] == '\n' || (slash && text[end] == '/')) {
+		end++
+	}
+	return end - i
+}
+
+// whitespace matches \s*[\r\n]+|\s+(?!\S)|\s+. Anything else is a single
+// rune that no alternative matched.
+func whitespace(text string, i int) int {
+	end := scan(text, i, unicode.IsSpace)
+	if end == i {
+		_, size := utf8.DecodeRuneInString(text[i:])
+		return size
+	}
+	// \s*[\r\n]+ ends after the last newline in the run.
+	for k := end; k > i; k-- {
+		if text[k-1] == '\r' || text[k-1] == '\n' {
+			return k - i
+		}
+	}
+	// \s+(?!\S) leaves the last space to prefix the following word.
+	if end < len(text) {
+		_, size := utf8.DecodeLastRuneInString(text[i:end])
+		if end-size > i {
+			return end - size - i
+		}
+	}
+	return end - i
+}
+
+func scan(text string, i int, match func(rune) bool) int {
+	for i < len(text) {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !match(r) {
+			break
+		}
+		i += size
+	}
+	return i
+}
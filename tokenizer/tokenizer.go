@@ -0,0 +1,199 @@
+// Package tokenizer counts tokens the way model providers bill them: exact
+// byte-pair encoding for OpenAI model families, a self-calibrating estimate
+// for the rest, and optionally a provider's count-tokens endpoint.
+package tokenizer
+
+import (
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+const (
+	EncodingCL100K = "cl100k_base"
+	EncodingO200K  = "o200k_base"
+)
+
+// Tokenizer counts the tokens in a piece of text.
+type Tokenizer interface {
+	Name() string
+	Count(text string) int
+}
+
+// Calibrator is implemented by tokenizers that learn from the input token
+// counts providers report. estimated is what the tokenizer predicted for a
+// request and actual what the provider billed.
+type Calibrator interface {
+	Calibrate(estimated, actual int)
+}
+
+// RequestCounter is implemented by providers with a count-tokens endpoint,
+// such as Anthropic and Gemini.
+type RequestCounter interface {
+	CountTokens(ctx context.Context, req types.Request) (int, error)
+}
+
+// ErrNoRanks is returned by LoadEncoding for an encoding whose merge ranks
+// were neither registered, bundled nor found in AGENT_TOKENIZER_DIR.
+var ErrNoRanks = errors.New("tokenizer: no merge ranks")
+
+// bundled holds the merge ranks embedded in the package as
+// ranks/<name>.tiktoken.gz; it is nil in builds tagged tokenizer_nobundle.
+var bundled fs.FS
+
+var (
+	warnedMu   sync.Mutex
+	warned     = map[string]bool{}
+	registryMu sync.Mutex
+	registry   = map[string]*BPE{}
+	loadErrs   = map[string]error{}
+)
+
+// Register makes an encoder available to For, for example one loaded from
+// an embedded rank file.
+func Register(bpe *BPE) {
+	if bpe == nil {
+		return
+	}
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[bpe.Name()] = bpe
+	delete(loadErrs, bpe.Name())
+}
+
+// Encoding returns the registered encoder for name, loading its merge ranks
+// on first use: <name>.tiktoken from the AGENT_TOKENIZER_DIR directory when
+// it is set, the ranks bundled with the package otherwise.
+func Encoding(name string) (*BPE, bool) {
+	bpe, err := LoadEncoding(name)
+	return bpe, err == nil
+}
+
+// LoadEncoding is Encoding with the reason an encoder is unavailable. It
+// fails with ErrNoRanks when the ranks are neither registered, in
+// AGENT_TOKENIZER_DIR nor bundled.
+func LoadEncoding(name string) (*BPE, error) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	if bpe, ok := registry[name]; ok {
+		return bpe, nil
+	}
+	if err, failed := loadErrs[name]; failed {
+		return nil, err
+	}
+	var (
+		bpe *BPE
+		err error
+	)
+	if dir := strings.TrimSpace(os.Getenv("AGENT_TOKENIZER_DIR")); dir != "" {
+		bpe, err = LoadBPEFile(name, filepath.Join(dir, name+".tiktoken"))
+	} else {
+		bpe, err = loadBundled(name)
+		if errors.Is(err, ErrNoRanks) {
+			return nil, err
+		}
+	}
+	if err != nil {
+		loadErrs[name] = err
+		return nil, err
+	}
+	registry[name] = bpe
+	return bpe, nil
+}
+
+// loadBundled reads the ranks of name embedded in the package.
+func loadBundled(name string) (*BPE, error) {
+	var f fs.File
+	err := fs.ErrNotExist
+	if bundled != nil {
+		f, err = bundled.Open("ranks/" + name + ".tiktoken.gz")
+	}
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, fmt.Errorf("%w for %s: none are bundled; set AGENT_TOKENIZER_DIR to a directory holding %s.tiktoken", ErrNoRanks, name, name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bundled %s ranks: %w", name, err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundled %s ranks: %w", name, err)
+	}
+	defer zr.Close()
+	return LoadBPE(name, zr)
+}
+
+// EncodingForModel returns the tiktoken encoding used by an OpenAI model,
+// or "" for models of other families. Azure deployments named after their
+// model resolve the same way.
+func EncodingForModel(model string) string {
+	m := strings.ToLower(strings.TrimSpace(model))
+	if i := strings.LastIndex(m, "/"); i >= 0 {
+		m = m[i+1:]
+	}
+	switch {
+	case strings.HasPrefix(m, "gpt-4o"), strings.HasPrefix(m, "chatgpt-4o"),
+		strings.HasPrefix(m, "gpt-4.1"), strings.HasPrefix(m, "gpt-4.5"),
+		strings.HasPrefix(m, "gpt-5"), strings.HasPrefix(m, "gpt-oss"),
+		strings.HasPrefix(m, "o1"), strings.HasPrefix(m, "o3"), strings.HasPrefix(m, "o4"):
+		return EncodingO200K
+	case strings.HasPrefix(m, "gpt-4"), strings.HasPrefix(m, "gpt-3.5"), strings.HasPrefix(m, "gpt-35"),
+		strings.HasPrefix(m, "text-embedding-3"), strings.HasPrefix(m, "text-embedding-ada-002"):
+		return EncodingCL100K
+	default:
+		return ""
+	}
+}
+
+// For returns the most accurate offline tokenizer for a provider and model:
+// the model's BPE encoding when it is an OpenAI family and the ranks are
+// available, otherwise an estimator calibrated for the provider. An OpenAI
+// model whose ranks are unavailable is logged once per encoding.
+func For(provider, model string) Tokenizer {
+	if encoding := EncodingForModel(model); encoding != "" {
+		bpe, err := LoadEncoding(encoding)
+		if err == nil {
+			return bpe
+		}
+		warnOnce(encoding, fmt.Sprintf("⚠️  %v; estimating %s tokens instead", err, model))
+	}
+	return NewEstimator(charsPerToken(provider, model))
+}
+
+func warnOnce(key, message string) {
+	warnedMu.Lock()
+	defer warnedMu.Unlock()
+	if warned[key] {
+		return
+	}
+	warned[key] = true
+	log.Print(message)
+}
+
+// charsPerToken is the typical ratio of English text and code for each
+// family; calibration corrects it from reported usage.
+func charsPerToken(provider, model string) float64 {
+	p := strings.ToLower(provider)
+	m := strings.ToLower(model)
+	switch {
+	case p == "anthropic" || strings.Contains(m, "claude"):
+		return 3.5
+	case p == "gemini" || strings.Contains(m, "gemini") || strings.Contains(m, "gemma"):
+		return 4.0
+	case strings.Contains(m, "llama") || strings.Contains(m, "mistral") || strings.Contains(m, "qwen"):
+		return 3.7
+	case EncodingForModel(model) == EncodingO200K:
+		return 4.2
+	default:
+		return DefaultCharsPerToken
+	}
+}
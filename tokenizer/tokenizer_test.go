@@ -0,0 +1,248 @@
+package tokenizer
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/types"
+)
+
+// testRanks is a tiny vocabulary: every byte, then merges that build
+// "hello" and " world".
+func testRanks() map[string]int {
+	ranks := make(map[string]int, 270)
+	for i := 0; i < 256; i++ {
+		ranks[string([]byte{byte(i)})] = i
+	}
+	for i, merged := range []string{"ll", "he", "hell", "hello", " w", "or", " wor", "ld", " world"} {
+		ranks[merged] = 256 + i
+	}
+	return ranks
+}
+
+func TestBPE_EncodeAndCount(t *testing.T) {
+	bpe, err := NewBPE(EncodingCL100K, testRanks())
+	if err != nil {
+		t.Fatalf("new bpe: %v", err)
+	}
+	if got := bpe.Encode("hello world"); !reflect.DeepEqual(got, []int{259, 264}) {
+		t.Fatalf("unexpected ids %v", got)
+	}
+	// "hellooo" is not a token, so it is merged pair by pair.
+	if got := bpe.Encode("hellooo"); !reflect.DeepEqual(got, []int{259, 'o', 'o'}) {
+		t.Fatalf("unexpected merged ids %v", got)
+	}
+	if got := bpe.Count("hello world hellooo"); got != 2+1+3 {
+		t.Fatalf("expected 6 tokens, got %d", got)
+	}
+}
+
+func TestLoadBPE(t *testing.T) {
+	var b strings.Builder
+	for token, rank := range testRanks() {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	bpe, err := LoadBPE(EncodingO200K, strings.NewReader(b.String()))
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	if bpe.Name() != EncodingO200K || bpe.Count("hello world") != 2 {
+		t.Fatalf("unexpected encoder %s counting %d", bpe.Name(), bpe.Count("hello world"))
+	}
+	if _, err := LoadBPE(EncodingO200K, strings.NewReader("not-a-rank-line\n")); err == nil {
+		t.Fatalf("expected malformed rank error")
+	}
+	if _, err := NewBPE("p50k_base", testRanks()); err == nil {
+		t.Fatalf("expected unsupported encoding error")
+	}
+}
+
+func TestSplitCL100K(t *testing.T) {
+	cases := map[string][]string{
+		"Hello world":    {"Hello", " world"},
+		"it's 12345 ok":  {"it", "'s", " ", "123", "45", " ok"},
+		"a  b":           {"a", " ", " b"},
+		"x\n\n  y":       {"x", "\n\n", " ", " y"},
+		"foo!!\nbar":     {"foo", "!!\n", "bar"},
+		"trailing  ":     {"trailing", "  "},
+		"(call) ":        {"(call", ")", " "},
+		"naïve café 日本語": {"naïve", " café", " 日本語"},
+	}
+	for in, want := range cases {
+		if got := splitCL100K(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitCL100K(%q) = %q, want %q", in, got, want)
+		}
+		if strings.Join(splitCL100K(in), "") != in {
+			t.Errorf("splitCL100K(%q) lost text", in)
+		}
+	}
+}
+
+func TestSplitO200K(t *testing.T) {
+	cases := map[string][]string{
+		"HelloWorld":  {"Hello", "World"},
+		"HTTPServer":  {"HTTPServer"},
+		"HTTP":        {"HTTP"},
+		"don't stop":  {"don't", " stop"},
+		"path/to/x\n": {"path", "/to", "/x", "\n"},
+		"1234":        {"123", "4"},
+	}
+	for in, want := range cases {
+		if got := splitO200K(in); !reflect.DeepEqual(got, want) {
+			t.Errorf("splitO200K(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEstimator_CountsAndCalibrates(t *testing.T) {
+	e := NewEstimator(4)
+	if got := e.Count("abcdefgh"); got != 2 {
+		t.Fatalf("expected 2 tokens, got %d", got)
+	}
+	if got := e.Count("日本語"); got != 3 {
+		t.Fatalf("expected wide runes to count one each, got %d", got)
+	}
+	// A request the provider billed at 150 tokens.
+	request := strings.Repeat("x", 400)
+	for i := 0; i < 50; i++ {
+		e.Calibrate(e.Count(request), 150)
+	}
+	if scale := e.Scale(); scale < 1.45 || scale > 1.5 {
+		t.Fatalf("expected scale to converge on 1.5, got %f", scale)
+	}
+	if got := e.Count("abcdefgh"); got != 3 {
+		t.Fatalf("expected calibrated count 3, got %d", got)
+	}
+	for i := 0; i < 50; i++ {
+		e.Calibrate(100, 1)
+	}
+	if e.Scale() != 0.5 {
+		t.Fatalf("expected scale to be clamped, got %f", e.Scale())
+	}
+}
+
+func TestFor(t *testing.T) {
+	if EncodingForModel("gpt-4o-mini") != EncodingO200K || EncodingForModel("gpt-35-turbo") != EncodingCL100K || EncodingForModel("claude-3-5-sonnet") != "" {
+		t.Fatalf("unexpected model encodings")
+	}
+	if _, ok := For("anthropic", "claude-3-5-sonnet").(*Estimator); !ok {
+		t.Fatalf("expected an estimator for anthropic")
+	}
+
+	resetEncodings(t, fstest.MapFS{})
+	t.Setenv("AGENT_TOKENIZER_DIR", "")
+	if _, err := LoadEncoding(EncodingCL100K); !errors.Is(err, ErrNoRanks) || !strings.Contains(err.Error(), "AGENT_TOKENIZER_DIR") {
+		t.Fatalf("expected an error naming AGENT_TOKENIZER_DIR, got %v", err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, EncodingCL100K+".tiktoken"), testRankFile(), 0o644); err != nil {
+		t.Fatalf("write ranks: %v", err)
+	}
+	t.Setenv("AGENT_TOKENIZER_DIR", dir)
+	if tok := For("openai", "gpt-4-turbo"); tok.Name() != EncodingCL100K {
+		t.Fatalf("expected ranks to load from AGENT_TOKENIZER_DIR, got %s", tok.Name())
+	}
+
+	bpe, _ := NewBPE(EncodingO200K, testRanks())
+	Register(bpe)
+	if tok := For("azureopenai", "gpt-4o"); tok != Tokenizer(bpe) {
+		t.Fatalf("expected the registered encoder, got %s", tok.Name())
+	}
+}
+
+func TestFor_BundledRanks(t *testing.T) {
+	var gz bytes.Buffer
+	zw := gzip.NewWriter(&gz)
+	zw.Write(testRankFile())
+	zw.Close()
+	resetEncodings(t, fstest.MapFS{"ranks/" + EncodingO200K + ".tiktoken.gz": {Data: gz.Bytes()}})
+	t.Setenv("AGENT_TOKENIZER_DIR", "")
+
+	tok := For("openai", "gpt-4o")
+	if tok.Name() != EncodingO200K || tok.Count("hello world") != 2 {
+		t.Fatalf("expected the bundled ranks to be used, got %s", tok.Name())
+	}
+	if _, err := LoadEncoding(EncodingCL100K); !errors.Is(err, ErrNoRanks) {
+		t.Fatalf("expected no ranks for an encoding that is not bundled, got %v", err)
+	}
+}
+
+// testRankFile is testRanks in the tiktoken file format.
+func testRankFile() []byte {
+	var b strings.Builder
+	for token, rank := range testRanks() {
+		fmt.Fprintf(&b, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	return []byte(b.String())
+}
+
+// resetEncodings forgets loaded encoders and bundles ranks for the test.
+func resetEncodings(t *testing.T, ranks fstest.MapFS) {
+	t.Helper()
+	reset := func(fsys fs.FS) {
+		registryMu.Lock()
+		defer registryMu.Unlock()
+		registry = map[string]*BPE{}
+		loadErrs = map[string]error{}
+		bundled = fsys
+	}
+	prev := bundled
+	reset(ranks)
+	t.Cleanup(func() { reset(prev) })
+}
+
+type fakeCounter struct {
+	calls int
+	err   error
+}
+
+// CountTokens reports five tokens of request overhead plus one per word.
+func (c *fakeCounter) CountTokens(ctx context.Context, req types.Request) (int, error) {
+	_ = ctx
+	c.calls++
+	if c.err != nil {
+		return 0, c.err
+	}
+	return 5 + len(strings.Fields(req.Messages[0].Content)), nil
+}
+
+func TestRemote(t *testing.T) {
+	counter := &fakeCounter{}
+	r := NewRemote(counter, NewEstimator(4))
+	if got := r.Count("abcdefgh"); got != 2 || counter.calls != 0 {
+		t.Fatalf("expected text to be counted offline, got %d after %d calls", got, counter.calls)
+	}
+	req := types.Request{Messages: []types.Message{{Role: types.RoleUser, Content: "one two three"}}}
+	if got, err := r.CountRequest(context.Background(), req); err != nil || got != 8 {
+		t.Fatalf("expected the endpoint's count, got %d, %v", got, err)
+	}
+
+	counter.err = errors.New("unavailable")
+	if _, err := r.CountRequest(context.Background(), req); err == nil {
+		t.Fatalf("expected the endpoint error")
+	}
+	calls := counter.calls
+	if _, err := r.CountRequest(context.Background(), req); err == nil || counter.calls != calls {
+		t.Fatalf("expected the failure to be remembered, got %v after %d calls", err, counter.calls)
+	}
+
+	cancelled := NewRemote(&fakeCounter{err: context.Canceled}, nil)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _ = cancelled.CountRequest(ctx, req)
+	if !cancelled.retryAt.IsZero() {
+		t.Fatalf("expected a cancelled request not to be held against the endpoint")
+	}
+}
@@ -0,0 +1,195 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/storage"
+)
+
+// ArtifactReaderName is the name of the tool that reads tool output the
+// agent stored as an artifact because it was too large for the conversation.
+const ArtifactReaderName = "artifact_reader"
+
+const (
+	artifactMaxLineBytes = 500
+	artifactMaxBytes     = 16000
+	// artifactRoot is the directory the agent spools tool output to; the
+	// reader does not read outside it.
+	artifactRoot = "tool-outputs"
+)
+
+type artifactDirContextKey struct{}
+
+// WithArtifactDir limits artifact_reader calls made with ctx to the
+// artifacts under dir, such as the tool output directory of the current
+// run. Without it the reader reads anything under tool-outputs.
+func WithArtifactDir(ctx context.Context, dir string) context.Context {
+	return context.WithValue(ctx, artifactDirContextKey{}, dir)
+}
+
+type artifactReaderArgs struct {
+	Artifact   string `json:"artifact" description:"Artifact path returned with a truncated tool result."`
+	Operation  string `json:"operation" enum:"read,grep,bytes" default:"read" description:"read pages through lines; grep searches them; bytes reads raw bytes, for lines too long to read whole."`
+	Offset     int    `json:"offset" default:"1" description:"First line to read, starting at 1."`
+	Limit      int    `json:"limit" default:"200" description:"Maximum number of lines to read."`
+	ByteOffset int    `json:"byteOffset,omitempty" description:"First byte to read with bytes, starting at 0."`
+	ByteLimit  int    `json:"byteLimit" default:"16000" description:"Maximum number of bytes to read with bytes."`
+	Pattern    string `json:"pattern,omitempty" description:"Regular expression for grep."`
+	IgnoreCase bool   `json:"ignoreCase,omitempty" description:"Case-insensitive grep."`
+	MaxMatches int    `json:"maxMatches" default:"50" description:"Maximum number of grep matches to return."`
+}
+
+type artifactLine struct {
+	Line int    `json:"line"`
+	Text string `json:"text"`
+}
+
+type artifactReaderResult struct {
+	Artifact   string         `json:"artifact"`
+	TotalLines int            `json:"totalLines"`
+	Lines      []artifactLine `json:"lines"`
+	// NextOffset is the offset to read from to continue, when there is more.
+	NextOffset int  `json:"nextOffset,omitempty"`
+	Truncated  bool `json:"truncated,omitempty"`
+	// TotalBytes, Text and NextByteOffset are set by the bytes operation.
+	TotalBytes     int    `json:"totalBytes,omitempty"`
+	Text           string `json:"text,omitempty"`
+	NextByteOffset int    `json:"nextByteOffset,omitempty"`
+}
+
+// NewArtifactReader returns the artifact_reader tool, which pages or greps
+// through artifacts saved by mgr under tool-outputs, or reads them by byte
+// offset. A nil mgr uses storage.Default().
+func NewArtifactReader(mgr *storage.Manager) Tool {
+	if mgr == nil {
+		mgr = storage.Default()
+	}
+	return MustTypedTool(
+		ArtifactReaderName,
+		"Read or grep a stored artifact, such as tool output that was too large to return in full. Use read with offset/limit to page through lines, grep with a regex pattern, or bytes with byteOffset/byteLimit to read lines that were clipped.",
+		func(ctx context.Context, in artifactReaderArgs) (artifactReaderResult, error) {
+			if err := checkArtifactPath(ctx, in.Artifact); err != nil {
+				return artifactReaderResult{}, err
+			}
+			content, err := mgr.ReadBytes(in.Artifact)
+			if err != nil {
+				return artifactReaderResult{}, fmt.Errorf("read artifact: %w", err)
+			}
+			lines := splitArtifactLines(content)
+			out := artifactReaderResult{Artifact: in.Artifact, TotalLines: len(lines), Lines: []artifactLine{}}
+			starts := lineStarts(lines)
+
+			if in.Operation == "bytes" {
+				out.TotalBytes = len(content)
+				start := min(max(in.ByteOffset, 0), len(content))
+				for start > 0 && start < len(content) && !utf8.RuneStart(content[start]) {
+					start--
+				}
+				end := min(start+min(max(in.ByteLimit, 1), artifactMaxBytes), len(content))
+				for end > start && end < len(content) && !utf8.RuneStart(content[end]) {
+					end--
+				}
+				out.Text = string(content[start:end])
+				if end < len(content) {
+					out.NextByteOffset = end
+				}
+				return out, nil
+			}
+
+			if in.Operation == "grep" {
+				if in.Pattern == "" {
+					return artifactReaderResult{}, fmt.Errorf("pattern is required for grep")
+				}
+				pattern := in.Pattern
+				if in.IgnoreCase {
+					pattern = "(?i)" + pattern
+				}
+				re, err := regexp.Compile(pattern)
+				if err != nil {
+					return artifactReaderResult{}, fmt.Errorf("invalid pattern: %w", err)
+				}
+				size := 0
+				for i, line := range lines {
+					if !re.MatchString(line) {
+						continue
+					}
+					if len(out.Lines) >= in.MaxMatches || size >= artifactMaxBytes {
+						out.Truncated = true
+						break
+					}
+					text := clipLine(line, starts[i])
+					size += len(text)
+					out.Lines = append(out.Lines, artifactLine{Line: i + 1, Text: text})
+				}
+				return out, nil
+			}
+
+			start := max(in.Offset, 1)
+			size := 0
+			for i := start - 1; i < len(lines) && i < start-1+max(in.Limit, 1); i++ {
+				if size >= artifactMaxBytes {
+					break
+				}
+				text := clipLine(lines[i], starts[i])
+				size += len(text)
+				out.Lines = append(out.Lines, artifactLine{Line: i + 1, Text: text})
+			}
+			if next := start + len(out.Lines); next <= len(lines) {
+				out.NextOffset = next
+			}
+			return out, nil
+		},
+	)
+}
+
+func splitArtifactLines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// checkArtifactPath rejects artifacts outside tool-outputs, or outside the
+// directory set with WithArtifactDir.
+func checkArtifactPath(ctx context.Context, artifact string) error {
+	dir, _ := ctx.Value(artifactDirContextKey{}).(string)
+	if dir == "" {
+		dir = artifactRoot
+	}
+	clean := filepath.ToSlash(filepath.Clean(strings.TrimPrefix(strings.TrimSpace(artifact), "./")))
+	if !strings.HasPrefix(clean, strings.TrimSuffix(filepath.ToSlash(dir), "/")+"/") {
+		return fmt.Errorf("artifact %q is not under %s", artifact, dir)
+	}
+	return nil
+}
+
+// lineStarts returns the byte offset at which each line starts.
+func lineStarts(lines []string) []int {
+	starts := make([]int, len(lines))
+	offset := 0
+	for i, line := range lines {
+		starts[i] = offset
+		offset += len(line) + 1
+	}
+	return starts
+}
+
+// clipLine shortens very long lines, such as minified JSON, so one line
+// cannot fill the reply. The note says where the rest of the line starts,
+// for the bytes operation; start is the line's own byte offset.
+func clipLine(line string, start int) string {
+	if len(line) <= artifactMaxLineBytes {
+		return line
+	}
+	cut := artifactMaxLineBytes
+	for cut > 0 && !utf8.RuneStart(line[cut]) {
+		cut--
+	}
+	return line[:cut] + fmt.Sprintf("… [%d more bytes from byteOffset %d]", len(line)-cut, start+cut)
+}
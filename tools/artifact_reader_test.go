@@ -0,0 +1,81 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/PipeOpsHQ/agent-sdk-go/storage"
+)
+
+func TestArtifactReader(t *testing.T) {
+	t.Setenv("AGENT_STORAGE_DIR", t.TempDir())
+	mgr := storage.NewFromEnv()
+	var b strings.Builder
+	for i := 1; i <= 300; i++ {
+		fmt.Fprintf(&b, "line %d\n", i)
+	}
+	b.WriteString(strings.Repeat("x", 2000) + "\n")
+	if _, err := mgr.SaveBytes(context.Background(), "tool-outputs/run/call.txt", "", []byte(b.String())); err != nil {
+		t.Fatalf("save failed: %v", err)
+	}
+	reader := NewArtifactReader(mgr)
+
+	execute := func(args string) artifactReaderResult {
+		t.Helper()
+		out, err := reader.Execute(context.Background(), json.RawMessage(args))
+		if err != nil {
+			t.Fatalf("execute %s failed: %v", args, err)
+		}
+		return out.(artifactReaderResult)
+	}
+
+	page := execute(`{"artifact":"tool-outputs/run/call.txt","offset":101,"limit":10}`)
+	if page.TotalLines != 301 || len(page.Lines) != 10 || page.Lines[0].Text != "line 101" || page.NextOffset != 111 {
+		t.Fatalf("unexpected page: %+v", page)
+	}
+
+	first := execute(`{"artifact":"tool-outputs/run/call.txt"}`)
+	if len(first.Lines) != 200 || first.NextOffset != 201 {
+		t.Fatalf("expected the default page of 200 lines, got %d (next %d)", len(first.Lines), first.NextOffset)
+	}
+
+	last := execute(`{"artifact":"tool-outputs/run/call.txt","offset":301}`)
+	if len(last.Lines) != 1 || last.NextOffset != 0 || !strings.HasSuffix(last.Lines[0].Text, "[1500 more bytes from byteOffset 3092]") {
+		t.Fatalf("expected the long last line to be clipped: %+v", last)
+	}
+
+	// The rest of a clipped line is read by byte offset.
+	rest := execute(`{"artifact":"tool-outputs/run/call.txt","operation":"bytes","byteOffset":3092,"byteLimit":1000}`)
+	if rest.TotalBytes != 4593 || rest.Text != strings.Repeat("x", 1000) || rest.NextByteOffset != 4092 {
+		t.Fatalf("unexpected bytes result: %+v", rest)
+	}
+	tail := execute(`{"artifact":"tool-outputs/run/call.txt","operation":"bytes","byteOffset":4092}`)
+	if tail.Text != strings.Repeat("x", 500)+"\n" || tail.NextByteOffset != 0 {
+		t.Fatalf("unexpected bytes tail: %+v", tail)
+	}
+
+	grep := execute(`{"artifact":"tool-outputs/run/call.txt","operation":"grep","pattern":"^LINE 2\\d$","ignoreCase":true,"maxMatches":5}`)
+	if len(grep.Lines) != 5 || grep.Lines[0].Line != 20 || !grep.Truncated {
+		t.Fatalf("unexpected grep result: %+v", grep)
+	}
+
+	for _, args := range []string{
+		`{"artifact":"../secret.txt"}`,
+		`{"artifact":"/etc/passwd"}`,
+		`{"artifact":"tool-outputs/run/call.txt","operation":"grep"}`,
+		`{"artifact":"tool-outputs/../config.json"}`,
+		`{"artifact":"notes.txt"}`,
+	} {
+		if _, err := reader.Execute(context.Background(), json.RawMessage(args)); err == nil {
+			t.Fatalf("expected %s to fail", args)
+		}
+	}
+
+	scoped := WithArtifactDir(context.Background(), "tool-outputs/other")
+	if _, err := reader.Execute(scoped, json.RawMessage(`{"artifact":"tool-outputs/run/call.txt"}`)); err == nil {
+		t.Fatal("expected an artifact outside the run's directory to fail")
+	}
+}
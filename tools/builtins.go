@@ -56,6 +56,11 @@ func init() {
 		"Create chat-ready previews and view/download links for generated documents.",
 		func() Tool { return NewDocumentPreview() },
 	)
+	MustRegisterTool(
+		ArtifactReaderName,
+		"Read or grep a stored artifact, such as tool output that was too large to return in full.",
+		func() Tool { return NewArtifactReader(nil) },
+	)
 
 	// Encoding tools
 	MustRegisterTool(
@@ -0,0 +1,253 @@
+package tools
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ValidateSchema checks value, decoded from JSON, against the subset of JSON
+// Schema tool definitions use: type, enum, required, properties and items.
+// It returns one message per violation, each prefixed with the JSON path
+// of the offending value.
+func ValidateSchema(value any, schema map[string]any) []string {
+	return validateSchema(value, schema, "$", nil)
+}
+
+func validateSchema(value any, schema map[string]any, path string, errs []string) []string {
+	if len(schema) == 0 {
+		return errs
+	}
+
+	if typ, ok := schema["type"].(string); ok {
+		if !matchesType(value, typ) {
+			return append(errs, fmt.Sprintf("%s: expected %s", path, typ))
+		}
+	}
+
+	if enumValues, ok := enumList(schema["enum"]); ok {
+		found := false
+		for _, ev := range enumValues {
+			if valuesEqual(value, ev) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			errs = append(errs, fmt.Sprintf("%s: value not in enum", path))
+		}
+	}
+
+	obj, isObj := value.(map[string]any)
+	if required := stringList(schema["required"]); required != nil {
+		if !isObj {
+			errs = append(errs, fmt.Sprintf("%s: required fields expect object", path))
+		} else {
+			for _, k := range required {
+				if strings.TrimSpace(k) == "" {
+					continue
+				}
+				if _, exists := obj[k]; !exists {
+					errs = append(errs, fmt.Sprintf("%s.%s: required field missing", path, k))
+				}
+			}
+		}
+	}
+
+	if props, ok := schema["properties"].(map[string]any); ok {
+		if !isObj {
+			errs = append(errs, fmt.Sprintf("%s: properties expect object", path))
+		} else {
+			for key, raw := range props {
+				subSchema, ok := raw.(map[string]any)
+				if !ok {
+					continue
+				}
+				v, exists := obj[key]
+				if !exists {
+					continue
+				}
+				errs = validateSchema(v, subSchema, path+"."+key, errs)
+			}
+		}
+	}
+
+	if itemSchemaRaw, ok := schema["items"].(map[string]any); ok {
+		arr, ok := value.([]any)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s: items expect array", path))
+		} else {
+			for i, item := range arr {
+				errs = validateSchema(item, itemSchemaRaw, fmt.Sprintf("%s[%d]", path, i), errs)
+			}
+		}
+	}
+
+	return errs
+}
+
+// enumList reads the enum keyword, which schemas built in Go often declare
+// as []string.
+func enumList(raw any) ([]any, bool) {
+	switch v := raw.(type) {
+	case []any:
+		return v, true
+	case []string:
+		out := make([]any, len(v))
+		for i, item := range v {
+			out[i] = item
+		}
+		return out, true
+	default:
+		return nil, false
+	}
+}
+
+// stringList reads a schema keyword holding strings. Schemas built in Go
+// use []string, schemas decoded from JSON use []any.
+func stringList(raw any) []string {
+	switch v := raw.(type) {
+	case []string:
+		return v
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	default:
+		return nil
+	}
+}
+
+func matchesType(value any, typ string) bool {
+	switch strings.ToLower(strings.TrimSpace(typ)) {
+	case "object":
+		_, ok := value.(map[string]any)
+		return ok
+	case "array":
+		_, ok := value.([]any)
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		switch value.(type) {
+		case float64, float32, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+			return true
+		default:
+			return false
+		}
+	case "integer":
+		n, ok := value.(float64)
+		if !ok {
+			return false
+		}
+		return n == float64(int64(n))
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	default:
+		return true
+	}
+}
+
+func valuesEqual(a, b any) bool {
+	left, err := json.Marshal(a)
+	if err != nil {
+		return false
+	}
+	right, err := json.Marshal(b)
+	if err != nil {
+		return false
+	}
+	return string(left) == string(right)
+}
+
+// RepairJSON makes a best-effort attempt to fix the malformed JSON models
+// commonly emit as tool arguments: markdown code fences, trailing commas
+// and objects encoded as a JSON string. It reports whether raw was changed;
+// when it cannot produce valid JSON it returns raw unchanged and false.
+func RepairJSON(raw []byte) ([]byte, bool) {
+	text := stripCodeFence(strings.TrimSpace(string(raw)))
+	if !json.Valid([]byte(text)) {
+		text = removeTrailingCommas(text)
+	}
+	if !json.Valid([]byte(text)) {
+		return raw, false
+	}
+	// A string holding an object or array is the object double-encoded.
+	for strings.HasPrefix(text, `"`) {
+		var inner string
+		if err := json.Unmarshal([]byte(text), &inner); err != nil {
+			break
+		}
+		inner = strings.TrimSpace(inner)
+		if !strings.HasPrefix(inner, "{") && !strings.HasPrefix(inner, "[") {
+			break
+		}
+		fixed, ok := RepairJSON([]byte(inner))
+		if !ok && !json.Valid(fixed) {
+			break
+		}
+		text = string(fixed)
+	}
+	if bytes.Equal([]byte(text), raw) {
+		return raw, false
+	}
+	return []byte(text), true
+}
+
+func stripCodeFence(text string) string {
+	if !strings.HasPrefix(text, "```") || !strings.HasSuffix(text, "```") || len(text) < 6 {
+		return text
+	}
+	body := strings.TrimSuffix(strings.TrimPrefix(text, "```"), "```")
+	// Drop the info string, e.g. ```json.
+	if nl := strings.IndexByte(body, '\n'); nl >= 0 && !strings.ContainsAny(body[:nl], "{[\"") {
+		body = body[nl+1:]
+	}
+	return strings.TrimSpace(body)
+}
+
+// removeTrailingCommas drops commas directly before a closing brace or
+// bracket, leaving string contents alone.
+func removeTrailingCommas(text string) string {
+	var b strings.Builder
+	b.Grow(len(text))
+	inString, escaped := false, false
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			b.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			j := i + 1
+			for j < len(text) && strings.IndexByte(" \t\r\n", text[j]) >= 0 {
+				j++
+			}
+			if j < len(text) && (text[j] == '}' || text[j] == ']') {
+				continue
+			}
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestValidateSchema(t *testing.T) {
+	schema := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"action": map[string]any{"type": "string", "enum": []string{"get", "list"}},
+			"limit":  map[string]any{"type": "integer"},
+			"tags":   map[string]any{"type": "array", "items": map[string]any{"type": "string"}},
+		},
+		"required": []string{"action"},
+	}
+	cases := []struct {
+		args string
+		want int
+	}{
+		{`{"action":"get","limit":5,"tags":["a"]}`, 0},
+		{`{"limit":5}`, 1},
+		{`{"action":"delete","limit":1.5,"tags":[1]}`, 3},
+		{`[]`, 1},
+	}
+	for _, tc := range cases {
+		var value any
+		if err := json.Unmarshal([]byte(tc.args), &value); err != nil {
+			t.Fatalf("decode %s: %v", tc.args, err)
+		}
+		if errs := ValidateSchema(value, schema); len(errs) != tc.want {
+			t.Errorf("ValidateSchema(%s) = %v, want %d violations", tc.args, errs, tc.want)
+		}
+	}
+}
+
+func TestRepairJSON(t *testing.T) {
+	cases := []struct {
+		in      string
+		want    string
+		changed bool
+	}{
+		{`{"a":1}`, `{"a":1}`, false},
+		{"```json\n{\"a\":1}\n```", `{"a":1}`, true},
+		{`{"a":[1,2,],"b":"x,}",}`, `{"a":[1,2],"b":"x,}"}`, true},
+		{`"{\"a\":1}"`, `{"a":1}`, true},
+		{`"plain"`, `"plain"`, false},
+		{`{"a":`, `{"a":`, false},
+	}
+	for _, tc := range cases {
+		got, changed := RepairJSON([]byte(tc.in))
+		if string(got) != tc.want || changed != tc.changed {
+			t.Errorf("RepairJSON(%s) = %s, %v; want %s, %v", tc.in, got, changed, tc.want, tc.changed)
+		}
+	}
+}
@@ -0,0 +1,287 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// NewTypedTool builds a tool from a Go function. The JSON schema is derived
+// from Args with SchemaOf, and call arguments are decoded into Args after
+// defaults are filled in and the schema is checked, so fn receives input
+// that has already been validated.
+func NewTypedTool[Args, Result any](name, description string, fn func(ctx context.Context, args Args) (Result, error)) (*FuncTool, error) {
+	if fn == nil {
+		return nil, fmt.Errorf("tool %q has no execute function", name)
+	}
+	t := reflect.TypeOf((*Args)(nil)).Elem()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("tool %q: arguments must be a struct, got %s", name, t)
+	}
+	schema, err := SchemaOf(t)
+	if err != nil {
+		return nil, fmt.Errorf("tool %q: %w", name, err)
+	}
+	return NewFuncTool(name, description, schema, func(ctx context.Context, raw json.RawMessage) (any, error) {
+		args, err := decodeArgs[Args](raw, schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s args: %w", name, err)
+		}
+		return fn(ctx, args)
+	}), nil
+}
+
+// MustTypedTool is NewTypedTool for package-level tools; it panics when
+// the schema cannot be derived from Args.
+func MustTypedTool[Args, Result any](name, description string, fn func(ctx context.Context, args Args) (Result, error)) *FuncTool {
+	tool, err := NewTypedTool(name, description, fn)
+	if err != nil {
+		panic(err)
+	}
+	return tool
+}
+
+func decodeArgs[Args any](raw json.RawMessage, schema map[string]any) (Args, error) {
+	var args Args
+	if len(raw) == 0 {
+		raw = json.RawMessage(`{}`)
+	}
+	var value any
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return args, err
+	}
+	value = applyDefaults(value, schema)
+	if errs := ValidateSchema(value, schema); len(errs) > 0 {
+		return args, fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	normalized, err := json.Marshal(value)
+	if err != nil {
+		return args, err
+	}
+	if err := json.Unmarshal(normalized, &args); err != nil {
+		return args, err
+	}
+	return args, nil
+}
+
+// applyDefaults fills in missing object properties that declare a default,
+// descending into nested objects and arrays.
+func applyDefaults(value any, schema map[string]any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		props, _ := schema["properties"].(map[string]any)
+		for key, raw := range props {
+			sub, ok := raw.(map[string]any)
+			if !ok {
+				continue
+			}
+			if current, exists := v[key]; exists {
+				v[key] = applyDefaults(current, sub)
+			} else if def, ok := sub["default"]; ok {
+				v[key] = def
+			}
+		}
+		return v
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i := range v {
+				v[i] = applyDefaults(v[i], items)
+			}
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+// SchemaFor derives a JSON schema from T; see SchemaOf.
+func SchemaFor[T any]() (map[string]any, error) {
+	return SchemaOf(reflect.TypeOf((*T)(nil)).Elem())
+}
+
+// SchemaOf derives a JSON schema from a Go type. Struct fields use their
+// json names, embedded structs are flattened and nested structs become
+// nested objects. Fields are required unless tagged omitempty or given a
+// default; a required:"true" or required:"false" tag overrides that.
+// Other tags:
+//
+//	description:"..."  documents the field
+//	enum:"a,b,c"       lists the allowed values, comma separated
+//	default:"..."      the value used when the field is missing
+//
+// For example:
+//
+//	type Review struct {
+//		Sentiment string  `json:"sentiment" enum:"positive,negative,neutral"`
+//		Score     float64 `json:"score" description:"confidence from 0 to 1"`
+//		Limit     int     `json:"limit" default:"10"`
+//		Notes     string  `json:"notes,omitempty"`
+//	}
+func SchemaOf(t reflect.Type) (map[string]any, error) {
+	return typeSchema(t, map[reflect.Type]bool{})
+}
+
+var (
+	timeType       = reflect.TypeOf(time.Time{})
+	rawMessageType = reflect.TypeOf(json.RawMessage(nil))
+)
+
+func typeSchema(t reflect.Type, visiting map[reflect.Type]bool) (map[string]any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == timeType:
+		return map[string]any{"type": "string", "format": "date-time"}, nil
+	case t == rawMessageType:
+		return map[string]any{}, nil
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Interface:
+		return map[string]any{}, nil
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			// encoding/json writes byte slices as base64 strings.
+			return map[string]any{"type": "string"}, nil
+		}
+		items, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Map:
+		if t.Key().Kind() != reflect.String {
+			return nil, fmt.Errorf("schema for %s: map keys must be strings", t)
+		}
+		values, err := typeSchema(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "object", "additionalProperties": values}, nil
+	case reflect.Struct:
+		if visiting[t] {
+			return nil, fmt.Errorf("schema for %s: recursive types are not supported", t)
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		properties := map[string]any{}
+		required := []any{}
+		if err := structFields(t, visiting, properties, &required); err != nil {
+			return nil, err
+		}
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		return schema, nil
+	default:
+		return nil, fmt.Errorf("schema for %s: unsupported kind %s", t, t.Kind())
+	}
+}
+
+// structFields adds t's fields to properties, flattening embedded structs
+// the way encoding/json does.
+func structFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]any, required *[]any) error {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				if err := structFields(embedded, visiting, properties, required); err != nil {
+					return err
+				}
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		schema, err := typeSchema(field.Type, visiting)
+		if err != nil {
+			return err
+		}
+		if desc := strings.TrimSpace(field.Tag.Get("description")); desc != "" {
+			schema["description"] = desc
+		}
+		if enum, ok := field.Tag.Lookup("enum"); ok {
+			var values []any
+			for _, part := range strings.Split(enum, ",") {
+				v, err := tagValue(field.Type, strings.TrimSpace(part))
+				if err != nil {
+					return fmt.Errorf("schema for %s.%s: invalid enum: %w", t, field.Name, err)
+				}
+				values = append(values, v)
+			}
+			schema["enum"] = values
+		}
+		isRequired := !strings.Contains(","+opts+",", ",omitempty,")
+		if def, ok := field.Tag.Lookup("default"); ok {
+			v, err := tagValue(field.Type, def)
+			if err != nil {
+				return fmt.Errorf("schema for %s.%s: invalid default: %w", t, field.Name, err)
+			}
+			schema["default"] = v
+			isRequired = false
+		}
+		properties[name] = schema
+
+		if override, err := strconv.ParseBool(field.Tag.Get("required")); err == nil {
+			isRequired = override
+		}
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+	return nil
+}
+
+// tagValue parses a struct tag value into the JSON value of a field's type.
+func tagValue(t reflect.Type, raw string) (any, error) {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return raw, nil
+	case reflect.Bool:
+		return strconv.ParseBool(raw)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(raw, 64)
+	default:
+		var v any
+		if err := json.Unmarshal([]byte(raw), &v); err != nil {
+			return nil, fmt.Errorf("%q is not valid for %s", raw, t.Kind())
+		}
+		return v, nil
+	}
+}
@@ -0,0 +1,102 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+type pageArgs struct {
+	Limit  int    `json:"limit" default:"20"`
+	Cursor string `json:"cursor,omitempty"`
+}
+
+type searchArgs struct {
+	Query  string   `json:"query" description:"text to search for"`
+	Sort   string   `json:"sort" enum:"relevance,date" default:"relevance"`
+	Tags   []string `json:"tags,omitempty"`
+	Filter struct {
+		Owner string `json:"owner"`
+	} `json:"filter,omitempty"`
+	pageArgs
+	internal string
+}
+
+func TestSchemaOf(t *testing.T) {
+	schema, err := SchemaFor[searchArgs]()
+	if err != nil {
+		t.Fatalf("schema: %v", err)
+	}
+	props := schema["properties"].(map[string]any)
+	if len(props) != 6 {
+		t.Fatalf("expected 6 properties, got %v", props)
+	}
+	if q := props["query"].(map[string]any); q["description"] != "text to search for" {
+		t.Fatalf("unexpected query schema %v", q)
+	}
+	sort := props["sort"].(map[string]any)
+	if !reflect.DeepEqual(sort["enum"], []any{"relevance", "date"}) || sort["default"] != "relevance" {
+		t.Fatalf("unexpected sort schema %v", sort)
+	}
+	if limit := props["limit"].(map[string]any); limit["type"] != "integer" || limit["default"] != float64(20) {
+		t.Fatalf("expected the embedded limit field, got %v", limit)
+	}
+	filter := props["filter"].(map[string]any)
+	if filter["type"] != "object" || !reflect.DeepEqual(filter["required"], []any{"owner"}) {
+		t.Fatalf("unexpected nested schema %v", filter)
+	}
+	if !reflect.DeepEqual(schema["required"], []any{"query"}) {
+		t.Fatalf("unexpected required fields %v", schema["required"])
+	}
+
+	type node struct {
+		Children []node `json:"children"`
+	}
+	if _, err := SchemaFor[node](); err == nil {
+		t.Fatalf("expected recursive type error")
+	}
+	type badDefault struct {
+		N int `json:"n" default:"many"`
+	}
+	if _, err := SchemaFor[badDefault](); err == nil {
+		t.Fatalf("expected invalid default error")
+	}
+}
+
+func TestNewTypedTool(t *testing.T) {
+	var got searchArgs
+	tool, err := NewTypedTool("search", "search documents", func(ctx context.Context, args searchArgs) ([]string, error) {
+		_ = ctx
+		got = args
+		return []string{"doc-1"}, nil
+	})
+	if err != nil {
+		t.Fatalf("new typed tool: %v", err)
+	}
+	def := tool.Definition()
+	if def.Name != "search" || def.JSONSchema["type"] != "object" {
+		t.Fatalf("unexpected definition %+v", def)
+	}
+
+	out, err := tool.Execute(context.Background(), json.RawMessage(`{"query":"logs","filter":{"owner":"ops"}}`))
+	if err != nil {
+		t.Fatalf("execute: %v", err)
+	}
+	if !reflect.DeepEqual(out, []string{"doc-1"}) {
+		t.Fatalf("unexpected result %v", out)
+	}
+	if got.Query != "logs" || got.Sort != "relevance" || got.Limit != 20 || got.Filter.Owner != "ops" {
+		t.Fatalf("expected decoded args with defaults, got %+v", got)
+	}
+
+	_, err = tool.Execute(context.Background(), json.RawMessage(`{"sort":"size"}`))
+	if err == nil || !strings.Contains(err.Error(), "$.query: required field missing") || !strings.Contains(err.Error(), "$.sort: value not in enum") {
+		t.Fatalf("expected validation errors, got %v", err)
+	}
+
+	if _, err := NewTypedTool("bad", "bad", func(ctx context.Context, args string) (string, error) { return args, nil }); err == nil {
+		t.Fatalf("expected non-struct arguments to fail")
+	}
+}
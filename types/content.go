@@ -0,0 +1,97 @@
+package types
+
+import (
+	"encoding/base64"
+	"strings"
+)
+
+// ContentPartType identifies the modality of a ContentPart.
+type ContentPartType string
+
+const (
+	ContentPartText  ContentPartType = "text"
+	ContentPartImage ContentPartType = "image"
+	ContentPartFile  ContentPartType = "file"
+	ContentPartAudio ContentPartType = "audio"
+)
+
+// ContentPart is one piece of multimodal message content. Binary payloads are
+// supplied inline through Data or by reference through URL; providers that
+// only accept one form convert where possible.
+type ContentPart struct {
+	Type     ContentPartType `json:"type"`
+	Text     string          `json:"text,omitempty"`
+	Data     []byte          `json:"data,omitempty"`
+	URL      string          `json:"url,omitempty"`
+	MIMEType string          `json:"mimeType,omitempty"`
+	Filename string          `json:"filename,omitempty"`
+}
+
+// TextPart returns a text content part.
+func TextPart(text string) ContentPart {
+	return ContentPart{Type: ContentPartText, Text: text}
+}
+
+// ImagePart returns an inline image content part.
+func ImagePart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartImage, Data: data, MIMEType: mimeType}
+}
+
+// ImageURLPart returns an image content part referenced by URL.
+func ImageURLPart(url string) ContentPart {
+	return ContentPart{Type: ContentPartImage, URL: url}
+}
+
+// FilePart returns an inline file or document content part, such as a PDF.
+func FilePart(data []byte, mimeType, filename string) ContentPart {
+	return ContentPart{Type: ContentPartFile, Data: data, MIMEType: mimeType, Filename: filename}
+}
+
+// AudioPart returns an inline audio content part.
+func AudioPart(data []byte, mimeType string) ContentPart {
+	return ContentPart{Type: ContentPartAudio, Data: data, MIMEType: mimeType}
+}
+
+// Base64 returns the part's inline data encoded as standard base64.
+func (p ContentPart) Base64() string {
+	return base64.StdEncoding.EncodeToString(p.Data)
+}
+
+// DataURL returns the part as a data: URL, or URL when the part has no inline
+// data.
+func (p ContentPart) DataURL() string {
+	if len(p.Data) == 0 {
+		return p.URL
+	}
+	mimeType := p.MIMEType
+	if mimeType == "" {
+		mimeType = "application/octet-stream"
+	}
+	return "data:" + mimeType + ";base64," + p.Base64()
+}
+
+// IsTextual reports whether the part's inline data is human-readable text
+// that can be inlined into a prompt when a provider lacks native support.
+func (p ContentPart) IsTextual() bool {
+	mimeType := strings.ToLower(p.MIMEType)
+	return strings.HasPrefix(mimeType, "text/") ||
+		strings.HasSuffix(mimeType, "json") ||
+		strings.HasSuffix(mimeType, "xml") ||
+		strings.HasSuffix(mimeType, "yaml")
+}
+
+// ContentParts returns the message content as parts, with Content (if any)
+// as a leading text part.
+func (m Message) ContentParts() []ContentPart {
+	if len(m.Parts) == 0 {
+		if m.Content == "" {
+			return nil
+		}
+		return []ContentPart{TextPart(m.Content)}
+	}
+	out := make([]ContentPart, 0, len(m.Parts)+1)
+	if m.Content != "" {
+		out = append(out, TextPart(m.Content))
+	}
+	return append(out, m.Parts...)
+}
@@ -5,15 +5,25 @@ import "time"
 type EventType string
 
 const (
-	EventRunStarted         EventType = "run.started"
-	EventBeforeGenerate     EventType = "run.before_generate"
-	EventAfterGenerate      EventType = "run.after_generate"
-	EventBeforeTool         EventType = "run.before_tool"
-	EventAfterTool          EventType = "run.after_tool"
-	EventGraphNodeStarted   EventType = "graph.node.started"
-	EventGraphNodeCompleted EventType = "graph.node.completed"
-	EventRunCompleted       EventType = "run.completed"
-	EventRunFailed          EventType = "run.failed"
+	EventRunStarted           EventType = "run.started"
+	EventBeforeGenerate       EventType = "run.before_generate"
+	EventAfterGenerate        EventType = "run.after_generate"
+	EventBeforeTool           EventType = "run.before_tool"
+	EventAfterTool            EventType = "run.after_tool"
+	EventToolValidationFailed EventType = "run.tool_validation_failed"
+	EventApprovalRequested    EventType = "run.approval_requested"
+	EventApprovalResolved     EventType = "run.approval_resolved"
+	EventRunPaused            EventType = "run.paused"
+	EventRunResumed           EventType = "run.resumed"
+	EventRunSteered           EventType = "run.steered"
+	EventRunCanceled          EventType = "run.canceled"
+	EventRunInterrupted       EventType = "run.interrupted"
+	EventGraphNodeStarted     EventType = "graph.node.started"
+	EventGraphNodeCompleted   EventType = "graph.node.completed"
+	EventGraphNodeRetrying    EventType = "graph.node.retrying"
+	EventGraphNodeFailed      EventType = "graph.node.failed"
+	EventRunCompleted         EventType = "run.completed"
+	EventRunFailed            EventType = "run.failed"
 )
 
 type Event struct {
@@ -27,4 +37,6 @@ type Event struct {
 	ToolCallID string    `json:"toolCallId,omitempty"`
 	Message    string    `json:"message,omitempty"`
 	Error      string    `json:"error,omitempty"`
+	Model      string    `json:"model,omitempty"`
+	Usage      *Usage    `json:"usage,omitempty"`
 }
@@ -0,0 +1,74 @@
+package types
+
+// StreamEventType identifies the kind of payload carried by a StreamChunk.
+type StreamEventType string
+
+const (
+	StreamEventText          StreamEventType = "text"
+	StreamEventReasoning     StreamEventType = "reasoning"
+	StreamEventToolCallDelta StreamEventType = "tool_call_delta"
+	StreamEventToolCall      StreamEventType = "tool_call"
+	StreamEventToolResult    StreamEventType = "tool_result"
+	StreamEventUsage         StreamEventType = "usage"
+	StreamEventError         StreamEventType = "error"
+	StreamEventDone          StreamEventType = "done"
+)
+
+// StreamChunk is a single typed event in a streamed generation or run.
+// Providers emit text, reasoning, tool-call argument deltas and usage while
+// generating; the agent loop additionally reports tool calls as they start
+// (ToolCall) and finish (ToolResult), errors, and attaches the final Result to
+// the terminal Done chunk.
+//
+// Type may be left empty by emitters that predate typed events; Kind infers
+// it from the populated fields.
+type StreamChunk struct {
+	Type          StreamEventType `json:"type,omitempty"`
+	Text          string          `json:"text,omitempty"`
+	Reasoning     string          `json:"reasoning,omitempty"`
+	ToolCallDelta *ToolCallDelta  `json:"toolCallDelta,omitempty"`
+	ToolCall      *ToolCall       `json:"toolCall,omitempty"`
+	ToolResult    *Message        `json:"toolResult,omitempty"`
+	Usage         *Usage          `json:"usage,omitempty"`
+	Error         string          `json:"error,omitempty"`
+	Result        *RunResult      `json:"result,omitempty"`
+	Done          bool            `json:"done,omitempty"`
+}
+
+// ToolCallDelta is an incremental fragment of a tool call being generated.
+// Fragments sharing an Index belong to the same call; ID and Name are usually
+// only present on the first fragment, and ArgumentsDelta must be concatenated
+// to obtain the complete JSON arguments.
+type ToolCallDelta struct {
+	Index          int    `json:"index"`
+	ID             string `json:"id,omitempty"`
+	Name           string `json:"name,omitempty"`
+	ArgumentsDelta string `json:"argumentsDelta,omitempty"`
+}
+
+// Kind returns the chunk's event type, inferring it from the populated
+// fields when Type is unset.
+func (c StreamChunk) Kind() StreamEventType {
+	switch {
+	case c.Type != "":
+		return c.Type
+	case c.Error != "":
+		return StreamEventError
+	case c.ToolResult != nil:
+		return StreamEventToolResult
+	case c.ToolCall != nil:
+		return StreamEventToolCall
+	case c.ToolCallDelta != nil:
+		return StreamEventToolCallDelta
+	case c.Reasoning != "":
+		return StreamEventReasoning
+	case c.Text != "":
+		return StreamEventText
+	case c.Usage != nil:
+		return StreamEventUsage
+	case c.Done:
+		return StreamEventDone
+	default:
+		return ""
+	}
+}
@@ -14,12 +14,13 @@ const (
 )
 
 type Message struct {
-	Role       Role       `json:"role"`
-	Content    string     `json:"content,omitempty"`
-	Reasoning  string     `json:"reasoning,omitempty"`
-	Name       string     `json:"name,omitempty"` // Tool name for tool role messages.
-	ToolCallID string     `json:"toolCallId,omitempty"`
-	ToolCalls  []ToolCall `json:"toolCalls,omitempty"`
+	Role       Role          `json:"role"`
+	Content    string        `json:"content,omitempty"`
+	Parts      []ContentPart `json:"parts,omitempty"` // Multimodal content; Content, if set, is sent first as text.
+	Reasoning  string        `json:"reasoning,omitempty"`
+	Name       string        `json:"name,omitempty"` // Tool name for tool role messages.
+	ToolCallID string        `json:"toolCallId,omitempty"`
+	ToolCalls  []ToolCall    `json:"toolCalls,omitempty"`
 }
 
 type ToolCall struct {
@@ -41,22 +42,59 @@ type Request struct {
 	Tools           []ToolDefinition `json:"tools,omitempty"`
 	MaxOutputTokens int              `json:"maxOutputTokens,omitempty"`
 	ResponseSchema  map[string]any   `json:"responseSchema,omitempty"`
+
+	// Sampling and generation controls. Nil or empty values leave the
+	// provider's own default in place.
+	Temperature       *float64    `json:"temperature,omitempty"`
+	TopP              *float64    `json:"topP,omitempty"`
+	TopK              *int        `json:"topK,omitempty"`
+	StopSequences     []string    `json:"stopSequences,omitempty"`
+	Seed              *int64      `json:"seed,omitempty"`
+	PresencePenalty   *float64    `json:"presencePenalty,omitempty"`
+	FrequencyPenalty  *float64    `json:"frequencyPenalty,omitempty"`
+	ToolChoice        *ToolChoice `json:"toolChoice,omitempty"`
+	ParallelToolCalls *bool       `json:"parallelToolCalls,omitempty"`
+
+	// NoCache asks response caches to skip this request in both directions.
+	NoCache bool `json:"noCache,omitempty"`
+}
+
+type ToolChoiceMode string
+
+const (
+	ToolChoiceAuto     ToolChoiceMode = "auto"
+	ToolChoiceRequired ToolChoiceMode = "required"
+	ToolChoiceNone     ToolChoiceMode = "none"
+	ToolChoiceTool     ToolChoiceMode = "tool"
+)
+
+// ToolChoice controls whether and which tools the model may call. Name is
+// only used with ToolChoiceTool.
+type ToolChoice struct {
+	Mode ToolChoiceMode `json:"mode"`
+	Name string         `json:"name,omitempty"`
 }
 
 type Usage struct {
 	InputTokens  int `json:"inputTokens,omitempty"`
 	OutputTokens int `json:"outputTokens,omitempty"`
 	TotalTokens  int `json:"totalTokens,omitempty"`
+	// CostUSD is the spend for these tokens. Providers that bill per request
+	// may report it directly; otherwise the agent prices it from a table.
+	CostUSD float64 `json:"costUsd,omitempty"`
 }
 
 type Response struct {
 	Message Message `json:"message"`
 	Usage   *Usage  `json:"usage,omitempty"`
-}
-
-type StreamChunk struct {
-	Text string `json:"text,omitempty"`
-	Done bool   `json:"done,omitempty"`
+	// Provider names the backend that served the response when it differs
+	// from the provider the request was sent to, e.g. behind a router.
+	Provider string `json:"provider,omitempty"`
+	// Model is the model that produced the response.
+	Model string `json:"model,omitempty"`
+	// Cached is set when the response was served from a response cache
+	// rather than generated, so no tokens were spent on it.
+	Cached bool `json:"cached,omitempty"`
 }
 
 type RunResult struct {
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/PipeOpsHQ/agent-sdk-go/graph"
 	"github.com/PipeOpsHQ/agent-sdk-go/state"
@@ -34,12 +35,29 @@ type FileNodeSpec struct {
 	CheckKey     string `json:"checkKey,omitempty"`
 	ExistsValue  string `json:"existsValue,omitempty"`
 	MissingValue string `json:"missingValue,omitempty"`
+
+	// Workflow, Inputs and Outputs configure "subgraph" nodes, which run the
+	// registered workflow Workflow as a child run.
+	Workflow string            `json:"workflow,omitempty"`
+	Inputs   map[string]string `json:"inputs,omitempty"`
+	Outputs  map[string]string `json:"outputs,omitempty"`
+
+	Retry           *FileRetrySpec `json:"retry,omitempty"`
+	TimeoutMs       int            `json:"timeoutMs,omitempty"`
+	ContinueOnError bool           `json:"continueOnError,omitempty"`
+}
+
+type FileRetrySpec struct {
+	MaxAttempts int `json:"maxAttempts"`
+	BackoffMs   int `json:"backoffMs,omitempty"`
 }
 
 type FileEdgeSpec struct {
 	From string        `json:"from"`
 	To   string        `json:"to"`
 	When *FileEdgeWhen `json:"when,omitempty"`
+	// OnError makes the edge the one taken when From fails.
+	OnError bool `json:"onError,omitempty"`
 }
 
 type FileEdgeWhen struct {
@@ -108,7 +126,9 @@ func normalizeFileSpec(spec FileSpec, source string) (FileSpec, error) {
 		spec.Nodes[i].CheckKey = strings.TrimSpace(spec.Nodes[i].CheckKey)
 		spec.Nodes[i].ExistsValue = strings.TrimSpace(spec.Nodes[i].ExistsValue)
 		spec.Nodes[i].MissingValue = strings.TrimSpace(spec.Nodes[i].MissingValue)
+		spec.Nodes[i].Workflow = strings.TrimSpace(spec.Nodes[i].Workflow)
 	}
+	errorEdges := map[string]bool{}
 	for i := range spec.Edges {
 		spec.Edges[i].From = strings.TrimSpace(spec.Edges[i].From)
 		spec.Edges[i].To = strings.TrimSpace(spec.Edges[i].To)
@@ -116,6 +136,15 @@ func normalizeFileSpec(spec FileSpec, source string) (FileSpec, error) {
 			spec.Edges[i].When.Key = strings.TrimSpace(spec.Edges[i].When.Key)
 			spec.Edges[i].When.Equals = strings.TrimSpace(spec.Edges[i].When.Equals)
 		}
+		if spec.Edges[i].OnError {
+			if spec.Edges[i].When != nil {
+				return FileSpec{}, fmt.Errorf("workflow %q error edge from %q cannot have a condition", strings.TrimSpace(source), spec.Edges[i].From)
+			}
+			if errorEdges[spec.Edges[i].From] {
+				return FileSpec{}, fmt.Errorf("workflow %q node %q has more than one error edge", strings.TrimSpace(source), spec.Edges[i].From)
+			}
+			errorEdges[spec.Edges[i].From] = true
+		}
 	}
 	return spec, nil
 }
@@ -135,6 +164,12 @@ func (b *fileBuilder) Description() string {
 }
 
 func (b *fileBuilder) NewExecutor(runner graph.AgentRunner, store state.Store, sessionID string) (*graph.Executor, error) {
+	return b.newExecutor(runner, store, sessionID, nil)
+}
+
+// newExecutor builds the executor; building lists the workflows whose
+// subgraph nodes are being built, which this one must not embed.
+func (b *fileBuilder) newExecutor(runner graph.AgentRunner, store state.Store, sessionID string, building []string) (*graph.Executor, error) {
 	if b == nil {
 		return nil, fmt.Errorf("file builder is nil")
 	}
@@ -146,16 +181,26 @@ func (b *fileBuilder) NewExecutor(runner graph.AgentRunner, store state.Store, s
 	if b.spec.AllowCycles {
 		g.AllowCycles(true)
 	}
+	errorEdges := map[string]string{}
+	for _, edgeSpec := range b.spec.Edges {
+		if edgeSpec.OnError {
+			errorEdges[edgeSpec.From] = edgeSpec.To
+		}
+	}
+	building = append(append([]string(nil), building...), b.Name())
 	for _, nodeSpec := range b.spec.Nodes {
-		node, err := buildNodeFromSpec(nodeSpec, runner)
+		node, err := buildNodeFromSpec(nodeSpec, runner, building)
 		if err != nil {
 			return nil, fmt.Errorf("node %q: %w", nodeSpec.ID, err)
 		}
-		g.AddNode(nodeSpec.ID, node)
+		g.AddNode(nodeSpec.ID, node, nodeOptionsFromSpec(nodeSpec, errorEdges[nodeSpec.ID])...)
 	}
 	g.SetStart(b.spec.Start)
 
 	for _, edgeSpec := range b.spec.Edges {
+		if edgeSpec.OnError {
+			continue
+		}
 		var condition graph.Condition
 		if edgeSpec.When != nil {
 			when := *edgeSpec.When
@@ -175,7 +220,24 @@ func (b *fileBuilder) NewExecutor(runner graph.AgentRunner, store state.Store, s
 	return graph.NewExecutor(g, opts...)
 }
 
-func buildNodeFromSpec(spec FileNodeSpec, runner graph.AgentRunner) (graph.Node, error) {
+func nodeOptionsFromSpec(spec FileNodeSpec, errorEdge string) []graph.NodeOption {
+	var opts []graph.NodeOption
+	if spec.Retry != nil {
+		opts = append(opts, graph.WithRetry(spec.Retry.MaxAttempts, time.Duration(spec.Retry.BackoffMs)*time.Millisecond))
+	}
+	if spec.TimeoutMs > 0 {
+		opts = append(opts, graph.WithTimeout(time.Duration(spec.TimeoutMs)*time.Millisecond))
+	}
+	if spec.ContinueOnError {
+		opts = append(opts, graph.WithContinueOnError())
+	}
+	if errorEdge != "" {
+		opts = append(opts, graph.WithErrorEdge(errorEdge))
+	}
+	return opts
+}
+
+func buildNodeFromSpec(spec FileNodeSpec, runner graph.AgentRunner, building []string) (graph.Node, error) {
 	spec.ID = strings.TrimSpace(spec.ID)
 	spec.Kind = strings.TrimSpace(spec.Kind)
 	if spec.ID == "" {
@@ -262,6 +324,49 @@ func buildNodeFromSpec(spec FileNodeSpec, runner graph.AgentRunner) (graph.Node,
 			return nil
 		}), nil
 
+	case "subgraph":
+		if spec.Workflow == "" {
+			return nil, fmt.Errorf("subgraph node requires workflow")
+		}
+		for _, name := range building {
+			if name == spec.Workflow {
+				return nil, fmt.Errorf("subgraph workflow %q embeds itself (%s)", spec.Workflow, strings.Join(append(building, spec.Workflow), " -> "))
+			}
+		}
+		builder, ok := Get(spec.Workflow)
+		if !ok {
+			return nil, fmt.Errorf("unknown subgraph workflow %q", spec.Workflow)
+		}
+		var exec *graph.Executor
+		var err error
+		if fb, ok := builder.(*fileBuilder); ok {
+			exec, err = fb.newExecutor(runner, nil, "", building)
+		} else {
+			exec, err = builder.NewExecutor(runner, nil, "")
+		}
+		if err != nil {
+			return nil, fmt.Errorf("subgraph workflow %q: %w", spec.Workflow, err)
+		}
+		return &graph.SubgraphNode{
+			Graph:     exec.Graph(),
+			InputKey:  spec.InputFrom,
+			Inputs:    spec.Inputs,
+			OutputKey: spec.OutputKey,
+			Outputs:   spec.Outputs,
+		}, nil
+
+	case "interrupt":
+		tpl := spec.Template
+		value := spec.Value
+		return &graph.InterruptNode{
+			PromptFunc: func(s *graph.State) string {
+				if strings.TrimSpace(tpl) != "" {
+					return renderTemplate(tpl, s)
+				}
+				return stringify(value)
+			},
+		}, nil
+
 	case "router_json_key":
 		checkKey := strings.TrimSpace(spec.CheckKey)
 		if checkKey == "" {
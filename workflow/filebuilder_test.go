@@ -2,11 +2,14 @@ package workflow
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
 
+	"github.com/PipeOpsHQ/agent-sdk-go/graph"
+	"github.com/PipeOpsHQ/agent-sdk-go/state/sqlite"
 	"github.com/PipeOpsHQ/agent-sdk-go/types"
 )
 
@@ -103,3 +106,164 @@ func TestNewFileBuilderFromPath_ConditionalEdge(t *testing.T) {
 		t.Fatalf("unexpected logs output: %q", resLogs.Output)
 	}
 }
+
+type flakyRunner struct {
+	failures int
+	calls    int
+}
+
+func (r *flakyRunner) RunDetailed(ctx context.Context, input string) (types.RunResult, error) {
+	_ = ctx
+	r.calls++
+	if r.calls <= r.failures {
+		return types.RunResult{}, errors.New("provider unavailable")
+	}
+	return types.RunResult{Output: "runner:" + input}, nil
+}
+
+func TestNewFileBuilder_NodePolicies(t *testing.T) {
+	spec := FileSpec{
+		Name:  "json-policies",
+		Start: "ask",
+		Nodes: []FileNodeSpec{
+			{ID: "ask", Kind: "agent", OutputKey: "answer", Retry: &FileRetrySpec{MaxAttempts: 2}, TimeoutMs: 1000},
+			{ID: "end", Kind: "output", From: "answer"},
+			{ID: "fallback", Kind: "output", Template: "fallback: {{data.error}}"},
+		},
+		Edges: []FileEdgeSpec{
+			{From: "ask", To: "end"},
+			{From: "ask", To: "fallback", OnError: true},
+		},
+	}
+	builder, err := NewFileBuilder(spec)
+	if err != nil {
+		t.Fatalf("NewFileBuilder failed: %v", err)
+	}
+
+	retried := &flakyRunner{failures: 1}
+	exec, err := builder.NewExecutor(retried, nil, "")
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+	res, err := exec.Run(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if res.Output != "runner:hello" || retried.calls != 2 {
+		t.Fatalf("expected a retry to succeed, got %q after %d calls", res.Output, retried.calls)
+	}
+
+	exec, err = builder.NewExecutor(&flakyRunner{failures: 2}, nil, "")
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+	res, err = exec.Run(context.Background(), "hello")
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if strings.Join(res.NodeTrace, ",") != "ask,fallback" || !strings.Contains(res.Output, "provider unavailable") {
+		t.Fatalf("expected the error edge to be taken, got %q via %v", res.Output, res.NodeTrace)
+	}
+
+	spec.Edges = append(spec.Edges, FileEdgeSpec{From: "ask", To: "end", OnError: true})
+	if _, err := NewFileBuilder(spec); err == nil {
+		t.Fatalf("expected an error for two error edges")
+	}
+}
+
+func TestNewFileBuilder_InterruptNode(t *testing.T) {
+	builder, err := NewFileBuilder(FileSpec{
+		Name:  "json-interrupt",
+		Start: "ask",
+		Nodes: []FileNodeSpec{
+			{ID: "ask", Kind: "interrupt", Template: "Which environment for {{input}}?"},
+			{ID: "end", Kind: "output", Template: "deploying {{input}} to {{data.env}}"},
+		},
+		Edges: []FileEdgeSpec{{From: "ask", To: "end"}},
+	})
+	if err != nil {
+		t.Fatalf("NewFileBuilder failed: %v", err)
+	}
+	store, err := sqlite.New(filepath.Join(t.TempDir(), "state.db"))
+	if err != nil {
+		t.Fatalf("sqlite.New failed: %v", err)
+	}
+	defer store.Close()
+	exec, err := builder.NewExecutor(fakeRunner{}, store, "")
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+	_, err = exec.Run(context.Background(), "api")
+	var interrupted *graph.InterruptedError
+	if !errors.As(err, &interrupted) || interrupted.Interrupt.Prompt != "Which environment for api?" {
+		t.Fatalf("expected an interrupt with the rendered prompt, got %v", err)
+	}
+	res, err := exec.ResumeWithInput(context.Background(), interrupted.RunID, map[string]any{"env": "staging"})
+	if err != nil {
+		t.Fatalf("ResumeWithInput failed: %v", err)
+	}
+	if res.Output != "deploying api to staging" {
+		t.Fatalf("unexpected output: %q", res.Output)
+	}
+}
+
+func TestNewFileBuilder_SubgraphNode(t *testing.T) {
+	registerTestSpec(t, FileSpec{
+		Name:  "test-subgraph-child",
+		Start: "greet",
+		Nodes: []FileNodeSpec{
+			{ID: "greet", Kind: "template", Template: "hello {{input}} from {{data.team}}", OutputKey: "greeting"},
+			{ID: "end", Kind: "output", From: "greeting"},
+		},
+		Edges: []FileEdgeSpec{{From: "greet", To: "end"}},
+	})
+	builder, err := NewFileBuilder(FileSpec{
+		Name:  "test-subgraph-parent",
+		Start: "setup",
+		Nodes: []FileNodeSpec{
+			{ID: "setup", Kind: "set", Key: "who", Value: "ops"},
+			{ID: "child", Kind: "subgraph", Workflow: "test-subgraph-child", InputFrom: "who",
+				Inputs: map[string]string{"who": "team"}, Outputs: map[string]string{"greeting": "childGreeting"}},
+			{ID: "end", Kind: "output", Template: "parent got {{data.childGreeting}}"},
+		},
+		Edges: []FileEdgeSpec{{From: "setup", To: "child"}, {From: "child", To: "end"}},
+	})
+	if err != nil {
+		t.Fatalf("NewFileBuilder failed: %v", err)
+	}
+	exec, err := builder.NewExecutor(fakeRunner{}, nil, "")
+	if err != nil {
+		t.Fatalf("NewExecutor failed: %v", err)
+	}
+	res, err := exec.Run(context.Background(), "ignored")
+	if err != nil {
+		t.Fatalf("run failed: %v", err)
+	}
+	if res.Output != "parent got hello ops from ops" {
+		t.Fatalf("unexpected output: %q", res.Output)
+	}
+
+	registerTestSpec(t, FileSpec{
+		Name:  "test-subgraph-loop",
+		Start: "again",
+		Nodes: []FileNodeSpec{{ID: "again", Kind: "subgraph", Workflow: "test-subgraph-loop"}},
+	})
+	loop, _ := Get("test-subgraph-loop")
+	if _, err := loop.NewExecutor(fakeRunner{}, nil, ""); err == nil || !strings.Contains(err.Error(), "embeds itself") {
+		t.Fatalf("expected an error for a workflow embedding itself, got %v", err)
+	}
+}
+
+func registerTestSpec(t *testing.T, spec FileSpec) {
+	t.Helper()
+	if _, ok := Get(spec.Name); ok {
+		return
+	}
+	builder, err := NewFileBuilder(spec)
+	if err != nil {
+		t.Fatalf("NewFileBuilder failed: %v", err)
+	}
+	if err := Register(builder); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+}